@@ -1,20 +1,14 @@
 package main
 
 import (
-	"context"
+	"flag"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"strings"
 
 	"devices-api/internal/config"
-	httphandler "devices-api/internal/handler/http"
-	"devices-api/internal/repository"
-	"devices-api/internal/service"
-	"devices-api/pkg/database"
+	"devices-api/pkg/logging"
 )
 
 //	@title						Devices API
@@ -30,71 +24,79 @@ import (
 //	@BasePath					/api/v1
 //	@schemes					http https
 
+//	@securityDefinitions.apikey	DeviceToken
+//	@in							header
+//	@name						Authorization
+//	@description				Heartbeat token of the device, as "Bearer <token>"
+
+//	@securityDefinitions.apikey	AdminToken
+//	@in							header
+//	@name						Authorization
+//	@description				Operator token configured as admin.token, as "Bearer <token>"
+
+const usage = `Usage: devices-api [command] [flags]
+
+Commands:
+  serve                  Run the HTTP server (default)
+  migrate up             Apply all pending migrations
+  migrate down [N]       Revert the last N migrations (default 1)
+  migrate status         Show the current schema version
+  migrate goto N         Migrate up or down to version N
+  config print           Print the effective configuration (secrets redacted)
+
+Every command accepts -config PATH (or CONFIG_PATH) to read a YAML config file.
+Environment variables override values from the file.
+
+Run 'devices-api serve --help' for server flags.
+`
+
 func main() {
-	// 0. Setup Logger (slog)
+	// Setup bootstrap Logger (slog); commands replace it once config is loaded
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
-	// 1. Setup graceful shutdown handling
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
-
-	logger.Info("Starting Devices API...")
-
-	// 2. Load Configuration
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		logger.Error("Failed to load config", "error", err)
+	if err := run(os.Args[1:], logger); err != nil {
+		logger.Error("Command failed", "error", err)
 		os.Exit(1)
 	}
-	logger.Info("Config loaded", "http_port", cfg.Server.HTTPPort, "grpc_port", cfg.Server.GRPCPort)
+}
 
-	// 3. Initialize Database Connection Pool
-	logger.Info("Connecting to database...")
-	dbPool, err := database.NewPostgresPool(ctx, cfg.Database.URL)
-	if err != nil {
-		logger.Error("Failed to connect to database", "error", err)
-		os.Exit(1)
-	}
-	defer dbPool.Close()
-	logger.Info("Database connection established")
-
-	// 4. Initialize Layers (Dependency Injection)
-	deviceRepo := repository.NewPostgresDeviceRepository(dbPool)
-	deviceService := service.NewDeviceService(deviceRepo)
-
-	// 5. Setup HTTP Server
-	router := httphandler.SetupRouter(deviceService)
-	httpServer := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Server.HTTPPort),
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+// run dispatches to the requested subcommand
+func run(args []string, logger *slog.Logger) error {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
 
-	// 6. Start HTTP Server in a goroutine
-	go func() {
-		logger.Info("Starting HTTP server", "port", cfg.Server.HTTPPort)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Error("HTTP server failed", "error", err)
-			os.Exit(1)
-		}
-	}()
-
-	logger.Info("Server is running. Press Ctrl+C to stop.")
-
-	// 7. Wait for termination signal
-	<-ctx.Done()
-	logger.Info("Shutdown signal received. Initiating graceful shutdown...")
+	switch command {
+	case "serve":
+		return serve(args, logger)
+	case "migrate":
+		return runMigrate(args, logger)
+	case "config":
+		return runConfig(args)
+	case "help":
+		fmt.Print(usage)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
 
-	// 8. Graceful shutdown with timeout
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// configFlag registers the -config flag, defaulting to CONFIG_PATH
+func configFlag(flags *flag.FlagSet) *string {
+	return flags.String("config", os.Getenv("CONFIG_PATH"), "path to a YAML config file (env: CONFIG_PATH)")
+}
 
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		logger.Error("HTTP server shutdown error", "error", err)
+// loadConfig loads and validates the configuration and installs the configured logger
+func loadConfig(path string) (*config.Config, *slog.Logger, error) {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	logger.Info("Server stopped gracefully")
+	logger := logging.New(os.Stdout, cfg.Log.Level, cfg.Log.Format)
+	slog.SetDefault(logger)
+	return cfg, logger, nil
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"devices-api/internal/migrate"
+	"devices-api/migrations"
+	"devices-api/pkg/database"
+)
+
+// runMigrate executes the migrate subcommands: up, down [N], status, goto N
+func runMigrate(args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	configPath := configFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+	args = flags.Args()
+
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return errors.New("missing migrate subcommand")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, logger, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	dbPool, err := database.NewPostgresPool(ctx, cfg.Database.URL, database.WithPoolConfig(poolConfig(cfg.Database)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbPool.Close()
+
+	runner, err := migrate.NewRunner(dbPool, migrations.FS, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	switch subcommand, rest := args[0], args[1:]; subcommand {
+	case "up":
+		return runner.Up(ctx)
+	case "down":
+		steps := 1
+		if len(rest) > 0 {
+			if steps, err = strconv.Atoi(rest[0]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps: %s", rest[0])
+			}
+		}
+		return runner.Down(ctx, steps)
+	case "goto":
+		if len(rest) != 1 {
+			return errors.New("usage: migrate goto N")
+		}
+		version, err := strconv.ParseUint(rest[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version: %s", rest[0])
+		}
+		return runner.Goto(ctx, uint(version))
+	case "status":
+		status, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(status)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown migrate subcommand: %s", subcommand)
+	}
+}
+
+// printStatus writes a human-readable migration status table to stdout
+func printStatus(status migrate.Status) {
+	state := "clean"
+	if status.Dirty {
+		state = "dirty"
+	}
+	fmt.Printf("Current version: %d (%s)\n", status.Version, state)
+	fmt.Printf("Latest version:  %d\n\n", status.Latest)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, m := range status.Migrations {
+		fmt.Fprintf(w, "%06d\t%s\t%t\n", m.Version, m.Name, m.Applied)
+	}
+	_ = w.Flush()
+}
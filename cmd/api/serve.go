@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"devices-api/internal/config"
+	"devices-api/internal/domain"
+	"devices-api/internal/events"
+	httphandler "devices-api/internal/handler/http"
+	"devices-api/internal/health"
+	"devices-api/internal/migrate"
+	"devices-api/internal/repository"
+	"devices-api/internal/service"
+	"devices-api/migrations"
+	"devices-api/pkg/database"
+	"devices-api/pkg/telemetry"
+)
+
+// serve runs the HTTP server until a termination signal arrives
+func serve(args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	configPath := configFlag(flags)
+	migrateOnStart := flags.Bool("migrate-on-start", false, "apply pending migrations before serving (guarded by an advisory lock)")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	// 1. Setup graceful shutdown handling
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 2. Load Configuration
+	cfg, logger, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	logger.Info("Starting Devices API...")
+	logger.Info("Config loaded", "http_port", cfg.Server.HTTPPort, "grpc_port", cfg.Server.GRPCPort, "config_file", *configPath)
+
+	// 3. Setup Tracing (OpenTelemetry)
+	shutdownTracing, err := telemetry.SetupTracing(ctx, telemetry.TracingOptions{
+		ServiceName:  cfg.Tracing.ServiceName,
+		Exporter:     cfg.Tracing.Exporter,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		OTLPProtocol: cfg.Tracing.OTLPProtocol,
+		OTLPInsecure: cfg.Tracing.OTLPInsecure,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %w", err)
+	}
+	logger.Info("Tracing configured", "exporter", cfg.Tracing.Exporter)
+
+	// 4. Initialize Database Connection Pool
+	logger.Info("Connecting to database...")
+	dbPool, err := database.NewPostgresPool(ctx, cfg.Database.URL,
+		database.WithPoolConfig(poolConfig(cfg.Database)),
+		database.WithQueryTracer(database.NewQueryTracer()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer dbPool.Close()
+	logger.Info("Database connection established")
+
+	// The replica is checked in the background; until it passes a check, reads use the primary
+	var replica *database.Replica
+	if cfg.Database.ReplicaURL != "" {
+		replica, err = database.NewReplica(cfg.Database.ReplicaURL, cfg.Database.ReplicaMaxStaleness,
+			database.WithPoolConfig(poolConfig(cfg.Database)),
+			database.WithQueryTracer(database.NewQueryTracer()),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to configure read replica: %w", err)
+		}
+		defer replica.Close()
+		go replica.Run(ctx, cfg.Database.ReplicaCheckInterval, logger)
+		logger.Info("Read replica configured", "max_staleness", cfg.Database.ReplicaMaxStaleness)
+	}
+
+	// 5. Apply Migrations (optional)
+	migrator, err := migrate.NewRunner(dbPool, migrations.FS, logger)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	if *migrateOnStart {
+		logger.Info("Applying migrations...")
+		if err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	}
+
+	// 6. Initialize Layers (Dependency Injection)
+	deviceRepo := repository.NewPostgresDeviceRepository(dbPool, repository.WithReadReplica(replica))
+	locationRepo := repository.NewPostgresLocationRepository(dbPool)
+	brandRepo := repository.NewPostgresBrandRepository(dbPool)
+	modelRepo := repository.NewPostgresModelRepository(dbPool)
+	expiryRepo := repository.NewPostgresExpiryRepository(dbPool)
+	var maintenanceRepo domain.MaintenanceRepository = repository.NewPostgresMaintenanceRepository(dbPool)
+	var heartbeatRepo domain.HeartbeatRepository = repository.NewPostgresHeartbeatRepository(dbPool)
+	telemetryRepo := repository.NewPostgresTelemetryRepository(dbPool)
+	// Device reads go through the cache; every repository writing devices drops the cached copy
+	var cachedDeviceRepo domain.DeviceRepository = deviceRepo
+	var deviceCache domain.DeviceCache
+	if cfg.Cache.DeviceSize > 0 {
+		cached := repository.NewCachedDeviceRepository(deviceRepo, cfg.Cache.DeviceSize, cfg.Cache.DeviceTTL)
+		cachedDeviceRepo, deviceCache = cached, cached
+		maintenanceRepo = repository.NewInvalidatingMaintenanceRepository(maintenanceRepo, cached)
+		heartbeatRepo = repository.NewInvalidatingHeartbeatRepository(heartbeatRepo, cached)
+		logger.Info("Device cache enabled", "size", cfg.Cache.DeviceSize, "ttl", cfg.Cache.DeviceTTL)
+	}
+	txManager := repository.NewPostgresTxManager(dbPool)
+	deviceService := service.NewDeviceService(cachedDeviceRepo,
+		service.WithPagination(cfg.Pagination.DefaultLimit, cfg.Pagination.MaxLimit),
+		service.WithLocationRepository(locationRepo),
+		service.WithBrandRepository(brandRepo),
+		service.WithModelRepository(modelRepo),
+		service.WithTxManager(txManager),
+	)
+	locationService := service.NewLocationService(locationRepo)
+	brandService := service.NewBrandService(brandRepo)
+	modelService := service.NewModelService(modelRepo)
+	reportService := service.NewReportService(expiryRepo)
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo, cachedDeviceRepo, txManager, cfg.Maintenance.Intervals())
+	heartbeatService := service.NewHeartbeatService(heartbeatRepo)
+	telemetryService := service.NewTelemetryService(telemetryRepo, cachedDeviceRepo, cfg.Telemetry.Retention, cfg.Telemetry.MaxBatch)
+
+	// 7. Setup Readiness Probe
+	probe := health.NewProbe(cfg.Server.ReadinessTimeout,
+		health.PostgresCheck(dbPool),
+		health.MigrationCheck(dbPool, migrator.Latest()),
+	)
+
+	// 8. Setup HTTP Server
+	router := httphandler.SetupRouter(deviceService,
+		httphandler.WithLogger(logger),
+		httphandler.WithReadinessProbe(probe),
+		httphandler.WithLocationService(locationService),
+		httphandler.WithBrandService(brandService),
+		httphandler.WithModelService(modelService),
+		httphandler.WithReportService(reportService),
+		httphandler.WithMaintenanceService(maintenanceService),
+		httphandler.WithHeartbeatService(heartbeatService),
+		httphandler.WithTelemetryService(telemetryService),
+		httphandler.WithDeviceCache(deviceCache),
+		httphandler.WithAdminToken(cfg.Admin.Token),
+		httphandler.WithGraphQLLimits(cfg.GraphQL.MaxDepth, cfg.GraphQL.MaxComplexity),
+	)
+	httpServer := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.Server.HTTPPort),
+		Handler:      router,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	// 9. Start HTTP Server in a goroutine
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Starting HTTP server", "port", cfg.Server.HTTPPort)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	// 10. Start background jobs; they stop when the shutdown signal cancels ctx
+	publisher := events.NewLogPublisher(logger)
+	if cfg.Expiry.CheckInterval > 0 {
+		monitor := service.NewExpiryMonitor(expiryRepo, publisher, cfg.Expiry.ThresholdDays, logger)
+		go monitor.Run(ctx, cfg.Expiry.CheckInterval)
+		logger.Info("Expiry monitor started", "interval", cfg.Expiry.CheckInterval, "threshold_days", cfg.Expiry.ThresholdDays)
+	}
+	if cfg.Heartbeat.CheckInterval > 0 {
+		monitor := service.NewStaleMonitor(heartbeatRepo, publisher, cfg.Heartbeat.StaleAfter, logger)
+		go monitor.Run(ctx, cfg.Heartbeat.CheckInterval)
+		logger.Info("Stale device monitor started", "interval", cfg.Heartbeat.CheckInterval, "stale_after", cfg.Heartbeat.StaleAfter)
+	}
+	if cfg.Telemetry.RetentionInterval > 0 {
+		pruner := service.NewTelemetryPruner(telemetryRepo, cfg.Telemetry.Retention, logger)
+		go pruner.Run(ctx, cfg.Telemetry.RetentionInterval)
+		logger.Info("Telemetry retention started", "interval", cfg.Telemetry.RetentionInterval, "retention", cfg.Telemetry.Retention)
+	}
+
+	logger.Info("Server is running. Press Ctrl+C to stop.")
+
+	// 11. Wait for termination signal
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("HTTP server failed: %w", err)
+	case <-ctx.Done():
+	}
+	logger.Info("Shutdown signal received. Initiating graceful shutdown...")
+
+	// Fail readiness first so load balancers drain traffic before the server stops
+	probe.StartDraining()
+	logger.Info("Readiness set to draining", "drain_delay", cfg.Server.ShutdownDrainDelay)
+	time.Sleep(cfg.Server.ShutdownDrainDelay)
+
+	// 12. Graceful shutdown with timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown error", "error", err)
+	}
+
+	// Flush pending spans after in-flight requests have completed
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Tracing shutdown error", "error", err)
+	}
+
+	logger.Info("Server stopped gracefully")
+	return nil
+}
+
+// poolConfig maps database settings onto the pool options
+func poolConfig(cfg config.DatabaseConfig) database.PoolConfig {
+	return database.PoolConfig{
+		MaxConns:          cfg.MaxConns,
+		MinConns:          cfg.MinConns,
+		MaxConnLifetime:   cfg.MaxConnLifetime,
+		MaxConnIdleTime:   cfg.MaxConnIdleTime,
+		HealthCheckPeriod: cfg.HealthCheckPeriod,
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultBaseURL = "http://localhost:8080"
+
+// Profile holds the connection settings for one API environment
+type Profile struct {
+	BaseURL string `yaml:"base_url"`
+	Token   string `yaml:"token,omitempty"`
+}
+
+// Config is the devicesctl configuration file
+type Config struct {
+	CurrentProfile string             `yaml:"current_profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/devicesctl/config.yaml (or the OS equivalent)
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "devicesctl.yaml"
+	}
+	return filepath.Join(dir, "devicesctl", "config.yaml")
+}
+
+// loadConfig reads the config file; a missing file yields an empty config
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]Profile{}}
+
+	// #nosec G304 - path is chosen by the user running the CLI
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]Profile{}
+	}
+	return cfg, nil
+}
+
+// save writes the config file with owner-only permissions since it may hold tokens
+func (c *Config) save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", path, err)
+	}
+	return nil
+}
+
+// profileNames returns the configured profile names in sorted order
+func (c *Config) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveProfile picks the active profile. An explicitly requested profile
+// must exist; otherwise the current profile or built-in defaults are used.
+func (c *Config) resolveProfile(name string) (Profile, error) {
+	if name != "" {
+		profile, ok := c.Profiles[name]
+		if !ok {
+			return Profile{}, usageErrorf("profile %q not found", name)
+		}
+		return profile, nil
+	}
+
+	if profile, ok := c.Profiles[c.CurrentProfile]; ok {
+		return profile, nil
+	}
+	return Profile{BaseURL: defaultBaseURL}, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func (a *app) newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage connection profiles",
+	}
+	cmd.AddCommand(
+		a.newConfigSetProfileCommand(),
+		a.newConfigUseProfileCommand(),
+		a.newConfigViewCommand(),
+	)
+	return cmd
+}
+
+func (a *app) newConfigSetProfileCommand() *cobra.Command {
+	var profile Profile
+
+	cmd := &cobra.Command{
+		Use:     "set-profile NAME",
+		Short:   "Create or update a profile",
+		Example: `  devicesctl config set-profile staging --base-url https://devices.staging.example.com --token $TOKEN`,
+		Args:    exactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(a.configPath)
+			if err != nil {
+				return err
+			}
+
+			existing := cfg.Profiles[args[0]]
+			if cmd.Flags().Changed("base-url") {
+				existing.BaseURL = profile.BaseURL
+			}
+			if cmd.Flags().Changed("token") {
+				existing.Token = profile.Token
+			}
+			if existing.BaseURL == "" {
+				existing.BaseURL = defaultBaseURL
+			}
+
+			cfg.Profiles[args[0]] = existing
+			if cfg.CurrentProfile == "" {
+				cfg.CurrentProfile = args[0]
+			}
+			if err := cfg.save(a.configPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Profile %q saved to %s\n", args[0], a.configPath)
+			return nil
+		},
+	}
+	// Local flags shadow the global --base-url/--token overrides on purpose
+	cmd.Flags().StringVar(&profile.BaseURL, "base-url", "", "API base URL")
+	cmd.Flags().StringVar(&profile.Token, "token", "", "bearer token")
+	return cmd
+}
+
+func (a *app) newConfigUseProfileCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "use-profile NAME",
+		Short:             "Set the current profile",
+		Args:              exactArgs(1),
+		ValidArgsFunction: a.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(a.configPath)
+			if err != nil {
+				return err
+			}
+			if _, ok := cfg.Profiles[args[0]]; !ok {
+				return usageErrorf("profile %q not found", args[0])
+			}
+
+			cfg.CurrentProfile = args[0]
+			if err := cfg.save(a.configPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Switched to profile %q\n", args[0])
+			return nil
+		},
+	}
+}
+
+func (a *app) newConfigViewCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "List profiles (tokens are redacted)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(a.configPath)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CURRENT\tNAME\tBASE_URL\tTOKEN")
+			for _, name := range cfg.profileNames() {
+				profile := cfg.Profiles[name]
+				current := ""
+				if name == cfg.CurrentProfile {
+					current = "*"
+				}
+				token := ""
+				if profile.Token != "" {
+					token = "<redacted>"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, name, profile.BaseURL, token)
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"devices-api/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+var deviceStates = []string{client.StateActive, client.StateInUse, client.StateInactive}
+
+// listFlags are the filters shared by list and watch
+type listFlags struct {
+	brand    string
+	state    string
+	limit    int
+	offset   int
+	all      bool
+	sort     string
+	attrs    []string
+	selector string
+	location string
+	model    string
+	category string
+}
+
+func (f *listFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.brand, "brand", "", "filter by brand")
+	cmd.Flags().StringVar(&f.state, "state", "", "filter by state (active, in-use, inactive, maintenance)")
+	cmd.Flags().IntVar(&f.limit, "limit", 0, "page size (default: server default)")
+	cmd.Flags().IntVar(&f.offset, "offset", 0, "number of devices to skip")
+	cmd.Flags().BoolVar(&f.all, "all", false, "fetch every page")
+	cmd.Flags().StringVar(&f.sort, "sort", "", "sort by name, brand, state, or created_at (prefix with - for descending)")
+	cmd.Flags().StringArrayVar(&f.attrs, "attr", nil, "filter by custom attribute, e.g. os=ios or ram_gb>=16 (repeatable)")
+	cmd.Flags().StringVarP(&f.selector, "selector", "l", "", "label selector, e.g. 'team=mobile,env in (lab,staging)'")
+	cmd.Flags().StringVar(&f.location, "location", "", "filter by location ID, including locations below it")
+	cmd.Flags().StringVar(&f.model, "model", "", "filter by catalog model ID")
+	cmd.Flags().StringVar(&f.category, "category", "", "filter by model category (laptop, phone, tablet, sensor)")
+	_ = cmd.RegisterFlagCompletionFunc("state", fixedCompletions(append(deviceStates, client.StateMaintenance)...))
+	_ = cmd.RegisterFlagCompletionFunc("category", fixedCompletions("laptop", "phone", "tablet", "sensor"))
+	_ = cmd.RegisterFlagCompletionFunc("sort", fixedCompletions(
+		"name", "-name", "brand", "-brand", "state", "-state", "created_at", "-created_at",
+	))
+}
+
+// fetch retrieves one page, or every page with --all, and applies --sort
+func (f *listFlags) fetch(ctx context.Context, c *client.Client) ([]client.Device, error) {
+	opts := client.ListOptions{
+		Limit:      f.limit,
+		Offset:     f.offset,
+		Brand:      f.brand,
+		State:      f.state,
+		Attributes: f.attrs,
+		Selector:   f.selector,
+		LocationID: f.location,
+		ModelID:    f.model,
+		Category:   f.category,
+	}
+
+	var devices []client.Device
+	if f.all {
+		for device, err := range c.Devices(ctx, opts) {
+			if err != nil {
+				return nil, err
+			}
+			devices = append(devices, device)
+		}
+	} else {
+		page, err := c.ListDevices(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		devices = page.Devices
+	}
+
+	if err := sortDevices(devices, f.sort); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (a *app) newListCommand() *cobra.Command {
+	var flags listFlags
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List devices",
+		Example: `  devicesctl list --brand Apple --state active
+  devicesctl list --attr os=ios --attr 'ram_gb>=16'
+  devicesctl list -l 'team=mobile,env notin (prod)'
+  devicesctl list --all --sort -created_at -o csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := a.callContext(cmd)
+			defer cancel()
+
+			devices, err := flags.fetch(ctx, c)
+			if err != nil {
+				return err
+			}
+			return renderDevices(cmd.OutOrStdout(), a.output, devices)
+		},
+	}
+	flags.register(cmd)
+	return cmd
+}
+
+func (a *app) newGetCommand() *cobra.Command {
+	var brand, serial string
+
+	cmd := &cobra.Command{
+		Use:   "get ID",
+		Short: "Show a device",
+		Example: `  devicesctl get 3f2b...
+  devicesctl get --brand Apple --serial C02XK0AAJGH5`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if serial != "" {
+				return exactArgs(0)(cmd, args)
+			}
+			return exactArgs(1)(cmd, args)
+		},
+		ValidArgsFunction: a.completeDeviceIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := a.callContext(cmd)
+			defer cancel()
+
+			var device *client.Device
+			if serial != "" {
+				device, err = c.GetDeviceBySerial(ctx, brand, serial)
+			} else {
+				device, err = c.GetDevice(ctx, args[0])
+			}
+			if err != nil {
+				return err
+			}
+			return renderDevice(cmd.OutOrStdout(), a.output, device)
+		},
+	}
+	cmd.Flags().StringVar(&brand, "brand", "", "brand name or alias, used with --serial")
+	cmd.Flags().StringVar(&serial, "serial", "", "look the device up by serial number instead of ID")
+	cmd.MarkFlagsRequiredTogether("brand", "serial")
+	return cmd
+}
+
+func (a *app) newCreateCommand() *cobra.Command {
+	var (
+		req   client.CreateDeviceRequest
+		model string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a device",
+		Example: `  devicesctl create --name "iPhone 15" --brand Apple --serial F2LXK0AAJGH5
+  devicesctl create --name "MacBook Pro" --brand Apple --purchase-date 2024-01-15 --warranty-end 2027-01-15
+  devicesctl create --name "Dev laptop" --model 0b6a3c0e-8d2c-4c7e-9d0a-1e2f3a4b5c6d`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := a.callContext(cmd)
+			defer cancel()
+
+			if model != "" {
+				req.ModelID = &model
+			}
+			device, err := c.CreateDevice(ctx, req)
+			if err != nil {
+				return err
+			}
+			return renderDevice(cmd.OutOrStdout(), a.output, device)
+		},
+	}
+	cmd.Flags().StringVar(&req.Name, "name", "", "device name")
+	cmd.Flags().StringVar(&req.Brand, "brand", "", "device brand (defaults to the model's brand)")
+	cmd.Flags().StringVar(&req.SerialNumber, "serial", "", "serial number, unique per brand")
+	cmd.Flags().StringVar(&req.PurchaseDate, "purchase-date", "", "purchase date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&req.WarrantyEnd, "warranty-end", "", "last day of the warranty (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&req.EOLDate, "eol-date", "", "planned end-of-life date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&model, "model", "", "catalog model ID; the device starts with the model's default attributes")
+	_ = cmd.MarkFlagRequired("name")
+	cmd.MarkFlagsOneRequired("brand", "model")
+	return cmd
+}
+
+func (a *app) newUpdateCommand() *cobra.Command {
+	var req client.UpdateDeviceRequest
+
+	cmd := &cobra.Command{
+		Use:               "update ID",
+		Short:             "Fully update a device (name, brand and state are required)",
+		Args:              exactArgs(1),
+		ValidArgsFunction: a.completeDeviceIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := a.callContext(cmd)
+			defer cancel()
+
+			device, err := c.UpdateDevice(ctx, args[0], req)
+			if err != nil {
+				return err
+			}
+			return renderDevice(cmd.OutOrStdout(), a.output, device)
+		},
+	}
+	cmd.Flags().StringVar(&req.Name, "name", "", "device name")
+	cmd.Flags().StringVar(&req.Brand, "brand", "", "device brand")
+	cmd.Flags().StringVar(&req.State, "state", "", "device state (active, in-use, inactive)")
+	_ = cmd.MarkFlagRequired("name")
+	_ = cmd.MarkFlagRequired("brand")
+	_ = cmd.MarkFlagRequired("state")
+	_ = cmd.RegisterFlagCompletionFunc("state", fixedCompletions(deviceStates...))
+	return cmd
+}
+
+func (a *app) newPatchCommand() *cobra.Command {
+	var name, brand, state string
+
+	cmd := &cobra.Command{
+		Use:               "patch ID",
+		Short:             "Partially update a device (only the given flags are changed)",
+		Example:           `  devicesctl patch 3f2b... --name "iPhone 15 Pro"`,
+		Args:              exactArgs(1),
+		ValidArgsFunction: a.completeDeviceIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req client.PatchDeviceRequest
+			if cmd.Flags().Changed("name") {
+				req.Name = client.String(name)
+			}
+			if cmd.Flags().Changed("brand") {
+				req.Brand = client.String(brand)
+			}
+			if cmd.Flags().Changed("state") {
+				req.State = client.String(state)
+			}
+			if req.Name == nil && req.Brand == nil && req.State == nil {
+				return usageErrorf("at least one of --name, --brand or --state is required")
+			}
+
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := a.callContext(cmd)
+			defer cancel()
+
+			device, err := c.PatchDevice(ctx, args[0], req)
+			if err != nil {
+				return err
+			}
+			return renderDevice(cmd.OutOrStdout(), a.output, device)
+		},
+	}
+	cmd.Flags().StringVar(&name, "name", "", "new device name")
+	cmd.Flags().StringVar(&brand, "brand", "", "new device brand")
+	cmd.Flags().StringVar(&state, "state", "", "new device state (active, in-use, inactive)")
+	_ = cmd.RegisterFlagCompletionFunc("state", fixedCompletions(deviceStates...))
+	return cmd
+}
+
+func (a *app) newDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete ID",
+		Aliases:           []string{"rm"},
+		Short:             "Delete a device",
+		Args:              exactArgs(1),
+		ValidArgsFunction: a.completeDeviceIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := a.callContext(cmd)
+			defer cancel()
+
+			if err := c.DeleteDevice(ctx, args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Device %s deleted\n", args[0])
+			return nil
+		},
+	}
+}
+
+func (a *app) newStateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "state ID STATE",
+		Short: "Transition a device to another state",
+		Example: `  devicesctl state 3f2b... in-use
+  devicesctl state 3f2b... inactive`,
+		Args: exactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return a.completeDeviceIDs(cmd, args, toComplete)
+			}
+			if len(args) == 1 {
+				return deviceStates, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := a.callContext(cmd)
+			defer cancel()
+
+			device, err := c.PatchDevice(ctx, args[0], client.PatchDeviceRequest{State: client.String(args[1])})
+			if err != nil {
+				return err
+			}
+			return renderDevice(cmd.OutOrStdout(), a.output, device)
+		},
+	}
+}
+
+func (a *app) newWatchCommand() *cobra.Command {
+	var (
+		flags    listFlags
+		interval time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch [ID]",
+		Short: "Watch a device or a filtered list and print it whenever it changes",
+		Example: `  devicesctl watch --state in-use
+  devicesctl watch 3f2b... --interval 5s -o json`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.completeDeviceIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return usageErrorf("--interval must be positive")
+			}
+
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+
+			render := func(ctx context.Context, w io.Writer) error {
+				if len(args) == 1 {
+					device, err := c.GetDevice(ctx, args[0])
+					if err != nil {
+						return err
+					}
+					return renderDevice(w, a.output, device)
+				}
+				devices, err := flags.fetch(ctx, c)
+				if err != nil {
+					return err
+				}
+				return renderDevices(w, a.output, devices)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return a.watch(ctx, cmd.OutOrStdout(), interval, render)
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "polling interval")
+	return cmd
+}
+
+// watch polls render every interval and prints the output only when it changes.
+// It returns nil when interrupted.
+func (a *app) watch(ctx context.Context, out io.Writer, interval time.Duration, render func(context.Context, io.Writer) error) error {
+	var previous []byte
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		callCtx, cancel := context.WithTimeout(ctx, a.timeout)
+		var buf bytes.Buffer
+		err := render(callCtx, &buf)
+		cancel()
+
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !bytes.Equal(buf.Bytes(), previous) {
+			if a.output == formatTable {
+				fmt.Fprintf(out, "--- %s ---\n", time.Now().Format(time.RFC3339))
+			}
+			if _, err := out.Write(buf.Bytes()); err != nil {
+				return err
+			}
+			previous = buf.Bytes()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// completeDeviceIDs completes device IDs from the first page of devices
+func (a *app) completeDeviceIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	c, err := a.client()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	ctx, cancel := a.callContext(cmd)
+	defer cancel()
+
+	page, err := c.ListDevices(ctx, client.ListOptions{Limit: 100})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	ids := make([]string, 0, len(page.Devices))
+	for _, d := range page.Devices {
+		ids = append(ids, d.ID+"\t"+d.Name)
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// callContext bounds a single command's API calls by --timeout
+func (a *app) callContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(cmd.Context(), a.timeout)
+}
+
+// exactArgs is cobra.ExactArgs reporting a usage error
+func exactArgs(n int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := cobra.ExactArgs(n)(cmd, args); err != nil {
+			return &usageError{err: err}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+
+	"devices-api/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func (a *app) newLabelCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "label ID [KEY=VALUE | KEY-]...",
+		Short: "Show, set or remove device labels",
+		Long: `Show the labels of a device, or change them.
+
+KEY=VALUE sets a label and KEY- removes it; other labels are left unchanged.`,
+		Example: `  devicesctl label 3f2b...
+  devicesctl label 3f2b... team=mobile env=lab
+  devicesctl label 3f2b... env-`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return usageErrorf("requires a device ID")
+			}
+			return nil
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return a.completeDeviceIDs(cmd, args, toComplete)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			changes, err := parseLabelChanges(args[1:])
+			if err != nil {
+				return err
+			}
+
+			c, err := a.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := a.callContext(cmd)
+			defer cancel()
+
+			var labels map[string]string
+			if len(changes) == 0 {
+				labels, err = c.GetLabels(ctx, args[0])
+			} else {
+				labels, err = c.PatchLabels(ctx, args[0], changes)
+			}
+			if err != nil {
+				return err
+			}
+			return renderLabels(cmd.OutOrStdout(), a.output, labels)
+		},
+	}
+}
+
+// parseLabelChanges turns KEY=VALUE and KEY- arguments into a label patch
+func parseLabelChanges(args []string) (map[string]*string, error) {
+	changes := make(map[string]*string, len(args))
+	for _, arg := range args {
+		if key, value, ok := strings.Cut(arg, "="); ok {
+			if key == "" {
+				return nil, usageErrorf("invalid label %q: key cannot be empty", arg)
+			}
+			changes[key] = client.String(value)
+			continue
+		}
+		if key, ok := strings.CutSuffix(arg, "-"); ok && key != "" {
+			changes[key] = nil
+			continue
+		}
+		return nil, usageErrorf("invalid label %q (use KEY=VALUE to set or KEY- to remove)", arg)
+	}
+	return changes, nil
+}
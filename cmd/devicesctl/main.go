@@ -0,0 +1,76 @@
+// Command devicesctl is a command-line client for the Devices API.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"devices-api/pkg/client"
+)
+
+// Exit codes let scripts distinguish failure classes without parsing output
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitUsage        = 2
+	exitNotFound     = 3
+	exitValidation   = 4
+	exitBusinessRule = 5
+	exitConflict     = 6
+)
+
+func main() {
+	os.Exit(execute(os.Args[1:]))
+}
+
+// execute runs the CLI and returns the process exit code
+func execute(args []string) int {
+	root := newRootCommand()
+	root.SetArgs(args)
+
+	err := root.Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	return exitCode(err)
+}
+
+// exitCode maps an error to the documented exit code
+func exitCode(err error) int {
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case client.IsNotFoundError(err):
+		return exitNotFound
+	case client.IsValidationError(err):
+		return exitValidation
+	case client.IsBusinessRuleError(err):
+		return exitBusinessRule
+	case client.IsConflictError(err):
+		return exitConflict
+	default:
+		return exitError
+	}
+}
+
+// usageError marks invalid command-line input
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// usageErrorf formats a usageError
+func usageErrorf(format string, args ...any) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
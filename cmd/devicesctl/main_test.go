@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devices-api/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCLI executes the root command against baseURL and returns stdout and the exit code
+func runCLI(t *testing.T, baseURL string, args ...string) (string, int) {
+	t.Helper()
+
+	root := newRootCommand()
+	var stdout, stderr bytes.Buffer
+	root.SetOut(&stdout)
+	root.SetErr(&stderr)
+	root.SetArgs(append([]string{
+		"--config", filepath.Join(t.TempDir(), "config.yaml"),
+		"--base-url", baseURL,
+	}, args...))
+
+	code := exitCode(root.Execute())
+	return stdout.String(), code
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code, "message": message})
+}
+
+func TestExitCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/devices/missing":
+			writeError(w, http.StatusNotFound, "not_found", "device not found")
+		case "/api/v1/devices/busy":
+			writeError(w, http.StatusUnprocessableEntity, "business_rule_violation", "cannot delete device in use")
+		case "/api/v1/devices/by-serial/Apple/SN-1":
+			writeError(w, http.StatusNotFound, "not_found", "device not found")
+		case "/api/v1/devices":
+			var req client.CreateDeviceRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.SerialNumber != "" {
+				writeError(w, http.StatusConflict, "conflict", "serial number already exists")
+				return
+			}
+			writeError(w, http.StatusBadRequest, "validation_error", "name is required")
+		default:
+			writeError(w, http.StatusInternalServerError, "internal_error", "boom")
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"not found", []string{"get", "missing"}, exitNotFound},
+		{"business rule", []string{"delete", "busy"}, exitBusinessRule},
+		{"validation", []string{"create", "--name", "x", "--brand", "y"}, exitValidation},
+		{"conflict", []string{"create", "--name", "MacBook Pro", "--brand", "Apple", "--serial", "SN-1"}, exitConflict},
+		{"serial not found", []string{"get", "--brand", "Apple", "--serial", "SN-1"}, exitNotFound},
+		{"missing argument", []string{"get"}, exitUsage},
+		{"unknown flag", []string{"list", "--nope"}, exitUsage},
+		{"invalid output", []string{"-o", "xml", "list"}, exitUsage},
+		{"empty patch", []string{"patch", "id"}, exitUsage},
+		{"invalid label change", []string{"label", "id", "team"}, exitUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, code := runCLI(t, server.URL, tt.args...)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
+
+func TestStateCommand(t *testing.T) {
+	var patched map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&patched))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(client.Device{ID: "abc", Name: "iPhone", Brand: "Apple", State: patched["state"]})
+	}))
+	defer server.Close()
+
+	out, code := runCLI(t, server.URL, "-o", "json", "state", "abc", "in-use")
+
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, map[string]string{"state": "in-use"}, patched)
+
+	var device client.Device
+	require.NoError(t, json.Unmarshal([]byte(out), &device))
+	assert.Equal(t, "in-use", device.State)
+}
+
+func TestLabelCommand(t *testing.T) {
+	var patched map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		require.Equal(t, "/api/v1/devices/abc/labels", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&patched))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"labels": map[string]string{"team": "mobile", "env": "lab"}})
+	}))
+	defer server.Close()
+
+	out, code := runCLI(t, server.URL, "label", "abc", "team=mobile", "env=lab", "owner-")
+
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, map[string]any{"team": "mobile", "env": "lab", "owner": nil}, patched)
+	assert.Equal(t, "KEY   VALUE\nenv   lab\nteam  mobile\n", out)
+}
+
+func TestRenderDevices(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	devices := []client.Device{
+		{ID: "1", Name: "iPhone", Brand: "Apple", State: "active", CreatedAt: created},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{formatTable, "ID  NAME    BRAND  STATE   CREATED_AT\n1   iPhone  Apple  active  2024-01-02T03:04:05Z\n"},
+		{formatCSV, "ID,NAME,BRAND,STATE,CREATED_AT\n1,iPhone,Apple,active,2024-01-02T03:04:05Z\n"},
+		{formatYAML, "- brand: Apple\n  created_at: \"2024-01-02T03:04:05Z\"\n  id: \"1\"\n  name: iPhone\n  state: active\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, renderDevices(&buf, tt.format, devices))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestSortDevices(t *testing.T) {
+	devices := []client.Device{{Name: "b"}, {Name: "C"}, {Name: "a"}}
+
+	require.NoError(t, sortDevices(devices, "-name"))
+	assert.Equal(t, []string{"C", "b", "a"}, []string{devices[0].Name, devices[1].Name, devices[2].Name})
+
+	assert.Equal(t, exitUsage, exitCode(sortDevices(devices, "color")))
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"devices-api/pkg/client"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatCSV   = "csv"
+)
+
+var outputFormats = []string{formatTable, formatJSON, formatYAML, formatCSV}
+
+// deviceColumns are the columns used by table and CSV output
+var deviceColumns = []string{"ID", "NAME", "BRAND", "STATE", "CREATED_AT"}
+
+// deviceRow flattens a device into table/CSV cells
+func deviceRow(d client.Device) []string {
+	return []string{d.ID, d.Name, d.Brand, d.State, d.CreatedAt.Format(time.RFC3339)}
+}
+
+// validateFormat checks the --output flag value
+func validateFormat(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return usageErrorf("invalid output format %q (must be: %s)", format, strings.Join(outputFormats, ", "))
+}
+
+// renderDevices writes a list of devices in the requested format
+func renderDevices(w io.Writer, format string, devices []client.Device) error {
+	if devices == nil {
+		devices = []client.Device{}
+	}
+
+	switch format {
+	case formatJSON:
+		return writeJSON(w, devices)
+	case formatYAML:
+		return writeYAML(w, devices)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(deviceColumns); err != nil {
+			return err
+		}
+		for _, d := range devices {
+			if err := cw.Write(deviceRow(d)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(deviceColumns, "\t"))
+		for _, d := range devices {
+			fmt.Fprintln(tw, strings.Join(deviceRow(d), "\t"))
+		}
+		return tw.Flush()
+	}
+}
+
+// renderDevice writes a single device in the requested format
+func renderDevice(w io.Writer, format string, device *client.Device) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, device)
+	case formatYAML:
+		return writeYAML(w, device)
+	default:
+		return renderDevices(w, format, []client.Device{*device})
+	}
+}
+
+// renderLabels writes device labels sorted by key
+func renderLabels(w io.Writer, format string, labels map[string]string) error {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	switch format {
+	case formatJSON:
+		return writeJSON(w, labels)
+	case formatYAML:
+		return writeYAML(w, labels)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"KEY", "VALUE"}); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := cw.Write([]string{key, labels[key]}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tVALUE")
+		for _, key := range keys {
+			fmt.Fprintf(tw, "%s\t%s\n", key, labels[key])
+		}
+		return tw.Flush()
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeYAML renders v as YAML using its JSON field names
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// sortDevices sorts devices by field; a leading "-" sorts descending
+func sortDevices(devices []client.Device, field string) error {
+	if field == "" {
+		return nil
+	}
+
+	descending := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	var less func(a, b client.Device) bool
+	switch field {
+	case "name":
+		less = func(a, b client.Device) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "brand":
+		less = func(a, b client.Device) bool { return strings.ToLower(a.Brand) < strings.ToLower(b.Brand) }
+	case "state":
+		less = func(a, b client.Device) bool { return a.State < b.State }
+	case "created_at":
+		less = func(a, b client.Device) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	default:
+		return usageErrorf("invalid sort field %q (must be: name, brand, state, or created_at)", field)
+	}
+
+	sort.SliceStable(devices, func(i, j int) bool {
+		if descending {
+			return less(devices[j], devices[i])
+		}
+		return less(devices[i], devices[j])
+	})
+	return nil
+}
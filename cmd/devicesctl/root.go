@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"devices-api/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// app holds the global flags shared by every command
+type app struct {
+	configPath string
+	profile    string
+	baseURL    string
+	token      string
+	output     string
+	timeout    time.Duration
+}
+
+// newRootCommand builds the devicesctl command tree
+func newRootCommand() *cobra.Command {
+	a := &app{}
+
+	root := &cobra.Command{
+		Use:   "devicesctl",
+		Short: "Command-line client for the Devices API",
+		Long: `devicesctl talks to the Devices API REST endpoints.
+
+Connection settings come from the active profile in the config file and can be
+overridden with --base-url/--token or DEVICESCTL_BASE_URL/DEVICESCTL_TOKEN.
+
+Exit codes:
+  0  success
+  1  unexpected error
+  2  invalid usage
+  3  device not found
+  4  validation error
+  5  business rule violation`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateFormat(a.output)
+		},
+	}
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&a.configPath, "config", envOr("DEVICESCTL_CONFIG", defaultConfigPath()), "path to the config file")
+	flags.StringVar(&a.profile, "profile", os.Getenv("DEVICESCTL_PROFILE"), "profile to use (default: current profile)")
+	flags.StringVar(&a.baseURL, "base-url", os.Getenv("DEVICESCTL_BASE_URL"), "API base URL (overrides the profile)")
+	flags.StringVar(&a.token, "token", os.Getenv("DEVICESCTL_TOKEN"), "bearer token (overrides the profile)")
+	flags.StringVarP(&a.output, "output", "o", formatTable, "output format: table, json, yaml, or csv")
+	flags.DurationVar(&a.timeout, "timeout", 30*time.Second, "timeout for each API call")
+	_ = root.RegisterFlagCompletionFunc("output", fixedCompletions(outputFormats...))
+	_ = root.RegisterFlagCompletionFunc("profile", a.completeProfiles)
+
+	root.AddCommand(
+		a.newListCommand(),
+		a.newGetCommand(),
+		a.newCreateCommand(),
+		a.newUpdateCommand(),
+		a.newPatchCommand(),
+		a.newDeleteCommand(),
+		a.newStateCommand(),
+		a.newLabelCommand(),
+		a.newWatchCommand(),
+		a.newConfigCommand(),
+	)
+
+	return root
+}
+
+// client builds an API client from the resolved profile and flag overrides
+func (a *app) client() (*client.Client, error) {
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := cfg.resolveProfile(a.profile)
+	if err != nil {
+		return nil, err
+	}
+	if a.baseURL != "" {
+		profile.BaseURL = a.baseURL
+	}
+	if a.token != "" {
+		profile.Token = a.token
+	}
+
+	opts := []client.Option{
+		client.WithRetry(client.DefaultRetryPolicy),
+		client.WithUserAgent("devicesctl"),
+	}
+	if profile.Token != "" {
+		opts = append(opts, client.WithBearerToken(profile.Token))
+	}
+
+	c, err := client.New(profile.BaseURL, opts...)
+	if err != nil {
+		return nil, usageErrorf("%v", err)
+	}
+	return c, nil
+}
+
+// completeProfiles completes --profile with the configured profile names
+func (a *app) completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := loadConfig(a.configPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return cfg.profileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// fixedCompletions completes a flag or argument from a fixed list of values
+func fixedCompletions(values ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// envOr returns the environment variable or the fallback when unset
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -1,37 +1,278 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"devices-api/internal/domain"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 type (
 	Config struct {
-		Server   ServerConfig   `yaml:"server"`
-		Database DatabaseConfig `yaml:"database"`
+		Server      ServerConfig      `yaml:"server"`
+		Database    DatabaseConfig    `yaml:"database"`
+		Pagination  PaginationConfig  `yaml:"pagination"`
+		Log         LogConfig         `yaml:"log"`
+		Tracing     TracingConfig     `yaml:"tracing"`
+		Expiry      ExpiryConfig      `yaml:"expiry"`
+		Maintenance MaintenanceConfig `yaml:"maintenance"`
+		Heartbeat   HeartbeatConfig   `yaml:"heartbeat"`
+		Telemetry   TelemetryConfig   `yaml:"telemetry"`
+		Cache       CacheConfig       `yaml:"cache"`
+		Admin       AdminConfig       `yaml:"admin"`
+		GraphQL     GraphQLConfig     `yaml:"graphql"`
 	}
 
 	ServerConfig struct {
-		HTTPPort int `yaml:"http_port" env:"SERVER_HTTP_PORT" env-default:"8080"`
-		GRPCPort int `yaml:"grpc_port" env:"SERVER_GRPC_PORT" env-default:"9090"`
+		HTTPPort     int           `yaml:"http_port" env:"SERVER_HTTP_PORT" env-default:"8080"`
+		GRPCPort     int           `yaml:"grpc_port" env:"SERVER_GRPC_PORT" env-default:"9090"`
+		ReadTimeout  time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" env-default:"15s"`
+		WriteTimeout time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" env-default:"15s"`
+		IdleTimeout  time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" env-default:"60s"`
+		// ShutdownTimeout bounds how long in-flight requests may take to finish on shutdown
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT" env-default:"10s"`
+		// ReadinessTimeout bounds each dependency check performed by /readyz
+		ReadinessTimeout time.Duration `yaml:"readiness_timeout" env:"SERVER_READINESS_TIMEOUT" env-default:"2s"`
+		// ShutdownDrainDelay is how long /readyz fails before the server stops accepting requests
+		ShutdownDrainDelay time.Duration `yaml:"shutdown_drain_delay" env:"SERVER_SHUTDOWN_DRAIN_DELAY" env-default:"5s"`
 	}
 
 	DatabaseConfig struct {
-		URL string `yaml:"url" env:"DATABASE_URL" env-required:"true"`
+		URL               string        `yaml:"url" env:"DATABASE_URL" env-required:"true"`
+		MaxConns          int32         `yaml:"max_conns" env:"DATABASE_MAX_CONNS" env-default:"25"`
+		MinConns          int32         `yaml:"min_conns" env:"DATABASE_MIN_CONNS" env-default:"5"`
+		MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" env:"DATABASE_MAX_CONN_LIFETIME" env-default:"1h"`
+		MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" env:"DATABASE_MAX_CONN_IDLE_TIME" env-default:"30m"`
+		HealthCheckPeriod time.Duration `yaml:"health_check_period" env:"DATABASE_HEALTH_CHECK_PERIOD" env-default:"1m"`
+		// ReplicaURL is an optional read replica serving device list and search queries
+		ReplicaURL string `yaml:"replica_url" env:"DATABASE_REPLICA_URL"`
+		// ReplicaMaxStaleness is how far the replica may lag behind the primary before reads fall back to it
+		ReplicaMaxStaleness time.Duration `yaml:"replica_max_staleness" env:"DATABASE_REPLICA_MAX_STALENESS" env-default:"5s"`
+		// ReplicaCheckInterval is how often the replica lag is checked
+		ReplicaCheckInterval time.Duration `yaml:"replica_check_interval" env:"DATABASE_REPLICA_CHECK_INTERVAL" env-default:"5s"`
+	}
+
+	PaginationConfig struct {
+		// DefaultLimit is used when a request does not specify a limit
+		DefaultLimit int `yaml:"default_limit" env:"PAGINATION_DEFAULT_LIMIT" env-default:"10"`
+		// MaxLimit caps the limit a client may request
+		MaxLimit int `yaml:"max_limit" env:"PAGINATION_MAX_LIMIT" env-default:"100"`
+	}
+
+	LogConfig struct {
+		Level  string `yaml:"level" env:"LOG_LEVEL" env-default:"info"`
+		Format string `yaml:"format" env:"LOG_FORMAT" env-default:"json"`
+	}
+
+	TracingConfig struct {
+		ServiceName  string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" env-default:"devices-api"`
+		Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none"`
+		OTLPEndpoint string  `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+		OTLPProtocol string  `yaml:"otlp_protocol" env:"OTEL_EXPORTER_OTLP_PROTOCOL" env-default:"grpc"`
+		OTLPInsecure bool    `yaml:"otlp_insecure" env:"TRACING_OTLP_INSECURE" env-default:"false"`
+		SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" env-default:"1.0"`
+	}
+
+	ExpiryConfig struct {
+		// CheckInterval is how often warranty and EOL dates are checked; 0 disables the check
+		CheckInterval time.Duration `yaml:"check_interval" env:"EXPIRY_CHECK_INTERVAL" env-default:"1h"`
+		// ThresholdDays lists how many days before expiry an expiring soon event is emitted
+		ThresholdDays []int `yaml:"threshold_days" env:"EXPIRY_THRESHOLD_DAYS" env-separator:"," env-default:"30,7,1"`
+	}
+
+	MaintenanceConfig struct {
+		// IntervalDays maps a device category to the days between periodic maintenances,
+		// e.g. "laptop:365,sensor:90"; categories without an interval are never due
+		IntervalDays map[string]int `yaml:"interval_days" env:"MAINTENANCE_INTERVAL_DAYS" env-separator:"," env-default:"laptop:365,phone:365,tablet:365,sensor:180"`
+	}
+
+	HeartbeatConfig struct {
+		// CheckInterval is how often devices are checked for missed heartbeats; 0 disables the check
+		CheckInterval time.Duration `yaml:"check_interval" env:"HEARTBEAT_CHECK_INTERVAL" env-default:"1m"`
+		// StaleAfter is how long a device may go without a heartbeat before it is made inactive
+		StaleAfter time.Duration `yaml:"stale_after" env:"HEARTBEAT_STALE_AFTER" env-default:"15m"`
+	}
+
+	TelemetryConfig struct {
+		// Retention is how long telemetry is kept; older samples are rejected and dropped
+		Retention time.Duration `yaml:"retention" env:"TELEMETRY_RETENTION" env-default:"720h"`
+		// RetentionInterval is how often telemetry past retention is dropped; 0 disables dropping
+		RetentionInterval time.Duration `yaml:"retention_interval" env:"TELEMETRY_RETENTION_INTERVAL" env-default:"1h"`
+		// MaxBatch caps the number of samples in a single ingestion request
+		MaxBatch int `yaml:"max_batch" env:"TELEMETRY_MAX_BATCH" env-default:"5000"`
+	}
+
+	CacheConfig struct {
+		// DeviceSize is how many devices each replica keeps in memory; 0 disables the cache
+		DeviceSize int `yaml:"device_size" env:"CACHE_DEVICE_SIZE" env-default:"10000"`
+		// DeviceTTL bounds how long a cached device may miss changes made elsewhere, such as on other replicas
+		DeviceTTL time.Duration `yaml:"device_ttl" env:"CACHE_DEVICE_TTL" env-default:"30s"`
+	}
+
+	AdminConfig struct {
+		// Token authenticates operators on admin endpoints such as issuing heartbeat tokens;
+		// when empty, admin endpoints reject every request
+		Token string `yaml:"token" env:"ADMIN_TOKEN"`
+	}
+
+	GraphQLConfig struct {
+		// MaxDepth caps how deeply the fields of a /graphql query may nest
+		MaxDepth int `yaml:"max_depth" env:"GRAPHQL_MAX_DEPTH" env-default:"10"`
+		// MaxComplexity caps the cost of a /graphql query: one per field, multiplied by the page size of paginated fields
+		MaxComplexity int `yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" env-default:"5000"`
 	}
 )
 
-// LoadConfig loads configuration from environment variables.
+// LoadConfig loads configuration from an optional YAML file and environment variables.
+// Environment variables always take precedence over values from the file, so
+// the file can hold shared tuning while deployments inject secrets via env.
+// When path is empty only environment variables are read.
 // Note: We deliberately do NOT automatically load .env files here to strictly follow
 // 12-factor app principles. In Docker, env vars are injected by the runtime.
 // For local development without Docker, export variables in your shell or use a tool like direnv.
-func LoadConfig() (*Config, error) {
+func LoadConfig(path string) (*Config, error) {
 	var cfg Config
 
-	if err := cleanenv.ReadEnv(&cfg); err != nil {
+	var err error
+	if path != "" {
+		err = cleanenv.ReadConfig(path, &cfg)
+	} else {
+		err = cleanenv.ReadEnv(&cfg)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("config error: %w", err)
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
+
+// Validate checks that settings are within usable ranges and reports every problem at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, field, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	check(validPort(c.Server.HTTPPort), "server.http_port", "must be between 1 and 65535, got %d", c.Server.HTTPPort)
+	check(validPort(c.Server.GRPCPort), "server.grpc_port", "must be between 1 and 65535, got %d", c.Server.GRPCPort)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout", "must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout", "must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout", "must be positive")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be positive")
+	check(c.Server.ReadinessTimeout > 0, "server.readiness_timeout", "must be positive")
+	check(c.Server.ShutdownDrainDelay >= 0, "server.shutdown_drain_delay", "must not be negative")
+
+	check(c.Database.URL != "", "database.url", "is required")
+	check(c.Database.MaxConns > 0, "database.max_conns", "must be positive, got %d", c.Database.MaxConns)
+	check(c.Database.MinConns >= 0 && c.Database.MinConns <= c.Database.MaxConns,
+		"database.min_conns", "must be between 0 and max_conns (%d), got %d", c.Database.MaxConns, c.Database.MinConns)
+	check(c.Database.MaxConnLifetime > 0, "database.max_conn_lifetime", "must be positive")
+	check(c.Database.MaxConnIdleTime > 0, "database.max_conn_idle_time", "must be positive")
+	check(c.Database.HealthCheckPeriod > 0, "database.health_check_period", "must be positive")
+	check(c.Database.ReplicaMaxStaleness > 0, "database.replica_max_staleness", "must be positive")
+	check(c.Database.ReplicaCheckInterval > 0, "database.replica_check_interval", "must be positive")
+
+	check(c.Pagination.MaxLimit > 0, "pagination.max_limit", "must be positive, got %d", c.Pagination.MaxLimit)
+	check(c.Pagination.DefaultLimit > 0 && c.Pagination.DefaultLimit <= c.Pagination.MaxLimit,
+		"pagination.default_limit", "must be between 1 and max_limit (%d), got %d", c.Pagination.MaxLimit, c.Pagination.DefaultLimit)
+
+	check(slices.Contains(logLevels, strings.ToLower(c.Log.Level)), "log.level", "must be one of %s, got %q", strings.Join(logLevels, ", "), c.Log.Level)
+	check(slices.Contains(logFormats, strings.ToLower(c.Log.Format)), "log.format", "must be one of %s, got %q", strings.Join(logFormats, ", "), c.Log.Format)
+
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	check(c.Expiry.CheckInterval >= 0, "expiry.check_interval", "must not be negative")
+	check(c.Expiry.CheckInterval == 0 || len(c.Expiry.ThresholdDays) > 0, "expiry.threshold_days", "must not be empty")
+	for _, days := range c.Expiry.ThresholdDays {
+		check(days >= 1 && days <= maxExpiryThresholdDays, "expiry.threshold_days", "must be between 1 and %d, got %d", maxExpiryThresholdDays, days)
+	}
+
+	check(c.Heartbeat.CheckInterval >= 0, "heartbeat.check_interval", "must not be negative")
+	check(c.Heartbeat.CheckInterval == 0 || c.Heartbeat.StaleAfter > 0, "heartbeat.stale_after", "must be positive")
+
+	check(c.Telemetry.Retention >= 24*time.Hour, "telemetry.retention", "must be at least 24h")
+	check(c.Telemetry.RetentionInterval >= 0, "telemetry.retention_interval", "must not be negative")
+	check(c.Telemetry.MaxBatch > 0, "telemetry.max_batch", "must be positive, got %d", c.Telemetry.MaxBatch)
+
+	check(c.Cache.DeviceSize >= 0, "cache.device_size", "must not be negative, got %d", c.Cache.DeviceSize)
+	check(c.Cache.DeviceSize == 0 || c.Cache.DeviceTTL > 0, "cache.device_ttl", "must be positive")
+
+	check(c.GraphQL.MaxDepth > 0, "graphql.max_depth", "must be positive, got %d", c.GraphQL.MaxDepth)
+	check(c.GraphQL.MaxComplexity > 0, "graphql.max_complexity", "must be positive, got %d", c.GraphQL.MaxComplexity)
+
+	if err := c.Maintenance.Intervals().Validate(); err != nil {
+		var validationErr *domain.ValidationError
+		if errors.As(err, &validationErr) {
+			err = errors.New(validationErr.Message)
+		}
+		check(false, "maintenance.interval_days", "%v", err)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Intervals returns the maintenance intervals keyed by device category
+func (c MaintenanceConfig) Intervals() domain.MaintenanceIntervals {
+	intervals := make(domain.MaintenanceIntervals, len(c.IntervalDays))
+	for category, days := range c.IntervalDays {
+		intervals[domain.DeviceCategory(category)] = days
+	}
+	return intervals
+}
+
+// maxExpiryThresholdDays matches the longest window the expiry report accepts
+const maxExpiryThresholdDays = 3650
+
+var (
+	logLevels  = []string{"debug", "info", "warn", "error"}
+	logFormats = []string{"json", "text"}
+)
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+// dsnPassword matches the password in key/value connection strings
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('[^']*'|\S+)`)
+
+// Redacted returns a copy of the configuration that is safe to print or log
+func (c Config) Redacted() Config {
+	c.Database.URL = redactDatabaseURL(c.Database.URL)
+	c.Database.ReplicaURL = redactDatabaseURL(c.Database.ReplicaURL)
+	if c.Admin.Token != "" {
+		c.Admin.Token = "REDACTED"
+	}
+	return c
+}
+
+// redactDatabaseURL masks the password in URL or key/value connection strings
+func redactDatabaseURL(raw string) string {
+	if u, err := url.Parse(raw); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "REDACTED")
+		}
+		query := u.Query()
+		if query.Has("password") {
+			query.Set("password", "REDACTED")
+			u.RawQuery = query.Encode()
+		}
+		return u.String()
+	}
+	return dsnPassword.ReplaceAllString(raw, "${1}REDACTED")
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig_FileWithEnvOverrides(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  http_port: 9000
+  read_timeout: 5s
+database:
+  url: postgres://file@localhost/devices
+  max_conns: 40
+pagination:
+  default_limit: 20
+`)
+	t.Setenv("SERVER_HTTP_PORT", "8081")
+
+	cfg, err := LoadConfig(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, 8081, cfg.Server.HTTPPort, "env overrides file")
+	assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout, "file overrides default")
+	assert.Equal(t, 15*time.Second, cfg.Server.WriteTimeout, "default applies when unset")
+	assert.Equal(t, "postgres://file@localhost/devices", cfg.Database.URL)
+	assert.Equal(t, int32(40), cfg.Database.MaxConns)
+	assert.Equal(t, 20, cfg.Pagination.DefaultLimit)
+	assert.Equal(t, 100, cfg.Pagination.MaxLimit)
+}
+
+func TestLoadConfig_EnvOnly(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://env@localhost/devices")
+
+	cfg, err := LoadConfig("")
+
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Server.HTTPPort)
+	assert.Equal(t, "info", cfg.Log.Level)
+	assert.Equal(t, time.Hour, cfg.Expiry.CheckInterval)
+	assert.Equal(t, []int{30, 7, 1}, cfg.Expiry.ThresholdDays)
+	assert.Equal(t, 180, cfg.Maintenance.Intervals()[domain.DeviceCategorySensor])
+	assert.Equal(t, time.Minute, cfg.Heartbeat.CheckInterval)
+	assert.Equal(t, 15*time.Minute, cfg.Heartbeat.StaleAfter)
+	assert.Equal(t, 720*time.Hour, cfg.Telemetry.Retention)
+	assert.Equal(t, 5000, cfg.Telemetry.MaxBatch)
+	assert.Equal(t, 10000, cfg.Cache.DeviceSize)
+	assert.Equal(t, 30*time.Second, cfg.Cache.DeviceTTL)
+	assert.Empty(t, cfg.Database.ReplicaURL)
+	assert.Equal(t, 5*time.Second, cfg.Database.ReplicaMaxStaleness)
+	assert.Equal(t, 10, cfg.GraphQL.MaxDepth)
+	assert.Equal(t, 5000, cfg.GraphQL.MaxComplexity)
+}
+
+func TestLoadConfig_MaintenanceIntervalsFromEnv(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://env@localhost/devices")
+	t.Setenv("MAINTENANCE_INTERVAL_DAYS", "laptop:180,sensor:90")
+
+	cfg, err := LoadConfig("")
+
+	require.NoError(t, err)
+	assert.Equal(t, domain.MaintenanceIntervals{domain.DeviceCategoryLaptop: 180, domain.DeviceCategorySensor: 90}, cfg.Maintenance.Intervals())
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	path := writeConfigFile(t, `
+database:
+  url: postgres://localhost/devices
+  max_conns: 5
+  min_conns: 10
+  replica_max_staleness: -1s
+pagination:
+  default_limit: 500
+log:
+  level: verbose
+expiry:
+  threshold_days: [30, 0]
+maintenance:
+  interval_days:
+    wearable: 30
+heartbeat:
+  stale_after: -1m
+telemetry:
+  retention: 1h
+cache:
+  device_ttl: -1s
+graphql:
+  max_depth: -1
+`)
+
+	_, err := LoadConfig(path)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database.min_conns: must be between 0 and max_conns (5), got 10")
+	assert.Contains(t, err.Error(), "database.replica_max_staleness: must be positive")
+	assert.Contains(t, err.Error(), "pagination.default_limit: must be between 1 and max_limit (100), got 500")
+	assert.Contains(t, err.Error(), `log.level: must be one of debug, info, warn, error, got "verbose"`)
+	assert.Contains(t, err.Error(), "expiry.threshold_days: must be between 1 and 3650, got 0")
+	assert.Contains(t, err.Error(), "maintenance.interval_days: invalid category: wearable")
+	assert.Contains(t, err.Error(), "heartbeat.stale_after: must be positive")
+	assert.Contains(t, err.Error(), "telemetry.retention: must be at least 24h")
+	assert.Contains(t, err.Error(), "cache.device_ttl: must be positive")
+	assert.Contains(t, err.Error(), "graphql.max_depth: must be positive, got -1")
+}
+
+func TestRedacted(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"url password", "postgres://user:secret@db:5432/devices?sslmode=disable", "postgres://user:REDACTED@db:5432/devices?sslmode=disable"},
+		{"query password", "postgres://db/devices?password=secret", "postgres://db/devices?password=REDACTED"},
+		{"key value", "host=db user=app password=secret dbname=devices", "host=db user=app password=REDACTED dbname=devices"},
+		{"no password", "postgres://user@db/devices", "postgres://user@db/devices"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Database: DatabaseConfig{URL: tt.url, ReplicaURL: tt.url}}
+			assert.Equal(t, tt.want, cfg.Redacted().Database.URL)
+			assert.Equal(t, tt.want, cfg.Redacted().Database.ReplicaURL)
+			assert.Equal(t, tt.url, cfg.Database.URL, "original is untouched")
+		})
+	}
+}
+
+func TestRedacted_AdminToken(t *testing.T) {
+	cfg := Config{Admin: AdminConfig{Token: "s3cret"}}
+	assert.Equal(t, "REDACTED", cfg.Redacted().Admin.Token)
+	assert.Equal(t, "s3cret", cfg.Admin.Token, "original is untouched")
+
+	assert.Empty(t, Config{}.Redacted().Admin.Token, "an unset token stays visibly unset")
+}
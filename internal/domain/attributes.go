@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxAttributes is the maximum number of custom attributes per device
+	MaxAttributes = 50
+	// MaxAttributeValueLength is the maximum length of a string attribute value
+	MaxAttributeValueLength = 256
+	// MaxAttributesSize is the maximum size in bytes of the encoded attributes
+	MaxAttributesSize = 8 * 1024
+)
+
+// attributeKeyPattern allows lowercase snake_case keys up to 64 characters
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// Attributes holds custom, type-specific device properties (serial numbers,
+// OS versions, RAM, ...). Values must be strings, numbers or booleans.
+type Attributes map[string]any
+
+// Validate enforces key names, value types and size limits
+func (a Attributes) Validate() error {
+	return a.validate("attributes")
+}
+
+// validate enforces the limits, reporting errors on field and its keys.
+// Every invalid key is reported, in key order.
+func (a Attributes) validate(field string) error {
+	if len(a) > MaxAttributes {
+		return NewValidationError(field, fmt.Sprintf("must not have more than %d keys", MaxAttributes))
+	}
+
+	var errs ValidationErrors
+	for _, key := range slices.Sorted(maps.Keys(a)) {
+		field := field + "." + key
+		if !attributeKeyPattern.MatchString(key) {
+			errs.Add(NewValidationError(field, "key must start with a lowercase letter and contain only lowercase letters, digits and underscores (max 64 characters)"))
+			continue
+		}
+
+		switch v := a[key].(type) {
+		case string:
+			if len(v) > MaxAttributeValueLength {
+				errs.Add(NewValidationError(field, fmt.Sprintf("must not exceed %d characters", MaxAttributeValueLength)))
+			}
+		case bool, float64, float32, int, int32, int64, json.Number:
+		default:
+			errs.Add(NewValidationError(field, "must be a string, number or boolean"))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	encoded, err := json.Marshal(a)
+	if err != nil {
+		return NewValidationError(field, "must be valid JSON")
+	}
+	if len(encoded) > MaxAttributesSize {
+		return NewValidationError(field, fmt.Sprintf("must not exceed %d bytes", MaxAttributesSize))
+	}
+
+	return nil
+}
+
+// Merge returns a copy of a with changes applied.
+// A nil value in changes removes the key (JSON merge patch semantics).
+func (a Attributes) Merge(changes map[string]any) Attributes {
+	merged := maps.Clone(a)
+	if merged == nil {
+		merged = Attributes{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// AttributeOperator is a comparison used by attribute filters
+type AttributeOperator string
+
+const (
+	AttributeOpExists       AttributeOperator = "exists"
+	AttributeOpEqual        AttributeOperator = "="
+	AttributeOpNotEqual     AttributeOperator = "!="
+	AttributeOpGreater      AttributeOperator = ">"
+	AttributeOpGreaterEqual AttributeOperator = ">="
+	AttributeOpLess         AttributeOperator = "<"
+	AttributeOpLessEqual    AttributeOperator = "<="
+)
+
+// AttributeFilter matches devices by a single custom attribute
+type AttributeFilter struct {
+	Key      string
+	Operator AttributeOperator
+	Value    string
+}
+
+// IsRange reports whether the filter compares numerically
+func (f AttributeFilter) IsRange() bool {
+	switch f.Operator {
+	case AttributeOpGreater, AttributeOpGreaterEqual, AttributeOpLess, AttributeOpLessEqual:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseAttributeFilter parses an expression such as "os=ios", "ram_gb>=16"
+// or "warranty" (key exists). The "attr." prefix used in query strings must
+// already be stripped.
+func ParseAttributeFilter(expr string) (AttributeFilter, error) {
+	// Longest operators first so ">=" is not read as ">"
+	for _, op := range []AttributeOperator{
+		AttributeOpGreaterEqual, AttributeOpLessEqual, AttributeOpNotEqual,
+		AttributeOpGreater, AttributeOpLess, AttributeOpEqual,
+	} {
+		key, value, found := strings.Cut(expr, string(op))
+		if !found || strings.ContainsAny(key, "<>!=") {
+			continue
+		}
+		filter := AttributeFilter{Key: key, Operator: op, Value: value}
+		return filter, filter.Validate()
+	}
+
+	filter := AttributeFilter{Key: expr, Operator: AttributeOpExists}
+	return filter, filter.Validate()
+}
+
+// Validate checks the key and, for range operators, that the value is numeric
+func (f AttributeFilter) Validate() error {
+	field := "attr." + f.Key
+	if !attributeKeyPattern.MatchString(f.Key) {
+		return NewValidationError(field, "invalid attribute key")
+	}
+	if f.IsRange() {
+		if _, err := strconv.ParseFloat(f.Value, 64); err != nil {
+			return NewValidationError(field, fmt.Sprintf("operator %s requires a numeric value", f.Operator))
+		}
+	}
+	if len(f.Value) > MaxAttributeValueLength {
+		return NewValidationError(field, fmt.Sprintf("value must not exceed %d characters", MaxAttributeValueLength))
+	}
+	return nil
+}
+
+// DeviceFilter narrows device listings; zero-valued fields are ignored.
+// LocationID matches devices in the location or any of its descendants.
+// AsOf selects devices as they were at that time, including devices deleted since.
+type DeviceFilter struct {
+	Brand      string
+	State      DeviceState
+	Attributes []AttributeFilter
+	Labels     LabelSelector
+	LocationID *uuid.UUID
+	ModelID    *uuid.UUID
+	Category   DeviceCategory
+	AsOf       *time.Time
+}
+
+// Validate checks every filter criterion
+func (f DeviceFilter) Validate() error {
+	if f.State != "" {
+		if err := f.State.IsValid(); err != nil {
+			return err
+		}
+	}
+	if f.Category != "" {
+		if err := f.Category.IsValid(); err != nil {
+			return err
+		}
+	}
+	for _, attr := range f.Attributes {
+		if err := attr.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, requirement := range f.Labels {
+		if err := ValidateLabelKey(requirement.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
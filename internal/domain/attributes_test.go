@@ -0,0 +1,73 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAttributeFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want domain.AttributeFilter
+	}{
+		{"os=ios", domain.AttributeFilter{Key: "os", Operator: domain.AttributeOpEqual, Value: "ios"}},
+		{"os!=android", domain.AttributeFilter{Key: "os", Operator: domain.AttributeOpNotEqual, Value: "android"}},
+		{"ram_gb>=16", domain.AttributeFilter{Key: "ram_gb", Operator: domain.AttributeOpGreaterEqual, Value: "16"}},
+		{"ram_gb<=32", domain.AttributeFilter{Key: "ram_gb", Operator: domain.AttributeOpLessEqual, Value: "32"}},
+		{"ram_gb>8", domain.AttributeFilter{Key: "ram_gb", Operator: domain.AttributeOpGreater, Value: "8"}},
+		{"ram_gb<64", domain.AttributeFilter{Key: "ram_gb", Operator: domain.AttributeOpLess, Value: "64"}},
+		{"note=a>b", domain.AttributeFilter{Key: "note", Operator: domain.AttributeOpEqual, Value: "a>b"}},
+		{"warranty", domain.AttributeFilter{Key: "warranty", Operator: domain.AttributeOpExists}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := domain.ParseAttributeFilter(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseAttributeFilter_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "OS=ios", "ram gb=8", "ram_gb>=lots", "=ios"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := domain.ParseAttributeFilter(expr)
+			assert.True(t, domain.IsValidationError(err))
+		})
+	}
+}
+
+func TestAttributes_Merge(t *testing.T) {
+	original := domain.Attributes{"os": "ios", "po": "PO-1"}
+
+	merged := original.Merge(map[string]any{"os": "ipados", "po": nil, "ram_gb": 8.0})
+
+	assert.Equal(t, domain.Attributes{"os": "ipados", "ram_gb": 8.0}, merged)
+	assert.Equal(t, domain.Attributes{"os": "ios", "po": "PO-1"}, original, "original is untouched")
+}
+
+func TestAttributes_Validate_ReportsEveryInvalidKey(t *testing.T) {
+	attributes := domain.Attributes{
+		"os":     "ios",
+		"zz_bad": []any{"x"},
+		"Bad":    "x",
+		"note":   strings.Repeat("a", domain.MaxAttributeValueLength+1),
+		"1st":    true,
+	}
+
+	for range 10 {
+		var violations domain.ValidationErrors
+		require.ErrorAs(t, attributes.Validate(), &violations)
+		fields := make([]string, len(violations))
+		for i, violation := range violations {
+			fields[i] = violation.Field
+		}
+		assert.Equal(t, []string{"attributes.1st", "attributes.Bad", "attributes.note", "attributes.zz_bad"}, fields)
+	}
+}
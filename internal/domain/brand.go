@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Brand is a manufacturer in the brand catalog. Devices reference brands by ID;
+// names and aliases are matched case-insensitively, ignoring surrounding spaces.
+type Brand struct {
+	ID          uuid.UUID
+	Name        string
+	Aliases     []string
+	DeviceCount int
+	CreatedAt   time.Time
+}
+
+// ValidateBrandName validates a brand name or alias reported under field
+func ValidateBrandName(field, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return NewValidationError(field, "cannot be empty")
+	}
+	if len(name) < 2 {
+		return NewValidationError(field, "must be at least 2 characters")
+	}
+	if len(name) > 50 {
+		return NewValidationError(field, "must not exceed 50 characters")
+	}
+	return nil
+}
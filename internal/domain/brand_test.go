@@ -0,0 +1,38 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateBrandName(t *testing.T) {
+	tests := []struct {
+		name    string
+		brand   string
+		wantErr bool
+	}{
+		{"valid", "Apple", false},
+		{"surrounding spaces", "  HP ", false},
+		{"empty", "   ", true},
+		{"too short", "A", true},
+		{"too long", strings.Repeat("x", 51), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := domain.ValidateBrandName("alias", tt.brand)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *domain.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, "alias", validationErr.Field)
+			}
+		})
+	}
+}
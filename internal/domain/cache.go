@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DeviceCache is implemented by device repositories that cache reads.
+// It lets operators inspect the cache and drop entries that went stale.
+type DeviceCache interface {
+	// Invalidate drops the cached copy of a device, if any
+	Invalidate(id uuid.UUID)
+
+	// Purge drops every cached device
+	Purge()
+
+	// Stats returns the cache counters since startup
+	Stats() CacheStats
+}
+
+// CacheStats counts how a cache served reads
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+	Capacity  int
+}
+
+// freshReadKey marks contexts whose reads must not be served from a cache
+type freshReadKey struct{}
+
+// WithFreshReads returns a context whose reads bypass caches. Read-modify-write
+// paths use it so business rules are checked against, and changes are based on,
+// the stored state rather than a cached copy.
+func WithFreshReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshReadKey{}, true)
+}
+
+// FreshReadsRequired reports whether reads in ctx must bypass caches
+func FreshReadsRequired(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshReadKey{}).(bool)
+	return fresh
+}
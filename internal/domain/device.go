@@ -2,8 +2,10 @@ package domain
 
 import (
 	"fmt"
+	"maps"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 )
@@ -15,35 +17,157 @@ const (
 	DeviceStateActive   DeviceState = "active"
 	DeviceStateInUse    DeviceState = "in-use"
 	DeviceStateInactive DeviceState = "inactive"
+	// DeviceStateMaintenance is entered by starting a maintenance and left by completing it
+	DeviceStateMaintenance DeviceState = "maintenance"
 )
 
 // IsValid checks if the device state is valid
 func (s DeviceState) IsValid() error {
 	switch s {
-	case DeviceStateActive, DeviceStateInUse, DeviceStateInactive:
+	case DeviceStateActive, DeviceStateInUse, DeviceStateInactive, DeviceStateMaintenance:
 		return nil
 	default:
-		return NewValidationError("state", fmt.Sprintf("invalid state: %s (must be: active, in-use, or inactive)", s))
+		return NewValidationError("state", fmt.Sprintf("invalid state: %s (must be: active, in-use, inactive, or maintenance)", s))
 	}
 }
 
-// Device represents a hardware device in the system
+// Device represents a hardware device in the system.
+// Brand holds the brand name; BrandID is assigned by the repository when
+// the name is resolved against the brand catalog.
+// Category comes from the device's model and selects extra validation rules.
+// SerialNumber is optional (empty means none) and unique per brand.
+// PurchaseDate, WarrantyEnd and EOLDate are optional calendar dates (midnight UTC).
+// LastSeenAt and Reported are set by heartbeats and never change through Update.
 type Device struct {
-	ID        uuid.UUID
-	Name      string
-	Brand     string
-	CreatedAt time.Time
-	State     DeviceState
+	ID           uuid.UUID
+	Name         string
+	Brand        string
+	BrandID      uuid.UUID
+	SerialNumber string
+	CreatedAt    time.Time
+	State        DeviceState
+	Attributes   Attributes
+	Labels       Labels
+	LocationID   *uuid.UUID
+	ModelID      *uuid.UUID
+	Category     DeviceCategory
+	PurchaseDate *time.Time
+	WarrantyEnd  *time.Time
+	EOLDate      *time.Time
+	LastSeenAt   *time.Time
+	Reported     Attributes
+}
+
+// MaxSerialNumberLength is the longest accepted serial number
+const MaxSerialNumberLength = 64
+
+// DeviceOption sets optional device fields on creation or update
+type DeviceOption func(*Device)
+
+// WithAttributes sets the device's custom attributes
+func WithAttributes(attributes Attributes) DeviceOption {
+	return func(d *Device) {
+		if attributes == nil {
+			attributes = Attributes{}
+		}
+		d.Attributes = attributes
+	}
+}
+
+// WithLabels sets the device's labels
+func WithLabels(labels Labels) DeviceOption {
+	return func(d *Device) {
+		if labels == nil {
+			labels = Labels{}
+		}
+		d.Labels = labels
+	}
+}
+
+// WithLocation assigns the device to a location; nil removes the assignment
+func WithLocation(locationID *uuid.UUID) DeviceOption {
+	return func(d *Device) {
+		d.LocationID = locationID
+	}
+}
+
+// WithModel references a catalog model; nil removes the reference.
+// The category is unknown until the model is attached with AssignModel.
+func WithModel(modelID *uuid.UUID) DeviceOption {
+	return func(d *Device) {
+		if !SameID(d.ModelID, modelID) {
+			d.Category = ""
+		}
+		d.ModelID = modelID
+	}
+}
+
+// WithSerialNumber sets the device's serial number; an empty value removes it
+func WithSerialNumber(serialNumber string) DeviceOption {
+	return func(d *Device) {
+		d.SerialNumber = strings.TrimSpace(serialNumber)
+	}
+}
+
+// WithPurchaseDate sets the purchase date; nil removes it
+func WithPurchaseDate(date *time.Time) DeviceOption {
+	return func(d *Device) {
+		d.PurchaseDate = dateOnly(date)
+	}
+}
+
+// WithWarrantyEnd sets the last day of the warranty; nil removes it
+func WithWarrantyEnd(date *time.Time) DeviceOption {
+	return func(d *Device) {
+		d.WarrantyEnd = dateOnly(date)
+	}
+}
+
+// WithEOLDate sets the planned end-of-life date; nil removes it
+func WithEOLDate(date *time.Time) DeviceOption {
+	return func(d *Device) {
+		d.EOLDate = dateOnly(date)
+	}
+}
+
+// DevicePatch describes a partial update; nil fields are left unchanged.
+// Attributes and labels are merged into the existing ones, and a nil value removes a key.
+// LocationID moves the device; ClearLocation removes it from its location.
+// ModelID and ClearModel change the model reference the same way.
+// An empty SerialNumber removes the serial number.
+// Each date has a Clear flag that removes it.
+type DevicePatch struct {
+	Name              *string
+	Brand             *string
+	SerialNumber      *string
+	State             *DeviceState
+	Attributes        map[string]any
+	Labels            map[string]*string
+	LocationID        *uuid.UUID
+	ClearLocation     bool
+	ModelID           *uuid.UUID
+	ClearModel        bool
+	PurchaseDate      *time.Time
+	ClearPurchaseDate bool
+	WarrantyEnd       *time.Time
+	ClearWarrantyEnd  bool
+	EOLDate           *time.Time
+	ClearEOLDate      bool
 }
 
 // NewDevice creates a new device with validation
-func NewDevice(name, brand string) (*Device, error) {
+func NewDevice(name, brand string, opts ...DeviceOption) (*Device, error) {
 	device := &Device{
-		ID:        uuid.New(),
-		Name:      name,
-		Brand:     brand,
-		CreatedAt: time.Now().UTC(),
-		State:     DeviceStateActive,
+		ID:         uuid.New(),
+		Name:       name,
+		Brand:      brand,
+		CreatedAt:  time.Now().UTC(),
+		State:      DeviceStateActive,
+		Attributes: Attributes{},
+		Labels:     Labels{},
+	}
+	for _, opt := range opts {
+		opt(device)
 	}
 
 	if err := device.Validate(); err != nil {
@@ -53,29 +177,28 @@ func NewDevice(name, brand string) (*Device, error) {
 	return device, nil
 }
 
-// Validate checks if the device has valid data
+// Validate checks if the device has valid data.
+// It reports every invalid field at once as ValidationErrors.
 func (d *Device) Validate() error {
+	var errs ValidationErrors
 	if d.ID == uuid.Nil {
-		return NewValidationError("id", "cannot be empty")
-	}
-
-	if err := d.ValidateName(); err != nil {
-		return err
-	}
-
-	if err := d.ValidateBrand(); err != nil {
-		return err
+		errs.Add(NewValidationError("id", "cannot be empty"))
 	}
-
+	errs.Add(d.ValidateName())
+	errs.Add(d.ValidateBrand())
+	errs.Add(d.ValidateSerialNumber())
 	if d.CreatedAt.IsZero() {
-		return NewValidationError("created_at", "cannot be empty")
+		errs.Add(NewValidationError("created_at", "cannot be empty"))
 	}
-
-	if err := d.ValidateState(); err != nil {
-		return err
+	errs.Add(d.ValidateState())
+	errs.Add(d.Attributes.Validate())
+	errs.Add(d.Labels.Validate())
+	errs.Add(d.ValidateDates())
+	if d.Category != "" {
+		errs.Add(d.Category.Validate(d))
 	}
 
-	return nil
+	return errs.Err()
 }
 
 // ValidateName validates the device name
@@ -95,19 +218,41 @@ func (d *Device) ValidateName() error {
 
 // ValidateBrand validates the device brand
 func (d *Device) ValidateBrand() error {
-	brand := strings.TrimSpace(d.Brand)
-	if brand == "" {
-		return NewValidationError("brand", "cannot be empty")
+	return ValidateBrandName("brand", d.Brand)
+}
+
+// ValidateSerialNumber validates the optional serial number.
+// Serial numbers appear in lookup paths, so they cannot contain whitespace or slashes.
+func (d *Device) ValidateSerialNumber() error {
+	if d.SerialNumber == "" {
+		return nil
 	}
-	if len(brand) < 2 {
-		return NewValidationError("brand", "must be at least 2 characters")
+	if len(d.SerialNumber) > MaxSerialNumberLength {
+		return NewValidationError("serial_number", fmt.Sprintf("must not exceed %d characters", MaxSerialNumberLength))
 	}
-	if len(brand) > 50 {
-		return NewValidationError("brand", "must not exceed 50 characters")
+	if strings.ContainsFunc(d.SerialNumber, func(r rune) bool { return r == '/' || unicode.IsSpace(r) }) {
+		return NewValidationError("serial_number", "must not contain whitespace or slashes")
 	}
 	return nil
 }
 
+// ValidateDates checks that the warranty end and EOL date come after the purchase date,
+// and that the warranty does not end before the device was created
+func (d *Device) ValidateDates() error {
+	var errs ValidationErrors
+	if d.WarrantyEnd != nil {
+		if d.PurchaseDate != nil && !d.WarrantyEnd.After(*d.PurchaseDate) {
+			errs.Add(NewValidationError("warranty_end", "must be after purchase_date"))
+		} else if d.WarrantyEnd.Before(*dateOnly(&d.CreatedAt)) {
+			errs.Add(NewValidationError("warranty_end", "must not be before the device was created"))
+		}
+	}
+	if d.EOLDate != nil && d.PurchaseDate != nil && !d.EOLDate.After(*d.PurchaseDate) {
+		errs.Add(NewValidationError("eol_date", "must be after purchase_date"))
+	}
+	return errs.Err()
+}
+
 // ValidateState validates the device state
 func (d *Device) ValidateState() error {
 	return d.State.IsValid()
@@ -126,29 +271,57 @@ func (d *Device) CanUpdate(newName, newBrand string) error {
 	return nil
 }
 
+// CanChangeState checks that a state change does not enter or leave the "maintenance" state,
+// which only starting and completing a maintenance may do
+func (d *Device) CanChangeState(newState DeviceState) error {
+	if newState == d.State {
+		return nil
+	}
+	if newState == DeviceStateMaintenance || d.State == DeviceStateMaintenance {
+		return NewBusinessRuleError("the 'maintenance' state is changed by starting or completing a maintenance")
+	}
+	return nil
+}
+
 // CanDelete checks if the device can be deleted based on business rules
-// Devices in "in-use" state cannot be deleted
+// Devices in "in-use" or "maintenance" state cannot be deleted
 func (d *Device) CanDelete() error {
-	if d.State == DeviceStateInUse {
-		return NewBusinessRuleError("cannot delete device in 'in-use' state")
+	if d.State == DeviceStateInUse || d.State == DeviceStateMaintenance {
+		return NewBusinessRuleError(fmt.Sprintf("cannot delete device in '%s' state", d.State))
 	}
 	return nil
 }
 
-// Update updates the device fields with validation
-func (d *Device) Update(name, brand string, state DeviceState) error {
+// Update updates the device fields with validation.
+// Attributes, labels and location are only replaced when the matching option is passed.
+func (d *Device) Update(name, brand string, state DeviceState, opts ...DeviceOption) error {
 	// Check business rules
 	if err := d.CanUpdate(name, brand); err != nil {
 		return err
 	}
+	if err := d.CanChangeState(state); err != nil {
+		return err
+	}
 
 	// Create temporary device to validate new values
 	temp := &Device{
-		ID:        d.ID,
-		Name:      name,
-		Brand:     brand,
-		CreatedAt: d.CreatedAt,
-		State:     state,
+		ID:           d.ID,
+		Name:         name,
+		Brand:        brand,
+		SerialNumber: d.SerialNumber,
+		CreatedAt:    d.CreatedAt,
+		State:        state,
+		Attributes:   d.Attributes,
+		Labels:       d.Labels,
+		LocationID:   d.LocationID,
+		ModelID:      d.ModelID,
+		Category:     d.Category,
+		PurchaseDate: d.PurchaseDate,
+		WarrantyEnd:  d.WarrantyEnd,
+		EOLDate:      d.EOLDate,
+	}
+	for _, opt := range opts {
+		opt(temp)
 	}
 
 	if err := temp.Validate(); err != nil {
@@ -156,9 +329,161 @@ func (d *Device) Update(name, brand string, state DeviceState) error {
 	}
 
 	// Apply updates
-	d.Name = name
-	d.Brand = brand
-	d.State = state
+	d.Name = temp.Name
+	d.Brand = temp.Brand
+	d.SerialNumber = temp.SerialNumber
+	d.State = temp.State
+	d.Attributes = temp.Attributes
+	d.Labels = temp.Labels
+	d.LocationID = temp.LocationID
+	d.ModelID = temp.ModelID
+	d.Category = temp.Category
+	d.PurchaseDate = temp.PurchaseDate
+	d.WarrantyEnd = temp.WarrantyEnd
+	d.EOLDate = temp.EOLDate
+
+	return nil
+}
+
+// ApplyPatch applies a partial update with the same validation and business rules as Update
+func (d *Device) ApplyPatch(patch DevicePatch) error {
+	name, brand, state := d.Name, d.Brand, d.State
+	if patch.Name != nil {
+		name = *patch.Name
+	}
+	if patch.Brand != nil {
+		brand = *patch.Brand
+	}
+	if patch.State != nil {
+		state = *patch.State
+	}
+
+	var opts []DeviceOption
+	if patch.SerialNumber != nil {
+		opts = append(opts, WithSerialNumber(*patch.SerialNumber))
+	}
+	if patch.Attributes != nil {
+		opts = append(opts, WithAttributes(d.Attributes.Merge(patch.Attributes)))
+	}
+	if patch.Labels != nil {
+		opts = append(opts, WithLabels(d.Labels.Merge(patch.Labels)))
+	}
+	if patch.ClearLocation {
+		opts = append(opts, WithLocation(nil))
+	} else if patch.LocationID != nil {
+		opts = append(opts, WithLocation(patch.LocationID))
+	}
+	if patch.ClearModel {
+		opts = append(opts, WithModel(nil))
+	} else if patch.ModelID != nil {
+		opts = append(opts, WithModel(patch.ModelID))
+	}
+	if patch.ClearPurchaseDate {
+		opts = append(opts, WithPurchaseDate(nil))
+	} else if patch.PurchaseDate != nil {
+		opts = append(opts, WithPurchaseDate(patch.PurchaseDate))
+	}
+	if patch.ClearWarrantyEnd {
+		opts = append(opts, WithWarrantyEnd(nil))
+	} else if patch.WarrantyEnd != nil {
+		opts = append(opts, WithWarrantyEnd(patch.WarrantyEnd))
+	}
+	if patch.ClearEOLDate {
+		opts = append(opts, WithEOLDate(nil))
+	} else if patch.EOLDate != nil {
+		opts = append(opts, WithEOLDate(patch.EOLDate))
+	}
+
+	return d.Update(name, brand, state, opts...)
+}
+
+// AssignModel attaches the device to model and validates it against the rules
+// of the model's category. With prefill, default attributes of the model that
+// the device does not set are copied to it, and a device with a purchase date
+// but no EOL date gets one from the model's lifecycle policy.
+// The device's brand must be the model's brand.
+func (d *Device) AssignModel(model *Model, prefill bool) error {
+	if !strings.EqualFold(strings.TrimSpace(d.Brand), strings.TrimSpace(model.Brand)) {
+		return NewValidationError("model_id", fmt.Sprintf("model belongs to brand %s", model.Brand))
+	}
+
+	temp := *d
+	temp.ModelID = &model.ID
+	temp.Category = model.Category
+	if prefill {
+		temp.Attributes = maps.Clone(d.Attributes)
+		if temp.Attributes == nil {
+			temp.Attributes = Attributes{}
+		}
+		for key, value := range model.DefaultAttributes {
+			if _, ok := temp.Attributes[key]; !ok {
+				temp.Attributes[key] = value
+			}
+		}
+		if temp.EOLDate == nil && temp.PurchaseDate != nil && model.LifecycleMonths > 0 {
+			eol := temp.PurchaseDate.AddDate(0, model.LifecycleMonths, 0)
+			temp.EOLDate = &eol
+		}
+	}
+
+	if err := temp.Validate(); err != nil {
+		return err
+	}
+
+	*d = temp
+	return nil
+}
 
+// dateOnly truncates an optional timestamp to midnight UTC of its calendar day
+func dateOnly(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	year, month, day := t.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
+// SameID reports whether two optional IDs are equal
+func SameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Clone returns a copy of the device that shares no maps or pointers with it
+func (d *Device) Clone() *Device {
+	clone := *d
+	clone.Attributes = maps.Clone(d.Attributes)
+	clone.Labels = maps.Clone(d.Labels)
+	clone.Reported = maps.Clone(d.Reported)
+	clone.LocationID = clonePtr(d.LocationID)
+	clone.ModelID = clonePtr(d.ModelID)
+	clone.PurchaseDate = clonePtr(d.PurchaseDate)
+	clone.WarrantyEnd = clonePtr(d.WarrantyEnd)
+	clone.EOLDate = clonePtr(d.EOLDate)
+	clone.LastSeenAt = clonePtr(d.LastSeenAt)
+	return &clone
+}
+
+// clonePtr copies the value behind an optional pointer
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// SetLabels replaces all labels. Labels are metadata, so they can change in any state.
+func (d *Device) SetLabels(labels Labels) error {
+	if labels == nil {
+		labels = Labels{}
+	}
+	if err := labels.Validate(); err != nil {
+		return err
+	}
+	d.Labels = labels
 	return nil
 }
@@ -3,12 +3,19 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Common domain errors
 var (
 	ErrDeviceNotFound      = errors.New("device not found")
 	ErrDeviceAlreadyExists = errors.New("device already exists")
+	ErrLabelNotFound       = errors.New("label not found")
+	ErrLocationNotFound    = errors.New("location not found")
+	ErrBrandNotFound       = errors.New("brand not found")
+	ErrModelNotFound       = errors.New("model not found")
+	ErrMaintenanceNotFound = errors.New("maintenance not found")
+	ErrUnauthorized        = errors.New("invalid or missing credentials")
 	ErrInvalidInput        = errors.New("invalid input")
 	ErrBusinessRule        = errors.New("business rule violation")
 )
@@ -31,7 +38,49 @@ func NewValidationError(field, message string) error {
 	}
 }
 
-// IsValidationError checks if an error is a ValidationError
+// ValidationErrors collects every field violation found while validating a value.
+// errors.As finds each violation, so callers matching *ValidationError get the first one.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the violations, so errors.As and errors.Is inspect each of them
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// Add records the violations of err, which must be nil or a validation error
+func (e *ValidationErrors) Add(err error) {
+	var violations ValidationErrors
+	if errors.As(err, &violations) {
+		*e = append(*e, violations...)
+		return
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		*e = append(*e, validationErr)
+	}
+}
+
+// Err returns the collected violations, or nil when there are none
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// IsValidationError checks if an error is a ValidationError or ValidationErrors
 func IsValidationError(err error) bool {
 	var validationErr *ValidationError
 	return errors.As(err, &validationErr)
@@ -59,9 +108,40 @@ func IsBusinessRuleError(err error) bool {
 	return errors.As(err, &businessRuleErr)
 }
 
+// ConflictError reports that a unique field is already taken by another resource.
+// It wraps the matching sentinel, such as ErrDeviceAlreadyExists.
+type ConflictError struct {
+	Field   string
+	Message string
+	Err     error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflict on field '%s': %s", e.Field, e.Message)
+}
+
+// Unwrap returns the sentinel error, so errors.Is matches it
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// NewConflictError creates a new conflict error wrapping err
+func NewConflictError(err error, field, message string) error {
+	return &ConflictError{
+		Field:   field,
+		Message: message,
+		Err:     err,
+	}
+}
+
 // IsNotFoundError checks if an error is a not found error
 func IsNotFoundError(err error) bool {
-	return errors.Is(err, ErrDeviceNotFound)
+	return errors.Is(err, ErrDeviceNotFound) ||
+		errors.Is(err, ErrLabelNotFound) ||
+		errors.Is(err, ErrLocationNotFound) ||
+		errors.Is(err, ErrBrandNotFound) ||
+		errors.Is(err, ErrModelNotFound) ||
+		errors.Is(err, ErrMaintenanceNotFound)
 }
 
 // IsAlreadyExistsError checks if an error is an already exists error
@@ -0,0 +1,67 @@
+package domain_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDevice_ReportsEveryInvalidField(t *testing.T) {
+	_, err := domain.NewDevice("  ", "A",
+		domain.WithSerialNumber("has space"),
+		domain.WithLabels(domain.Labels{"-bad": "x"}),
+		domain.WithPurchaseDate(date(2024, 1, 15)),
+		domain.WithEOLDate(date(2023, 12, 31)),
+	)
+	require.Error(t, err)
+	assert.True(t, domain.IsValidationError(err))
+
+	var violations domain.ValidationErrors
+	require.ErrorAs(t, err, &violations)
+	fields := make([]string, len(violations))
+	for i, violation := range violations {
+		fields[i] = violation.Field
+	}
+	assert.Equal(t, []string{"name", "brand", "serial_number", "labels.-bad", "eol_date"}, fields)
+
+	// Callers matching a single violation get the first one
+	var first *domain.ValidationError
+	require.ErrorAs(t, err, &first)
+	assert.Equal(t, "name", first.Field)
+}
+
+func TestNewDevice_SingleInvalidField(t *testing.T) {
+	_, err := domain.NewDevice("Pixel 8", "x")
+	require.Error(t, err)
+	assert.EqualError(t, err, "validation error on field 'brand': must be at least 2 characters")
+
+	var violations domain.ValidationErrors
+	require.ErrorAs(t, err, &violations)
+	assert.Len(t, violations, 1)
+}
+
+func TestValidationErrors(t *testing.T) {
+	var errs domain.ValidationErrors
+	errs.Add(nil)
+	assert.NoError(t, errs.Err())
+
+	errs.Add(domain.NewValidationError("name", "cannot be empty"))
+	errs.Add(fmt.Errorf("wrapped: %w", domain.ValidationErrors{
+		{Field: "brand", Message: "cannot be empty"},
+		{Field: "state", Message: "invalid state: x"},
+	}))
+	require.Len(t, errs, 3)
+	assert.Equal(t, "state", errs[2].Field)
+
+	err := fmt.Errorf("create device: %w", errs.Err())
+	assert.True(t, domain.IsValidationError(err))
+	assert.False(t, domain.IsBusinessRuleError(err))
+	assert.EqualError(t, err, "create device: validation error on field 'name': cannot be empty; "+
+		"validation error on field 'brand': cannot be empty; validation error on field 'state': invalid state: x")
+	assert.False(t, errors.Is(err, domain.ErrDeviceNotFound))
+}
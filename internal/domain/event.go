@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types emitted by background jobs
+const (
+	// EventDeviceExpiringSoon is emitted when a device's warranty or EOL date comes within a threshold
+	EventDeviceExpiringSoon = "device.expiring_soon"
+	// EventDeviceStale is emitted when a device that stopped sending heartbeats is made inactive
+	EventDeviceStale = "device.stale"
+)
+
+// Event is a notification about something that happened to a device
+type Event struct {
+	Type       string
+	DeviceID   uuid.UUID
+	OccurredAt time.Time
+	Data       map[string]any
+}
+
+// EventPublisher delivers events to interested parties.
+// This interface is defined in the domain layer; implementations live elsewhere.
+type EventPublisher interface {
+	// Publish delivers a single event
+	Publish(ctx context.Context, event Event) error
+}
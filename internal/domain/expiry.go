@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExpiryKind names the date that expires
+type ExpiryKind string
+
+const (
+	ExpiryKindWarranty ExpiryKind = "warranty"
+	ExpiryKindEOL      ExpiryKind = "eol"
+)
+
+// MaxExpiryWindowDays bounds how far ahead expiry reports may look
+const MaxExpiryWindowDays = 3650
+
+// Expiry is a device whose warranty or planned end of life falls on Date
+type Expiry struct {
+	DeviceID     uuid.UUID
+	DeviceName   string
+	Brand        string
+	BrandID      uuid.UUID
+	SerialNumber string
+	Kind         ExpiryKind
+	Date         time.Time
+}
+
+// DaysLeft returns the number of days from today until the expiry date
+func (e Expiry) DaysLeft(today time.Time) int {
+	return int(e.Date.Sub(*dateOnly(&today)).Hours() / 24)
+}
+
+// BrandExpiries holds the expiries of one brand, ordered by date
+type BrandExpiries struct {
+	BrandID  uuid.UUID
+	Brand    string
+	Expiries []Expiry
+}
+
+// ExpiryReport lists the expiries between From and To (inclusive), grouped by brand
+type ExpiryReport struct {
+	From   time.Time
+	To     time.Time
+	Brands []BrandExpiries
+}
+
+// NewExpiryReport groups expiries by brand. Expiries of one brand must be adjacent,
+// as returned by ExpiryRepository.ListExpiring.
+func NewExpiryReport(from, to time.Time, expiries []Expiry) *ExpiryReport {
+	report := &ExpiryReport{From: from, To: to, Brands: []BrandExpiries{}}
+	for _, expiry := range expiries {
+		last := len(report.Brands) - 1
+		if last < 0 || report.Brands[last].BrandID != expiry.BrandID {
+			report.Brands = append(report.Brands, BrandExpiries{BrandID: expiry.BrandID, Brand: expiry.Brand})
+			last++
+		}
+		report.Brands[last].Expiries = append(report.Brands[last].Expiries, expiry)
+	}
+	return report
+}
+
+// ExpiryWindow returns the first and last day of a report looking days ahead of now
+func ExpiryWindow(now time.Time, days int) (time.Time, time.Time, error) {
+	if days < 1 || days > MaxExpiryWindowDays {
+		return time.Time{}, time.Time{}, NewValidationError("within", fmt.Sprintf("must be between 1 and %d days", MaxExpiryWindowDays))
+	}
+	from := *dateOnly(&now)
+	return from, from.AddDate(0, 0, days), nil
+}
+
+// ExpiryNotice records that an expiring soon event was emitted for an expiry
+// once it came within ThresholdDays
+type ExpiryNotice struct {
+	Expiry
+	ThresholdDays int
+}
+
+// ReachedThreshold returns the smallest threshold (in days) that daysLeft is within,
+// and false when it is outside all of them. Emitting only the smallest threshold
+// keeps a device first seen 5 days before expiry from also triggering the 30 day notice.
+func ReachedThreshold(daysLeft int, thresholds []int) (int, bool) {
+	sorted := slices.Clone(thresholds)
+	slices.Sort(sorted)
+	for _, threshold := range sorted {
+		if daysLeft <= threshold {
+			return threshold, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,145 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestNewDevice_DateValidation(t *testing.T) {
+	today := time.Now().UTC()
+	nextYear := date(today.Year()+1, today.Month(), 1)
+	tests := []struct {
+		name      string
+		opts      []domain.DeviceOption
+		wantField string
+	}{
+		{"no dates", nil, ""},
+		{"valid dates", []domain.DeviceOption{
+			domain.WithPurchaseDate(date(2024, 1, 15)),
+			domain.WithWarrantyEnd(nextYear),
+			domain.WithEOLDate(nextYear),
+		}, ""},
+		{"warranty equal to purchase", []domain.DeviceOption{
+			domain.WithPurchaseDate(nextYear),
+			domain.WithWarrantyEnd(nextYear),
+		}, "warranty_end"},
+		{"eol before purchase", []domain.DeviceOption{
+			domain.WithPurchaseDate(date(2024, 1, 15)),
+			domain.WithEOLDate(date(2023, 12, 31)),
+		}, "eol_date"},
+		{"warranty ended before creation", []domain.DeviceOption{
+			domain.WithWarrantyEnd(date(2020, 1, 1)),
+		}, "warranty_end"},
+		{"past eol is allowed", []domain.DeviceOption{
+			domain.WithEOLDate(date(2020, 1, 1)),
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domain.NewDevice("Laptop", "Apple", tt.opts...)
+			if tt.wantField == "" {
+				require.NoError(t, err)
+				return
+			}
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.wantField, validationErr.Field)
+		})
+	}
+}
+
+func TestWithPurchaseDate_TruncatesToDay(t *testing.T) {
+	ts := time.Date(2024, 3, 10, 23, 30, 0, 0, time.UTC)
+
+	device, err := domain.NewDevice("Laptop", "Apple", domain.WithPurchaseDate(&ts))
+
+	require.NoError(t, err)
+	assert.Equal(t, date(2024, 3, 10), device.PurchaseDate)
+}
+
+func TestDevice_ApplyPatch_ClearsDates(t *testing.T) {
+	device, err := domain.NewDevice("Laptop", "Apple", domain.WithPurchaseDate(date(2024, 1, 15)))
+	require.NoError(t, err)
+
+	require.NoError(t, device.ApplyPatch(domain.DevicePatch{ClearPurchaseDate: true, EOLDate: date(2020, 1, 1)}))
+
+	assert.Nil(t, device.PurchaseDate)
+	assert.Equal(t, date(2020, 1, 1), device.EOLDate)
+}
+
+func TestExpiryWindow(t *testing.T) {
+	now := time.Date(2025, 6, 1, 15, 0, 0, 0, time.UTC)
+
+	from, to, err := domain.ExpiryWindow(now, 30)
+	require.NoError(t, err)
+	assert.Equal(t, *date(2025, 6, 1), from)
+	assert.Equal(t, *date(2025, 7, 1), to)
+
+	for _, days := range []int{0, -1, domain.MaxExpiryWindowDays + 1} {
+		_, _, err := domain.ExpiryWindow(now, days)
+		var validationErr *domain.ValidationError
+		require.ErrorAs(t, err, &validationErr, "days=%d", days)
+		assert.Equal(t, "within", validationErr.Field)
+	}
+}
+
+func TestExpiry_DaysLeft(t *testing.T) {
+	expiry := domain.Expiry{Date: *date(2025, 6, 8)}
+
+	assert.Equal(t, 7, expiry.DaysLeft(time.Date(2025, 6, 1, 23, 59, 0, 0, time.UTC)))
+	assert.Equal(t, 0, expiry.DaysLeft(time.Date(2025, 6, 8, 8, 0, 0, 0, time.UTC)))
+}
+
+func TestReachedThreshold(t *testing.T) {
+	thresholds := []int{30, 7, 1}
+	tests := []struct {
+		daysLeft int
+		want     int
+		wantOK   bool
+	}{
+		{45, 0, false},
+		{30, 30, true},
+		{12, 30, true},
+		{5, 7, true},
+		{0, 1, true},
+	}
+
+	for _, tt := range tests {
+		got, ok := domain.ReachedThreshold(tt.daysLeft, thresholds)
+		assert.Equal(t, tt.wantOK, ok, "daysLeft=%d", tt.daysLeft)
+		assert.Equal(t, tt.want, got, "daysLeft=%d", tt.daysLeft)
+	}
+	assert.Equal(t, []int{30, 7, 1}, thresholds, "thresholds must not be reordered")
+}
+
+func TestNewExpiryReport_GroupsByBrand(t *testing.T) {
+	apple, samsung := uuid.New(), uuid.New()
+	expiries := []domain.Expiry{
+		{DeviceName: "MacBook", BrandID: apple, Brand: "Apple", Kind: domain.ExpiryKindWarranty},
+		{DeviceName: "iPhone", BrandID: apple, Brand: "Apple", Kind: domain.ExpiryKindEOL},
+		{DeviceName: "Galaxy", BrandID: samsung, Brand: "Samsung", Kind: domain.ExpiryKindWarranty},
+	}
+
+	report := domain.NewExpiryReport(*date(2025, 6, 1), *date(2025, 7, 1), expiries)
+
+	require.Len(t, report.Brands, 2)
+	assert.Equal(t, "Apple", report.Brands[0].Brand)
+	assert.Len(t, report.Brands[0].Expiries, 2)
+	assert.Equal(t, "Samsung", report.Brands[1].Brand)
+	assert.Len(t, report.Brands[1].Expiries, 1)
+
+	empty := domain.NewExpiryReport(*date(2025, 6, 1), *date(2025, 7, 1), nil)
+	assert.NotNil(t, empty.Brands)
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Heartbeat is a report from a connected device that it is alive.
+// Reported holds the fields the device reports about itself, such as a
+// firmware version or battery level; they replace the previously reported
+// fields, and a nil Reported keeps them.
+type Heartbeat struct {
+	DeviceID uuid.UUID
+	SeenAt   time.Time
+	Reported Attributes
+}
+
+// NewHeartbeat creates a heartbeat of the device received at seenAt.
+// Reported fields follow the same rules as custom attributes.
+func NewHeartbeat(deviceID uuid.UUID, seenAt time.Time, reported Attributes) (*Heartbeat, error) {
+	if deviceID == uuid.Nil {
+		return nil, NewValidationError("device_id", "cannot be empty")
+	}
+	if reported != nil {
+		if err := reported.validate("reported"); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Heartbeat{
+		DeviceID: deviceID,
+		SeenAt:   seenAt.UTC(),
+		Reported: reported,
+	}, nil
+}
+
+// StaleCutoff returns the time before which a device must have last been seen
+// to be stale at now
+func StaleCutoff(now time.Time, staleAfter time.Duration) time.Time {
+	return now.UTC().Add(-staleAfter)
+}
+
+// Deactivate makes a device that stopped sending heartbeats inactive through
+// the usual update rules. Devices in use are left alone, since they may be
+// used offline, and devices in maintenance cannot change state.
+// It reports whether the state changed.
+func (d *Device) Deactivate() (bool, error) {
+	if d.State == DeviceStateInactive || d.State == DeviceStateInUse {
+		return false, nil
+	}
+	if err := d.Update(d.Name, d.Brand, DeviceStateInactive); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,71 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHeartbeat(t *testing.T) {
+	seenAt := time.Date(2025, 6, 1, 11, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	heartbeat, err := domain.NewHeartbeat(uuid.New(), seenAt, domain.Attributes{"firmware": "1.4.2", "battery": 80})
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, heartbeat.SeenAt.Location())
+	assert.True(t, heartbeat.SeenAt.Equal(seenAt))
+
+	heartbeat, err = domain.NewHeartbeat(uuid.New(), seenAt, nil)
+	require.NoError(t, err)
+	assert.Nil(t, heartbeat.Reported, "nil keeps the reported fields")
+
+	_, err = domain.NewHeartbeat(uuid.Nil, seenAt, nil)
+	assert.True(t, domain.IsValidationError(err))
+
+	_, err = domain.NewHeartbeat(uuid.New(), seenAt, domain.Attributes{"location": map[string]any{"lat": 1}})
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "reported.location", validationErr.Field)
+}
+
+func TestDevice_Deactivate(t *testing.T) {
+	tests := []struct {
+		state       domain.DeviceState
+		wantChanged bool
+		wantErr     bool
+		wantState   domain.DeviceState
+	}{
+		{domain.DeviceStateActive, true, false, domain.DeviceStateInactive},
+		{domain.DeviceStateInactive, false, false, domain.DeviceStateInactive},
+		{domain.DeviceStateInUse, false, false, domain.DeviceStateInUse},
+		{domain.DeviceStateMaintenance, false, true, domain.DeviceStateMaintenance},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			device, err := domain.NewDevice("Lab sensor", "Bosch")
+			require.NoError(t, err)
+			device.State = tt.state
+
+			changed, err := device.Deactivate()
+
+			assert.Equal(t, tt.wantChanged, changed)
+			if tt.wantErr {
+				assert.True(t, domain.IsBusinessRuleError(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantState, device.State)
+		})
+	}
+}
+
+func TestStaleCutoff(t *testing.T) {
+	now := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 6, 1, 8, 45, 0, 0, time.UTC), domain.StaleCutoff(now, 15*time.Minute))
+}
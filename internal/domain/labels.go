@@ -0,0 +1,386 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// MaxLabels is the maximum number of labels per device
+	MaxLabels = 64
+	// maxLabelNameLength limits label names and values, as in Kubernetes
+	maxLabelNameLength = 63
+	// maxLabelPrefixLength limits the optional DNS subdomain prefix of a key
+	maxLabelPrefixLength = 253
+)
+
+var (
+	// labelNamePattern matches label names and non-empty values
+	labelNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	// labelPrefixPattern matches a DNS subdomain such as example.com
+	labelPrefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// Labels are key/value tags (team=mobile, env=lab) used to group and select devices.
+// Keys and values follow Kubernetes label syntax.
+type Labels map[string]string
+
+// Validate checks the number of labels and the syntax of every key and value.
+// Every invalid label is reported, in key order.
+func (l Labels) Validate() error {
+	if len(l) > MaxLabels {
+		return NewValidationError("labels", fmt.Sprintf("must not have more than %d labels", MaxLabels))
+	}
+	var errs ValidationErrors
+	for _, key := range slices.Sorted(maps.Keys(l)) {
+		if err := ValidateLabelKey(key); err != nil {
+			errs.Add(err)
+			continue
+		}
+		errs.Add(ValidateLabelValue(key, l[key]))
+	}
+	return errs.Err()
+}
+
+// Merge returns a copy of l with changes applied; a nil value removes the key
+func (l Labels) Merge(changes map[string]*string) Labels {
+	merged := maps.Clone(l)
+	if merged == nil {
+		merged = Labels{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = *value
+	}
+	return merged
+}
+
+// ValidateLabelKey checks a label key: an optional DNS subdomain prefix and "/",
+// followed by a name of at most 63 alphanumerics, '-', '_' or '.'
+func ValidateLabelKey(key string) error {
+	field := "labels." + key
+
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		name, prefix = prefix, ""
+	}
+
+	if hasPrefix {
+		if prefix == "" || len(prefix) > maxLabelPrefixLength || !labelPrefixPattern.MatchString(prefix) {
+			return NewValidationError(field, "key prefix must be a lowercase DNS subdomain of at most 253 characters")
+		}
+	}
+	if name == "" {
+		return NewValidationError(field, "key name cannot be empty")
+	}
+	if len(name) > maxLabelNameLength {
+		return NewValidationError(field, "key name must not exceed 63 characters")
+	}
+	if !labelNamePattern.MatchString(name) {
+		return NewValidationError(field, "key name must start and end with an alphanumeric character and contain only alphanumerics, '-', '_' or '.'")
+	}
+	return nil
+}
+
+// ValidateLabelValue checks a label value: empty, or at most 63 characters with
+// the same character rules as a key name
+func ValidateLabelValue(key, value string) error {
+	if value == "" {
+		return nil
+	}
+	field := "labels." + key
+	if len(value) > maxLabelNameLength {
+		return NewValidationError(field, "value must not exceed 63 characters")
+	}
+	if !labelNamePattern.MatchString(value) {
+		return NewValidationError(field, "value must start and end with an alphanumeric character and contain only alphanumerics, '-', '_' or '.'")
+	}
+	return nil
+}
+
+// SelectorOperator is the operator of a label requirement
+type SelectorOperator string
+
+const (
+	SelectorOpEquals       SelectorOperator = "="
+	SelectorOpNotEquals    SelectorOperator = "!="
+	SelectorOpIn           SelectorOperator = "in"
+	SelectorOpNotIn        SelectorOperator = "notin"
+	SelectorOpExists       SelectorOperator = "exists"
+	SelectorOpDoesNotExist SelectorOperator = "!"
+)
+
+// LabelRequirement is a single clause of a label selector
+type LabelRequirement struct {
+	Key      string
+	Operator SelectorOperator
+	Values   []string
+}
+
+// Matches reports whether labels satisfy the requirement.
+// As in Kubernetes, != and notin also match devices without the key.
+func (r LabelRequirement) Matches(labels Labels) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case SelectorOpEquals, SelectorOpIn:
+		return ok && slices.Contains(r.Values, value)
+	case SelectorOpNotEquals, SelectorOpNotIn:
+		return !ok || !slices.Contains(r.Values, value)
+	case SelectorOpExists:
+		return ok
+	case SelectorOpDoesNotExist:
+		return !ok
+	default:
+		return false
+	}
+}
+
+// LabelSelector is a conjunction of label requirements
+type LabelSelector []LabelRequirement
+
+// Matches reports whether labels satisfy every requirement
+func (s LabelSelector) Matches(labels Labels) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabelSelector parses a Kubernetes-style label selector, e.g.
+//
+//	team=mobile,env!=prod,tier in (gold, silver),region notin (eu),gpu,!deprecated
+//
+// Syntax errors are returned as a ValidationError on the "selector" field.
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	p := &selectorParser{tokens: lexSelector(selector)}
+
+	var result LabelSelector
+	if p.peek().kind == tokenEnd {
+		return result, nil
+	}
+
+	for {
+		requirement, err := p.parseRequirement()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, requirement)
+
+		switch tok := p.next(); tok.kind {
+		case tokenEnd:
+			return result, nil
+		case tokenComma:
+			continue
+		default:
+			return nil, selectorError(tok, fmt.Sprintf("expected ',' or end of selector, found %s", tok))
+		}
+	}
+}
+
+// selectorError builds a ValidationError pointing at the offending token
+func selectorError(tok selectorToken, message string) error {
+	return NewValidationError("selector", fmt.Sprintf("%s at position %d", message, tok.pos+1))
+}
+
+type selectorTokenKind int
+
+const (
+	tokenEnd selectorTokenKind = iota
+	tokenIdentifier
+	tokenComma
+	tokenOpenParen
+	tokenCloseParen
+	tokenEquals
+	tokenNotEquals
+	tokenNot
+)
+
+type selectorToken struct {
+	kind  selectorTokenKind
+	value string
+	pos   int
+}
+
+func (t selectorToken) String() string {
+	switch t.kind {
+	case tokenEnd:
+		return "end of selector"
+	case tokenIdentifier:
+		return fmt.Sprintf("%q", t.value)
+	default:
+		return fmt.Sprintf("'%s'", t.value)
+	}
+}
+
+// lexSelector splits a selector into tokens; whitespace separates tokens and is otherwise ignored
+func lexSelector(input string) []selectorToken {
+	var tokens []selectorToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == ',':
+			tokens = append(tokens, selectorToken{tokenComma, ",", i})
+			i++
+		case c == '(':
+			tokens = append(tokens, selectorToken{tokenOpenParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, selectorToken{tokenCloseParen, ")", i})
+			i++
+		case c == '!' && i+1 < len(input) && input[i+1] == '=':
+			tokens = append(tokens, selectorToken{tokenNotEquals, "!=", i})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, selectorToken{tokenNot, "!", i})
+			i++
+		case c == '=':
+			// "==" is accepted as a synonym for "="
+			width := 1
+			if i+1 < len(input) && input[i+1] == '=' {
+				width = 2
+			}
+			tokens = append(tokens, selectorToken{tokenEquals, input[i : i+width], i})
+			i += width
+		default:
+			start := i
+			for i < len(input) && !strings.ContainsRune(" \t,()!=", rune(input[i])) {
+				i++
+			}
+			tokens = append(tokens, selectorToken{tokenIdentifier, input[start:i], start})
+		}
+	}
+	return append(tokens, selectorToken{tokenEnd, "", len(input)})
+}
+
+type selectorParser struct {
+	tokens []selectorToken
+	pos    int
+}
+
+func (p *selectorParser) peek() selectorToken {
+	return p.tokens[p.pos]
+}
+
+func (p *selectorParser) next() selectorToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEnd {
+		p.pos++
+	}
+	return tok
+}
+
+// parseRequirement parses "!key", "key", "key=value", "key!=value",
+// "key in (a,b)" or "key notin (a,b)"
+func (p *selectorParser) parseRequirement() (LabelRequirement, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		key, err := p.parseKey()
+		if err != nil {
+			return LabelRequirement{}, err
+		}
+		return LabelRequirement{Key: key, Operator: SelectorOpDoesNotExist}, nil
+	}
+
+	key, err := p.parseKey()
+	if err != nil {
+		return LabelRequirement{}, err
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenComma || tok.kind == tokenEnd:
+		return LabelRequirement{Key: key, Operator: SelectorOpExists}, nil
+	case tok.kind == tokenEquals || tok.kind == tokenNotEquals:
+		p.next()
+		value, err := p.parseValue(key)
+		if err != nil {
+			return LabelRequirement{}, err
+		}
+		op := SelectorOpEquals
+		if tok.kind == tokenNotEquals {
+			op = SelectorOpNotEquals
+		}
+		return LabelRequirement{Key: key, Operator: op, Values: []string{value}}, nil
+	case tok.kind == tokenIdentifier && (tok.value == "in" || tok.value == "notin"):
+		p.next()
+		values, err := p.parseValueSet(key, tok.value)
+		if err != nil {
+			return LabelRequirement{}, err
+		}
+		return LabelRequirement{Key: key, Operator: SelectorOperator(tok.value), Values: values}, nil
+	default:
+		return LabelRequirement{}, selectorError(tok, fmt.Sprintf("expected '=', '!=', 'in', 'notin', ',' or end of selector after key %q, found %s", key, tok))
+	}
+}
+
+func (p *selectorParser) parseKey() (string, error) {
+	tok := p.next()
+	if tok.kind != tokenIdentifier {
+		return "", selectorError(tok, fmt.Sprintf("expected label key, found %s", tok))
+	}
+	if err := ValidateLabelKey(tok.value); err != nil {
+		return "", selectorError(tok, fmt.Sprintf("invalid label key %q: %s", tok.value, validationMessage(err)))
+	}
+	return tok.value, nil
+}
+
+// parseValue reads a value after = or !=; an empty value is allowed
+func (p *selectorParser) parseValue(key string) (string, error) {
+	tok := p.peek()
+	if tok.kind == tokenComma || tok.kind == tokenEnd {
+		return "", nil
+	}
+	p.next()
+	if tok.kind != tokenIdentifier {
+		return "", selectorError(tok, fmt.Sprintf("expected value for key %q, found %s", key, tok))
+	}
+	if err := ValidateLabelValue(key, tok.value); err != nil {
+		return "", selectorError(tok, fmt.Sprintf("invalid value %q for key %q: %s", tok.value, key, validationMessage(err)))
+	}
+	return tok.value, nil
+}
+
+// parseValueSet reads "(a, b, c)" after in or notin
+func (p *selectorParser) parseValueSet(key, operator string) ([]string, error) {
+	if tok := p.next(); tok.kind != tokenOpenParen {
+		return nil, selectorError(tok, fmt.Sprintf("expected '(' after %q, found %s", operator, tok))
+	}
+
+	var values []string
+	for {
+		value, err := p.parseValue(key)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		switch tok := p.next(); tok.kind {
+		case tokenComma:
+			continue
+		case tokenCloseParen:
+			return values, nil
+		default:
+			return nil, selectorError(tok, fmt.Sprintf("expected ',' or ')' in value list, found %s", tok))
+		}
+	}
+}
+
+// validationMessage extracts the message of a ValidationError
+func validationMessage(err error) string {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Message
+	}
+	return err.Error()
+}
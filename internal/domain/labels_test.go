@@ -0,0 +1,143 @@
+package domain_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels_Validate(t *testing.T) {
+	valid := domain.Labels{
+		"team":                   "mobile",
+		"example.com/env":        "lab",
+		"app.kubernetes.io/tier": "gold_1.2-b",
+		"empty":                  "",
+	}
+	assert.NoError(t, valid.Validate())
+
+	for _, labels := range []domain.Labels{
+		{"": "x"},
+		{"-team": "mobile"},
+		{"team": "mobile!"},
+		{"Example.com/env": "lab"},
+		{"/env": "lab"},
+		{"team": strings.Repeat("a", 64)},
+		{strings.Repeat("a", 64): "x"},
+	} {
+		assert.True(t, domain.IsValidationError(labels.Validate()), "%v", labels)
+	}
+}
+
+func TestLabels_Validate_ReportsEveryInvalidLabel(t *testing.T) {
+	labels := domain.Labels{"team": "mobile!", "-bad": "x", "env": "lab", "Example.com/env": "lab"}
+
+	for range 10 {
+		var violations domain.ValidationErrors
+		require.ErrorAs(t, labels.Validate(), &violations)
+		fields := make([]string, len(violations))
+		for i, violation := range violations {
+			fields[i] = violation.Field
+		}
+		assert.Equal(t, []string{"labels.-bad", "labels.Example.com/env", "labels.team"}, fields)
+	}
+}
+
+func TestLabels_Merge(t *testing.T) {
+	original := domain.Labels{"team": "web", "env": "lab"}
+	team := "mobile"
+
+	merged := original.Merge(map[string]*string{"team": &team, "env": nil})
+
+	assert.Equal(t, domain.Labels{"team": "mobile"}, merged)
+	assert.Equal(t, domain.Labels{"team": "web", "env": "lab"}, original, "original is untouched")
+}
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     domain.LabelSelector
+	}{
+		{"", nil},
+		{"team=mobile", domain.LabelSelector{{Key: "team", Operator: domain.SelectorOpEquals, Values: []string{"mobile"}}}},
+		{"team==mobile", domain.LabelSelector{{Key: "team", Operator: domain.SelectorOpEquals, Values: []string{"mobile"}}}},
+		{"env!=prod", domain.LabelSelector{{Key: "env", Operator: domain.SelectorOpNotEquals, Values: []string{"prod"}}}},
+		{"owner=", domain.LabelSelector{{Key: "owner", Operator: domain.SelectorOpEquals, Values: []string{""}}}},
+		{"tier in (gold, silver)", domain.LabelSelector{{Key: "tier", Operator: domain.SelectorOpIn, Values: []string{"gold", "silver"}}}},
+		{"region notin (eu)", domain.LabelSelector{{Key: "region", Operator: domain.SelectorOpNotIn, Values: []string{"eu"}}}},
+		{"gpu", domain.LabelSelector{{Key: "gpu", Operator: domain.SelectorOpExists}}},
+		{"!deprecated", domain.LabelSelector{{Key: "deprecated", Operator: domain.SelectorOpDoesNotExist}}},
+		{" team = mobile , example.com/env in (lab,staging) , !deprecated ", domain.LabelSelector{
+			{Key: "team", Operator: domain.SelectorOpEquals, Values: []string{"mobile"}},
+			{Key: "example.com/env", Operator: domain.SelectorOpIn, Values: []string{"lab", "staging"}},
+			{Key: "deprecated", Operator: domain.SelectorOpDoesNotExist},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			got, err := domain.ParseLabelSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseLabelSelector_Errors(t *testing.T) {
+	tests := []struct {
+		selector string
+		message  string
+	}{
+		{"team=mobile,", "expected label key, found end of selector at position 13"},
+		{"team mobile", `expected '=', '!=', 'in', 'notin', ',' or end of selector after key "team", found "mobile" at position 6`},
+		{"tier in gold", `expected '(' after "in", found "gold" at position 9`},
+		{"tier in (gold", "expected ',' or ')' in value list, found end of selector at position 14"},
+		{"team=(mobile)", `expected value for key "team", found '(' at position 6`},
+		{"-team=mobile", `invalid label key "-team"`},
+		{"team=mobile-", `invalid value "mobile-" for key "team"`},
+		{"team=mobile!", "expected ',' or end of selector, found '!' at position 12"},
+		{"=mobile", "expected label key, found '=' at position 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			_, err := domain.ParseLabelSelector(tt.selector)
+
+			var validationErr *domain.ValidationError
+			require.True(t, errors.As(err, &validationErr), "got %v", err)
+			assert.Equal(t, "selector", validationErr.Field)
+			assert.Contains(t, validationErr.Message, tt.message)
+		})
+	}
+}
+
+func TestLabelSelector_Matches(t *testing.T) {
+	labels := domain.Labels{"team": "mobile", "env": "lab"}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"team=mobile", true},
+		{"team!=mobile", false},
+		{"owner!=alice", true},
+		{"env in (lab,staging)", true},
+		{"env notin (lab)", false},
+		{"region notin (eu)", true},
+		{"team", true},
+		{"!team", false},
+		{"team=mobile,env=prod", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			selector, err := domain.ParseLabelSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, selector.Matches(labels))
+		})
+	}
+}
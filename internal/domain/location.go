@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocationType is the level of a location in the site > building > room hierarchy
+type LocationType string
+
+const (
+	LocationTypeSite     LocationType = "site"
+	LocationTypeBuilding LocationType = "building"
+	LocationTypeRoom     LocationType = "room"
+)
+
+// IsValid checks if the location type is valid
+func (t LocationType) IsValid() error {
+	switch t {
+	case LocationTypeSite, LocationTypeBuilding, LocationTypeRoom:
+		return nil
+	default:
+		return NewValidationError("type", fmt.Sprintf("invalid location type: %s (must be: site, building, or room)", t))
+	}
+}
+
+// ParentType returns the type a parent location must have, or "" for top-level sites
+func (t LocationType) ParentType() LocationType {
+	switch t {
+	case LocationTypeBuilding:
+		return LocationTypeSite
+	case LocationTypeRoom:
+		return LocationTypeBuilding
+	default:
+		return ""
+	}
+}
+
+// Location is a place where devices physically live
+type Location struct {
+	ID        uuid.UUID
+	Name      string
+	Type      LocationType
+	ParentID  *uuid.UUID
+	CreatedAt time.Time
+}
+
+// NewLocation creates a new location under parent (nil for sites) with validation
+func NewLocation(name string, locationType LocationType, parent *Location) (*Location, error) {
+	location := &Location{
+		ID:        uuid.New(),
+		Name:      name,
+		Type:      locationType,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := location.Validate(); err != nil {
+		return nil, err
+	}
+	if err := location.ValidateParent(parent); err != nil {
+		return nil, err
+	}
+	if parent != nil {
+		location.ParentID = &parent.ID
+	}
+
+	return location, nil
+}
+
+// Validate checks if the location has valid data
+func (l *Location) Validate() error {
+	if l.ID == uuid.Nil {
+		return NewValidationError("id", "cannot be empty")
+	}
+
+	name := strings.TrimSpace(l.Name)
+	if name == "" {
+		return NewValidationError("name", "cannot be empty")
+	}
+	if len(name) > 100 {
+		return NewValidationError("name", "must not exceed 100 characters")
+	}
+
+	return l.Type.IsValid()
+}
+
+// ValidateParent enforces the hierarchy: sites are top-level,
+// buildings belong to a site and rooms belong to a building
+func (l *Location) ValidateParent(parent *Location) error {
+	want := l.Type.ParentType()
+	if want == "" {
+		if parent != nil {
+			return NewValidationError("parent_id", fmt.Sprintf("a %s cannot have a parent", l.Type))
+		}
+		return nil
+	}
+
+	if parent == nil {
+		return NewValidationError("parent_id", fmt.Sprintf("a %s requires a parent %s", l.Type, want))
+	}
+	if parent.Type != want {
+		return NewValidationError("parent_id", fmt.Sprintf("a %s must belong to a %s, not a %s", l.Type, want, parent.Type))
+	}
+	return nil
+}
+
+// Update renames the location and moves it under parent with validation
+func (l *Location) Update(name string, parent *Location) error {
+	temp := *l
+	temp.Name = name
+	if err := temp.Validate(); err != nil {
+		return err
+	}
+	if err := temp.ValidateParent(parent); err != nil {
+		return err
+	}
+
+	l.Name = name
+	l.ParentID = nil
+	if parent != nil {
+		l.ParentID = &parent.ID
+	}
+	return nil
+}
+
+// LocationFilter narrows location listings; zero-valued fields are ignored.
+// IDs restricts the listing to the given locations.
+type LocationFilter struct {
+	Type     LocationType
+	ParentID *uuid.UUID
+	IDs      []uuid.UUID
+}
@@ -0,0 +1,72 @@
+package domain_test
+
+import (
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLocation_Hierarchy(t *testing.T) {
+	site, err := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	require.NoError(t, err)
+	assert.Nil(t, site.ParentID)
+
+	building, err := domain.NewLocation("HQ", domain.LocationTypeBuilding, site)
+	require.NoError(t, err)
+	assert.Equal(t, &site.ID, building.ParentID)
+
+	room, err := domain.NewLocation("Lab 1", domain.LocationTypeRoom, building)
+	require.NoError(t, err)
+	assert.Equal(t, &building.ID, room.ParentID)
+}
+
+func TestNewLocation_InvalidHierarchy(t *testing.T) {
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	building, _ := domain.NewLocation("HQ", domain.LocationTypeBuilding, site)
+
+	tests := []struct {
+		name         string
+		locationType domain.LocationType
+		parent       *domain.Location
+	}{
+		{"site with parent", domain.LocationTypeSite, site},
+		{"building without parent", domain.LocationTypeBuilding, nil},
+		{"building in building", domain.LocationTypeBuilding, building},
+		{"room in site", domain.LocationTypeRoom, site},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domain.NewLocation("Somewhere", tt.locationType, tt.parent)
+
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, "parent_id", validationErr.Field)
+		})
+	}
+}
+
+func TestNewLocation_Validation(t *testing.T) {
+	_, err := domain.NewLocation("  ", domain.LocationTypeSite, nil)
+	assert.True(t, domain.IsValidationError(err))
+
+	_, err = domain.NewLocation("Berlin", domain.LocationType("campus"), nil)
+	assert.True(t, domain.IsValidationError(err))
+}
+
+func TestLocation_Update(t *testing.T) {
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	otherSite, _ := domain.NewLocation("Lisbon", domain.LocationTypeSite, nil)
+	building, _ := domain.NewLocation("HQ", domain.LocationTypeBuilding, site)
+
+	require.NoError(t, building.Update("HQ North", otherSite))
+	assert.Equal(t, "HQ North", building.Name)
+	assert.Equal(t, &otherSite.ID, building.ParentID)
+
+	err := building.Update("HQ South", nil)
+	assert.True(t, domain.IsValidationError(err))
+	assert.Equal(t, "HQ North", building.Name, "failed update leaves the location unchanged")
+}
@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaintenanceType describes why a device goes into maintenance
+type MaintenanceType string
+
+const (
+	MaintenanceTypePreventive MaintenanceType = "preventive"
+	MaintenanceTypeRepair     MaintenanceType = "repair"
+	MaintenanceTypeInspection MaintenanceType = "inspection"
+	MaintenanceTypeUpgrade    MaintenanceType = "upgrade"
+)
+
+// IsValid checks if the maintenance type is valid
+func (t MaintenanceType) IsValid() error {
+	switch t {
+	case MaintenanceTypePreventive, MaintenanceTypeRepair, MaintenanceTypeInspection, MaintenanceTypeUpgrade:
+		return nil
+	default:
+		return NewValidationError("type", fmt.Sprintf("invalid type: %s (must be: preventive, repair, inspection, or upgrade)", t))
+	}
+}
+
+// MaintenanceStatus is derived from which maintenance timestamps are set
+type MaintenanceStatus string
+
+const (
+	MaintenanceStatusScheduled  MaintenanceStatus = "scheduled"
+	MaintenanceStatusInProgress MaintenanceStatus = "in-progress"
+	MaintenanceStatusCompleted  MaintenanceStatus = "completed"
+)
+
+// MaxVendorLength bounds the vendor name of a maintenance record
+const MaxVendorLength = 100
+
+// Maintenance is a service record for a device.
+// It is scheduled, then started, which moves the device into the maintenance
+// state, then completed, which restores the state the device had before.
+// CostCents is in minor currency units.
+type Maintenance struct {
+	ID            uuid.UUID
+	DeviceID      uuid.UUID
+	Type          MaintenanceType
+	ScheduledAt   time.Time
+	StartedAt     *time.Time
+	CompletedAt   *time.Time
+	Vendor        string
+	CostCents     int64
+	Notes         string
+	PreviousState DeviceState
+	CreatedAt     time.Time
+}
+
+// NewMaintenance schedules a maintenance of deviceID at scheduledAt
+func NewMaintenance(deviceID uuid.UUID, maintenanceType MaintenanceType, scheduledAt time.Time, vendor string, costCents int64, notes string) (*Maintenance, error) {
+	m := &Maintenance{
+		ID:          uuid.New(),
+		DeviceID:    deviceID,
+		Type:        maintenanceType,
+		ScheduledAt: scheduledAt.UTC(),
+		Vendor:      strings.TrimSpace(vendor),
+		CostCents:   costCents,
+		Notes:       notes,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Validate checks if the maintenance record has valid data
+func (m *Maintenance) Validate() error {
+	if m.DeviceID == uuid.Nil {
+		return NewValidationError("device_id", "cannot be empty")
+	}
+	if err := m.Type.IsValid(); err != nil {
+		return err
+	}
+	if m.ScheduledAt.IsZero() {
+		return NewValidationError("scheduled_at", "is required")
+	}
+	if len(m.Vendor) > MaxVendorLength {
+		return NewValidationError("vendor", fmt.Sprintf("must be at most %d characters", MaxVendorLength))
+	}
+	if m.CostCents < 0 {
+		return NewValidationError("cost_cents", "cannot be negative")
+	}
+	return nil
+}
+
+// Status reports whether the maintenance is scheduled, in progress or completed
+func (m *Maintenance) Status() MaintenanceStatus {
+	switch {
+	case m.CompletedAt != nil:
+		return MaintenanceStatusCompleted
+	case m.StartedAt != nil:
+		return MaintenanceStatusInProgress
+	default:
+		return MaintenanceStatusScheduled
+	}
+}
+
+// Start begins the maintenance at now and moves device into the maintenance state,
+// remembering its current state so Complete can restore it
+func (m *Maintenance) Start(device *Device, now time.Time) error {
+	if m.Status() != MaintenanceStatusScheduled {
+		return NewBusinessRuleError(fmt.Sprintf("cannot start maintenance that is %s", m.Status()))
+	}
+	if device.State == DeviceStateMaintenance {
+		return NewBusinessRuleError("device is already in maintenance")
+	}
+
+	startedAt := now.UTC()
+	m.StartedAt = &startedAt
+	m.PreviousState = device.State
+	device.State = DeviceStateMaintenance
+	return nil
+}
+
+// Complete ends the maintenance at now and returns device to the state it had
+// before the maintenance started. A non-nil costCents or notes replaces the recorded value.
+func (m *Maintenance) Complete(device *Device, now time.Time, costCents *int64, notes *string) error {
+	if m.Status() != MaintenanceStatusInProgress {
+		return NewBusinessRuleError(fmt.Sprintf("cannot complete maintenance that is %s", m.Status()))
+	}
+	if costCents != nil {
+		if *costCents < 0 {
+			return NewValidationError("cost_cents", "cannot be negative")
+		}
+		m.CostCents = *costCents
+	}
+	if notes != nil {
+		m.Notes = *notes
+	}
+
+	completedAt := now.UTC()
+	if completedAt.Before(*m.StartedAt) {
+		completedAt = *m.StartedAt
+	}
+	m.CompletedAt = &completedAt
+	device.State = m.PreviousState
+	return nil
+}
+
+// MaintenanceIntervals maps a device category to the number of days between
+// periodic maintenances. Categories without an interval are never due.
+type MaintenanceIntervals map[DeviceCategory]int
+
+// Validate checks that every category is known and every interval is positive
+func (i MaintenanceIntervals) Validate() error {
+	for category, days := range i {
+		if err := category.IsValid(); err != nil {
+			return err
+		}
+		if days < 1 {
+			return NewValidationError("interval_days", fmt.Sprintf("must be positive for %s, got %d", category, days))
+		}
+	}
+	return nil
+}
+
+// DueMaintenance is a device whose periodic maintenance is due.
+// LastMaintainedAt is the completion of its last maintenance, or nil if it never had one,
+// in which case the interval counts from the purchase date or, without one, from creation.
+type DueMaintenance struct {
+	Device           *Device
+	LastMaintainedAt *time.Time
+	DueAt            time.Time
+}
@@ -0,0 +1,112 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaintenance_Validation(t *testing.T) {
+	scheduled := time.Now().Add(24 * time.Hour)
+	tests := []struct {
+		name        string
+		mType       domain.MaintenanceType
+		scheduledAt time.Time
+		vendor      string
+		cost        int64
+		wantField   string
+	}{
+		{"valid", domain.MaintenanceTypeRepair, scheduled, "iFixit", 12500, ""},
+		{"unknown type", "cleaning", scheduled, "", 0, "type"},
+		{"missing schedule", domain.MaintenanceTypePreventive, time.Time{}, "", 0, "scheduled_at"},
+		{"negative cost", domain.MaintenanceTypeRepair, scheduled, "", -1, "cost_cents"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := domain.NewMaintenance(uuid.New(), tt.mType, tt.scheduledAt, tt.vendor, tt.cost, "")
+			if tt.wantField == "" {
+				require.NoError(t, err)
+				assert.Equal(t, domain.MaintenanceStatusScheduled, m.Status())
+				return
+			}
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.wantField, validationErr.Field)
+		})
+	}
+}
+
+func TestMaintenance_StartAndComplete_RestoresState(t *testing.T) {
+	device, err := domain.NewDevice("Dev laptop", "Apple")
+	require.NoError(t, err)
+	device.State = domain.DeviceStateInUse
+	m, err := domain.NewMaintenance(device.ID, domain.MaintenanceTypeRepair, time.Now(), "iFixit", 0, "")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Start(device, time.Now()))
+	assert.Equal(t, domain.MaintenanceStatusInProgress, m.Status())
+	assert.Equal(t, domain.DeviceStateMaintenance, device.State)
+	assert.Equal(t, domain.DeviceStateInUse, m.PreviousState)
+
+	err = m.Start(device, time.Now())
+	assert.True(t, domain.IsBusinessRuleError(err), "cannot start twice")
+
+	cost := int64(8900)
+	require.NoError(t, m.Complete(device, time.Now(), &cost, nil))
+	assert.Equal(t, domain.MaintenanceStatusCompleted, m.Status())
+	assert.Equal(t, domain.DeviceStateInUse, device.State)
+	assert.Equal(t, int64(8900), m.CostCents)
+
+	err = m.Complete(device, time.Now(), nil, nil)
+	assert.True(t, domain.IsBusinessRuleError(err), "cannot complete twice")
+}
+
+func TestMaintenance_Complete_NotStarted(t *testing.T) {
+	device, _ := domain.NewDevice("Dev laptop", "Apple")
+	m, _ := domain.NewMaintenance(device.ID, domain.MaintenanceTypeInspection, time.Now(), "", 0, "")
+
+	err := m.Complete(device, time.Now(), nil, nil)
+
+	assert.True(t, domain.IsBusinessRuleError(err))
+	assert.Equal(t, domain.DeviceStateActive, device.State)
+}
+
+func TestMaintenance_Start_DeviceAlreadyInMaintenance(t *testing.T) {
+	device, _ := domain.NewDevice("Dev laptop", "Apple")
+	first, _ := domain.NewMaintenance(device.ID, domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	second, _ := domain.NewMaintenance(device.ID, domain.MaintenanceTypeUpgrade, time.Now(), "", 0, "")
+	require.NoError(t, first.Start(device, time.Now()))
+
+	err := second.Start(device, time.Now())
+
+	assert.True(t, domain.IsBusinessRuleError(err))
+	assert.Nil(t, second.StartedAt)
+}
+
+func TestDevice_Update_MaintenanceStateIsManaged(t *testing.T) {
+	device, _ := domain.NewDevice("Dev laptop", "Apple")
+
+	err := device.Update("Dev laptop", "Apple", domain.DeviceStateMaintenance)
+	assert.True(t, domain.IsBusinessRuleError(err), "cannot enter maintenance directly")
+
+	m, _ := domain.NewMaintenance(device.ID, domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	require.NoError(t, m.Start(device, time.Now()))
+
+	err = device.Update("Dev laptop", "Apple", domain.DeviceStateInUse)
+	assert.True(t, domain.IsBusinessRuleError(err), "cannot be put in use during maintenance")
+
+	require.NoError(t, device.Update("Dev laptop (repair)", "Apple", domain.DeviceStateMaintenance))
+	assert.True(t, domain.IsBusinessRuleError(device.CanDelete()))
+}
+
+func TestMaintenanceIntervals_Validate(t *testing.T) {
+	assert.NoError(t, domain.MaintenanceIntervals{domain.DeviceCategoryLaptop: 365}.Validate())
+	assert.True(t, domain.IsValidationError(domain.MaintenanceIntervals{"wearable": 30}.Validate()))
+	assert.True(t, domain.IsValidationError(domain.MaintenanceIntervals{domain.DeviceCategorySensor: 0}.Validate()))
+}
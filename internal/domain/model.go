@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeviceCategory groups models with the same validation rules
+type DeviceCategory string
+
+const (
+	DeviceCategoryLaptop DeviceCategory = "laptop"
+	DeviceCategoryPhone  DeviceCategory = "phone"
+	DeviceCategoryTablet DeviceCategory = "tablet"
+	DeviceCategorySensor DeviceCategory = "sensor"
+)
+
+// IsValid checks if the device category is valid
+func (c DeviceCategory) IsValid() error {
+	switch c {
+	case DeviceCategoryLaptop, DeviceCategoryPhone, DeviceCategoryTablet, DeviceCategorySensor:
+		return nil
+	default:
+		return NewValidationError("category", fmt.Sprintf("invalid category: %s (must be: laptop, phone, tablet, or sensor)", c))
+	}
+}
+
+// CategoryRule is an extra validation applied to every device of a category
+type CategoryRule func(d *Device) error
+
+// categoryRules holds the rules for each category; add a rule here to enforce it
+// on create, update and patch of every device whose model has that category
+var categoryRules = map[DeviceCategory][]CategoryRule{
+	DeviceCategoryPhone: {RequireAttribute("imei")},
+}
+
+// Validate applies the category's rules to d
+func (c DeviceCategory) Validate(d *Device) error {
+	for _, rule := range categoryRules[c] {
+		if err := rule(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequireAttribute returns a rule that requires a non-empty attribute
+func RequireAttribute(key string) CategoryRule {
+	return func(d *Device) error {
+		value, ok := d.Attributes[key]
+		if !ok || value == "" {
+			return NewValidationError("attributes."+key, fmt.Sprintf("is required for %s devices", d.Category))
+		}
+		return nil
+	}
+}
+
+// Model is a product in the model catalog, e.g. "MacBook Pro 14 M3".
+// Devices created from a model start with its default attributes.
+type Model struct {
+	ID                uuid.UUID
+	Name              string
+	Brand             string
+	BrandID           uuid.UUID
+	Category          DeviceCategory
+	DefaultAttributes Attributes
+	// LifecycleMonths is the expected service life of devices of this model; 0 means no policy
+	LifecycleMonths int
+	CreatedAt       time.Time
+}
+
+// NewModel creates a new catalog model with validation.
+// Brand and category are fixed once the model exists.
+func NewModel(name, brand string, category DeviceCategory, defaults Attributes, lifecycleMonths int) (*Model, error) {
+	if defaults == nil {
+		defaults = Attributes{}
+	}
+	model := &Model{
+		ID:                uuid.New(),
+		Name:              name,
+		Brand:             brand,
+		Category:          category,
+		DefaultAttributes: defaults,
+		LifecycleMonths:   lifecycleMonths,
+		CreatedAt:         time.Now().UTC(),
+	}
+
+	if err := model.Validate(); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// Validate checks if the model has valid data
+func (m *Model) Validate() error {
+	if m.ID == uuid.Nil {
+		return NewValidationError("id", "cannot be empty")
+	}
+
+	name := strings.TrimSpace(m.Name)
+	if name == "" {
+		return NewValidationError("name", "cannot be empty")
+	}
+	if len(name) > 100 {
+		return NewValidationError("name", "must not exceed 100 characters")
+	}
+
+	if err := ValidateBrandName("brand", m.Brand); err != nil {
+		return err
+	}
+
+	if err := m.Category.IsValid(); err != nil {
+		return err
+	}
+
+	if err := m.DefaultAttributes.Validate(); err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			return NewValidationError("default_"+validationErr.Field, validationErr.Message)
+		}
+		return err
+	}
+
+	if m.LifecycleMonths < 0 {
+		return NewValidationError("lifecycle_months", "must not be negative")
+	}
+
+	return nil
+}
+
+// Update changes the model's name, default attributes and lifecycle policy with validation.
+// Existing devices keep their attributes; new defaults apply to devices created afterwards.
+func (m *Model) Update(name string, defaults Attributes, lifecycleMonths int) error {
+	if defaults == nil {
+		defaults = Attributes{}
+	}
+	temp := *m
+	temp.Name = name
+	temp.DefaultAttributes = defaults
+	temp.LifecycleMonths = lifecycleMonths
+	if err := temp.Validate(); err != nil {
+		return err
+	}
+
+	*m = temp
+	return nil
+}
+
+// ModelFilter narrows model listings; zero-valued fields are ignored.
+// IDs restricts the listing to the given models.
+type ModelFilter struct {
+	Brand    string
+	Category DeviceCategory
+	IDs      []uuid.UUID
+}
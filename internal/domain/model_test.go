@@ -0,0 +1,95 @@
+package domain_test
+
+import (
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewModel_Validation(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		category  domain.DeviceCategory
+		defaults  domain.Attributes
+		lifecycle int
+		wantField string
+	}{
+		{"valid", "MacBook Pro 14 M3", domain.DeviceCategoryLaptop, domain.Attributes{"ram_gb": 16}, 48, ""},
+		{"empty name", " ", domain.DeviceCategoryLaptop, nil, 0, "name"},
+		{"unknown category", "Watch", "wearable", nil, 0, "category"},
+		{"invalid default attribute", "iPhone 15", domain.DeviceCategoryPhone, domain.Attributes{"OS": "ios"}, 0, "default_attributes.OS"},
+		{"negative lifecycle", "iPad Air", domain.DeviceCategoryTablet, nil, -1, "lifecycle_months"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := domain.NewModel(tt.model, "Apple", tt.category, tt.defaults, tt.lifecycle)
+			if tt.wantField == "" {
+				require.NoError(t, err)
+				assert.NotNil(t, model.DefaultAttributes)
+				return
+			}
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.wantField, validationErr.Field)
+		})
+	}
+}
+
+func TestDevice_AssignModel_PrefillsAttributes(t *testing.T) {
+	model, err := domain.NewModel("MacBook Pro 14 M3", "Apple", domain.DeviceCategoryLaptop,
+		domain.Attributes{"ram_gb": 16, "os": "macos"}, 48)
+	require.NoError(t, err)
+
+	device, err := domain.NewDevice("Dev laptop", "apple", domain.WithAttributes(domain.Attributes{"ram_gb": 32}))
+	require.NoError(t, err)
+
+	require.NoError(t, device.AssignModel(model, true))
+	assert.Equal(t, &model.ID, device.ModelID)
+	assert.Equal(t, domain.DeviceCategoryLaptop, device.Category)
+	// Explicit attributes win over model defaults
+	assert.Equal(t, domain.Attributes{"ram_gb": 32, "os": "macos"}, device.Attributes)
+}
+
+func TestDevice_AssignModel_WrongBrand(t *testing.T) {
+	model, _ := domain.NewModel("Galaxy S24", "Samsung", domain.DeviceCategoryPhone, nil, 0)
+	device, _ := domain.NewDevice("iPhone 15", "Apple")
+
+	err := device.AssignModel(model, false)
+
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "model_id", validationErr.Field)
+	assert.Nil(t, device.ModelID)
+}
+
+func TestDevice_CategoryRules(t *testing.T) {
+	phone, _ := domain.NewModel("iPhone 15", "Apple", domain.DeviceCategoryPhone, nil, 0)
+
+	// Phones require an IMEI
+	device, _ := domain.NewDevice("iPhone 15", "Apple")
+	err := device.AssignModel(phone, true)
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "attributes.imei", validationErr.Field)
+	assert.Equal(t, "is required for phone devices", validationErr.Message)
+
+	device, _ = domain.NewDevice("iPhone 15", "Apple",
+		domain.WithAttributes(domain.Attributes{"imei": "490154203237518"}))
+	require.NoError(t, device.AssignModel(phone, true))
+
+	// Rules keep applying to later updates
+	err = device.ApplyPatch(domain.DevicePatch{Attributes: map[string]any{"imei": nil}})
+	assert.True(t, domain.IsValidationError(err))
+	assert.Equal(t, "490154203237518", device.Attributes["imei"])
+
+	// Switching models drops the category until the new model is assigned
+	otherID := uuid.New()
+	require.NoError(t, device.ApplyPatch(domain.DevicePatch{ModelID: &otherID}))
+	assert.Empty(t, device.Category)
+}
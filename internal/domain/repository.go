@@ -2,10 +2,18 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// TxManager runs work atomically across repositories
+type TxManager interface {
+	// WithinTx runs fn in a transaction that repositories called with the context passed to fn take part in.
+	// The transaction commits when fn returns nil and rolls back otherwise. Calls within fn join the transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // DeviceRepository defines the interface for device persistence operations.
 // This interface is defined in the domain layer (Dependency Inversion Principle).
 // The actual implementation will be in the repository layer.
@@ -16,15 +24,32 @@ type DeviceRepository interface {
 	// GetByID retrieves a device by its unique identifier
 	GetByID(ctx context.Context, id uuid.UUID) (*Device, error)
 
+	// GetByIDAsOf retrieves the version of a device that was current at the given time,
+	// even if the device has been deleted since
+	GetByIDAsOf(ctx context.Context, id uuid.UUID, at time.Time) (*Device, error)
+
+	// GetByIDForUpdate retrieves a device and locks it until the transaction of ctx ends,
+	// so concurrent read-modify-write paths on the device run one after the other
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*Device, error)
+
+	// GetBySerial retrieves the device with serialNumber whose brand matches brand by name or alias
+	GetBySerial(ctx context.Context, brand, serialNumber string) (*Device, error)
+
 	// List retrieves all devices with optional pagination
 	List(ctx context.Context, limit, offset int) ([]*Device, error)
 
-	// ListByBrand retrieves devices filtered by brand
+	// ListByBrand retrieves devices whose brand matches brand by name or alias
 	ListByBrand(ctx context.Context, brand string, limit, offset int) ([]*Device, error)
 
 	// ListByState retrieves devices filtered by state
 	ListByState(ctx context.Context, state DeviceState, limit, offset int) ([]*Device, error)
 
+	// Search retrieves devices matching every criterion in filter
+	Search(ctx context.Context, filter DeviceFilter, limit, offset int) ([]*Device, error)
+
+	// Aggregate counts the devices matching query in groups; groups without devices are omitted
+	Aggregate(ctx context.Context, query DeviceStatsQuery) ([]DeviceStatsGroup, error)
+
 	// Update modifies an existing device
 	Update(ctx context.Context, device *Device) error
 
@@ -34,3 +59,135 @@ type DeviceRepository interface {
 	// ExistsByID checks if a device exists
 	ExistsByID(ctx context.Context, id uuid.UUID) (bool, error)
 }
+
+// LocationRepository defines the interface for location persistence operations
+type LocationRepository interface {
+	// Create persists a new location
+	Create(ctx context.Context, location *Location) error
+
+	// GetByID retrieves a location by its unique identifier
+	GetByID(ctx context.Context, id uuid.UUID) (*Location, error)
+
+	// List retrieves locations matching filter, ordered by name
+	List(ctx context.Context, filter LocationFilter) ([]*Location, error)
+
+	// Update modifies an existing location
+	Update(ctx context.Context, location *Location) error
+
+	// Delete removes a location by its unique identifier
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// HasChildren checks if any location has the given location as parent
+	HasChildren(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// HasDevices checks if any device is assigned to the location
+	HasDevices(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// BrandRepository defines the interface for brand catalog persistence operations
+type BrandRepository interface {
+	// Resolve finds the brand whose name or alias matches name, ignoring case
+	Resolve(ctx context.Context, name string) (*Brand, error)
+
+	// GetByID retrieves a brand with its aliases and device count
+	GetByID(ctx context.Context, id uuid.UUID) (*Brand, error)
+
+	// List retrieves all brands with their aliases and device counts, ordered by name
+	List(ctx context.Context) ([]*Brand, error)
+
+	// AddAlias registers alias as an alternative name for the brand
+	AddAlias(ctx context.Context, id uuid.UUID, alias string) error
+}
+
+// ModelRepository defines the interface for model catalog persistence operations
+type ModelRepository interface {
+	// Create persists a new model, resolving its brand through the brand catalog
+	Create(ctx context.Context, model *Model) error
+
+	// GetByID retrieves a model by its unique identifier
+	GetByID(ctx context.Context, id uuid.UUID) (*Model, error)
+
+	// List retrieves models matching filter, ordered by brand and name
+	List(ctx context.Context, filter ModelFilter) ([]*Model, error)
+
+	// Update modifies an existing model
+	Update(ctx context.Context, model *Model) error
+
+	// Delete removes a model by its unique identifier
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// HasDevices checks if any device references the model
+	HasDevices(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// ExpiryRepository defines the persistence operations behind expiry reports and notices
+type ExpiryRepository interface {
+	// ListExpiring retrieves warranty and EOL dates between from and to (inclusive),
+	// ordered by brand name and date
+	ListExpiring(ctx context.Context, from, to time.Time) ([]Expiry, error)
+
+	// MarkNotified records a notice and reports false when it was already recorded
+	MarkNotified(ctx context.Context, notice ExpiryNotice) (bool, error)
+
+	// UnmarkNotified removes a notice, so it is emitted again on the next check
+	UnmarkNotified(ctx context.Context, notice ExpiryNotice) error
+}
+
+// MaintenanceRepository defines the interface for maintenance record persistence operations
+type MaintenanceRepository interface {
+	// Create persists a new maintenance record
+	Create(ctx context.Context, maintenance *Maintenance) error
+
+	// GetByID retrieves a maintenance record by its unique identifier
+	GetByID(ctx context.Context, id uuid.UUID) (*Maintenance, error)
+
+	// ListByDevice retrieves the maintenance records of a device, most recently scheduled first
+	ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]*Maintenance, error)
+
+	// SaveTransition persists a started or completed maintenance together with
+	// the state of its device, unless the device's state is no longer previousState,
+	// the state it had when it was read. Callers run it in a transaction of
+	// TxManager, so both are saved atomically.
+	SaveTransition(ctx context.Context, maintenance *Maintenance, device *Device, previousState DeviceState) error
+
+	// ListDue retrieves devices whose last maintenance completed more than their
+	// category's interval before now and that have no open maintenance, oldest due first
+	ListDue(ctx context.Context, intervals MaintenanceIntervals, now time.Time, limit, offset int) ([]DueMaintenance, error)
+}
+
+// HeartbeatRepository defines the interface for device heartbeat persistence operations
+type HeartbeatRepository interface {
+	// SetTokenHash stores the hash of a device's heartbeat token, replacing any previous one
+	SetTokenHash(ctx context.Context, deviceID uuid.UUID, hash []byte) error
+
+	// TokenHash retrieves the hash of a device's heartbeat token.
+	// A device without a token is reported as ErrDeviceNotFound.
+	TokenHash(ctx context.Context, deviceID uuid.UUID) ([]byte, error)
+
+	// Record saves the time of a heartbeat and, when present, the reported fields
+	Record(ctx context.Context, heartbeat *Heartbeat) error
+
+	// ListStale retrieves devices last seen before seenBefore that are not inactive,
+	// in use or in maintenance, longest unseen first
+	ListStale(ctx context.Context, seenBefore time.Time, limit, offset int) ([]*Device, error)
+
+	// MarkInactive saves the inactive state of a stale device unless it was seen
+	// or changed state since it was listed, and reports whether it was saved
+	MarkInactive(ctx context.Context, device *Device, previousState DeviceState, seenBefore time.Time) (bool, error)
+}
+
+// TelemetryRepository defines the interface for device telemetry persistence operations
+type TelemetryRepository interface {
+	// Insert stores a batch of samples atomically.
+	// A sample of a device that does not exist is reported as ErrDeviceNotFound.
+	Insert(ctx context.Context, points []TelemetryPoint) error
+
+	// Aggregate returns the min, max, average and count of the samples matching
+	// query per bucket, oldest first; buckets without samples are left out
+	Aggregate(ctx context.Context, query TelemetryQuery) ([]TelemetryBucket, error)
+
+	// DropBefore removes all samples older than cutoff at the granularity of its
+	// storage, keeping any that share storage with newer samples, and returns
+	// the number of storage units removed
+	DropBefore(ctx context.Context, cutoff time.Time) (int, error)
+}
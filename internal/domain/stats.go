@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// StatsDimension is a device property inventory statistics can be grouped by
+type StatsDimension string
+
+const (
+	StatsByBrand    StatsDimension = "brand"
+	StatsByState    StatsDimension = "state"
+	StatsByCategory StatsDimension = "category"
+	StatsByLocation StatsDimension = "location"
+)
+
+// IsValid checks if the dimension is one statistics can be grouped by
+func (d StatsDimension) IsValid() error {
+	switch d {
+	case StatsByBrand, StatsByState, StatsByCategory, StatsByLocation:
+		return nil
+	default:
+		return NewValidationError("group_by", fmt.Sprintf("invalid dimension: %s (must be: brand, state, category, or location)", d))
+	}
+}
+
+// StatsInterval is the calendar period device creation times are bucketed by, in UTC
+type StatsInterval string
+
+const (
+	StatsIntervalDay     StatsInterval = "day"
+	StatsIntervalWeek    StatsInterval = "week"
+	StatsIntervalMonth   StatsInterval = "month"
+	StatsIntervalQuarter StatsInterval = "quarter"
+	StatsIntervalYear    StatsInterval = "year"
+)
+
+// IsValid checks if the interval is a supported calendar period
+func (i StatsInterval) IsValid() error {
+	switch i {
+	case StatsIntervalDay, StatsIntervalWeek, StatsIntervalMonth, StatsIntervalQuarter, StatsIntervalYear:
+		return nil
+	default:
+		return NewValidationError("interval", fmt.Sprintf("invalid interval: %s (must be: day, week, month, quarter, or year)", i))
+	}
+}
+
+// DeviceStatsQuery selects the devices matching Filter and groups them by every
+// dimension in GroupBy and, when Interval is set, by the period they were created in.
+// Without either, all matching devices form a single group.
+type DeviceStatsQuery struct {
+	Filter   DeviceFilter
+	GroupBy  []StatsDimension
+	Interval StatsInterval
+}
+
+// Validate checks the filter, and that dimensions are valid and not repeated
+func (q DeviceStatsQuery) Validate() error {
+	if err := q.Filter.Validate(); err != nil {
+		return err
+	}
+	seen := make(map[StatsDimension]bool, len(q.GroupBy))
+	for _, dimension := range q.GroupBy {
+		if err := dimension.IsValid(); err != nil {
+			return err
+		}
+		if seen[dimension] {
+			return NewValidationError("group_by", fmt.Sprintf("dimension %s is repeated", dimension))
+		}
+		seen[dimension] = true
+	}
+	if q.Interval != "" {
+		return q.Interval.IsValid()
+	}
+	return nil
+}
+
+// DeviceStatsGroup counts the devices sharing a value for every grouped dimension
+type DeviceStatsGroup struct {
+	// Keys holds the value of each grouped dimension; devices without a category
+	// or location have no key for it
+	Keys map[StatsDimension]string
+	// Period is the start of the creation period when grouped by interval
+	Period *time.Time
+	Count  int64
+	// AverageAge is the mean time since the devices were created
+	AverageAge time.Duration
+}
@@ -0,0 +1,36 @@
+package domain_test
+
+import (
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceStatsQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   domain.DeviceStatsQuery
+		wantErr string
+	}{
+		{"no grouping", domain.DeviceStatsQuery{}, ""},
+		{"dimensions and interval", domain.DeviceStatsQuery{GroupBy: []domain.StatsDimension{domain.StatsByBrand, domain.StatsByState}, Interval: domain.StatsIntervalMonth}, ""},
+		{"unknown dimension", domain.DeviceStatsQuery{GroupBy: []domain.StatsDimension{"color"}}, "invalid dimension: color"},
+		{"repeated dimension", domain.DeviceStatsQuery{GroupBy: []domain.StatsDimension{domain.StatsByBrand, domain.StatsByBrand}}, "dimension brand is repeated"},
+		{"unknown interval", domain.DeviceStatsQuery{Interval: "hour"}, "invalid interval: hour"},
+		{"invalid filter", domain.DeviceStatsQuery{Filter: domain.DeviceFilter{State: "broken"}}, "invalid state: broken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, domain.IsValidationError(err))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
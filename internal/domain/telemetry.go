@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxTelemetryClockSkew is how far in the future a reported timestamp may lie
+	MaxTelemetryClockSkew = 5 * time.Minute
+	// MinTelemetryBucket is the smallest downsampling bucket
+	MinTelemetryBucket = time.Minute
+	// MaxTelemetryBuckets bounds the number of buckets a single query may return
+	MaxTelemetryBuckets = 10000
+)
+
+// TelemetryPoint is a single metric sample reported by a device, such as its
+// battery level, temperature or free storage at Timestamp
+type TelemetryPoint struct {
+	DeviceID  uuid.UUID
+	Metric    string
+	Value     float64
+	Timestamp time.Time
+}
+
+// NewTelemetryPoint creates a sample of metric. Metric names follow the same
+// rules as attribute keys, and the value must be a finite number.
+// Errors are reported below field, usually TelemetryPointField of the point's position in its batch.
+func NewTelemetryPoint(field string, deviceID uuid.UUID, metric string, value float64, timestamp time.Time) (TelemetryPoint, error) {
+	if deviceID == uuid.Nil {
+		return TelemetryPoint{}, NewValidationError("device_id", "cannot be empty")
+	}
+	if !attributeKeyPattern.MatchString(metric) {
+		return TelemetryPoint{}, NewValidationError(field+".metric", "must start with a lowercase letter and contain only lowercase letters, digits and underscores (max 64 characters)")
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return TelemetryPoint{}, NewValidationError(field+".value", "must be a finite number")
+	}
+	if timestamp.IsZero() {
+		return TelemetryPoint{}, NewValidationError(field+".ts", "cannot be empty")
+	}
+
+	return TelemetryPoint{
+		DeviceID:  deviceID,
+		Metric:    metric,
+		Value:     value,
+		Timestamp: timestamp.UTC(),
+	}, nil
+}
+
+// TelemetryPointField names the point at index (counting from 0) of a batch in validation errors
+func TelemetryPointField(index int) string {
+	return fmt.Sprintf("points[%d]", index)
+}
+
+// CheckAge rejects a point older than retention allows or further in the future
+// than MaxTelemetryClockSkew at now
+func (p TelemetryPoint) CheckAge(field string, now time.Time, retention time.Duration) error {
+	if p.Timestamp.Before(TelemetryCutoff(now, retention)) {
+		return NewValidationError(field+".ts", fmt.Sprintf("must not be older than the retention period (%s)", retention))
+	}
+	if p.Timestamp.After(now.Add(MaxTelemetryClockSkew)) {
+		return NewValidationError(field+".ts", "must not be in the future")
+	}
+	return nil
+}
+
+// TelemetryCutoff returns the time before which telemetry is past retention at now
+func TelemetryCutoff(now time.Time, retention time.Duration) time.Time {
+	return now.UTC().Add(-retention)
+}
+
+// TelemetryQuery selects the samples of one metric of a device between From
+// (inclusive) and To (exclusive), downsampled into buckets of Bucket
+type TelemetryQuery struct {
+	DeviceID uuid.UUID
+	Metric   string
+	From     time.Time
+	To       time.Time
+	Bucket   time.Duration
+}
+
+// Validate checks the metric, the time range and that the number of buckets is bounded
+func (q TelemetryQuery) Validate() error {
+	if !attributeKeyPattern.MatchString(q.Metric) {
+		return NewValidationError("metric", "must start with a lowercase letter and contain only lowercase letters, digits and underscores (max 64 characters)")
+	}
+	if !q.To.After(q.From) {
+		return NewValidationError("to", "must be after from")
+	}
+	if q.Bucket < MinTelemetryBucket || q.Bucket%time.Minute != 0 {
+		return NewValidationError("bucket", "must be a whole number of minutes, at least 1m")
+	}
+	if q.To.Sub(q.From)/q.Bucket >= MaxTelemetryBuckets {
+		return NewValidationError("bucket", fmt.Sprintf("is too small for the range: at most %d buckets are returned", MaxTelemetryBuckets))
+	}
+	return nil
+}
+
+// TelemetryBucket summarizes the samples of one bucket starting at Start
+type TelemetryBucket struct {
+	Start time.Time
+	Min   float64
+	Max   float64
+	Avg   float64
+	Count int64
+}
@@ -0,0 +1,87 @@
+package domain_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTelemetryPoint(t *testing.T) {
+	ts := time.Date(2025, 6, 1, 11, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	point, err := domain.NewTelemetryPoint("points[0]", uuid.New(), "battery", 80, ts)
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, point.Timestamp.Location())
+	assert.True(t, point.Timestamp.Equal(ts))
+
+	tests := []struct {
+		name      string
+		metric    string
+		value     float64
+		ts        time.Time
+		wantField string
+	}{
+		{"invalid metric", "Battery", 80, ts, "points[0].metric"},
+		{"NaN value", "battery", math.NaN(), ts, "points[0].value"},
+		{"infinite value", "battery", math.Inf(1), ts, "points[0].value"},
+		{"missing timestamp", "battery", 80, time.Time{}, "points[0].ts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domain.NewTelemetryPoint("points[0]", uuid.New(), tt.metric, tt.value, tt.ts)
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.wantField, validationErr.Field)
+		})
+	}
+}
+
+func TestTelemetryPoint_CheckAge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	retention := 30 * 24 * time.Hour
+
+	at := func(ts time.Time) domain.TelemetryPoint {
+		point, err := domain.NewTelemetryPoint("points[0]", uuid.New(), "battery", 80, ts)
+		require.NoError(t, err)
+		return point
+	}
+
+	assert.NoError(t, at(now.Add(-time.Hour)).CheckAge("points[0]", now, retention))
+	assert.NoError(t, at(now.Add(time.Minute)).CheckAge("points[0]", now, retention), "small clock skew is accepted")
+	assert.True(t, domain.IsValidationError(at(now.Add(-31*24*time.Hour)).CheckAge("points[0]", now, retention)))
+	assert.True(t, domain.IsValidationError(at(now.Add(time.Hour)).CheckAge("points[0]", now, retention)))
+}
+
+func TestTelemetryQuery_Validate(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		query     domain.TelemetryQuery
+		wantField string
+	}{
+		{"valid", domain.TelemetryQuery{Metric: "temperature", From: from, To: from.Add(24 * time.Hour), Bucket: time.Hour}, ""},
+		{"invalid metric", domain.TelemetryQuery{Metric: "temp-c", From: from, To: from.Add(time.Hour), Bucket: time.Minute}, "metric"},
+		{"empty range", domain.TelemetryQuery{Metric: "temperature", From: from, To: from, Bucket: time.Minute}, "to"},
+		{"sub-minute bucket", domain.TelemetryQuery{Metric: "temperature", From: from, To: from.Add(time.Hour), Bucket: 30 * time.Second}, "bucket"},
+		{"too many buckets", domain.TelemetryQuery{Metric: "temperature", From: from, To: from.Add(365 * 24 * time.Hour), Bucket: time.Minute}, "bucket"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.wantField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.wantField, validationErr.Field)
+		})
+	}
+}
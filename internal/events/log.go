@@ -0,0 +1,38 @@
+// Package events provides implementations of domain.EventPublisher.
+package events
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"devices-api/internal/domain"
+)
+
+// LogPublisher publishes events as structured log lines.
+// It is the default publisher until a message broker is configured.
+type LogPublisher struct {
+	logger *slog.Logger
+}
+
+// NewLogPublisher creates a publisher that writes events to logger
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	return &LogPublisher{
+		logger: logger,
+	}
+}
+
+// Publish logs the event at info level with its data as attributes
+func (p *LogPublisher) Publish(ctx context.Context, event domain.Event) error {
+	attrs := []slog.Attr{
+		slog.String("event_type", event.Type),
+		slog.String("device_id", event.DeviceID.String()),
+		slog.Time("occurred_at", event.OccurredAt),
+	}
+	for _, key := range slices.Sorted(maps.Keys(event.Data)) {
+		attrs = append(attrs, slog.Any(key, event.Data[key]))
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Event published", attrs...)
+	return nil
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"net/http"
+
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BrandHandler handles HTTP requests for the brand catalog
+type BrandHandler struct {
+	service *service.BrandService
+}
+
+// NewBrandHandler creates a new brand handler
+func NewBrandHandler(service *service.BrandService) *BrandHandler {
+	return &BrandHandler{
+		service: service,
+	}
+}
+
+// ListBrands godoc
+// @Summary List brands
+// @Description List all brands ordered by name, with their aliases and the number of devices referencing them
+// @Tags brands
+// @Produce json
+// @Success 200 {object} dto.ListBrandsResponse
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /brands [get]
+func (h *BrandHandler) ListBrands(c *gin.Context) {
+	brands, err := h.service.ListBrands(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ListBrandsResponse{
+		Brands: MapBrandsToResponse(brands),
+		Total:  len(brands),
+	})
+}
+
+// GetBrand godoc
+// @Summary Get a brand by ID
+// @Description Get a single brand with its aliases and device count
+// @Tags brands
+// @Produce json
+// @Param id path string true "Brand ID (UUID)"
+// @Success 200 {object} dto.BrandResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /brands/{id} [get]
+func (h *BrandHandler) GetBrand(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	brand, err := h.service.GetBrand(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapBrandToResponse(brand))
+}
+
+// AddAlias godoc
+// @Summary Add a brand alias
+// @Description Register an alternative name for a brand. Devices created or updated with the alias reference the brand.
+// @Tags brands
+// @Accept json
+// @Produce json
+// @Param id path string true "Brand ID (UUID)"
+// @Param alias body dto.AddBrandAliasRequest true "Alias"
+// @Success 201 {object} dto.BrandResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /brands/{id}/aliases [post]
+func (h *BrandHandler) AddAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	var req dto.AddBrandAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	brand, err := h.service.AddAlias(c.Request.Context(), id, req.Alias)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, MapBrandToResponse(brand))
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CacheHandler lets operators inspect and invalidate the device cache
+type CacheHandler struct {
+	cache domain.DeviceCache
+}
+
+// NewCacheHandler creates a new cache handler
+func NewCacheHandler(cache domain.DeviceCache) *CacheHandler {
+	return &CacheHandler{
+		cache: cache,
+	}
+}
+
+// GetDeviceCacheStats godoc
+// @Summary Get device cache statistics
+// @Description Get the hits, misses and evictions of the device cache since startup.
+// @Description Each replica has its own cache, so the numbers are those of the replica serving the request. Requires the admin token.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} dto.CacheStatsResponse
+// @Failure 401 {object} dto.ProblemDetails
+// @Router /admin/cache/devices [get]
+func (h *CacheHandler) GetDeviceCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, MapCacheStatsToResponse(h.cache.Stats()))
+}
+
+// PurgeDeviceCache godoc
+// @Summary Purge the device cache
+// @Description Drop every cached device on the replica serving the request. Other replicas serve their copies until they expire.
+// @Description Requires the admin token.
+// @Tags admin
+// @Security AdminToken
+// @Success 204 "No Content"
+// @Failure 401 {object} dto.ProblemDetails
+// @Router /admin/cache/devices [delete]
+func (h *CacheHandler) PurgeDeviceCache(c *gin.Context) {
+	h.cache.Purge()
+	c.Status(http.StatusNoContent)
+}
+
+// InvalidateDevice godoc
+// @Summary Invalidate a cached device
+// @Description Drop the cached copy of a device on the replica serving the request, so its next read loads it from the database.
+// @Description Other replicas serve their copies until they expire. Requires the admin token.
+// @Tags admin
+// @Security AdminToken
+// @Param id path string true "Device ID (UUID)"
+// @Success 204 "No Content"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 401 {object} dto.ProblemDetails
+// @Router /admin/cache/devices/{id} [delete]
+func (h *CacheHandler) InvalidateDevice(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	h.cache.Invalidate(id)
+	c.Status(http.StatusNoContent)
+}
@@ -1,13 +1,18 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"devices-api/internal/domain"
 	"devices-api/internal/handler/http/dto"
 	"devices-api/internal/service"
+	"devices-api/pkg/logging"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -27,58 +32,121 @@ func NewDeviceHandler(service *service.DeviceService) *DeviceHandler {
 
 // CreateDevice godoc
 // @Summary Create a new device
-// @Description Create a new device with name and brand
+// @Description Create a new device with name, brand and optional custom attributes and labels.
+// @Description With model_id the device starts with the model's default attributes and must satisfy its category's rules.
 // @Tags devices
 // @Accept json
 // @Produce json
 // @Param device body dto.CreateDeviceRequest true "Device data"
 // @Success 201 {object} dto.DeviceResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /devices [post]
 func (h *DeviceHandler) CreateDevice(c *gin.Context) {
 	var req dto.CreateDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
-	device, err := h.service.CreateDevice(c.Request.Context(), req.Name, req.Brand)
+	device, err := createDevice(c.Request.Context(), h.service, req)
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, MapDeviceToResponse(device))
 }
 
+// createDevice creates the device described by req, starting from its model when model_id is set
+func createDevice(ctx context.Context, devices *service.DeviceService, req dto.CreateDeviceRequest) (*domain.Device, error) {
+	locationID, err := parseOptionalID("location_id", req.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	modelID, err := parseOptionalID("model_id", req.ModelID)
+	if err != nil {
+		return nil, err
+	}
+	dateOpts, err := dateOptions(req.PurchaseDate, req.WarrantyEnd, req.EOLDate)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []domain.DeviceOption{
+		domain.WithSerialNumber(req.SerialNumber),
+		domain.WithAttributes(req.Attributes),
+		domain.WithLabels(req.Labels),
+		domain.WithLocation(locationID),
+	}
+	opts = append(opts, dateOpts...)
+
+	if modelID != nil {
+		return devices.CreateDeviceFromModel(ctx, *modelID, req.Name, req.Brand, opts...)
+	}
+	return devices.CreateDevice(ctx, req.Name, req.Brand, opts...)
+}
+
 // GetDevice godoc
 // @Summary Get a device by ID
-// @Description Get a single device by its ID
+// @Description Get a single device by its ID.
+// @Description With as_of, the device is returned as it was at that time, even if it has been deleted since.
 // @Tags devices
 // @Produce json
 // @Param id path string true "Device ID (UUID)"
+// @Param as_of query string false "RFC 3339 timestamp to read the device at"
 // @Success 200 {object} dto.DeviceResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /devices/{id} [get]
 func (h *DeviceHandler) GetDevice(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Invalid UUID format",
 		})
 		return
 	}
 
-	device, err := h.service.GetDevice(c.Request.Context(), id)
+	asOf, err := parseAsOf(c)
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
+		return
+	}
+
+	var device *domain.Device
+	if asOf != nil {
+		device, err = h.service.GetDeviceAsOf(c.Request.Context(), id, *asOf)
+	} else {
+		device, err = h.service.GetDevice(c.Request.Context(), id)
+	}
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapDeviceToResponse(device))
+}
+
+// GetDeviceBySerial godoc
+// @Summary Get a device by serial number
+// @Description Get a single device by its brand and serial number. The brand may be the canonical name or an alias.
+// @Tags devices
+// @Produce json
+// @Param brand path string true "Brand name or alias"
+// @Param serial path string true "Serial number"
+// @Success 200 {object} dto.DeviceResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/by-serial/{brand}/{serial} [get]
+func (h *DeviceHandler) GetDeviceBySerial(c *gin.Context) {
+	device, err := h.service.GetDeviceBySerial(c.Request.Context(), c.Param("brand"), c.Param("serial"))
+	if err != nil {
+		handleError(c, err)
 		return
 	}
 
@@ -87,20 +155,32 @@ func (h *DeviceHandler) GetDevice(c *gin.Context) {
 
 // ListDevices godoc
 // @Summary List all devices
-// @Description Get all devices with optional pagination, brand, or state filters
+// @Description Get all devices with optional pagination, brand, state and attribute filters.
+// @Description Attribute filters use the form attr.KEY=VALUE, attr.KEY!=VALUE, attr.KEY>=N (also >, <, <=) or attr.KEY (key exists), e.g. ?attr.os=ios&attr.ram_gb>=16
+// @Description The selector parameter takes a label selector, e.g. ?selector=team=mobile,env in (lab,staging),!deprecated
+// @Description Supported requirements: key=value, key!=value, key in (a,b), key notin (a,b), key (exists) and !key (does not exist).
+// @Description location_id matches devices in the location or any location below it.
+// @Description With as_of, devices are listed as they were at that time, including devices deleted since.
+// @Description All filters are combined with AND.
 // @Tags devices
 // @Produce json
-// @Param limit query int false "Limit" default(10)
+// @Param limit query int false "Limit (capped at the configured maximum)" default(10)
 // @Param offset query int false "Offset" default(0)
-// @Param brand query string false "Filter by brand"
-// @Param state query string false "Filter by state (active, in-use, inactive)"
+// @Param brand query string false "Filter by brand name or alias, ignoring case"
+// @Param state query string false "Filter by state (active, in-use, inactive, maintenance)"
+// @Param attr.KEY query string false "Filter by custom attribute (see description for operators)"
+// @Param selector query string false "Label selector (see description for syntax)"
+// @Param location_id query string false "Filter by location, including its descendants"
+// @Param model_id query string false "Filter by catalog model"
+// @Param category query string false "Filter by model category (laptop, phone, tablet, sensor)"
+// @Param as_of query string false "RFC 3339 timestamp to list devices at"
 // @Success 200 {object} dto.ListDevicesResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /devices [get]
 func (h *DeviceHandler) ListDevices(c *gin.Context) {
-	// Parse query parameters
-	limit := 10
+	// Parse query parameters; the service fills in the configured default and cap
+	limit := 0
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := parsePositiveInt(l); err == nil {
 			limit = parsed
@@ -114,24 +194,17 @@ func (h *DeviceHandler) ListDevices(c *gin.Context) {
 		}
 	}
 
-	brand := c.Query("brand")
-	stateStr := c.Query("state")
-
-	var devices []*domain.Device
-	var err error
+	limit, offset = h.service.NormalizePagination(limit, offset)
 
-	// Handle filtering
-	if brand != "" {
-		devices, err = h.service.ListDevicesByBrand(c.Request.Context(), brand, limit, offset)
-	} else if stateStr != "" {
-		state := domain.DeviceState(stateStr)
-		devices, err = h.service.ListDevicesByState(c.Request.Context(), state, limit, offset)
-	} else {
-		devices, err = h.service.ListDevices(c.Request.Context(), limit, offset)
+	filter, err := parseDeviceFilter(c)
+	if err != nil {
+		handleError(c, err)
+		return
 	}
 
+	devices, err := h.service.SearchDevices(c.Request.Context(), filter, limit, offset)
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
 		return
 	}
 
@@ -145,6 +218,119 @@ func (h *DeviceHandler) ListDevices(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetDeviceStats godoc
+// @Summary Device inventory statistics
+// @Description Count devices and average their age, grouped by any of brand, state, category and location,
+// @Description and optionally by the calendar period (in UTC) they were created in, e.g. ?group_by=brand,state&interval=month
+// @Description Without grouping, all matching devices form a single group. Groups without devices are omitted.
+// @Description The filters are those of GET /devices.
+// @Tags stats
+// @Produce json
+// @Param group_by query string false "Comma-separated dimensions (brand, state, category, location)"
+// @Param interval query string false "Group by creation period (day, week, month, quarter, year)"
+// @Param brand query string false "Filter by brand name or alias, ignoring case"
+// @Param state query string false "Filter by state (active, in-use, inactive, maintenance)"
+// @Param attr.KEY query string false "Filter by custom attribute (see GET /devices)"
+// @Param selector query string false "Label selector (see GET /devices)"
+// @Param location_id query string false "Filter by location, including its descendants"
+// @Param model_id query string false "Filter by catalog model"
+// @Param category query string false "Filter by model category (laptop, phone, tablet, sensor)"
+// @Param as_of query string false "RFC 3339 timestamp to count devices at; ages are measured at that time"
+// @Success 200 {object} dto.DeviceStatsResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /stats/devices [get]
+func (h *DeviceHandler) GetDeviceStats(c *gin.Context) {
+	filter, err := parseDeviceFilter(c)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	query := domain.DeviceStatsQuery{
+		Filter:   filter,
+		Interval: domain.StatsInterval(c.Query("interval")),
+	}
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		for _, dimension := range strings.Split(groupBy, ",") {
+			query.GroupBy = append(query.GroupBy, domain.StatsDimension(strings.TrimSpace(dimension)))
+		}
+	}
+
+	stats, err := h.service.DeviceStats(c.Request.Context(), query)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapDeviceStatsToResponse(query, stats))
+}
+
+// parseDeviceFilter reads the device list filters from the query string
+func parseDeviceFilter(c *gin.Context) (domain.DeviceFilter, error) {
+	attributes, err := parseAttributeFilters(c.Request.URL.RawQuery)
+	if err != nil {
+		return domain.DeviceFilter{}, err
+	}
+
+	selector, err := domain.ParseLabelSelector(c.Query("selector"))
+	if err != nil {
+		return domain.DeviceFilter{}, err
+	}
+
+	var locationID *uuid.UUID
+	if l := c.Query("location_id"); l != "" {
+		if locationID, err = parseOptionalID("location_id", &l); err != nil {
+			return domain.DeviceFilter{}, err
+		}
+	}
+
+	var modelID *uuid.UUID
+	if m := c.Query("model_id"); m != "" {
+		if modelID, err = parseOptionalID("model_id", &m); err != nil {
+			return domain.DeviceFilter{}, err
+		}
+	}
+
+	asOf, err := parseAsOf(c)
+	if err != nil {
+		return domain.DeviceFilter{}, err
+	}
+
+	return domain.DeviceFilter{
+		Brand:      c.Query("brand"),
+		State:      domain.DeviceState(c.Query("state")),
+		Attributes: attributes,
+		Labels:     selector,
+		LocationID: locationID,
+		ModelID:    modelID,
+		Category:   domain.DeviceCategory(c.Query("category")),
+		AsOf:       asOf,
+	}, nil
+}
+
+// parseAsOf reads the optional as_of timestamp of a point-in-time read
+func parseAsOf(c *gin.Context) (*time.Time, error) {
+	return parseAsOfValue("as_of", c.Query("as_of"))
+}
+
+// parseAsOfValue parses a point-in-time read timestamp, reporting failures on field.
+// An empty value means the current state.
+func parseAsOfValue(field, raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	asOf, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, domain.NewValidationError(field, "must be an RFC 3339 timestamp")
+	}
+	if asOf.After(time.Now()) {
+		return nil, domain.NewValidationError(field, "must not be in the future")
+	}
+	asOf = asOf.UTC()
+	return &asOf, nil
+}
+
 // UpdateDevice godoc
 // @Summary Fully update a device
 // @Description Fully update an existing device (all fields required)
@@ -154,15 +340,16 @@ func (h *DeviceHandler) ListDevices(c *gin.Context) {
 // @Param id path string true "Device ID (UUID)"
 // @Param device body dto.UpdateDeviceRequest true "Device data"
 // @Success 200 {object} dto.DeviceResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 422 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /devices/{id} [put]
 func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Invalid UUID format",
 		})
@@ -171,17 +358,47 @@ func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
 
 	var req dto.UpdateDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
+	var opts []domain.DeviceOption
+	if req.SerialNumber != nil {
+		opts = append(opts, domain.WithSerialNumber(*req.SerialNumber))
+	}
+	if req.Attributes != nil {
+		opts = append(opts, domain.WithAttributes(req.Attributes))
+	}
+	if req.Labels != nil {
+		opts = append(opts, domain.WithLabels(req.Labels))
+	}
+	if req.LocationID != nil {
+		locationID, err := parseOptionalID("location_id", req.LocationID)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		opts = append(opts, domain.WithLocation(locationID))
+	}
+	if req.ModelID != nil {
+		modelID, err := parseOptionalID("model_id", req.ModelID)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		opts = append(opts, domain.WithModel(modelID))
+	}
+	dateOpts, err := dateOptions(req.PurchaseDate, req.WarrantyEnd, req.EOLDate)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	opts = append(opts, dateOpts...)
+
 	state := domain.DeviceState(req.State)
-	device, err := h.service.UpdateDevice(c.Request.Context(), id, req.Name, req.Brand, state)
+	device, err := h.service.UpdateDevice(c.Request.Context(), id, req.Name, req.Brand, state, opts...)
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
 		return
 	}
 
@@ -197,15 +414,16 @@ func (h *DeviceHandler) UpdateDevice(c *gin.Context) {
 // @Param id path string true "Device ID (UUID)"
 // @Param device body dto.PartialUpdateDeviceRequest true "Device data"
 // @Success 200 {object} dto.DeviceResponse
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 422 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 409 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /devices/{id} [patch]
 func (h *DeviceHandler) PartialUpdateDevice(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Invalid UUID format",
 		})
@@ -214,22 +432,19 @@ func (h *DeviceHandler) PartialUpdateDevice(c *gin.Context) {
 
 	var req dto.PartialUpdateDeviceRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error:   "validation_error",
-			Message: err.Error(),
-		})
+		bindError(c, err)
 		return
 	}
 
-	var state *domain.DeviceState
-	if req.State != nil {
-		s := domain.DeviceState(*req.State)
-		state = &s
+	patch, err := MapPatchRequest(req)
+	if err != nil {
+		handleError(c, err)
+		return
 	}
 
-	device, err := h.service.PartialUpdateDevice(c.Request.Context(), id, req.Name, req.Brand, state)
+	device, err := h.service.PartialUpdateDevice(c.Request.Context(), id, patch)
 	if err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
 		return
 	}
 
@@ -242,15 +457,15 @@ func (h *DeviceHandler) PartialUpdateDevice(c *gin.Context) {
 // @Tags devices
 // @Param id path string true "Device ID (UUID)"
 // @Success 204 "No Content"
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 422 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
 // @Router /devices/{id} [delete]
 func (h *DeviceHandler) DeleteDevice(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
 			Error:   "invalid_id",
 			Message: "Invalid UUID format",
 		})
@@ -258,7 +473,145 @@ func (h *DeviceHandler) DeleteDevice(c *gin.Context) {
 	}
 
 	if err := h.service.DeleteDevice(c.Request.Context(), id); err != nil {
-		h.handleError(c, err)
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetLabels godoc
+// @Summary Get device labels
+// @Description Get the labels of a device
+// @Tags labels
+// @Produce json
+// @Param id path string true "Device ID (UUID)"
+// @Success 200 {object} dto.LabelsResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/labels [get]
+func (h *DeviceHandler) GetLabels(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	device, err := h.service.GetDevice(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LabelsResponse{Labels: mapLabels(device.Labels)})
+}
+
+// ReplaceLabels godoc
+// @Summary Replace device labels
+// @Description Replace all labels of a device. Labels can be changed in any device state.
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID (UUID)"
+// @Param labels body object true "Label map (key to value)"
+// @Success 200 {object} dto.LabelsResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/labels [put]
+func (h *DeviceHandler) ReplaceLabels(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	var labels map[string]string
+	if err := c.ShouldBindJSON(&labels); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	device, err := h.service.ReplaceLabels(c.Request.Context(), id, labels)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LabelsResponse{Labels: mapLabels(device.Labels)})
+}
+
+// UpdateLabels godoc
+// @Summary Update device labels
+// @Description Merge labels into the existing ones; a null value removes a label
+// @Tags labels
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID (UUID)"
+// @Param labels body object true "Label changes (key to value, null removes)"
+// @Success 200 {object} dto.LabelsResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/labels [patch]
+func (h *DeviceHandler) UpdateLabels(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	var changes map[string]*string
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	device, err := h.service.UpdateLabels(c.Request.Context(), id, changes)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.LabelsResponse{Labels: mapLabels(device.Labels)})
+}
+
+// DeleteLabel godoc
+// @Summary Delete a device label
+// @Description Remove a single label; prefixed keys such as example.com/env are supported
+// @Tags labels
+// @Param id path string true "Device ID (UUID)"
+// @Param key path string true "Label key"
+// @Success 204 "No Content"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/labels/{key} [delete]
+func (h *DeviceHandler) DeleteLabel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	// The key is a catch-all parameter so prefixed keys keep their slash
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	if _, err := h.service.DeleteLabel(c.Request.Context(), id, key); err != nil {
+		handleError(c, err)
 		return
 	}
 
@@ -266,40 +619,74 @@ func (h *DeviceHandler) DeleteDevice(c *gin.Context) {
 }
 
 // handleError maps domain errors to appropriate HTTP responses
-func (h *DeviceHandler) handleError(c *gin.Context, err error) {
+func handleError(c *gin.Context, err error) {
+	logError(c, err)
+
+	status, response := classifyError(err)
+	if status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Bearer realm="devices-api"`)
+	}
+	respondError(c, status, response, invalidFields(err, response))
+}
+
+// classifyError maps a domain error to its HTTP status and error response.
+// Unexpected errors are masked so internal details never reach clients.
+func classifyError(err error) (int, dto.ErrorResponse) {
 	if domain.IsNotFoundError(err) {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+		return http.StatusNotFound, dto.ErrorResponse{
 			Error:   "not_found",
 			Message: err.Error(),
-		})
-		return
+		}
+	}
+
+	if errors.Is(err, domain.ErrUnauthorized) {
+		return http.StatusUnauthorized, dto.ErrorResponse{
+			Error:   "unauthorized",
+			Message: err.Error(),
+		}
+	}
+
+	if domain.IsAlreadyExistsError(err) {
+		response := dto.ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		}
+		var conflictErr *domain.ConflictError
+		if errors.As(err, &conflictErr) {
+			response.Message = conflictErr.Message
+			response.Field = conflictErr.Field
+		}
+		return http.StatusConflict, response
 	}
 
 	if domain.IsValidationError(err) {
 		var validationErr *domain.ValidationError
 		if errors.As(err, &validationErr) {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			response := dto.ErrorResponse{
 				Error:   "validation_error",
 				Message: validationErr.Message,
 				Field:   validationErr.Field,
-			})
-			return
+			}
+			// Several violations are summarized; the field is the first one
+			if invalid := invalidFields(err, response); len(invalid) > 1 {
+				response.Message = summarizeFields(invalid)
+			}
+			return http.StatusBadRequest, response
 		}
 	}
 
 	if domain.IsBusinessRuleError(err) {
-		c.JSON(http.StatusUnprocessableEntity, dto.ErrorResponse{
+		return http.StatusUnprocessableEntity, dto.ErrorResponse{
 			Error:   "business_rule_violation",
 			Message: err.Error(),
-		})
-		return
+		}
 	}
 
 	// Internal server error
-	c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+	return http.StatusInternalServerError, dto.ErrorResponse{
 		Error:   "internal_error",
 		Message: "An unexpected error occurred",
-	})
+	}
 }
 
 // parsePositiveInt is a helper to parse positive integers
@@ -313,3 +700,43 @@ func parsePositiveInt(s string) (int, error) {
 	}
 	return i, nil
 }
+
+// attributeFilterPrefix marks query parameters that filter by custom attribute
+const attributeFilterPrefix = "attr."
+
+// parseAttributeFilters extracts attr.* filters from the raw query string.
+// The raw query is needed because operators such as >= are not key=value pairs.
+func parseAttributeFilters(rawQuery string) ([]domain.AttributeFilter, error) {
+	var filters []domain.AttributeFilter
+	for _, term := range strings.Split(rawQuery, "&") {
+		term, err := url.QueryUnescape(term)
+		if err != nil {
+			return nil, domain.NewValidationError("query", "malformed query string")
+		}
+		expr, ok := strings.CutPrefix(term, attributeFilterPrefix)
+		if !ok {
+			continue
+		}
+
+		filter, err := domain.ParseAttributeFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// logError logs a service or repository error with the request-scoped logger.
+// Expected domain errors are logged at info level, everything else as an error.
+func logError(c *gin.Context, err error) {
+	logger := logging.FromContext(c.Request.Context())
+
+	if domain.IsNotFoundError(err) || domain.IsValidationError(err) || domain.IsBusinessRuleError(err) ||
+		domain.IsAlreadyExistsError(err) || errors.Is(err, domain.ErrUnauthorized) {
+		logger.Info("Request rejected", "error", err)
+		return
+	}
+
+	logger.Error("Request failed", "error", err)
+}
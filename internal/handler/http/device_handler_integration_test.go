@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
-	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"devices-api/internal/domain"
 	httphandler "devices-api/internal/handler/http"
 	"devices-api/internal/handler/http/dto"
 	"devices-api/internal/repository"
@@ -37,8 +42,7 @@ func TestMain(m *testing.M) {
 	}
 
 	// Apply migrations
-	migrationsPath := filepath.Join("..", "..", "..", "migrations")
-	err = pgContainer.ApplyMigrations(ctx, migrationsPath)
+	err = pgContainer.ApplyMigrations(ctx)
 	if err != nil {
 		pgContainer.Close(ctx)
 		panic("failed to apply migrations: " + err.Error())
@@ -55,20 +59,61 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-// setupTestRouter creates a test router with fresh database state
-func setupTestRouter(t *testing.T) *httptest.Server {
+// setupTestRouter creates a test router with fresh database state.
+// Options are applied after the defaults.
+func setupTestRouter(t *testing.T, opts ...httphandler.RouterOption) *httptest.Server {
 	ctx := context.Background()
 	err := pgContainer.Cleanup(ctx)
 	require.NoError(t, err, "failed to cleanup database")
 
 	pool := pgContainer.GetPool()
 	repo := repository.NewPostgresDeviceRepository(pool)
-	svc := service.NewDeviceService(repo)
-	router := httphandler.SetupRouter(svc)
+	locationRepo := repository.NewPostgresLocationRepository(pool)
+	brandRepo := repository.NewPostgresBrandRepository(pool)
+	modelRepo := repository.NewPostgresModelRepository(pool)
+	txManager := repository.NewPostgresTxManager(pool)
+	svc := service.NewDeviceService(repo,
+		service.WithLocationRepository(locationRepo),
+		service.WithBrandRepository(brandRepo),
+		service.WithModelRepository(modelRepo),
+		service.WithTxManager(txManager),
+	)
+	router := httphandler.SetupRouter(svc, append([]httphandler.RouterOption{
+		httphandler.WithLocationService(service.NewLocationService(locationRepo)),
+		httphandler.WithBrandService(service.NewBrandService(brandRepo)),
+		httphandler.WithModelService(service.NewModelService(modelRepo)),
+		httphandler.WithReportService(service.NewReportService(repository.NewPostgresExpiryRepository(pool))),
+		httphandler.WithMaintenanceService(service.NewMaintenanceService(
+			repository.NewPostgresMaintenanceRepository(pool), repo, txManager,
+			domain.MaintenanceIntervals{domain.DeviceCategoryLaptop: 365},
+		)),
+		httphandler.WithHeartbeatService(service.NewHeartbeatService(repository.NewPostgresHeartbeatRepository(pool))),
+		httphandler.WithTelemetryService(service.NewTelemetryService(
+			repository.NewPostgresTelemetryRepository(pool), repo, 30*24*time.Hour, testTelemetryMaxBatch,
+		)),
+		httphandler.WithAdminToken(testAdminToken),
+	}, opts...)...)
 
 	return httptest.NewServer(router)
 }
 
+// testAdminToken is the admin token of the test router
+const testAdminToken = "test-admin-token"
+
+// testTelemetryMaxBatch is the largest telemetry batch the test router accepts
+const testTelemetryMaxBatch = 3
+
+// adminPost sends a POST authenticated with the admin token
+func adminPost(t *testing.T, url string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
 // ========== Health Check Tests ==========
 
 func TestHealthCheck(t *testing.T) {
@@ -142,10 +187,10 @@ func TestCreateDevice_ValidationError_EmptyName(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var result dto.ErrorResponse
+	var result dto.ProblemDetails
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	require.NoError(t, err)
-	assert.Equal(t, "validation_error", result.Error)
+	assert.Equal(t, "validation_error", result.Code)
 }
 
 func TestCreateDevice_ValidationError_ShortName(t *testing.T) {
@@ -170,10 +215,10 @@ func TestCreateDevice_ValidationError_ShortName(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 
-	var result dto.ErrorResponse
+	var result dto.ProblemDetails
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	require.NoError(t, err)
-	assert.Equal(t, "validation_error", result.Error)
+	assert.Equal(t, "validation_error", result.Code)
 }
 
 func TestCreateDevice_InvalidJSON(t *testing.T) {
@@ -192,6 +237,98 @@ func TestCreateDevice_InvalidJSON(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 }
 
+func TestCreateDevice_ProblemDetails(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	post := func(accept string) *http.Response {
+		body := []byte(`{"name": "ab", "location_id": "nowhere"}`)
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/devices", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(httphandler.RequestIDHeader, "req-123")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// Every invalid field is listed by its JSON name
+	resp := post("")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	var problem dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "urn:devices-api:problem:validation-error", problem.Type)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "urn:devices-api:request:req-123", problem.Instance)
+	assert.Equal(t, "validation_error", problem.Code)
+	assert.Equal(t, []dto.InvalidField{
+		{Field: "name", Message: "must be at least 3 characters long"},
+		{Field: "brand", Message: "is required"},
+		{Field: "location_id", Message: "must be a UUID"},
+	}, problem.Errors)
+
+	// Clients asking for application/json keep the legacy format
+	resp = post("application/json")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+
+	var legacy dto.ErrorResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&legacy))
+	assert.Equal(t, "validation_error", legacy.Error)
+	assert.Equal(t, "name", legacy.Field)
+	assert.Equal(t, "name must be at least 3 characters long; brand is required; location_id must be a UUID", legacy.Message)
+}
+
+func TestCreateDevice_ReportsEveryDomainViolation(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	// Both pass binding but are blank once trimmed
+	body := []byte(`{"name": "   ", "brand": "  ", "serial_number": "has space"}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var problem dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "validation_error", problem.Code)
+	assert.Equal(t, []dto.InvalidField{
+		{Field: "name", Message: "cannot be empty"},
+		{Field: "brand", Message: "cannot be empty"},
+		{Field: "serial_number", Message: "must not contain whitespace or slashes"},
+	}, problem.Errors)
+}
+
+func TestGetDevice_NotFound_ProblemDetails(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/devices/"+uuid.New().String(), nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/problem+json, application/json;q=0.5")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+	var problem dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	assert.Equal(t, "urn:devices-api:problem:not-found", problem.Type)
+	assert.Equal(t, "urn:devices-api:request:"+resp.Header.Get(httphandler.RequestIDHeader), problem.Instance)
+	assert.Empty(t, problem.Errors)
+}
+
 // ========== Get Device Tests ==========
 
 func TestGetDevice_Success(t *testing.T) {
@@ -222,157 +359,1241 @@ func TestGetDevice_NotFound(t *testing.T) {
 	server := setupTestRouter(t)
 	defer server.Close()
 
-	nonExistentID := uuid.New().String()
-	resp, err := http.Get(server.URL + "/api/v1/devices/" + nonExistentID)
+	nonExistentID := uuid.New().String()
+	resp, err := http.Get(server.URL + "/api/v1/devices/" + nonExistentID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var result dto.ProblemDetails
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, "not_found", result.Code)
+}
+
+func TestGetDevice_InvalidUUID(t *testing.T) {
+
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/devices/invalid-uuid")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result dto.ProblemDetails
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_id", result.Code)
+}
+
+// ========== List Devices Tests ==========
+
+func TestListDevices_Empty(t *testing.T) {
+
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/devices")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.ListDevicesResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Total)
+	assert.Len(t, result.Devices, 0)
+}
+
+func TestListDevices_WithData(t *testing.T) {
+
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	// Create multiple devices
+	createTestDevice(t, server, "iPhone 15", "Apple")
+	createTestDevice(t, server, "Galaxy S24", "Samsung")
+	createTestDevice(t, server, "Pixel 9", "Google")
+
+	resp, err := http.Get(server.URL + "/api/v1/devices")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.ListDevicesResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+	assert.Len(t, result.Devices, 3)
+}
+
+func TestListDevices_WithPagination(t *testing.T) {
+
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	// Create 5 devices
+	for i := 1; i <= 5; i++ {
+		createTestDevice(t, server, "Device "+string(rune('A'+i-1)), "Brand")
+	}
+
+	// Get first page
+	resp, err := http.Get(server.URL + "/api/v1/devices?limit=2&offset=0")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.ListDevicesResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 0, result.Offset)
+}
+
+func TestListDevices_FilterByBrand(t *testing.T) {
+
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	// Create devices from different brands
+	createTestDevice(t, server, "iPhone 15", "Apple")
+	createTestDevice(t, server, "MacBook Pro", "Apple")
+	createTestDevice(t, server, "Galaxy S24", "Samsung")
+
+	resp, err := http.Get(server.URL + "/api/v1/devices?brand=Apple")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.ListDevicesResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+
+	for _, device := range result.Devices {
+		assert.Equal(t, "Apple", device.Brand)
+	}
+}
+
+func TestListDevices_FilterByState(t *testing.T) {
+
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	// Create devices and update their states
+	device1 := createTestDevice(t, server, "Device 1", "Brand1")
+	device2 := createTestDevice(t, server, "Device 2", "Brand2")
+
+	// Update device2 to inactive
+	updatePayload := dto.PartialUpdateDeviceRequest{
+		State: stringPtr("inactive"),
+	}
+	updateTestDevice(t, server, device2.ID, updatePayload)
+
+	// Filter by active state
+	resp, err := http.Get(server.URL + "/api/v1/devices?state=active")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.ListDevicesResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, device1.ID, result.Devices[0].ID)
+}
+
+func TestListDevices_FilterByAttributes(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	createTestDeviceWithAttributes(t, server, "iPhone 15", "Apple", map[string]any{"os": "ios", "ram_gb": 8})
+	laptop := createTestDeviceWithAttributes(t, server, "MacBook Pro", "Apple", map[string]any{"os": "macos", "ram_gb": 32})
+	createTestDeviceWithAttributes(t, server, "Galaxy S24", "Samsung", map[string]any{"os": "android", "ram_gb": 16})
+
+	resp, err := http.Get(server.URL + "/api/v1/devices?brand=Apple&attr.ram_gb>=16")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.ListDevicesResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, laptop.ID, result.Devices[0].ID)
+	assert.Equal(t, "macos", result.Devices[0].Attributes["os"])
+}
+
+func TestListDevices_InvalidAttributeFilter(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/devices?attr.ram_gb>=lots")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result dto.ProblemDetails
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, "validation_error", result.Code)
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, "attr.ram_gb", result.Errors[0].Field)
+}
+
+func TestCreateDevice_InvalidAttributeKey(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	body := []byte(`{"name": "iPhone 15", "brand": "Apple", "attributes": {"Bad-Key": "x"}}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result dto.ProblemDetails
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, "attributes.Bad-Key", result.Errors[0].Field)
+}
+
+// ========== Label Tests ==========
+
+func TestListDevices_FilterByLabelSelector(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	createTestDeviceWithLabels(t, server, "iPhone 15", "Apple", map[string]string{"team": "mobile", "env": "prod"})
+	lab := createTestDeviceWithLabels(t, server, "Galaxy S24", "Samsung", map[string]string{"team": "mobile", "env": "lab"})
+	createTestDeviceWithLabels(t, server, "MacBook Pro", "Apple", map[string]string{"team": "web", "env": "lab"})
+	createTestDeviceWithLabels(t, server, "Pixel 9", "Google", map[string]string{"team": "mobile", "env": "lab", "deprecated": ""})
+
+	selector := url.QueryEscape("team=mobile,env in (lab,staging),!deprecated")
+	resp, err := http.Get(server.URL + "/api/v1/devices?selector=" + selector)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.ListDevicesResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, lab.ID, result.Devices[0].ID)
+	assert.Equal(t, map[string]string{"team": "mobile", "env": "lab"}, result.Devices[0].Labels)
+}
+
+func TestListDevices_InvalidLabelSelector(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/devices?selector=" + url.QueryEscape("tier in gold"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result dto.ProblemDetails
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, "validation_error", result.Code)
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, "selector", result.Errors[0].Field)
+	assert.Contains(t, result.Detail, "position 9")
+}
+
+func TestDeviceLabels_CRUD(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	device := createTestDevice(t, server, "iPhone 15", "Apple")
+	assert.Equal(t, map[string]string{}, device.Labels)
+	labelsURL := server.URL + "/api/v1/devices/" + device.ID + "/labels"
+
+	// Replace
+	resp := doLabelRequest(t, http.MethodPut, labelsURL, `{"team": "mobile", "example.com/env": "lab"}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Merge: change one, add one, remove one
+	resp = doLabelRequest(t, http.MethodPatch, labelsURL, `{"team": "web", "tier": "gold", "example.com/env": null}`)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Delete a single label
+	resp = doLabelRequest(t, http.MethodDelete, labelsURL+"/tier", "")
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp = doLabelRequest(t, http.MethodDelete, labelsURL+"/tier", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err := http.Get(labelsURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result dto.LabelsResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "web"}, result.Labels)
+}
+
+func TestDeviceLabels_InvalidLabel(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	device := createTestDevice(t, server, "iPhone 15", "Apple")
+
+	resp := doLabelRequest(t, http.MethodPut, server.URL+"/api/v1/devices/"+device.ID+"/labels", `{"team": "-mobile"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// ========== Location Tests ==========
+
+func TestLocations_HierarchyAndDeviceFilter(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	site := createTestLocation(t, server, "Berlin", "site", nil)
+	building := createTestLocation(t, server, "HQ", "building", &site.ID)
+	room := createTestLocation(t, server, "Lab 1", "room", &building.ID)
+	otherSite := createTestLocation(t, server, "Lisbon", "site", nil)
+
+	inRoom := createTestDevice(t, server, "iPhone 15", "Apple")
+	updateTestDevice(t, server, inRoom.ID, dto.PartialUpdateDeviceRequest{
+		LocationID: dto.NullableString{Set: true, Value: &room.ID},
+	})
+	createTestDevice(t, server, "Galaxy S24", "Samsung")
+
+	// Filtering by the site includes devices in its buildings and rooms
+	resp, err := http.Get(server.URL + "/api/v1/devices?location_id=" + site.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result dto.ListDevicesResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, inRoom.ID, result.Devices[0].ID)
+	assert.Equal(t, &room.ID, result.Devices[0].LocationID)
+
+	resp, err = http.Get(server.URL + "/api/v1/devices?location_id=" + otherSite.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, 0, result.Total)
+}
+
+func TestLocations_InvalidHierarchy(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	site := createTestLocation(t, server, "Berlin", "site", nil)
+
+	body := []byte(`{"name": "Lab 1", "type": "room", "parent_id": "` + site.ID + `"}`)
+	resp, err := http.Post(server.URL+"/api/v1/locations", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, "parent_id", result.Errors[0].Field)
+}
+
+func TestDeleteLocation_WithDevices(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	site := createTestLocation(t, server, "Berlin", "site", nil)
+	device := createTestDevice(t, server, "iPhone 15", "Apple")
+	updateTestDevice(t, server, device.ID, dto.PartialUpdateDeviceRequest{
+		LocationID: dto.NullableString{Set: true, Value: &site.ID},
+	})
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/api/v1/locations/"+site.ID, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	// Removing the device from the location allows the delete
+	body := []byte(`{"location_id": null}`)
+	req, err = http.NewRequest(http.MethodPatch, server.URL+"/api/v1/devices/"+device.ID, bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var updated dto.DeviceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&updated))
+	assert.Nil(t, updated.LocationID)
+
+	req, err = http.NewRequest(http.MethodDelete, server.URL+"/api/v1/locations/"+site.ID, nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestCreateDevice_UnknownLocation(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	body := []byte(`{"name": "iPhone 15", "brand": "Apple", "location_id": "` + uuid.New().String() + `"}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, "location_id", result.Errors[0].Field)
+}
+
+func TestBrands_MergeVariantSpellings(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	first := createTestDevice(t, server, "iPhone 15", "Apple")
+	second := createTestDevice(t, server, "MacBook Pro", "apple")
+	third := createTestDevice(t, server, "iPad Air", "APPLE ")
+	createTestDevice(t, server, "Galaxy S24", "Samsung")
+
+	// Variant spellings reference the same brand and read back canonically
+	assert.Equal(t, first.BrandID, second.BrandID)
+	assert.Equal(t, first.BrandID, third.BrandID)
+	assert.Equal(t, "Apple", third.Brand)
+
+	resp, err := http.Get(server.URL + "/api/v1/devices?brand=aPPle")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var devices dto.ListDevicesResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&devices))
+	assert.Equal(t, 3, devices.Total)
+
+	brandsResp, err := http.Get(server.URL + "/api/v1/brands")
+	require.NoError(t, err)
+	defer brandsResp.Body.Close()
+	assert.Equal(t, http.StatusOK, brandsResp.StatusCode)
+
+	var brands dto.ListBrandsResponse
+	require.NoError(t, json.NewDecoder(brandsResp.Body).Decode(&brands))
+	require.Equal(t, 2, brands.Total)
+	assert.Equal(t, "Apple", brands.Brands[0].Name)
+	assert.Equal(t, 3, brands.Brands[0].DeviceCount)
+	assert.Equal(t, "Samsung", brands.Brands[1].Name)
+	assert.Equal(t, 1, brands.Brands[1].DeviceCount)
+}
+
+func TestBrands_Alias(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	laptop := createTestDevice(t, server, "EliteBook", "Hewlett-Packard")
+
+	resp, err := http.Post(server.URL+"/api/v1/brands/"+laptop.BrandID+"/aliases", "application/json",
+		bytes.NewBufferString(`{"alias": "HP"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var brand dto.BrandResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&brand))
+	assert.Equal(t, []string{"HP"}, brand.Aliases)
+
+	// Devices created with the alias reference the canonical brand
+	printer := createTestDevice(t, server, "LaserJet", "hp")
+	assert.Equal(t, laptop.BrandID, printer.BrandID)
+	assert.Equal(t, "Hewlett-Packard", printer.Brand)
+
+	// An alias cannot name an existing brand
+	resp, err = http.Post(server.URL+"/api/v1/brands/"+laptop.BrandID+"/aliases", "application/json",
+		bytes.NewBufferString(`{"alias": "hewlett-packard"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/api/v1/brands/" + uuid.New().String())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestModels_CreateDeviceFromModel(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	laptop := createTestModel(t, server, dto.CreateModelRequest{
+		Name:              "MacBook Pro 14 M3",
+		Brand:             "Apple",
+		Category:          "laptop",
+		DefaultAttributes: map[string]any{"ram_gb": 16, "os": "macos"},
+		LifecycleMonths:   48,
+	})
+	assert.Equal(t, "laptop", laptop.Category)
+
+	body := []byte(`{"name": "Dev laptop", "model_id": "` + laptop.ID + `", "attributes": {"ram_gb": 32}}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var device dto.DeviceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&device))
+	assert.Equal(t, "Apple", device.Brand)
+	assert.Equal(t, &laptop.ID, device.ModelID)
+	assert.Equal(t, "laptop", device.Category)
+	assert.Equal(t, map[string]any{"ram_gb": float64(32), "os": "macos"}, device.Attributes)
+
+	createTestDevice(t, server, "Galaxy S24", "Samsung")
+
+	for _, query := range []string{"model_id=" + laptop.ID, "category=laptop"} {
+		resp, err := http.Get(server.URL + "/api/v1/devices?" + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var result dto.ListDevicesResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		require.Equal(t, 1, result.Total, query)
+		assert.Equal(t, device.ID, result.Devices[0].ID)
+	}
+
+	// Models referenced by devices cannot be deleted
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/api/v1/models/"+laptop.ID, nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestModels_PhoneRequiresIMEI(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	phone := createTestModel(t, server, dto.CreateModelRequest{Name: "iPhone 15", Brand: "Apple", Category: "phone"})
+
+	body := []byte(`{"name": "iPhone 15", "model_id": "` + phone.ID + `"}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var result dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, "attributes.imei", result.Errors[0].Field)
+
+	body = []byte(`{"name": "iPhone 15", "model_id": "` + phone.ID + `", "attributes": {"imei": "490154203237518"}}`)
+	resp, err = http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// A model of another brand is rejected
+	body = []byte(`{"name": "Galaxy S24", "brand": "Samsung", "model_id": "` + phone.ID + `", "attributes": {"imei": "490154203237518"}}`)
+	resp, err = http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestModels_CRUD(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	model := createTestModel(t, server, dto.CreateModelRequest{Name: "Pixel 9", Brand: "Google", Category: "phone"})
+	createTestModel(t, server, dto.CreateModelRequest{Name: "Chromebook", Brand: "Google", Category: "laptop"})
+
+	resp, err := http.Get(server.URL + "/api/v1/models?category=phone")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var list dto.ListModelsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	require.Equal(t, 1, list.Total)
+	assert.Equal(t, "Pixel 9", list.Models[0].Name)
+
+	body := []byte(`{"name": "Pixel 9 Pro", "default_attributes": {"storage_gb": 256}, "lifecycle_months": 36}`)
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/api/v1/models/"+model.ID, bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var updated dto.ModelResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&updated))
+	assert.Equal(t, "Pixel 9 Pro", updated.Name)
+	assert.Equal(t, "phone", updated.Category)
+	assert.Equal(t, 36, updated.LifecycleMonths)
+
+	// Model names are unique per brand
+	payload, err := json.Marshal(dto.CreateModelRequest{Name: "pixel 9 pro", Brand: "google", Category: "phone"})
+	require.NoError(t, err)
+	resp, err = http.Post(server.URL+"/api/v1/models", "application/json", bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	req, err = http.NewRequest(http.MethodDelete, server.URL+"/api/v1/models/"+model.ID, nil)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/api/v1/models/" + model.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestDevices_SerialNumber(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	body := []byte(`{"name": "MacBook Pro", "brand": "Apple", "serial_number": "C02XK0AAJGH5"}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var laptop dto.DeviceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&laptop))
+	assert.Equal(t, "C02XK0AAJGH5", laptop.SerialNumber)
+
+	// A second device of the same brand cannot reuse the serial number
+	body = []byte(`{"name": "MacBook Air", "brand": "apple", "serial_number": "C02XK0AAJGH5"}`)
+	resp, err = http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	var errResp dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	assert.Equal(t, "conflict", errResp.Code)
+	require.NotEmpty(t, errResp.Errors)
+	assert.Equal(t, "serial_number", errResp.Errors[0].Field)
+
+	// Lookup by brand and serial number
+	resp, err = http.Get(server.URL + "/api/v1/devices/by-serial/apple/C02XK0AAJGH5")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var found dto.DeviceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&found))
+	assert.Equal(t, laptop.ID, found.ID)
+
+	resp, err = http.Get(server.URL + "/api/v1/devices/by-serial/Samsung/C02XK0AAJGH5")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// A null serial number removes it
+	updateTestDevice(t, server, laptop.ID, dto.PartialUpdateDeviceRequest{
+		SerialNumber: dto.NullableString{Set: true},
+	})
+	resp, err = http.Get(server.URL + "/api/v1/devices/" + laptop.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&found))
+	assert.Empty(t, found.SerialNumber)
+}
+
+func TestDevices_LifecycleDatesAndExpiringReport(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	soon := time.Now().UTC().AddDate(0, 0, 10).Format(time.DateOnly)
+	later := time.Now().UTC().AddDate(0, 6, 0).Format(time.DateOnly)
+
+	body := []byte(`{"name": "MacBook Pro", "brand": "Apple", "purchase_date": "2024-01-15", "warranty_end": "` + soon + `", "eol_date": "` + later + `"}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var laptop dto.DeviceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&laptop))
+	require.NotNil(t, laptop.WarrantyEnd)
+	assert.Equal(t, soon, *laptop.WarrantyEnd)
+	require.NotNil(t, laptop.PurchaseDate)
+	assert.Equal(t, "2024-01-15", *laptop.PurchaseDate)
+
+	// The warranty must end after the purchase date
+	body = []byte(`{"name": "Galaxy S24", "brand": "Samsung", "purchase_date": "2024-01-15", "warranty_end": "2024-01-01"}`)
+	resp, err = http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var errResp dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	require.NotEmpty(t, errResp.Errors)
+	assert.Equal(t, "warranty_end", errResp.Errors[0].Field)
+
+	// Only the warranty falls within 30 days
+	resp, err = http.Get(server.URL + "/api/v1/reports/expiring?within=30d")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report dto.ExpiryReportResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	require.Len(t, report.Brands, 1)
+	assert.Equal(t, "Apple", report.Brands[0].Brand)
+	require.Len(t, report.Brands[0].Items, 1)
+	assert.Equal(t, laptop.ID, report.Brands[0].Items[0].DeviceID)
+	assert.Equal(t, "warranty", report.Brands[0].Items[0].Kind)
+	assert.Equal(t, 10, report.Brands[0].Items[0].DaysLeft)
+
+	// Both dates fall within 30 weeks
+	resp, err = http.Get(server.URL + "/api/v1/reports/expiring?within=30w")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	require.Len(t, report.Brands, 1)
+	assert.Len(t, report.Brands[0].Items, 2)
+
+	resp, err = http.Get(server.URL + "/api/v1/reports/expiring?within=soon")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// A null warranty_end removes it from the report
+	updateTestDevice(t, server, laptop.ID, dto.PartialUpdateDeviceRequest{
+		WarrantyEnd: dto.NullableString{Set: true},
+	})
+	resp, err = http.Get(server.URL + "/api/v1/reports/expiring")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Empty(t, report.Brands)
+}
+
+// ========== Maintenance Tests ==========
+
+func TestDevices_MaintenanceLifecycle(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	model := createTestModel(t, server, dto.CreateModelRequest{Name: "MacBook Pro 14", Brand: "Apple", Category: "laptop"})
+	purchased := time.Now().UTC().AddDate(-2, 0, 0).Format(time.DateOnly)
+	body := []byte(`{"name": "Dev laptop", "model_id": "` + model.ID + `", "purchase_date": "` + purchased + `"}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var laptop dto.DeviceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&laptop))
+	updateTestDevice(t, server, laptop.ID, dto.PartialUpdateDeviceRequest{State: stringPtr("in-use")})
+
+	// Bought two years ago and never maintained, so the laptop is due
+	var due dto.ListDueMaintenanceResponse
+	resp, err = http.Get(server.URL + "/api/v1/maintenance/due")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&due))
+	require.Len(t, due.Devices, 1)
+	assert.Equal(t, laptop.ID, due.Devices[0].Device.ID)
+	assert.Nil(t, due.Devices[0].LastMaintainedAt)
+
+	body = []byte(`{"type": "preventive", "scheduled_at": "` + time.Now().UTC().Format(time.RFC3339) + `", "vendor": "iFixit", "cost_cents": 4500}`)
+	resp, err = http.Post(server.URL+"/api/v1/devices/"+laptop.ID+"/maintenance", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var scheduled dto.MaintenanceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&scheduled))
+	assert.Equal(t, "scheduled", scheduled.Status)
+
+	// A scheduled maintenance takes the laptop off the due list
+	resp, err = http.Get(server.URL + "/api/v1/maintenance/due")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&due))
+	assert.Empty(t, due.Devices)
+
+	maintenanceURL := server.URL + "/api/v1/devices/" + laptop.ID + "/maintenance/" + scheduled.ID
+	resp, err = http.Post(maintenanceURL+"/start", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var started dto.MaintenanceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&started))
+	assert.Equal(t, "in-progress", started.Status)
+	assert.Equal(t, "in-use", started.PreviousState)
+
+	device := getTestDevice(t, server, laptop.ID)
+	assert.Equal(t, "maintenance", device.State)
+
+	// A device in maintenance cannot be put in use
+	payload, err := json.Marshal(dto.UpdateDeviceRequest{Name: "Dev laptop", Brand: "Apple", State: "in-use"})
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPut, server.URL+"/api/v1/devices/"+laptop.ID, bytes.NewBuffer(payload))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	// Starting again is rejected
+	resp, err = http.Post(maintenanceURL+"/start", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	body = []byte(`{"cost_cents": 8900, "notes": "Replaced battery"}`)
+	resp, err = http.Post(maintenanceURL+"/complete", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var completed dto.MaintenanceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&completed))
+	assert.Equal(t, "completed", completed.Status)
+	assert.Equal(t, int64(8900), completed.CostCents)
+
+	device = getTestDevice(t, server, laptop.ID)
+	assert.Equal(t, "in-use", device.State)
+
+	resp, err = http.Get(server.URL + "/api/v1/devices/" + laptop.ID + "/maintenance")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var list dto.ListMaintenanceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	require.Equal(t, 1, list.Total)
+	assert.Equal(t, "Replaced battery", list.Maintenance[0].Notes)
+
+	// Just maintained, so no longer due
+	resp, err = http.Get(server.URL + "/api/v1/maintenance/due")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&due))
+	assert.Empty(t, due.Devices)
+}
+
+func TestDevices_Maintenance_UnknownRecords(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	laptop := createTestDevice(t, server, "Dev laptop", "Apple")
+	other := createTestDevice(t, server, "Spare laptop", "Apple")
+
+	body := []byte(`{"type": "repair", "scheduled_at": "2025-06-01T09:00:00Z"}`)
+	resp, err := http.Post(server.URL+"/api/v1/devices/"+uuid.New().String()+"/maintenance", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	body = []byte(`{"type": "cleaning", "scheduled_at": "2025-06-01T09:00:00Z"}`)
+	resp, err = http.Post(server.URL+"/api/v1/devices/"+laptop.ID+"/maintenance", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	body = []byte(`{"type": "repair", "scheduled_at": "2025-06-01T09:00:00Z"}`)
+	resp, err = http.Post(server.URL+"/api/v1/devices/"+laptop.ID+"/maintenance", "application/json", bytes.NewBuffer(body))
 	require.NoError(t, err)
 	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var scheduled dto.MaintenanceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&scheduled))
 
+	// A maintenance is only reachable through its own device
+	resp, err = http.Post(server.URL+"/api/v1/devices/"+other.ID+"/maintenance/"+scheduled.ID+"/start", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 
-	var result dto.ErrorResponse
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	resp, err = http.Post(server.URL+"/api/v1/devices/"+laptop.ID+"/maintenance/not-a-uuid/start", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Only started maintenance can be completed
+	resp, err = http.Post(server.URL+"/api/v1/devices/"+laptop.ID+"/maintenance/"+scheduled.ID+"/complete", "application/json", nil)
 	require.NoError(t, err)
-	assert.Equal(t, "not_found", result.Error)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
 }
 
-func TestGetDevice_InvalidUUID(t *testing.T) {
+// ========== Heartbeat Tests ==========
 
+func TestDevices_Heartbeat(t *testing.T) {
 	server := setupTestRouter(t)
 	defer server.Close()
 
-	resp, err := http.Get(server.URL + "/api/v1/devices/invalid-uuid")
-	require.NoError(t, err)
+	sensor := createTestDevice(t, server, "Lab sensor", "Bosch")
+	heartbeatURL := server.URL + "/api/v1/devices/" + sensor.ID + "/heartbeat"
+
+	sendHeartbeat := func(token, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, heartbeatURL, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	// No token has been issued yet
+	resp := sendHeartbeat("", "")
 	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
 
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	// Issuing a token requires the admin token
+	resp, err := http.Post(heartbeatURL+"/token", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-	var result dto.ErrorResponse
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	req, err := http.NewRequest(http.MethodPost, heartbeatURL+"/token", nil)
 	require.NoError(t, err)
-	assert.Equal(t, "invalid_id", result.Error)
-}
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-// ========== List Devices Tests ==========
+	resp = adminPost(t, heartbeatURL+"/token")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
 
-func TestListDevices_Empty(t *testing.T) {
+	var issued dto.HeartbeatTokenResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&issued))
+	require.NotEmpty(t, issued.Token)
 
-	server := setupTestRouter(t)
-	defer server.Close()
+	resp = sendHeartbeat("wrong", "")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
 
-	resp, err := http.Get(server.URL + "/api/v1/devices")
-	require.NoError(t, err)
+	resp = sendHeartbeat(issued.Token, `{"reported": {"firmware": "1.4.2", "battery": 80}}`)
 	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	device := getTestDevice(t, server, sensor.ID)
+	require.NotNil(t, device.LastSeenAt)
+	assert.Equal(t, "1.4.2", device.Reported["firmware"])
+	assert.Equal(t, "active", device.State)
 
-	var result dto.ListDevicesResponse
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	// A heartbeat without a body keeps the reported fields
+	resp = sendHeartbeat(issued.Token, "")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	device = getTestDevice(t, server, sensor.ID)
+	assert.Equal(t, "1.4.2", device.Reported["firmware"])
+
+	resp = sendHeartbeat(issued.Token, `{"reported": {"Firmware": "1.4.2"}}`)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// A new token revokes the old one
+	resp = adminPost(t, heartbeatURL+"/token")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp = sendHeartbeat(issued.Token, "")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// A token of one device does not authenticate another
+	other := createTestDevice(t, server, "Spare sensor", "Bosch")
+	req, err = http.NewRequest(http.MethodPost, server.URL+"/api/v1/devices/"+other.ID+"/heartbeat", nil)
 	require.NoError(t, err)
-	assert.Equal(t, 0, result.Total)
-	assert.Len(t, result.Devices, 0)
+	req.Header.Set("Authorization", "Bearer "+issued.Token)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = adminPost(t, server.URL+"/api/v1/devices/"+uuid.New().String()+"/heartbeat/token")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
 
-func TestListDevices_WithData(t *testing.T) {
+// ========== Device Cache Tests ==========
 
+func TestDeviceStats(t *testing.T) {
 	server := setupTestRouter(t)
 	defer server.Close()
 
-	// Create multiple devices
 	createTestDevice(t, server, "iPhone 15", "Apple")
+	createTestDevice(t, server, "iPhone 14", "apple")
+	inUse := createTestDevice(t, server, "MacBook Pro", "Apple")
+	updateTestDevice(t, server, inUse.ID, dto.PartialUpdateDeviceRequest{State: stringPtr("in-use")})
 	createTestDevice(t, server, "Galaxy S24", "Samsung")
-	createTestDevice(t, server, "Pixel 9", "Google")
 
-	resp, err := http.Get(server.URL + "/api/v1/devices")
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	getStats := func(query string) dto.DeviceStatsResponse {
+		t.Helper()
+		resp, err := http.Get(server.URL + "/api/v1/stats/devices?" + query)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+		var stats dto.DeviceStatsResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+		return stats
+	}
+	key := func(group dto.DeviceStatsGroupResponse, dimension string) string {
+		require.NotNil(t, group.Keys[dimension])
+		return *group.Keys[dimension]
+	}
 
-	var result dto.ListDevicesResponse
-	err = json.NewDecoder(resp.Body).Decode(&result)
-	require.NoError(t, err)
-	assert.Equal(t, 3, result.Total)
-	assert.Len(t, result.Devices, 3)
+	stats := getStats("group_by=brand,state")
+	assert.Equal(t, int64(4), stats.Total)
+	require.Len(t, stats.Groups, 3)
+	assert.Equal(t, "Apple", key(stats.Groups[0], "brand"))
+	assert.Equal(t, "active", key(stats.Groups[0], "state"))
+	assert.Equal(t, int64(2), stats.Groups[0].Count)
+	assert.Equal(t, "in-use", key(stats.Groups[1], "state"))
+	assert.Equal(t, "Samsung", key(stats.Groups[2], "brand"))
+
+	// Devices without a category are grouped under null
+	stats = getStats("group_by=category&brand=samsung")
+	require.Len(t, stats.Groups, 1)
+	assert.Nil(t, stats.Groups[0].Keys["category"])
+	assert.Equal(t, int64(1), stats.Total)
+
+	stats = getStats("interval=month")
+	require.Len(t, stats.Groups, 1)
+	require.NotNil(t, stats.Groups[0].Period)
+	now := time.Now().UTC()
+	assert.Equal(t, time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC), stats.Groups[0].Period.UTC())
+	assert.Equal(t, int64(4), stats.Groups[0].Count)
+	assert.Less(t, stats.Groups[0].AverageAgeDays, 1.0)
+
+	stats = getStats("state=inactive")
+	assert.Empty(t, stats.Groups)
+	assert.Zero(t, stats.Total)
+
+	for _, query := range []string{"group_by=color", "group_by=brand,brand", "interval=hour", "state=broken"} {
+		resp, err := http.Get(server.URL + "/api/v1/stats/devices?" + query)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
 }
 
-func TestListDevices_WithPagination(t *testing.T) {
-
+func TestDevices_AsOf(t *testing.T) {
 	server := setupTestRouter(t)
 	defer server.Close()
 
-	// Create 5 devices
-	for i := 1; i <= 5; i++ {
-		createTestDevice(t, server, "Device "+string(rune('A'+i-1)), "Brand")
-	}
+	created := createTestDevice(t, server, "iPhone 15", "Apple")
+	// Versions are stamped by the database clock, so step past it
+	time.Sleep(50 * time.Millisecond)
+	before := time.Now().UTC()
+	time.Sleep(50 * time.Millisecond)
+	updateTestDevice(t, server, created.ID, dto.PartialUpdateDeviceRequest{State: stringPtr("in-use")})
 
-	// Get first page
-	resp, err := http.Get(server.URL + "/api/v1/devices?limit=2&offset=0")
+	resp, err := http.Get(server.URL + "/api/v1/devices/" + created.ID + "?as_of=" + url.QueryEscape(before.Format(time.RFC3339Nano)))
 	require.NoError(t, err)
 	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
-
-	var result dto.ListDevicesResponse
-	err = json.NewDecoder(resp.Body).Decode(&result)
-	require.NoError(t, err)
-	assert.Equal(t, 2, result.Total)
-	assert.Equal(t, 2, result.Limit)
-	assert.Equal(t, 0, result.Offset)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var device dto.DeviceResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&device))
+	assert.Equal(t, "active", device.State)
+
+	listResp, err := http.Get(server.URL + "/api/v1/devices?state=active&as_of=" + url.QueryEscape(before.Format(time.RFC3339Nano)))
+	require.NoError(t, err)
+	defer listResp.Body.Close()
+	var list dto.ListDevicesResponse
+	require.NoError(t, json.NewDecoder(listResp.Body).Decode(&list))
+	require.Len(t, list.Devices, 1)
+	assert.Equal(t, created.ID, list.Devices[0].ID)
+
+	for _, asOf := range []string{"yesterday", time.Now().Add(time.Hour).Format(time.RFC3339)} {
+		resp, err := http.Get(server.URL + "/api/v1/devices/" + created.ID + "?as_of=" + url.QueryEscape(asOf))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, asOf)
+	}
 }
 
-func TestListDevices_FilterByBrand(t *testing.T) {
-
-	server := setupTestRouter(t)
+func TestDeviceCache(t *testing.T) {
+	require.NoError(t, pgContainer.Cleanup(context.Background()))
+	pool := pgContainer.GetPool()
+	repo := repository.NewPostgresDeviceRepository(pool)
+	cache := repository.NewCachedDeviceRepository(repo, 10, time.Hour)
+	server := httptest.NewServer(httphandler.SetupRouter(service.NewDeviceService(cache),
+		httphandler.WithDeviceCache(cache),
+		httphandler.WithAdminToken(testAdminToken),
+	))
 	defer server.Close()
 
-	// Create devices from different brands
-	createTestDevice(t, server, "iPhone 15", "Apple")
-	createTestDevice(t, server, "MacBook Pro", "Apple")
-	createTestDevice(t, server, "Galaxy S24", "Samsung")
+	created := createTestDevice(t, server, "Kiosk screen", "Samsung")
+	getTestDevice(t, server, created.ID)
+	getTestDevice(t, server, created.ID)
 
-	resp, err := http.Get(server.URL + "/api/v1/devices?brand=Apple")
+	// A write that bypasses the cache stays invisible until the device is invalidated
+	_, err := pool.Exec(context.Background(), `UPDATE devices SET name = 'Lobby screen' WHERE id = $1`, created.ID)
 	require.NoError(t, err)
+	assert.Equal(t, "Kiosk screen", getTestDevice(t, server, created.ID).Name)
+
+	resp := doAdminRequest(t, http.MethodDelete, server.URL+"/api/v1/admin/cache/devices/"+created.ID, "")
 	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "Lobby screen", getTestDevice(t, server, created.ID).Name)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	resp = doAdminRequest(t, http.MethodGet, server.URL+"/api/v1/admin/cache/devices", "")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var stats dto.CacheStatsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&stats))
+	assert.Equal(t, uint64(2), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, 1, stats.Size)
+
+	resp = doAdminRequest(t, http.MethodDelete, server.URL+"/api/v1/admin/cache/devices", "")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
 
-	var result dto.ListDevicesResponse
-	err = json.NewDecoder(resp.Body).Decode(&result)
-	require.NoError(t, err)
-	assert.Equal(t, 2, result.Total)
+	resp = doAdminRequest(t, http.MethodDelete, server.URL+"/api/v1/admin/cache/devices", "wrong")
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
 
-	for _, device := range result.Devices {
-		assert.Equal(t, "Apple", device.Brand)
+// doAdminRequest sends a request authenticated with token, or the admin token when token is empty
+func doAdminRequest(t *testing.T, method, url, token string) *http.Response {
+	t.Helper()
+	if token == "" {
+		token = testAdminToken
 	}
+	req, err := http.NewRequest(method, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
 }
 
-func TestListDevices_FilterByState(t *testing.T) {
+// ========== Telemetry Tests ==========
 
+func TestTelemetry(t *testing.T) {
 	server := setupTestRouter(t)
 	defer server.Close()
 
-	// Create devices and update their states
-	device1 := createTestDevice(t, server, "Device 1", "Brand1")
-	device2 := createTestDevice(t, server, "Device 2", "Brand2")
+	sensor := createTestDevice(t, server, "Lab sensor", "Bosch")
+	telemetryURL := server.URL + "/api/v1/devices/" + sensor.ID + "/telemetry"
 
-	// Update device2 to inactive
-	updatePayload := dto.PartialUpdateDeviceRequest{
-		State: stringPtr("inactive"),
+	resp := adminPost(t, server.URL+"/api/v1/devices/"+sensor.ID+"/heartbeat/token")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var issued dto.HeartbeatTokenResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&issued))
+
+	sendTelemetry := func(token, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, telemetryURL, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
 	}
-	updateTestDevice(t, server, device2.ID, updatePayload)
 
-	// Filter by active state
-	resp, err := http.Get(server.URL + "/api/v1/devices?state=active")
+	hour := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	sample := func(metric string, value float64, ts time.Time) string {
+		return fmt.Sprintf(`{"metric":%q,"value":%g,"ts":%q}`, metric, value, ts.Format(time.RFC3339))
+	}
+
+	resp = sendTelemetry("", sample("battery", 90, hour))
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = sendTelemetry(issued.Token, strings.Join([]string{
+		sample("battery", 90, hour.Add(10*time.Minute)),
+		sample("battery", 80, hour.Add(20*time.Minute)),
+		sample("battery", 70, hour.Add(70*time.Minute)),
+	}, "\n")+"\n")
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var ingested dto.TelemetryIngestResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ingested))
+	assert.Equal(t, 3, ingested.Accepted)
+
+	// An invalid sample rejects the whole batch and is reported by position
+	resp = sendTelemetry(issued.Token, sample("battery", 60, hour)+"\n"+`{"metric":"battery","ts":"`+hour.Format(time.RFC3339)+`"}`)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	var errResp dto.ProblemDetails
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	require.NotEmpty(t, errResp.Errors)
+	assert.Equal(t, "points[1].value", errResp.Errors[0].Field)
+
+	resp = sendTelemetry(issued.Token, strings.Repeat(sample("battery", 60, hour)+"\n", testTelemetryMaxBatch+1))
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp = sendTelemetry(issued.Token, sample("battery", 60, time.Now().Add(time.Hour)))
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	query := url.Values{
+		"metric": {"battery"},
+		"from":   {hour.Format(time.RFC3339)},
+		"to":     {hour.Add(3 * time.Hour).Format(time.RFC3339)},
+		"bucket": {"1h"},
+	}
+	resp, err := http.Get(telemetryURL + "?" + query.Encode())
 	require.NoError(t, err)
 	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var result dto.TelemetryResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	assert.Equal(t, "battery", result.Metric)
+	require.Len(t, result.Buckets, 2)
+	assert.Equal(t, dto.TelemetryBucketResponse{Start: hour, Min: 80, Max: 90, Avg: 85, Count: 2}, result.Buckets[0])
+	assert.Equal(t, int64(1), result.Buckets[1].Count)
 
-	var result dto.ListDevicesResponse
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	query.Set("bucket", "30s")
+	resp, err = http.Get(telemetryURL + "?" + query.Encode())
 	require.NoError(t, err)
-	assert.Equal(t, 1, result.Total)
-	assert.Equal(t, device1.ID, result.Devices[0].ID)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/api/v1/devices/" + uuid.New().String() + "/telemetry?metric=battery")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 }
 
 // ========== Update Device Tests ==========
@@ -494,10 +1715,10 @@ func TestUpdateDevice_CannotUpdateInUseDevice(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnprocessableEntity, resp2.StatusCode)
 
-	var result dto.ErrorResponse
+	var result dto.ProblemDetails
 	err = json.NewDecoder(resp2.Body).Decode(&result)
 	require.NoError(t, err)
-	assert.Equal(t, "business_rule_violation", result.Error)
+	assert.Equal(t, "business_rule_violation", result.Code)
 }
 
 // ========== Partial Update Device Tests ==========
@@ -576,6 +1797,29 @@ func TestPartialUpdateDevice_UpdateState(t *testing.T) {
 
 // ========== Delete Device Tests ==========
 
+func TestPartialUpdateDevice_MergeAttributes(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	device := createTestDeviceWithAttributes(t, server, "iPhone 15", "Apple", map[string]any{"os": "ios", "po": "PO-1"})
+
+	body := []byte(`{"attributes": {"ram_gb": 8, "po": null}}`)
+	req, err := http.NewRequest(http.MethodPatch, server.URL+"/api/v1/devices/"+device.ID, bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.DeviceResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"os": "ios", "ram_gb": float64(8)}, result.Attributes)
+}
+
 func TestDeleteDevice_Success(t *testing.T) {
 
 	server := setupTestRouter(t)
@@ -653,10 +1897,67 @@ func TestDeleteDevice_CannotDeleteInUseDevice(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
 
-	var result dto.ErrorResponse
+	var result dto.ProblemDetails
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	require.NoError(t, err)
-	assert.Equal(t, "business_rule_violation", result.Error)
+	assert.Equal(t, "business_rule_violation", result.Code)
+}
+
+// TestDeleteDevice_ConcurrentCheckout races deletes against checkouts: a device
+// that was checked out must never be deleted, whichever request runs first
+func TestDeleteDevice_ConcurrentCheckout(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	for i := range 20 {
+		created := createTestDevice(t, server, fmt.Sprintf("Kiosk %d", i), "Apple")
+		target := server.URL + "/api/v1/devices/" + created.ID
+
+		var wg sync.WaitGroup
+		var deleted, checkedOut int
+		var deleteErr, checkoutErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			deleted, deleteErr = sendRequest(http.MethodDelete, target, "")
+		}()
+		go func() {
+			defer wg.Done()
+			checkedOut, checkoutErr = sendRequest(http.MethodPatch, target, `{"state": "in-use"}`)
+		}()
+		wg.Wait()
+		require.NoError(t, deleteErr)
+		require.NoError(t, checkoutErr)
+
+		getResp, err := http.Get(target)
+		require.NoError(t, err)
+		getResp.Body.Close()
+
+		if deleted == http.StatusNoContent {
+			assert.Equal(t, http.StatusNotFound, checkedOut, "a deleted device cannot be checked out")
+			assert.Equal(t, http.StatusNotFound, getResp.StatusCode)
+		} else {
+			assert.Equal(t, http.StatusUnprocessableEntity, deleted, "a checked out device cannot be deleted")
+			assert.Equal(t, http.StatusOK, checkedOut)
+			assert.Equal(t, http.StatusOK, getResp.StatusCode)
+		}
+	}
+}
+
+// sendRequest sends a JSON request and returns the response status; it is safe to call from any goroutine
+func sendRequest(method, target, body string) (int, error) {
+	req, err := http.NewRequest(method, target, bytes.NewBufferString(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
 }
 
 // ========== End-to-End Workflow Tests ==========
@@ -767,6 +2068,110 @@ func createTestDevice(t *testing.T, server *httptest.Server, name, brand string)
 	return result
 }
 
+func createTestDeviceWithAttributes(t *testing.T, server *httptest.Server, name, brand string, attributes map[string]any) dto.DeviceResponse {
+	payload := dto.CreateDeviceRequest{
+		Name:       name,
+		Brand:      brand,
+		Attributes: attributes,
+	}
+
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result dto.DeviceResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+
+	return result
+}
+
+func createTestDeviceWithLabels(t *testing.T, server *httptest.Server, name, brand string, labels map[string]string) dto.DeviceResponse {
+	payload := dto.CreateDeviceRequest{
+		Name:   name,
+		Brand:  brand,
+		Labels: labels,
+	}
+
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result dto.DeviceResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+
+	return result
+}
+
+// doLabelRequest sends a request to a labels endpoint and closes the response body
+func doLabelRequest(t *testing.T, method, target, body string) *http.Response {
+	req, err := http.NewRequest(method, target, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	return resp
+}
+
+func createTestLocation(t *testing.T, server *httptest.Server, name, locationType string, parentID *string) dto.LocationResponse {
+	payload := dto.CreateLocationRequest{
+		Name:     name,
+		Type:     locationType,
+		ParentID: parentID,
+	}
+
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/locations", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result dto.LocationResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+
+	return result
+}
+
+func createTestModel(t *testing.T, server *httptest.Server, payload dto.CreateModelRequest) dto.ModelResponse {
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/api/v1/models", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var result dto.ModelResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+
+	return result
+}
+
+func getTestDevice(t *testing.T, server *httptest.Server, deviceID string) dto.DeviceResponse {
+	resp, err := http.Get(server.URL + "/api/v1/devices/" + deviceID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result dto.DeviceResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+
+	return result
+}
+
 func updateTestDevice(t *testing.T, server *httptest.Server, deviceID string, payload dto.PartialUpdateDeviceRequest) {
 	body, err := json.Marshal(payload)
 	require.NoError(t, err)
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+// AddBrandAliasRequest represents the request to add an alternative name for a brand
+type AddBrandAliasRequest struct {
+	Alias string `json:"alias" binding:"required,max=50"`
+}
+
+// BrandResponse represents a brand in the API response
+type BrandResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Aliases     []string  `json:"aliases"`
+	DeviceCount int       `json:"device_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ListBrandsResponse represents a list of brands response
+type ListBrandsResponse struct {
+	Brands []BrandResponse `json:"brands"`
+	Total  int             `json:"total"`
+}
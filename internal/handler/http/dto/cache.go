@@ -0,0 +1,11 @@
+package dto
+
+// CacheStatsResponse reports how the device cache of the replica serving the request has performed since startup
+type CacheStatsResponse struct {
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	HitRatio  float64 `json:"hit_ratio" example:"0.97"`
+	Evictions uint64  `json:"evictions"`
+	Size      int     `json:"size"`
+	Capacity  int     `json:"capacity"`
+}
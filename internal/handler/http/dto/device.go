@@ -1,36 +1,105 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 )
 
-// CreateDeviceRequest represents the request to create a device
+// CreateDeviceRequest represents the request to create a device.
+// With model_id the device starts with the model's default attributes,
+// and brand defaults to the model's brand.
+// serial_number is optional and must be unique per brand.
+// Dates use the YYYY-MM-DD format.
 type CreateDeviceRequest struct {
-	Name  string `json:"name" binding:"required,min=3,max=100"`
-	Brand string `json:"brand" binding:"required,min=2,max=50"`
+	Name         string            `json:"name" binding:"required,min=3,max=100"`
+	Brand        string            `json:"brand,omitempty" binding:"required_without=ModelID,omitempty,min=2,max=50"`
+	SerialNumber string            `json:"serial_number,omitempty" binding:"omitempty,max=64"`
+	Attributes   map[string]any    `json:"attributes,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	LocationID   *string           `json:"location_id,omitempty" binding:"omitempty,uuid"`
+	ModelID      *string           `json:"model_id,omitempty" binding:"omitempty,uuid"`
+	PurchaseDate *string           `json:"purchase_date,omitempty" example:"2024-01-15"`
+	WarrantyEnd  *string           `json:"warranty_end,omitempty" example:"2027-01-15"`
+	EOLDate      *string           `json:"eol_date,omitempty" example:"2029-01-15"`
 }
 
-// UpdateDeviceRequest represents the request to fully update a device
+// UpdateDeviceRequest represents the request to fully update a device.
+// Serial number, attributes, labels, location and model replace the existing ones when present
+// and are kept when omitted; an empty serial_number or date removes it.
 type UpdateDeviceRequest struct {
-	Name  string `json:"name" binding:"required,min=3,max=100"`
-	Brand string `json:"brand" binding:"required,min=2,max=50"`
-	State string `json:"state" binding:"required,oneof=active in-use inactive"`
+	Name         string            `json:"name" binding:"required,min=3,max=100"`
+	Brand        string            `json:"brand" binding:"required,min=2,max=50"`
+	State        string            `json:"state" binding:"required,oneof=active in-use inactive maintenance"`
+	SerialNumber *string           `json:"serial_number,omitempty" binding:"omitempty,max=64"`
+	Attributes   map[string]any    `json:"attributes,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	LocationID   *string           `json:"location_id,omitempty" binding:"omitempty,uuid"`
+	ModelID      *string           `json:"model_id,omitempty" binding:"omitempty,uuid"`
+	PurchaseDate *string           `json:"purchase_date,omitempty" example:"2024-01-15"`
+	WarrantyEnd  *string           `json:"warranty_end,omitempty" example:"2027-01-15"`
+	EOLDate      *string           `json:"eol_date,omitempty" example:"2029-01-15"`
 }
 
-// PartialUpdateDeviceRequest represents the request to partially update a device
+// PartialUpdateDeviceRequest represents the request to partially update a device.
+// Attributes and labels are merged into the existing ones; a null value removes a key.
+// A null location_id removes the device from its location, and a null model_id from its model.
+// A null serial_number or date removes it.
 type PartialUpdateDeviceRequest struct {
-	Name  *string `json:"name,omitempty" binding:"omitempty,min=3,max=100"`
-	Brand *string `json:"brand,omitempty" binding:"omitempty,min=2,max=50"`
-	State *string `json:"state,omitempty" binding:"omitempty,oneof=active in-use inactive"`
+	Name         *string            `json:"name,omitempty" binding:"omitempty,min=3,max=100"`
+	Brand        *string            `json:"brand,omitempty" binding:"omitempty,min=2,max=50"`
+	State        *string            `json:"state,omitempty" binding:"omitempty,oneof=active in-use inactive maintenance"`
+	SerialNumber NullableString     `json:"serial_number,omitzero" swaggertype:"string"`
+	Attributes   map[string]any     `json:"attributes,omitempty"`
+	Labels       map[string]*string `json:"labels,omitempty"`
+	LocationID   NullableString     `json:"location_id,omitzero" swaggertype:"string"`
+	ModelID      NullableString     `json:"model_id,omitzero" swaggertype:"string"`
+	PurchaseDate NullableString     `json:"purchase_date,omitzero" swaggertype:"string"`
+	WarrantyEnd  NullableString     `json:"warranty_end,omitzero" swaggertype:"string"`
+	EOLDate      NullableString     `json:"eol_date,omitzero" swaggertype:"string"`
 }
 
-// DeviceResponse represents a device in the API response
+// NullableString distinguishes an omitted field from an explicit null
+type NullableString struct {
+	Set   bool
+	Value *string
+}
+
+// UnmarshalJSON is only called when the field is present, including for null
+func (n *NullableString) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Value)
+}
+
+// MarshalJSON renders the value or null; unset fields are dropped by omitzero
+func (n NullableString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.Value)
+}
+
+// DeviceResponse represents a device in the API response.
+// last_seen_at and reported are set by heartbeats and omitted for devices that never sent one.
 type DeviceResponse struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Brand     string    `json:"brand"`
-	State     string    `json:"state"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Brand        string            `json:"brand"`
+	BrandID      string            `json:"brand_id"`
+	SerialNumber string            `json:"serial_number,omitempty"`
+	State        string            `json:"state"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Attributes   map[string]any    `json:"attributes"`
+	Labels       map[string]string `json:"labels"`
+	LocationID   *string           `json:"location_id"`
+	ModelID      *string           `json:"model_id"`
+	Category     string            `json:"category,omitempty"`
+	PurchaseDate *string           `json:"purchase_date,omitempty"`
+	WarrantyEnd  *string           `json:"warranty_end,omitempty"`
+	EOLDate      *string           `json:"eol_date,omitempty"`
+	LastSeenAt   *time.Time        `json:"last_seen_at,omitempty"`
+	Reported     map[string]any    `json:"reported,omitempty"`
+}
+
+// LabelsResponse represents the labels of a device
+type LabelsResponse struct {
+	Labels map[string]string `json:"labels"`
 }
 
 // ListDevicesResponse represents a list of devices response
@@ -41,7 +110,7 @@ type ListDevicesResponse struct {
 	Offset  int              `json:"offset"`
 }
 
-// ErrorResponse represents an error response
+// ErrorResponse represents the legacy error response, still served to clients that prefer application/json
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message,omitempty"`
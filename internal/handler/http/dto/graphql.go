@@ -0,0 +1,18 @@
+package dto
+
+import "github.com/graphql-go/graphql/gqlerrors"
+
+// GraphQLRequest represents a GraphQL query or mutation
+type GraphQLRequest struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLResponse represents the result of a GraphQL request.
+// Data is omitted when the request was rejected before it ran.
+// Every error carries its class in extensions.code, using the codes of ErrorResponse.
+type GraphQLResponse struct {
+	Data   any                        `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// HeartbeatRequest represents a heartbeat sent by a device.
+// reported replaces the fields the device reported before; omit it to keep them.
+type HeartbeatRequest struct {
+	Reported map[string]any `json:"reported,omitempty"`
+}
+
+// HeartbeatResponse acknowledges a heartbeat
+type HeartbeatResponse struct {
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// HeartbeatTokenResponse carries a newly issued heartbeat token.
+// The token is only shown once; issuing a new one revokes it.
+type HeartbeatTokenResponse struct {
+	DeviceID string `json:"device_id"`
+	Token    string `json:"token"`
+}
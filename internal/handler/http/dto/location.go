@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// CreateLocationRequest represents the request to create a location.
+// Sites have no parent, buildings belong to a site and rooms to a building.
+type CreateLocationRequest struct {
+	Name     string  `json:"name" binding:"required,max=100"`
+	Type     string  `json:"type" binding:"required,oneof=site building room"`
+	ParentID *string `json:"parent_id,omitempty" binding:"omitempty,uuid"`
+}
+
+// UpdateLocationRequest represents the request to rename or move a location.
+// The location type cannot change.
+type UpdateLocationRequest struct {
+	Name     string  `json:"name" binding:"required,max=100"`
+	ParentID *string `json:"parent_id,omitempty" binding:"omitempty,uuid"`
+}
+
+// LocationResponse represents a location in the API response
+type LocationResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	ParentID  *string   `json:"parent_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListLocationsResponse represents a list of locations response
+type ListLocationsResponse struct {
+	Locations []LocationResponse `json:"locations"`
+	Total     int                `json:"total"`
+}
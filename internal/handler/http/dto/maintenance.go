@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+// ScheduleMaintenanceRequest represents the request to schedule a maintenance of a device.
+// cost_cents is in minor currency units and may be updated when the maintenance is completed.
+type ScheduleMaintenanceRequest struct {
+	Type        string    `json:"type" binding:"required,oneof=preventive repair inspection upgrade"`
+	ScheduledAt time.Time `json:"scheduled_at" binding:"required"`
+	Vendor      string    `json:"vendor,omitempty" binding:"max=100"`
+	CostCents   int64     `json:"cost_cents,omitempty" binding:"min=0"`
+	Notes       string    `json:"notes,omitempty"`
+}
+
+// CompleteMaintenanceRequest represents the request to complete a maintenance.
+// cost_cents and notes replace the recorded values when present.
+type CompleteMaintenanceRequest struct {
+	CostCents *int64  `json:"cost_cents,omitempty" binding:"omitempty,min=0"`
+	Notes     *string `json:"notes,omitempty"`
+}
+
+// MaintenanceResponse represents a maintenance record in the API response.
+// status is scheduled, in-progress or completed.
+type MaintenanceResponse struct {
+	ID            string     `json:"id"`
+	DeviceID      string     `json:"device_id"`
+	Type          string     `json:"type"`
+	Status        string     `json:"status"`
+	ScheduledAt   time.Time  `json:"scheduled_at"`
+	StartedAt     *time.Time `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+	Vendor        string     `json:"vendor,omitempty"`
+	CostCents     int64      `json:"cost_cents"`
+	Notes         string     `json:"notes,omitempty"`
+	PreviousState string     `json:"previous_state,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// ListMaintenanceResponse represents the maintenance records of a device
+type ListMaintenanceResponse struct {
+	Maintenance []MaintenanceResponse `json:"maintenance"`
+	Total       int                   `json:"total"`
+}
+
+// DueMaintenanceResponse represents a device due for periodic maintenance.
+// last_maintained_at is null when the device never completed a maintenance.
+type DueMaintenanceResponse struct {
+	Device           DeviceResponse `json:"device"`
+	LastMaintainedAt *time.Time     `json:"last_maintained_at"`
+	DueAt            time.Time      `json:"due_at"`
+}
+
+// ListDueMaintenanceResponse represents a page of devices due for maintenance
+type ListDueMaintenanceResponse struct {
+	Devices []DueMaintenanceResponse `json:"devices"`
+	Total   int                      `json:"total"`
+	Limit   int                      `json:"limit"`
+	Offset  int                      `json:"offset"`
+}
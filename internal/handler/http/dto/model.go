@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// CreateModelRequest represents the request to add a model to the catalog.
+// lifecycle_months is the expected service life; 0 or omitted means no lifecycle policy.
+type CreateModelRequest struct {
+	Name              string         `json:"name" binding:"required,max=100"`
+	Brand             string         `json:"brand" binding:"required,min=2,max=50"`
+	Category          string         `json:"category" binding:"required,oneof=laptop phone tablet sensor"`
+	DefaultAttributes map[string]any `json:"default_attributes,omitempty"`
+	LifecycleMonths   int            `json:"lifecycle_months,omitempty" binding:"min=0"`
+}
+
+// UpdateModelRequest represents the request to update a catalog model.
+// Brand and category cannot change.
+type UpdateModelRequest struct {
+	Name              string         `json:"name" binding:"required,max=100"`
+	DefaultAttributes map[string]any `json:"default_attributes,omitempty"`
+	LifecycleMonths   int            `json:"lifecycle_months,omitempty" binding:"min=0"`
+}
+
+// ModelResponse represents a catalog model in the API response
+type ModelResponse struct {
+	ID                string         `json:"id"`
+	Name              string         `json:"name"`
+	Brand             string         `json:"brand"`
+	BrandID           string         `json:"brand_id"`
+	Category          string         `json:"category"`
+	DefaultAttributes map[string]any `json:"default_attributes"`
+	LifecycleMonths   int            `json:"lifecycle_months"`
+	CreatedAt         time.Time      `json:"created_at"`
+}
+
+// ListModelsResponse represents a list of models response
+type ListModelsResponse struct {
+	Models []ModelResponse `json:"models"`
+	Total  int             `json:"total"`
+}
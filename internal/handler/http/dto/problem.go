@@ -0,0 +1,19 @@
+package dto
+
+// ProblemDetails represents an RFC 9457 problem details error response.
+// Code carries the same error code as ErrorResponse.Error.
+type ProblemDetails struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Code     string         `json:"code"`
+	Errors   []InvalidField `json:"errors,omitempty"`
+}
+
+// InvalidField describes a single invalid request field by its JSON name
+type InvalidField struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
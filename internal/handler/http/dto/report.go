@@ -0,0 +1,25 @@
+package dto
+
+// ExpiryReportResponse lists warranty and EOL dates within a window, grouped by brand
+type ExpiryReportResponse struct {
+	From   string                `json:"from" example:"2025-06-01"`
+	To     string                `json:"to" example:"2025-07-01"`
+	Brands []BrandExpiryResponse `json:"brands"`
+}
+
+// BrandExpiryResponse holds the expiring devices of one brand
+type BrandExpiryResponse struct {
+	BrandID string           `json:"brand_id"`
+	Brand   string           `json:"brand"`
+	Items   []ExpiryResponse `json:"items"`
+}
+
+// ExpiryResponse is a device whose warranty (kind "warranty") or planned end of life (kind "eol") falls on date
+type ExpiryResponse struct {
+	DeviceID     string `json:"device_id"`
+	Name         string `json:"name"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	Kind         string `json:"kind" example:"warranty"`
+	Date         string `json:"date" example:"2025-06-15"`
+	DaysLeft     int    `json:"days_left"`
+}
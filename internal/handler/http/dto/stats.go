@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// DeviceStatsResponse holds device counts grouped by the requested dimensions and period
+type DeviceStatsResponse struct {
+	GroupBy  []string                   `json:"group_by"`
+	Interval string                     `json:"interval,omitempty" example:"month"`
+	Total    int64                      `json:"total"`
+	Groups   []DeviceStatsGroupResponse `json:"groups"`
+}
+
+// DeviceStatsGroupResponse counts the devices of one group. Keys holds the value of
+// every grouped dimension, null for devices without a category or location.
+type DeviceStatsGroupResponse struct {
+	Keys           map[string]*string `json:"keys"`
+	Period         *time.Time         `json:"period,omitempty"`
+	Count          int64              `json:"count"`
+	AverageAgeDays float64            `json:"average_age_days"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// TelemetrySample is one line of an NDJSON telemetry batch
+type TelemetrySample struct {
+	Metric string    `json:"metric" example:"battery"`
+	Value  *float64  `json:"value" example:"87.5"`
+	TS     time.Time `json:"ts" example:"2025-06-01T12:00:00Z"`
+}
+
+// TelemetryIngestResponse acknowledges a stored telemetry batch
+type TelemetryIngestResponse struct {
+	Accepted int `json:"accepted"`
+}
+
+// TelemetryResponse holds one metric of a device downsampled into buckets
+type TelemetryResponse struct {
+	DeviceID string                    `json:"device_id"`
+	Metric   string                    `json:"metric" example:"battery"`
+	From     time.Time                 `json:"from"`
+	To       time.Time                 `json:"to"`
+	Bucket   string                    `json:"bucket" example:"1h0m0s"`
+	Buckets  []TelemetryBucketResponse `json:"buckets"`
+}
+
+// TelemetryBucketResponse summarizes the samples of one bucket; buckets without samples are omitted
+type TelemetryBucketResponse struct {
+	Start time.Time `json:"start"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Avg   float64   `json:"avg"`
+	Count int64     `json:"count"`
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"net/http"
+
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+	"devices-api/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// GraphQLHandler serves device queries and mutations over GraphQL
+type GraphQLHandler struct {
+	schema    graphql.Schema
+	limits    queryLimits
+	locations *service.LocationService
+	models    *service.ModelService
+}
+
+// NewGraphQLHandler creates a new GraphQL handler.
+// Location and model services are optional; without them devices do not expose
+// their location and model objects.
+func NewGraphQLHandler(devices *service.DeviceService, locations *service.LocationService, models *service.ModelService, maxDepth, maxComplexity int) *GraphQLHandler {
+	return &GraphQLHandler{
+		schema: mustGraphQLSchema(&graphQLResolver{
+			devices:   devices,
+			locations: locations,
+			models:    models,
+		}),
+		limits: queryLimits{
+			maxDepth:      maxDepth,
+			maxComplexity: maxComplexity,
+			pageSize: func(first int) int {
+				limit, _ := devices.NormalizePagination(first, 0)
+				return limit
+			},
+		},
+		locations: locations,
+		models:    models,
+	}
+}
+
+// Query runs a GraphQL query or mutation.
+// Requests that cannot run, because they are malformed, invalid against the schema or exceed
+// the depth or complexity limits, are rejected with 400 and no data. Otherwise the response is
+// 200 and failed fields are null with an error classified like the REST endpoints' errors.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req dto.GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.reject(c, "validation_error", err)
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		h.reject(c, "invalid_query", err)
+		return
+	}
+
+	if validation := graphql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		c.JSON(http.StatusBadRequest, dto.GraphQLResponse{
+			Errors: withCode(validation.Errors, "invalid_query"),
+		})
+		return
+	}
+
+	if err := h.limits.check(&h.schema, doc, req.Variables); err != nil {
+		h.reject(c, err.code, err)
+		return
+	}
+
+	ctx := withGraphQLLoaders(c.Request.Context(), h.locations, h.models)
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+
+	// Without data the operation never started, e.g. because of invalid variables
+	if result.Data == nil && ctx.Err() == nil {
+		c.JSON(http.StatusBadRequest, dto.GraphQLResponse{
+			Errors: withCode(result.Errors, "invalid_query"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.GraphQLResponse{
+		Data:   result.Data,
+		Errors: h.classifyErrors(c, result.Errors),
+	})
+}
+
+// classifyErrors replaces resolver errors with the code and message handleError would use
+func (h *GraphQLHandler) classifyErrors(c *gin.Context, errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	classified := make([]gqlerrors.FormattedError, len(errs))
+	for i, err := range errs {
+		cause := originalError(err)
+		logError(c, cause)
+
+		_, response := classifyError(cause)
+		extensions := map[string]any{"code": response.Error}
+		if response.Field != "" {
+			extensions["field"] = response.Field
+		}
+		if invalid := invalidFields(cause, response); len(invalid) > 1 {
+			extensions["errors"] = invalid
+		}
+		classified[i] = gqlerrors.FormattedError{
+			Message:    response.Message,
+			Locations:  err.Locations,
+			Path:       err.Path,
+			Extensions: extensions,
+		}
+	}
+	return classified
+}
+
+// reject answers 400 with a single error that prevented the request from running
+func (h *GraphQLHandler) reject(c *gin.Context, code string, err error) {
+	logging.FromContext(c.Request.Context()).Info("Request rejected", "error", err)
+	c.JSON(http.StatusBadRequest, dto.GraphQLResponse{
+		Errors: withCode(gqlerrors.FormatErrors(err), code),
+	})
+}
+
+// withCode sets the error code of every error
+func withCode(errs []gqlerrors.FormattedError, code string) []gqlerrors.FormattedError {
+	for i := range errs {
+		errs[i].Extensions = map[string]any{"code": code}
+	}
+	return errs
+}
+
+// originalError returns the error a resolver failed with, unwrapping the
+// located and formatted errors the executor wraps it in
+func originalError(err error) error {
+	for {
+		switch wrapped := err.(type) {
+		case gqlerrors.FormattedError:
+			if wrapped.OriginalError() == nil {
+				return err
+			}
+			err = wrapped.OriginalError()
+		case *gqlerrors.Error:
+			if wrapped.OriginalError == nil {
+				return err
+			}
+			err = wrapped.OriginalError
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httphandler "devices-api/internal/handler/http"
+	"devices-api/internal/handler/http/dto"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphQLResponse is the decoded body of a /graphql response
+type graphQLResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string `json:"message"`
+		Path       []any  `json:"path"`
+		Extensions struct {
+			Code  string `json:"code"`
+			Field string `json:"field"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// postGraphQL sends a GraphQL request and returns the status code and decoded body
+func postGraphQL(t *testing.T, server *httptest.Server, query string, variables map[string]any) (int, graphQLResponse) {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(t, err)
+	resp, err := http.Post(server.URL+"/graphql", "application/json", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var result graphQLResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+
+	return resp.StatusCode, result
+}
+
+// decodeGraphQLField decodes a top-level field of the response data
+func decodeGraphQLField(t *testing.T, result graphQLResponse, field string, v any) {
+	require.Contains(t, result.Data, field)
+	require.NoError(t, json.Unmarshal(result.Data[field], v))
+}
+
+func TestGraphQL_DevicesConnection(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	office := createTestLocation(t, server, "Berlin Office", "site", nil)
+	model := createTestModel(t, server, dto.CreateModelRequest{Name: "MacBook Air", Brand: "Apple", Category: "laptop"})
+	for _, name := range []string{"Laptop 1", "Laptop 2", "Laptop 3"} {
+		body := []byte(`{"name": "` + name + `", "model_id": "` + model.ID + `", "location_id": "` + office.ID + `"}`)
+		resp, err := http.Post(server.URL+"/api/v1/devices", "application/json", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	createTestDevice(t, server, "Galaxy S24", "Samsung")
+
+	const query = `query($after: String) {
+		devices(filter: {brand: "apple"}, first: 2, after: $after) {
+			totalCount
+			pageInfo { hasNextPage hasPreviousPage endCursor }
+			nodes { name brand location { name } model { name category } }
+		}
+	}`
+
+	type page struct {
+		TotalCount int `json:"totalCount"`
+		PageInfo   struct {
+			HasNextPage     bool   `json:"hasNextPage"`
+			HasPreviousPage bool   `json:"hasPreviousPage"`
+			EndCursor       string `json:"endCursor"`
+		} `json:"pageInfo"`
+		Nodes []struct {
+			Name     string `json:"name"`
+			Brand    string `json:"brand"`
+			Location struct {
+				Name string `json:"name"`
+			} `json:"location"`
+			Model struct {
+				Name     string `json:"name"`
+				Category string `json:"category"`
+			} `json:"model"`
+		} `json:"nodes"`
+	}
+
+	status, result := postGraphQL(t, server, query, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, result.Errors)
+
+	var first page
+	decodeGraphQLField(t, result, "devices", &first)
+	assert.Equal(t, 3, first.TotalCount)
+	assert.True(t, first.PageInfo.HasNextPage)
+	assert.False(t, first.PageInfo.HasPreviousPage)
+	require.Len(t, first.Nodes, 2)
+	for _, node := range first.Nodes {
+		assert.Equal(t, "Apple", node.Brand)
+		assert.Equal(t, "Berlin Office", node.Location.Name)
+		assert.Equal(t, "MacBook Air", node.Model.Name)
+		assert.Equal(t, "LAPTOP", node.Model.Category)
+	}
+
+	status, result = postGraphQL(t, server, query, map[string]any{"after": first.PageInfo.EndCursor})
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, result.Errors)
+
+	var second page
+	decodeGraphQLField(t, result, "devices", &second)
+	assert.False(t, second.PageInfo.HasNextPage)
+	assert.True(t, second.PageInfo.HasPreviousPage)
+	require.Len(t, second.Nodes, 1)
+}
+
+func TestGraphQL_DeviceMutations(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	status, result := postGraphQL(t, server, `mutation($input: CreateDeviceInput!) {
+		createDevice(input: $input) { id name brand state labels }
+	}`, map[string]any{"input": map[string]any{
+		"name":   "iPad Pro",
+		"brand":  "Apple",
+		"labels": map[string]any{"team": "mobile"},
+	}})
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, result.Errors)
+
+	var created struct {
+		ID     string            `json:"id"`
+		Name   string            `json:"name"`
+		State  string            `json:"state"`
+		Labels map[string]string `json:"labels"`
+	}
+	decodeGraphQLField(t, result, "createDevice", &created)
+	assert.Equal(t, "iPad Pro", created.Name)
+	assert.Equal(t, "ACTIVE", created.State)
+	assert.Equal(t, map[string]string{"team": "mobile"}, created.Labels)
+
+	status, result = postGraphQL(t, server, `mutation($id: ID!, $input: UpdateDeviceInput!) {
+		updateDevice(id: $id, input: $input) { name state labels }
+	}`, map[string]any{"id": created.ID, "input": map[string]any{
+		"name":   "iPad Pro 13",
+		"state":  "IN_USE",
+		"labels": map[string]any{"team": nil, "env": "lab"},
+	}})
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, result.Errors)
+
+	var updated struct {
+		Name   string            `json:"name"`
+		State  string            `json:"state"`
+		Labels map[string]string `json:"labels"`
+	}
+	decodeGraphQLField(t, result, "updateDevice", &updated)
+	assert.Equal(t, "iPad Pro 13", updated.Name)
+	assert.Equal(t, "IN_USE", updated.State)
+	assert.Equal(t, map[string]string{"env": "lab"}, updated.Labels)
+
+	// In-use devices cannot be deleted
+	status, result = postGraphQL(t, server, `mutation($id: ID!) { deleteDevice(id: $id) }`, map[string]any{"id": created.ID})
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "business_rule_violation", result.Errors[0].Extensions.Code)
+	assert.Equal(t, []any{"deleteDevice"}, result.Errors[0].Path)
+}
+
+func TestGraphQL_Errors(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	t.Run("not found", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `query($id: ID!) { device(id: $id) { name } }`,
+			map[string]any{"id": uuid.New().String()})
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "not_found", result.Errors[0].Extensions.Code)
+		assert.Equal(t, "null", string(result.Data["device"]))
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `{ device(id: "not-a-uuid") { name } }`, nil)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "validation_error", result.Errors[0].Extensions.Code)
+		assert.Equal(t, "id", result.Errors[0].Extensions.Field)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `{ devices(after: "bogus") { totalCount } }`, nil)
+		require.Equal(t, http.StatusOK, status)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "validation_error", result.Errors[0].Extensions.Code)
+		assert.Equal(t, "after", result.Errors[0].Extensions.Field)
+	})
+
+	t.Run("syntax error", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `{ devices {`, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "invalid_query", result.Errors[0].Extensions.Code)
+		assert.Nil(t, result.Data)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `{ devices { nodes { owner } } }`, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+		require.NotEmpty(t, result.Errors)
+		assert.Equal(t, "invalid_query", result.Errors[0].Extensions.Code)
+	})
+}
+
+func TestGraphQL_QueryLimits(t *testing.T) {
+	server := setupTestRouter(t, httphandler.WithGraphQLLimits(3, 50))
+	defer server.Close()
+
+	t.Run("within limits", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `{ devices(first: 10) { nodes { name } } }`, nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, result.Errors)
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `{ devices(first: 1) { nodes { location { name } } } }`, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "query_too_deep", result.Errors[0].Extensions.Code)
+	})
+
+	t.Run("too complex", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `query($first: Int) { devices(first: $first) { nodes { id name brand } } }`,
+			map[string]any{"first": 20})
+		assert.Equal(t, http.StatusBadRequest, status)
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, "query_too_complex", result.Errors[0].Extensions.Code)
+	})
+
+	t.Run("introspection is not counted", func(t *testing.T) {
+		status, result := postGraphQL(t, server, `{ __schema { queryType { fields { name args { name } } } } }`, nil)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Empty(t, result.Errors)
+	})
+}
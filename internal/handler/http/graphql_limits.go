@@ -0,0 +1,192 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// DefaultGraphQLMaxDepth is the deepest field nesting a GraphQL query may use
+	DefaultGraphQLMaxDepth = 10
+	// DefaultGraphQLMaxComplexity is the highest cost a GraphQL query may have
+	DefaultGraphQLMaxComplexity = 5000
+)
+
+// queryLimits rejects GraphQL queries that are too deep or too expensive before they run.
+// Every selected field costs 1, and the selection of a paginated field (one taking a
+// first argument) is counted once per item of the requested page.
+// Introspection fields are not counted so tooling can always load the schema.
+type queryLimits struct {
+	maxDepth      int
+	maxComplexity int
+	// pageSize normalizes the first argument of paginated fields; 0 means omitted
+	pageSize func(first int) int
+}
+
+// limitError reports a query rejected by queryLimits
+type limitError struct {
+	code    string
+	message string
+}
+
+func (e *limitError) Error() string {
+	return e.message
+}
+
+// check measures every operation in the document, which must already be validated
+func (l queryLimits) check(schema *graphql.Schema, doc *ast.Document, variables map[string]any) *limitError {
+	m := queryMeasure{
+		schema:    schema,
+		limits:    l,
+		variables: variables,
+		fragments: make(map[string]*ast.FragmentDefinition),
+	}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		var root graphql.Type = schema.QueryType()
+		if operation.Operation == ast.OperationTypeMutation {
+			root = schema.MutationType()
+		}
+
+		depth, complexity := m.selectionSet(operation.SelectionSet, root)
+		if depth > l.maxDepth {
+			return &limitError{
+				code:    "query_too_deep",
+				message: fmt.Sprintf("query depth %d exceeds the maximum of %d", depth, l.maxDepth),
+			}
+		}
+		if complexity > l.maxComplexity {
+			return &limitError{
+				code:    "query_too_complex",
+				message: fmt.Sprintf("query complexity %d exceeds the maximum of %d", complexity, l.maxComplexity),
+			}
+		}
+	}
+	return nil
+}
+
+// queryMeasure walks the selections of a single document
+type queryMeasure struct {
+	schema    *graphql.Schema
+	limits    queryLimits
+	variables map[string]any
+	fragments map[string]*ast.FragmentDefinition
+}
+
+// selectionSet returns the depth and complexity of a selection set on parent.
+// Complexity saturates at the maximum so huge page sizes cannot overflow it.
+func (m queryMeasure) selectionSet(set *ast.SelectionSet, parent graphql.Type) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		var d, c int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			d, c = m.field(selection, parent)
+		case *ast.InlineFragment:
+			d, c = m.selectionSet(selection.SelectionSet, m.typeCondition(selection.TypeCondition, parent))
+		case *ast.FragmentSpread:
+			if selection.Name == nil {
+				continue
+			}
+			if fragment, ok := m.fragments[selection.Name.Value]; ok {
+				d, c = m.selectionSet(fragment.SelectionSet, m.typeCondition(fragment.TypeCondition, parent))
+			}
+		}
+		depth = max(depth, d)
+		complexity = m.saturate(complexity + c)
+	}
+	return depth, complexity
+}
+
+// field returns the depth and complexity of a field and its selections
+func (m queryMeasure) field(field *ast.Field, parent graphql.Type) (depth, complexity int) {
+	if field.Name == nil || strings.HasPrefix(field.Name.Value, "__") {
+		return 0, 0
+	}
+
+	var (
+		returnType graphql.Type
+		paginated  bool
+	)
+	if definition := fieldDefinition(parent, field.Name.Value); definition != nil {
+		returnType, _ = graphql.GetNamed(definition.Type).(graphql.Type)
+		for _, argument := range definition.Args {
+			if argument.Name() == "first" {
+				paginated = true
+			}
+		}
+	}
+
+	childDepth, childComplexity := m.selectionSet(field.SelectionSet, returnType)
+	if paginated {
+		childComplexity = m.saturate(childComplexity * m.limits.pageSize(m.intArgument(field, "first")))
+	}
+	return childDepth + 1, m.saturate(1 + childComplexity)
+}
+
+// intArgument reads an integer argument given literally or as a variable; 0 when absent
+func (m queryMeasure) intArgument(field *ast.Field, name string) int {
+	for _, argument := range field.Arguments {
+		if argument.Name == nil || argument.Name.Value != name {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			n, _ := strconv.Atoi(value.Value)
+			return n
+		case *ast.Variable:
+			if value.Name == nil {
+				return 0
+			}
+			switch n := m.variables[value.Name.Value].(type) {
+			case float64:
+				return int(n)
+			case int:
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// typeCondition resolves the type a fragment applies to, defaulting to parent
+func (m queryMeasure) typeCondition(condition *ast.Named, parent graphql.Type) graphql.Type {
+	if condition == nil || condition.Name == nil {
+		return parent
+	}
+	if named := m.schema.Type(condition.Name.Value); named != nil {
+		return named
+	}
+	return parent
+}
+
+func (m queryMeasure) saturate(complexity int) int {
+	return min(complexity, m.limits.maxComplexity+1)
+}
+
+// fieldDefinition looks up a field of an object or interface type
+func fieldDefinition(parent graphql.Type, name string) *graphql.FieldDefinition {
+	switch parent := parent.(type) {
+	case *graphql.Object:
+		return parent.Fields()[name]
+	case *graphql.Interface:
+		return parent.Fields()[name]
+	default:
+		return nil
+	}
+}
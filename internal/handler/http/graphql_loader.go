@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"sync"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// batchLoader collects the IDs requested while a level of a GraphQL query is resolved
+// and fetches them with a single call once the first value is needed.
+// Results, including IDs that were not found, are kept for the rest of the request.
+type batchLoader[T any] struct {
+	fetch func(ids []uuid.UUID) ([]T, error)
+	id    func(T) uuid.UUID
+
+	mu      sync.Mutex
+	pending []uuid.UUID
+	results map[uuid.UUID]loadResult[T]
+}
+
+// loadResult is the outcome of loading a single ID
+type loadResult[T any] struct {
+	value T
+	found bool
+	err   error
+}
+
+func newBatchLoader[T any](fetch func(ids []uuid.UUID) ([]T, error), id func(T) uuid.UUID) *batchLoader[T] {
+	return &batchLoader[T]{
+		fetch:   fetch,
+		id:      id,
+		results: make(map[uuid.UUID]loadResult[T]),
+	}
+}
+
+// load queues id and returns a thunk resolving to its value, or nil when it does not exist.
+// The GraphQL executor calls thunks only after every field of the current level has
+// been resolved, so all IDs of that level end up in the same batch.
+func (l *batchLoader[T]) load(id uuid.UUID) func() (any, error) {
+	l.mu.Lock()
+	if _, ok := l.results[id]; !ok {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			l.flush()
+		}
+
+		result := l.results[id]
+		if result.err != nil || !result.found {
+			return nil, result.err
+		}
+		return result.value, nil
+	}
+}
+
+// flush fetches every pending ID; the caller must hold mu
+func (l *batchLoader[T]) flush() {
+	ids := make([]uuid.UUID, 0, len(l.pending))
+	for _, id := range l.pending {
+		if _, ok := l.results[id]; !ok {
+			ids = append(ids, id)
+			l.results[id] = loadResult[T]{}
+		}
+	}
+	l.pending = nil
+	if len(ids) == 0 {
+		return
+	}
+
+	values, err := l.fetch(ids)
+	if err != nil {
+		for _, id := range ids {
+			l.results[id] = loadResult[T]{err: err}
+		}
+		return
+	}
+	for _, value := range values {
+		l.results[l.id(value)] = loadResult[T]{value: value, found: true}
+	}
+}
+
+// graphQLLoaders holds the loaders of a single GraphQL request
+type graphQLLoaders struct {
+	locations *batchLoader[*domain.Location]
+	models    *batchLoader[*domain.Model]
+}
+
+// graphQLLoadersKey is the context key of the request's loaders
+type graphQLLoadersKey struct{}
+
+// withGraphQLLoaders returns a context carrying fresh loaders backed by the given services.
+// A nil service leaves its loader unset.
+func withGraphQLLoaders(ctx context.Context, locations *service.LocationService, models *service.ModelService) context.Context {
+	var loaders graphQLLoaders
+	if locations != nil {
+		loaders.locations = newBatchLoader(
+			func(ids []uuid.UUID) ([]*domain.Location, error) {
+				return locations.ListLocations(ctx, domain.LocationFilter{IDs: ids})
+			},
+			func(location *domain.Location) uuid.UUID { return location.ID },
+		)
+	}
+	if models != nil {
+		loaders.models = newBatchLoader(
+			func(ids []uuid.UUID) ([]*domain.Model, error) {
+				return models.ListModels(ctx, domain.ModelFilter{IDs: ids})
+			},
+			func(model *domain.Model) uuid.UUID { return model.ID },
+		)
+	}
+	return context.WithValue(ctx, graphQLLoadersKey{}, &loaders)
+}
+
+// loadersFromContext returns the request's loaders
+func loadersFromContext(ctx context.Context) *graphQLLoaders {
+	loaders, _ := ctx.Value(graphQLLoadersKey{}).(*graphQLLoaders)
+	if loaders == nil {
+		return &graphQLLoaders{}
+	}
+	return loaders
+}
@@ -0,0 +1,803 @@
+package http
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// graphQLResolver resolves the GraphQL schema with the device services.
+// Location and model services are optional; without them devices do not
+// expose the related location and model objects.
+type graphQLResolver struct {
+	devices   *service.DeviceService
+	locations *service.LocationService
+	models    *service.ModelService
+}
+
+// deviceConnection is a page of devices and what is needed to resolve its page info
+type deviceConnection struct {
+	devices []*domain.Device
+	filter  domain.DeviceFilter
+	limit   int
+	offset  int
+}
+
+// deviceEdge is a device and its position in the listing
+type deviceEdge struct {
+	cursor string
+	node   *domain.Device
+}
+
+// jsonScalar passes arbitrary JSON values through, used for attributes and labels
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "An arbitrary JSON value",
+	Serialize:   func(value any) any { return value },
+	ParseValue:  func(value any) any { return value },
+	ParseLiteral: func(value ast.Value) any {
+		return parseJSONLiteral(value)
+	},
+})
+
+// parseJSONLiteral converts an inline GraphQL value to its JSON equivalent
+func parseJSONLiteral(value ast.Value) any {
+	switch value := value.(type) {
+	case *ast.StringValue:
+		return value.Value
+	case *ast.BooleanValue:
+		return value.Value
+	case *ast.IntValue:
+		n, err := strconv.ParseInt(value.Value, 10, 64)
+		if err != nil {
+			return nil
+		}
+		return n
+	case *ast.FloatValue:
+		f, err := strconv.ParseFloat(value.Value, 64)
+		if err != nil {
+			return nil
+		}
+		return f
+	case *ast.ListValue:
+		list := make([]any, len(value.Values))
+		for i, item := range value.Values {
+			list[i] = parseJSONLiteral(item)
+		}
+		return list
+	case *ast.ObjectValue:
+		object := make(map[string]any, len(value.Fields))
+		for _, field := range value.Fields {
+			object[field.Name.Value] = parseJSONLiteral(field.Value)
+		}
+		return object
+	default:
+		return nil
+	}
+}
+
+var deviceStateEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "DeviceState",
+	Values: graphql.EnumValueConfigMap{
+		"ACTIVE":      {Value: domain.DeviceStateActive},
+		"IN_USE":      {Value: domain.DeviceStateInUse},
+		"INACTIVE":    {Value: domain.DeviceStateInactive},
+		"MAINTENANCE": {Value: domain.DeviceStateMaintenance},
+	},
+})
+
+var deviceCategoryEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "DeviceCategory",
+	Values: graphql.EnumValueConfigMap{
+		"LAPTOP": {Value: domain.DeviceCategoryLaptop},
+		"PHONE":  {Value: domain.DeviceCategoryPhone},
+		"TABLET": {Value: domain.DeviceCategoryTablet},
+		"SENSOR": {Value: domain.DeviceCategorySensor},
+	},
+})
+
+var statsDimensionEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "StatsDimension",
+	Values: graphql.EnumValueConfigMap{
+		"BRAND":    {Value: domain.StatsByBrand},
+		"STATE":    {Value: domain.StatsByState},
+		"CATEGORY": {Value: domain.StatsByCategory},
+		"LOCATION": {Value: domain.StatsByLocation},
+	},
+})
+
+var statsIntervalEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "StatsInterval",
+	Values: graphql.EnumValueConfigMap{
+		"DAY":     {Value: domain.StatsIntervalDay},
+		"WEEK":    {Value: domain.StatsIntervalWeek},
+		"MONTH":   {Value: domain.StatsIntervalMonth},
+		"QUARTER": {Value: domain.StatsIntervalQuarter},
+		"YEAR":    {Value: domain.StatsIntervalYear},
+	},
+})
+
+// clearableDeviceField names the optional device fields updateDevice can remove.
+// GraphQL input objects cannot tell an explicit null from an omitted field,
+// so removals are requested by name instead.
+type clearableDeviceField string
+
+const (
+	clearSerialNumber clearableDeviceField = "serial_number"
+	clearLocation     clearableDeviceField = "location_id"
+	clearModel        clearableDeviceField = "model_id"
+	clearPurchaseDate clearableDeviceField = "purchase_date"
+	clearWarrantyEnd  clearableDeviceField = "warranty_end"
+	clearEOLDate      clearableDeviceField = "eol_date"
+)
+
+var clearableDeviceFieldEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "ClearableDeviceField",
+	Values: graphql.EnumValueConfigMap{
+		"SERIAL_NUMBER": {Value: clearSerialNumber},
+		"LOCATION":      {Value: clearLocation},
+		"MODEL":         {Value: clearModel},
+		"PURCHASE_DATE": {Value: clearPurchaseDate},
+		"WARRANTY_END":  {Value: clearWarrantyEnd},
+		"EOL_DATE":      {Value: clearEOLDate},
+	},
+})
+
+var deviceFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "DeviceFilter",
+	Description: "Narrows device listings like the query parameters of GET /api/v1/devices; all criteria are combined with AND",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"brand":      {Type: graphql.String, Description: "Brand name or alias, ignoring case"},
+		"state":      {Type: deviceStateEnum},
+		"category":   {Type: deviceCategoryEnum},
+		"locationId": {Type: graphql.ID, Description: "Location, including its descendants"},
+		"modelId":    {Type: graphql.ID},
+		"selector":   {Type: graphql.String, Description: "Label selector, e.g. team=mobile,env in (lab,staging)"},
+		"attributes": {Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Attribute filters, e.g. os=ios or ram_gb>=16"},
+		"asOf":       {Type: graphql.String, Description: "RFC 3339 timestamp to read devices at"},
+	},
+})
+
+var createDeviceInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "CreateDeviceInput",
+	Description: "A new device; with modelId it starts with the model's default attributes and brand",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":         {Type: graphql.NewNonNull(graphql.String)},
+		"brand":        {Type: graphql.String},
+		"serialNumber": {Type: graphql.String},
+		"attributes":   {Type: jsonScalar},
+		"labels":       {Type: jsonScalar},
+		"locationId":   {Type: graphql.ID},
+		"modelId":      {Type: graphql.ID},
+		"purchaseDate": {Type: graphql.String, Description: "YYYY-MM-DD"},
+		"warrantyEnd":  {Type: graphql.String, Description: "YYYY-MM-DD"},
+		"eolDate":      {Type: graphql.String, Description: "YYYY-MM-DD"},
+	},
+})
+
+var updateDeviceInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "UpdateDeviceInput",
+	Description: "Changes to a device; omitted fields are kept. Attributes and labels are merged, a null value removing a key.",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":         {Type: graphql.String},
+		"brand":        {Type: graphql.String},
+		"state":        {Type: deviceStateEnum},
+		"serialNumber": {Type: graphql.String},
+		"attributes":   {Type: jsonScalar},
+		"labels":       {Type: jsonScalar},
+		"locationId":   {Type: graphql.ID},
+		"modelId":      {Type: graphql.ID},
+		"purchaseDate": {Type: graphql.String, Description: "YYYY-MM-DD"},
+		"warrantyEnd":  {Type: graphql.String, Description: "YYYY-MM-DD"},
+		"eolDate":      {Type: graphql.String, Description: "YYYY-MM-DD"},
+		"clear":        {Type: graphql.NewList(graphql.NewNonNull(clearableDeviceFieldEnum)), Description: "Optional fields to remove"},
+	},
+})
+
+// newGraphQLSchema builds the schema; it only fails on programming errors
+func newGraphQLSchema(r *graphQLResolver) (graphql.Schema, error) {
+	deviceType := r.deviceType()
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage":     {Type: graphql.NewNonNull(graphql.Boolean), Resolve: r.hasNextPage},
+			"hasPreviousPage": {Type: graphql.NewNonNull(graphql.Boolean), Resolve: connectionField(func(c *deviceConnection) any { return c.offset > 0 })},
+			"startCursor": {Type: graphql.String, Resolve: connectionField(func(c *deviceConnection) any {
+				if len(c.devices) == 0 {
+					return nil
+				}
+				return encodeCursor(c.offset)
+			})},
+			"endCursor": {Type: graphql.String, Resolve: connectionField(func(c *deviceConnection) any {
+				if len(c.devices) == 0 {
+					return nil
+				}
+				return encodeCursor(c.offset + len(c.devices) - 1)
+			})},
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DeviceEdge",
+		Fields: graphql.Fields{
+			"cursor": {Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(deviceEdge).cursor, nil
+			}},
+			"node": {Type: graphql.NewNonNull(deviceType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(deviceEdge).node, nil
+			}},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DeviceConnection",
+		Fields: graphql.Fields{
+			"edges": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))), Resolve: connectionField(func(c *deviceConnection) any {
+				edges := make([]deviceEdge, len(c.devices))
+				for i, device := range c.devices {
+					edges[i] = deviceEdge{cursor: encodeCursor(c.offset + i), node: device}
+				}
+				return edges
+			})},
+			"nodes": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(deviceType))), Resolve: connectionField(func(c *deviceConnection) any {
+				return c.devices
+			})},
+			"pageInfo":   {Type: graphql.NewNonNull(pageInfoType), Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source, nil }},
+			"totalCount": {Type: graphql.NewNonNull(graphql.Int), Resolve: r.totalCount},
+		},
+	})
+
+	statsGroupType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "DeviceStatsGroup",
+		Description: "The devices sharing a value for every grouped dimension; ungrouped dimensions are null",
+		Fields: graphql.Fields{
+			"brand":          {Type: graphql.String, Resolve: statsKey(domain.StatsByBrand)},
+			"state":          {Type: graphql.String, Resolve: statsKey(domain.StatsByState)},
+			"category":       {Type: graphql.String, Resolve: statsKey(domain.StatsByCategory)},
+			"locationId":     {Type: graphql.ID, Resolve: statsKey(domain.StatsByLocation)},
+			"period":         {Type: graphql.DateTime, Resolve: statsGroupField(func(g domain.DeviceStatsGroup) any { return g.Period })},
+			"count":          {Type: graphql.NewNonNull(graphql.Int), Resolve: statsGroupField(func(g domain.DeviceStatsGroup) any { return g.Count })},
+			"averageAgeDays": {Type: graphql.NewNonNull(graphql.Float), Resolve: statsGroupField(func(g domain.DeviceStatsGroup) any { return g.AverageAge.Hours() / 24 })},
+		},
+	})
+
+	statsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DeviceStats",
+		Fields: graphql.Fields{
+			"total": {Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+				var total int64
+				for _, group := range p.Source.([]domain.DeviceStatsGroup) {
+					total += group.Count
+				}
+				return total, nil
+			}},
+			"groups": {Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(statsGroupType))), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source, nil
+			}},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"device": {
+				Type:        deviceType,
+				Description: "A device by ID; with asOf, as it was at that time, even if deleted since",
+				Args: graphql.FieldConfigArgument{
+					"id":   {Type: graphql.NewNonNull(graphql.ID)},
+					"asOf": {Type: graphql.String, Description: "RFC 3339 timestamp"},
+				},
+				Resolve: r.device,
+			},
+			"deviceBySerial": {
+				Type:        deviceType,
+				Description: "A device by brand (name or alias) and serial number",
+				Args: graphql.FieldConfigArgument{
+					"brand":        {Type: graphql.NewNonNull(graphql.String)},
+					"serialNumber": {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.deviceBySerial,
+			},
+			"devices": {
+				Type:        connectionType,
+				Description: "Devices matching filter, a page at a time; first is capped at the configured maximum page size",
+				Args: graphql.FieldConfigArgument{
+					"filter": {Type: deviceFilterInput},
+					"first":  {Type: graphql.Int},
+					"after":  {Type: graphql.String, Description: "Cursor of the last device of the previous page"},
+				},
+				Resolve: r.listDevices,
+			},
+			"deviceStats": {
+				Type:        statsType,
+				Description: "Device counts and average age, grouped by dimensions and creation period (in UTC)",
+				Args: graphql.FieldConfigArgument{
+					"filter":   {Type: deviceFilterInput},
+					"groupBy":  {Type: graphql.NewList(graphql.NewNonNull(statsDimensionEnum))},
+					"interval": {Type: statsIntervalEnum},
+				},
+				Resolve: r.deviceStats,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createDevice": {
+				Type:    deviceType,
+				Args:    graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(createDeviceInput)}},
+				Resolve: r.createDevice,
+			},
+			"updateDevice": {
+				Type: deviceType,
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: graphql.NewNonNull(graphql.ID)},
+					"input": {Type: graphql.NewNonNull(updateDeviceInput)},
+				},
+				Resolve: r.updateDevice,
+			},
+			"deleteDevice": {
+				Type:        graphql.ID,
+				Description: "Deletes a device and returns its ID",
+				Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve:     r.deleteDevice,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// deviceType builds the Device object, linking its location and model when their services are available
+func (r *graphQLResolver) deviceType() *graphql.Object {
+	fields := graphql.Fields{
+		"id":           {Type: graphql.NewNonNull(graphql.ID), Resolve: deviceField(func(d *domain.Device) any { return d.ID.String() })},
+		"name":         {Type: graphql.NewNonNull(graphql.String), Resolve: deviceField(func(d *domain.Device) any { return d.Name })},
+		"brand":        {Type: graphql.NewNonNull(graphql.String), Resolve: deviceField(func(d *domain.Device) any { return d.Brand })},
+		"brandId":      {Type: graphql.NewNonNull(graphql.ID), Resolve: deviceField(func(d *domain.Device) any { return d.BrandID.String() })},
+		"serialNumber": {Type: graphql.String, Resolve: deviceField(func(d *domain.Device) any { return optionalString(d.SerialNumber) })},
+		"state":        {Type: graphql.NewNonNull(deviceStateEnum), Resolve: deviceField(func(d *domain.Device) any { return d.State })},
+		"category":     {Type: deviceCategoryEnum, Resolve: deviceField(func(d *domain.Device) any { return d.Category })},
+		"attributes":   {Type: graphql.NewNonNull(jsonScalar), Resolve: deviceField(func(d *domain.Device) any { return mapAttributes(d.Attributes) })},
+		"labels":       {Type: graphql.NewNonNull(jsonScalar), Resolve: deviceField(func(d *domain.Device) any { return mapLabels(d.Labels) })},
+		"locationId":   {Type: graphql.ID, Resolve: deviceField(func(d *domain.Device) any { return formatOptionalID(d.LocationID) })},
+		"modelId":      {Type: graphql.ID, Resolve: deviceField(func(d *domain.Device) any { return formatOptionalID(d.ModelID) })},
+		"purchaseDate": {Type: graphql.String, Resolve: deviceField(func(d *domain.Device) any { return formatOptionalDate(d.PurchaseDate) })},
+		"warrantyEnd":  {Type: graphql.String, Resolve: deviceField(func(d *domain.Device) any { return formatOptionalDate(d.WarrantyEnd) })},
+		"eolDate":      {Type: graphql.String, Resolve: deviceField(func(d *domain.Device) any { return formatOptionalDate(d.EOLDate) })},
+		"lastSeenAt":   {Type: graphql.DateTime, Resolve: deviceField(func(d *domain.Device) any { return d.LastSeenAt })},
+		"reported":     {Type: jsonScalar, Resolve: deviceField(func(d *domain.Device) any { return d.Reported })},
+		"createdAt":    {Type: graphql.NewNonNull(graphql.DateTime), Resolve: deviceField(func(d *domain.Device) any { return d.CreatedAt })},
+	}
+
+	if r.locations != nil {
+		locationType := graphql.NewObject(graphql.ObjectConfig{
+			Name: "Location",
+			Fields: graphql.Fields{
+				"id":        {Type: graphql.NewNonNull(graphql.ID), Resolve: locationField(func(l *domain.Location) any { return l.ID.String() })},
+				"name":      {Type: graphql.NewNonNull(graphql.String), Resolve: locationField(func(l *domain.Location) any { return l.Name })},
+				"type":      {Type: graphql.NewNonNull(graphql.String), Resolve: locationField(func(l *domain.Location) any { return string(l.Type) })},
+				"parentId":  {Type: graphql.ID, Resolve: locationField(func(l *domain.Location) any { return formatOptionalID(l.ParentID) })},
+				"createdAt": {Type: graphql.NewNonNull(graphql.DateTime), Resolve: locationField(func(l *domain.Location) any { return l.CreatedAt })},
+			},
+		})
+		fields["location"] = &graphql.Field{
+			Type: locationType,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				device := p.Source.(*domain.Device)
+				if device.LocationID == nil {
+					return nil, nil
+				}
+				return loadersFromContext(p.Context).locations.load(*device.LocationID), nil
+			},
+		}
+	}
+
+	if r.models != nil {
+		modelType := graphql.NewObject(graphql.ObjectConfig{
+			Name: "Model",
+			Fields: graphql.Fields{
+				"id":                {Type: graphql.NewNonNull(graphql.ID), Resolve: modelField(func(m *domain.Model) any { return m.ID.String() })},
+				"name":              {Type: graphql.NewNonNull(graphql.String), Resolve: modelField(func(m *domain.Model) any { return m.Name })},
+				"brand":             {Type: graphql.NewNonNull(graphql.String), Resolve: modelField(func(m *domain.Model) any { return m.Brand })},
+				"category":          {Type: graphql.NewNonNull(deviceCategoryEnum), Resolve: modelField(func(m *domain.Model) any { return m.Category })},
+				"defaultAttributes": {Type: graphql.NewNonNull(jsonScalar), Resolve: modelField(func(m *domain.Model) any { return mapAttributes(m.DefaultAttributes) })},
+				"lifecycleMonths":   {Type: graphql.NewNonNull(graphql.Int), Resolve: modelField(func(m *domain.Model) any { return m.LifecycleMonths })},
+				"createdAt":         {Type: graphql.NewNonNull(graphql.DateTime), Resolve: modelField(func(m *domain.Model) any { return m.CreatedAt })},
+			},
+		})
+		fields["model"] = &graphql.Field{
+			Type: modelType,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				device := p.Source.(*domain.Device)
+				if device.ModelID == nil {
+					return nil, nil
+				}
+				return loadersFromContext(p.Context).models.load(*device.ModelID), nil
+			},
+		}
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{Name: "Device", Fields: fields})
+}
+
+func (r *graphQLResolver) device(p graphql.ResolveParams) (any, error) {
+	id, err := parseIDArgument(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := p.Args["asOf"].(string)
+	asOf, err := parseAsOfValue("asOf", raw)
+	if err != nil {
+		return nil, err
+	}
+	if asOf != nil {
+		return r.devices.GetDeviceAsOf(p.Context, id, *asOf)
+	}
+	return r.devices.GetDevice(p.Context, id)
+}
+
+func (r *graphQLResolver) deviceBySerial(p graphql.ResolveParams) (any, error) {
+	brand, _ := p.Args["brand"].(string)
+	serialNumber, _ := p.Args["serialNumber"].(string)
+	return r.devices.GetDeviceBySerial(p.Context, brand, serialNumber)
+}
+
+func (r *graphQLResolver) listDevices(p graphql.ResolveParams) (any, error) {
+	filter, err := parseFilterArgument(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	first, _ := p.Args["first"].(int)
+	if first < 0 {
+		return nil, domain.NewValidationError("first", "must not be negative")
+	}
+	offset := 0
+	if after, ok := p.Args["after"].(string); ok {
+		position, err := decodeCursor(after)
+		if err != nil {
+			return nil, err
+		}
+		offset = position + 1
+	}
+	limit, offset := r.devices.NormalizePagination(first, offset)
+
+	devices, err := r.devices.SearchDevices(p.Context, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &deviceConnection{devices: devices, filter: filter, limit: limit, offset: offset}, nil
+}
+
+// hasNextPage only queries for the next device when the page is full
+func (r *graphQLResolver) hasNextPage(p graphql.ResolveParams) (any, error) {
+	c := p.Source.(*deviceConnection)
+	if len(c.devices) < c.limit {
+		return false, nil
+	}
+	next, err := r.devices.SearchDevices(p.Context, c.filter, 1, c.offset+len(c.devices))
+	if err != nil {
+		return nil, err
+	}
+	return len(next) > 0, nil
+}
+
+// totalCount counts every device matching the connection's filter, only when selected
+func (r *graphQLResolver) totalCount(p graphql.ResolveParams) (any, error) {
+	c := p.Source.(*deviceConnection)
+	stats, err := r.devices.DeviceStats(p.Context, domain.DeviceStatsQuery{Filter: c.filter})
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	for _, group := range stats {
+		total += group.Count
+	}
+	return total, nil
+}
+
+func (r *graphQLResolver) deviceStats(p graphql.ResolveParams) (any, error) {
+	filter, err := parseFilterArgument(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	query := domain.DeviceStatsQuery{Filter: filter}
+	if groupBy, ok := p.Args["groupBy"].([]any); ok {
+		for _, dimension := range groupBy {
+			query.GroupBy = append(query.GroupBy, dimension.(domain.StatsDimension))
+		}
+	}
+	if interval, ok := p.Args["interval"].(domain.StatsInterval); ok {
+		query.Interval = interval
+	}
+	return r.devices.DeviceStats(p.Context, query)
+}
+
+func (r *graphQLResolver) createDevice(p graphql.ResolveParams) (any, error) {
+	input, _ := p.Args["input"].(map[string]any)
+
+	attributes, err := objectInput(input, "attributes")
+	if err != nil {
+		return nil, err
+	}
+	labels, err := labelsInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	req := dto.CreateDeviceRequest{
+		Attributes:   attributes,
+		LocationID:   stringInput(input, "locationId"),
+		ModelID:      stringInput(input, "modelId"),
+		PurchaseDate: stringInput(input, "purchaseDate"),
+		WarrantyEnd:  stringInput(input, "warrantyEnd"),
+		EOLDate:      stringInput(input, "eolDate"),
+	}
+	req.Name, _ = input["name"].(string)
+	req.Brand, _ = input["brand"].(string)
+	req.SerialNumber, _ = input["serialNumber"].(string)
+	if labels != nil {
+		req.Labels = make(map[string]string, len(labels))
+		for key, value := range labels {
+			if value == nil {
+				return nil, domain.NewValidationError("labels."+key, "must be a string")
+			}
+			req.Labels[key] = *value
+		}
+	}
+
+	return createDevice(p.Context, r.devices, req)
+}
+
+func (r *graphQLResolver) updateDevice(p graphql.ResolveParams) (any, error) {
+	id, err := parseIDArgument(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	input, _ := p.Args["input"].(map[string]any)
+
+	attributes, err := objectInput(input, "attributes")
+	if err != nil {
+		return nil, err
+	}
+	labels, err := labelsInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	req := dto.PartialUpdateDeviceRequest{
+		Name:         stringInput(input, "name"),
+		Brand:        stringInput(input, "brand"),
+		Attributes:   attributes,
+		Labels:       labels,
+		SerialNumber: nullableInput(input, "serialNumber"),
+		LocationID:   nullableInput(input, "locationId"),
+		ModelID:      nullableInput(input, "modelId"),
+		PurchaseDate: nullableInput(input, "purchaseDate"),
+		WarrantyEnd:  nullableInput(input, "warrantyEnd"),
+		EOLDate:      nullableInput(input, "eolDate"),
+	}
+	if state, ok := input["state"].(domain.DeviceState); ok {
+		s := string(state)
+		req.State = &s
+	}
+	fields, _ := input["clear"].([]any)
+	for _, field := range fields {
+		cleared := dto.NullableString{Set: true}
+		switch field.(clearableDeviceField) {
+		case clearSerialNumber:
+			req.SerialNumber = cleared
+		case clearLocation:
+			req.LocationID = cleared
+		case clearModel:
+			req.ModelID = cleared
+		case clearPurchaseDate:
+			req.PurchaseDate = cleared
+		case clearWarrantyEnd:
+			req.WarrantyEnd = cleared
+		case clearEOLDate:
+			req.EOLDate = cleared
+		}
+	}
+
+	patch, err := MapPatchRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return r.devices.PartialUpdateDevice(p.Context, id, patch)
+}
+
+func (r *graphQLResolver) deleteDevice(p graphql.ResolveParams) (any, error) {
+	id, err := parseIDArgument(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.devices.DeleteDevice(p.Context, id); err != nil {
+		return nil, err
+	}
+	return id.String(), nil
+}
+
+// parseFilterArgument converts the optional filter argument to a device filter
+func parseFilterArgument(args map[string]any) (domain.DeviceFilter, error) {
+	input, _ := args["filter"].(map[string]any)
+
+	var filter domain.DeviceFilter
+	filter.Brand, _ = input["brand"].(string)
+	filter.State, _ = input["state"].(domain.DeviceState)
+	filter.Category, _ = input["category"].(domain.DeviceCategory)
+
+	var err error
+	if filter.LocationID, err = parseOptionalID("filter.locationId", stringInput(input, "locationId")); err != nil {
+		return domain.DeviceFilter{}, err
+	}
+	if filter.ModelID, err = parseOptionalID("filter.modelId", stringInput(input, "modelId")); err != nil {
+		return domain.DeviceFilter{}, err
+	}
+
+	selector, _ := input["selector"].(string)
+	if filter.Labels, err = domain.ParseLabelSelector(selector); err != nil {
+		return domain.DeviceFilter{}, err
+	}
+
+	attributes, _ := input["attributes"].([]any)
+	for _, expression := range attributes {
+		attribute, err := domain.ParseAttributeFilter(strings.TrimPrefix(expression.(string), attributeFilterPrefix))
+		if err != nil {
+			return domain.DeviceFilter{}, err
+		}
+		filter.Attributes = append(filter.Attributes, attribute)
+	}
+
+	asOf, _ := input["asOf"].(string)
+	if filter.AsOf, err = parseAsOfValue("filter.asOf", asOf); err != nil {
+		return domain.DeviceFilter{}, err
+	}
+
+	return filter, nil
+}
+
+// parseIDArgument parses a required UUID argument
+func parseIDArgument(args map[string]any, name string) (uuid.UUID, error) {
+	raw, _ := args[name].(string)
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, domain.NewValidationError(name, "must be a valid UUID")
+	}
+	return id, nil
+}
+
+// stringInput returns an optional string field of an input object
+func stringInput(input map[string]any, name string) *string {
+	value, ok := input[name].(string)
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+// nullableInput returns an optional string field of an input object as a patch value
+func nullableInput(input map[string]any, name string) dto.NullableString {
+	value := stringInput(input, name)
+	return dto.NullableString{Set: value != nil, Value: value}
+}
+
+// objectInput returns an optional JSON object field of an input object
+func objectInput(input map[string]any, name string) (map[string]any, error) {
+	value, ok := input[name]
+	if !ok {
+		return nil, nil
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, domain.NewValidationError(name, "must be an object")
+	}
+	return object, nil
+}
+
+// labelsInput returns the optional labels field, mapping null values to nil
+func labelsInput(input map[string]any) (map[string]*string, error) {
+	object, err := objectInput(input, "labels")
+	if err != nil || object == nil {
+		return nil, err
+	}
+	labels := make(map[string]*string, len(object))
+	for key, value := range object {
+		switch value := value.(type) {
+		case nil:
+			labels[key] = nil
+		case string:
+			labels[key] = &value
+		default:
+			return nil, domain.NewValidationError("labels."+key, "must be a string")
+		}
+	}
+	return labels, nil
+}
+
+// encodeCursor renders the position of a device in a listing as an opaque cursor
+func encodeCursor(position int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(position)))
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if position, ok := strings.CutPrefix(string(raw), "offset:"); ok {
+			if n, err := strconv.Atoi(position); err == nil && n >= 0 {
+				return n, nil
+			}
+		}
+	}
+	return 0, domain.NewValidationError("after", "invalid cursor")
+}
+
+// optionalString maps an empty string to null
+func optionalString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func deviceField(get func(*domain.Device) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(*domain.Device)), nil
+	}
+}
+
+func locationField(get func(*domain.Location) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(*domain.Location)), nil
+	}
+}
+
+func modelField(get func(*domain.Model) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(*domain.Model)), nil
+	}
+}
+
+func connectionField(get func(*deviceConnection) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(*deviceConnection)), nil
+	}
+}
+
+func statsGroupField(get func(domain.DeviceStatsGroup) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(domain.DeviceStatsGroup)), nil
+	}
+}
+
+// statsKey resolves a dimension of a stats group, null when not grouped by it or unset
+func statsKey(dimension domain.StatsDimension) graphql.FieldResolveFn {
+	return statsGroupField(func(g domain.DeviceStatsGroup) any {
+		if value, ok := g.Keys[dimension]; ok {
+			return value
+		}
+		return nil
+	})
+}
+
+// mustGraphQLSchema builds the schema, panicking on a programming error
+func mustGraphQLSchema(r *graphQLResolver) graphql.Schema {
+	schema, err := newGraphQLSchema(r)
+	if err != nil {
+		panic(fmt.Sprintf("invalid GraphQL schema: %v", err))
+	}
+	return schema
+}
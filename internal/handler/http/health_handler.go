@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+
+	"devices-api/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler serves liveness and readiness probes
+type HealthHandler struct {
+	probe *health.Probe
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(probe *health.Probe) *HealthHandler {
+	return &HealthHandler{
+		probe: probe,
+	}
+}
+
+// Livez godoc
+// @Summary Liveness probe
+// @Description Reports whether the process is running. Does not check dependencies.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /livez [get]
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": health.StatusOK,
+	})
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Reports whether the service can accept traffic, with per-component status.
+// @Description Fails while the service is draining during shutdown.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.probe.Check(c.Request.Context())
+	if !report.Ready() {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
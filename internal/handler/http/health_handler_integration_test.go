@@ -0,0 +1,98 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httphandler "devices-api/internal/handler/http"
+	"devices-api/internal/health"
+	"devices-api/internal/repository"
+	"devices-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupProbeRouter creates a test router whose readiness probe checks the test database
+func setupProbeRouter(t *testing.T, expectedVersion uint) (*httptest.Server, *health.Probe) {
+	pool := pgContainer.GetPool()
+	probe := health.NewProbe(time.Second,
+		health.PostgresCheck(pool),
+		health.MigrationCheck(pool, expectedVersion),
+	)
+
+	svc := service.NewDeviceService(repository.NewPostgresDeviceRepository(pool))
+	router := httphandler.SetupRouter(svc, httphandler.WithReadinessProbe(probe))
+
+	return httptest.NewServer(router), probe
+}
+
+func getReadiness(t *testing.T, url string) (int, health.Report) {
+	resp, err := http.Get(url + "/readyz")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var report health.Report
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	return resp.StatusCode, report
+}
+
+func TestLivez(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/livez")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestReadyz_Ready(t *testing.T) {
+	version := currentSchemaVersion(t)
+	server, _ := setupProbeRouter(t, version)
+	defer server.Close()
+
+	status, report := getReadiness(t, server.URL)
+
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.ComponentUp, report.Components["postgres"].Status)
+	assert.Equal(t, health.ComponentUp, report.Components["migrations"].Status)
+}
+
+func TestReadyz_UnexpectedMigrationVersion(t *testing.T) {
+	version := currentSchemaVersion(t)
+	server, _ := setupProbeRouter(t, version+1)
+	defer server.Close()
+
+	status, report := getReadiness(t, server.URL)
+
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+	assert.Equal(t, health.ComponentUp, report.Components["postgres"].Status)
+	assert.Equal(t, health.ComponentDown, report.Components["migrations"].Status)
+}
+
+func TestReadyz_Draining(t *testing.T) {
+	server, probe := setupProbeRouter(t, currentSchemaVersion(t))
+	defer server.Close()
+
+	probe.StartDraining()
+	status, report := getReadiness(t, server.URL)
+
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, health.StatusDraining, report.Status)
+}
+
+// currentSchemaVersion reads the migration version recorded by the test helper
+func currentSchemaVersion(t *testing.T) uint {
+	var version int64
+	err := pgContainer.GetPool().QueryRow(context.Background(), `SELECT version FROM schema_migrations`).Scan(&version)
+	require.NoError(t, err)
+	return uint(version)
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"net/http"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HeartbeatHandler handles heartbeats from connected devices
+type HeartbeatHandler struct {
+	service *service.HeartbeatService
+}
+
+// NewHeartbeatHandler creates a new heartbeat handler
+func NewHeartbeatHandler(service *service.HeartbeatService) *HeartbeatHandler {
+	return &HeartbeatHandler{
+		service: service,
+	}
+}
+
+// IssueToken godoc
+// @Summary Issue a heartbeat token
+// @Description Create the token a device authenticates its heartbeats with. Any previous token of the device stops working.
+// @Description The token is only returned once. Requires the admin token.
+// @Tags heartbeats
+// @Produce json
+// @Security AdminToken
+// @Param id path string true "Device ID (UUID)"
+// @Success 201 {object} dto.HeartbeatTokenResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 401 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/heartbeat/token [post]
+func (h *HeartbeatHandler) IssueToken(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	token, err := h.service.IssueToken(c.Request.Context(), deviceID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.HeartbeatTokenResponse{
+		DeviceID: deviceID.String(),
+		Token:    token,
+	})
+}
+
+// RecordHeartbeat godoc
+// @Summary Send a heartbeat
+// @Description Report that a device is alive, optionally with fields it reports about itself.
+// @Description Authenticated with the device's heartbeat token as a bearer token.
+// @Tags heartbeats
+// @Accept json
+// @Produce json
+// @Security DeviceToken
+// @Param id path string true "Device ID (UUID)"
+// @Param heartbeat body dto.HeartbeatRequest false "Reported fields"
+// @Success 200 {object} dto.HeartbeatResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 401 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/heartbeat [post]
+func (h *HeartbeatHandler) RecordHeartbeat(c *gin.Context) {
+	// DeviceAuth has already validated the ID
+	deviceID := uuid.MustParse(c.Param("id"))
+
+	// The body is optional
+	var req dto.HeartbeatRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	var reported domain.Attributes
+	if req.Reported != nil {
+		reported = domain.Attributes(req.Reported)
+	}
+
+	heartbeat, err := h.service.RecordHeartbeat(c.Request.Context(), deviceID, reported)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.HeartbeatResponse{
+		LastSeenAt: heartbeat.SeenAt,
+	})
+}
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"net/http"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// LocationHandler handles HTTP requests for locations
+type LocationHandler struct {
+	service *service.LocationService
+}
+
+// NewLocationHandler creates a new location handler
+func NewLocationHandler(service *service.LocationService) *LocationHandler {
+	return &LocationHandler{
+		service: service,
+	}
+}
+
+// CreateLocation godoc
+// @Summary Create a new location
+// @Description Create a site, building or room. Sites have no parent, buildings belong to a site and rooms to a building.
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Param location body dto.CreateLocationRequest true "Location data"
+// @Success 201 {object} dto.LocationResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /locations [post]
+func (h *LocationHandler) CreateLocation(c *gin.Context) {
+	var req dto.CreateLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	parentID, err := parseOptionalID("parent_id", req.ParentID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	location, err := h.service.CreateLocation(c.Request.Context(), req.Name, domain.LocationType(req.Type), parentID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, MapLocationToResponse(location))
+}
+
+// GetLocation godoc
+// @Summary Get a location by ID
+// @Description Get a single location by its ID
+// @Tags locations
+// @Produce json
+// @Param id path string true "Location ID (UUID)"
+// @Success 200 {object} dto.LocationResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /locations/{id} [get]
+func (h *LocationHandler) GetLocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	location, err := h.service.GetLocation(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapLocationToResponse(location))
+}
+
+// ListLocations godoc
+// @Summary List locations
+// @Description List locations ordered by name, optionally filtered by type or parent
+// @Tags locations
+// @Produce json
+// @Param type query string false "Filter by type (site, building, room)"
+// @Param parent_id query string false "Filter by direct parent"
+// @Success 200 {object} dto.ListLocationsResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /locations [get]
+func (h *LocationHandler) ListLocations(c *gin.Context) {
+	filter := domain.LocationFilter{
+		Type: domain.LocationType(c.Query("type")),
+	}
+	if p := c.Query("parent_id"); p != "" {
+		parentID, err := parseOptionalID("parent_id", &p)
+		if err != nil {
+			handleError(c, err)
+			return
+		}
+		filter.ParentID = parentID
+	}
+
+	locations, err := h.service.ListLocations(c.Request.Context(), filter)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ListLocationsResponse{
+		Locations: MapLocationsToResponse(locations),
+		Total:     len(locations),
+	})
+}
+
+// UpdateLocation godoc
+// @Summary Update a location
+// @Description Rename a location or move it under another parent. The type cannot change.
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Param id path string true "Location ID (UUID)"
+// @Param location body dto.UpdateLocationRequest true "Location data"
+// @Success 200 {object} dto.LocationResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /locations/{id} [put]
+func (h *LocationHandler) UpdateLocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	var req dto.UpdateLocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	parentID, err := parseOptionalID("parent_id", req.ParentID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	location, err := h.service.UpdateLocation(c.Request.Context(), id, req.Name, parentID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapLocationToResponse(location))
+}
+
+// DeleteLocation godoc
+// @Summary Delete a location
+// @Description Delete a location that has no child locations and no devices
+// @Tags locations
+// @Param id path string true "Location ID (UUID)"
+// @Success 204 "No Content"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /locations/{id} [delete]
+func (h *LocationHandler) DeleteLocation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	if err := h.service.DeleteLocation(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"net/http"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MaintenanceHandler handles HTTP requests for device maintenance records
+type MaintenanceHandler struct {
+	service *service.MaintenanceService
+	devices *service.DeviceService
+}
+
+// NewMaintenanceHandler creates a new maintenance handler.
+// Device listings use the pagination settings of devices.
+func NewMaintenanceHandler(service *service.MaintenanceService, devices *service.DeviceService) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		service: service,
+		devices: devices,
+	}
+}
+
+// ScheduleMaintenance godoc
+// @Summary Schedule a maintenance
+// @Description Record a planned maintenance of a device. The device state does not change until the maintenance is started.
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID (UUID)"
+// @Param maintenance body dto.ScheduleMaintenanceRequest true "Maintenance data"
+// @Success 201 {object} dto.MaintenanceResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/maintenance [post]
+func (h *MaintenanceHandler) ScheduleMaintenance(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	var req dto.ScheduleMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	maintenance, err := h.service.ScheduleMaintenance(c.Request.Context(), deviceID,
+		domain.MaintenanceType(req.Type), req.ScheduledAt, req.Vendor, req.CostCents, req.Notes)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, MapMaintenanceToResponse(maintenance))
+}
+
+// ListMaintenance godoc
+// @Summary List maintenance of a device
+// @Description Get the maintenance records of a device, most recently scheduled first
+// @Tags maintenance
+// @Produce json
+// @Param id path string true "Device ID (UUID)"
+// @Success 200 {object} dto.ListMaintenanceResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/maintenance [get]
+func (h *MaintenanceHandler) ListMaintenance(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	records, err := h.service.ListMaintenance(c.Request.Context(), deviceID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ListMaintenanceResponse{
+		Maintenance: MapMaintenanceListToResponse(records),
+		Total:       len(records),
+	})
+}
+
+// StartMaintenance godoc
+// @Summary Start a maintenance
+// @Description Start a scheduled maintenance. The device moves into the maintenance state and cannot be put in use until the maintenance is completed.
+// @Tags maintenance
+// @Produce json
+// @Param id path string true "Device ID (UUID)"
+// @Param maintenanceId path string true "Maintenance ID (UUID)"
+// @Success 200 {object} dto.MaintenanceResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/maintenance/{maintenanceId}/start [post]
+func (h *MaintenanceHandler) StartMaintenance(c *gin.Context) {
+	deviceID, maintenanceID, ok := parseMaintenanceIDs(c)
+	if !ok {
+		return
+	}
+
+	maintenance, err := h.service.StartMaintenance(c.Request.Context(), deviceID, maintenanceID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapMaintenanceToResponse(maintenance))
+}
+
+// CompleteMaintenance godoc
+// @Summary Complete a maintenance
+// @Description Complete a started maintenance. The device returns to the state it had before the maintenance started.
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param id path string true "Device ID (UUID)"
+// @Param maintenanceId path string true "Maintenance ID (UUID)"
+// @Param completion body dto.CompleteMaintenanceRequest false "Final cost and notes"
+// @Success 200 {object} dto.MaintenanceResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/maintenance/{maintenanceId}/complete [post]
+func (h *MaintenanceHandler) CompleteMaintenance(c *gin.Context) {
+	deviceID, maintenanceID, ok := parseMaintenanceIDs(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req dto.CompleteMaintenanceRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			bindError(c, err)
+			return
+		}
+	}
+
+	maintenance, err := h.service.CompleteMaintenance(c.Request.Context(), deviceID, maintenanceID, req.CostCents, req.Notes)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapMaintenanceToResponse(maintenance))
+}
+
+// ListDueMaintenance godoc
+// @Summary List devices due for maintenance
+// @Description Get devices whose periodic maintenance is due, oldest due first.
+// @Description The interval depends on the category of the device's model and counts from its last completed maintenance,
+// @Description or from its purchase date or creation if it never had one. Devices with a scheduled or started maintenance are not listed.
+// @Tags maintenance
+// @Produce json
+// @Param limit query int false "Limit (capped at the configured maximum)" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} dto.ListDueMaintenanceResponse
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /maintenance/due [get]
+func (h *MaintenanceHandler) ListDueMaintenance(c *gin.Context) {
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := parsePositiveInt(l); err == nil {
+			limit = parsed
+		}
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		if parsed, err := parsePositiveInt(o); err == nil {
+			offset = parsed
+		}
+	}
+
+	limit, offset = h.devices.NormalizePagination(limit, offset)
+
+	due, err := h.service.ListDueMaintenance(c.Request.Context(), limit, offset)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ListDueMaintenanceResponse{
+		Devices: MapDueMaintenanceToResponse(due),
+		Total:   len(due),
+		Limit:   limit,
+		Offset:  offset,
+	})
+}
+
+// parseMaintenanceIDs parses the device and maintenance IDs from the path,
+// writing a 400 response and returning false when either is invalid
+func parseMaintenanceIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	maintenanceID, err := uuid.Parse(c.Param("maintenanceId"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid maintenance UUID format",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return deviceID, maintenanceID, true
+}
@@ -1,19 +1,51 @@
 package http
 
 import (
+	"time"
+
 	"devices-api/internal/domain"
 	"devices-api/internal/handler/http/dto"
+
+	"github.com/google/uuid"
 )
 
 // MapDeviceToResponse converts a domain device to a response DTO
 func MapDeviceToResponse(device *domain.Device) dto.DeviceResponse {
 	return dto.DeviceResponse{
-		ID:        device.ID.String(),
-		Name:      device.Name,
-		Brand:     device.Brand,
-		State:     string(device.State),
-		CreatedAt: device.CreatedAt,
+		ID:           device.ID.String(),
+		Name:         device.Name,
+		Brand:        device.Brand,
+		BrandID:      device.BrandID.String(),
+		SerialNumber: device.SerialNumber,
+		State:        string(device.State),
+		CreatedAt:    device.CreatedAt,
+		Attributes:   mapAttributes(device.Attributes),
+		Labels:       mapLabels(device.Labels),
+		LocationID:   formatOptionalID(device.LocationID),
+		ModelID:      formatOptionalID(device.ModelID),
+		Category:     string(device.Category),
+		PurchaseDate: formatOptionalDate(device.PurchaseDate),
+		WarrantyEnd:  formatOptionalDate(device.WarrantyEnd),
+		EOLDate:      formatOptionalDate(device.EOLDate),
+		LastSeenAt:   device.LastSeenAt,
+		Reported:     device.Reported,
+	}
+}
+
+// mapAttributes always renders attributes as a JSON object, never null
+func mapAttributes(attributes domain.Attributes) map[string]any {
+	if attributes == nil {
+		return map[string]any{}
 	}
+	return attributes
+}
+
+// mapLabels always renders labels as a JSON object, never null
+func mapLabels(labels domain.Labels) map[string]string {
+	if labels == nil {
+		return map[string]string{}
+	}
+	return labels
 }
 
 // MapDevicesToResponse converts a list of domain devices to response DTOs
@@ -24,3 +56,336 @@ func MapDevicesToResponse(devices []*domain.Device) []dto.DeviceResponse {
 	}
 	return responses
 }
+
+// MapPatchRequest converts a partial update request to a domain patch
+func MapPatchRequest(req dto.PartialUpdateDeviceRequest) (domain.DevicePatch, error) {
+	patch := domain.DevicePatch{
+		Name:       req.Name,
+		Brand:      req.Brand,
+		Attributes: req.Attributes,
+		Labels:     req.Labels,
+	}
+	if req.State != nil {
+		state := domain.DeviceState(*req.State)
+		patch.State = &state
+	}
+	if req.SerialNumber.Set {
+		serialNumber := ""
+		if req.SerialNumber.Value != nil {
+			serialNumber = *req.SerialNumber.Value
+		}
+		patch.SerialNumber = &serialNumber
+	}
+	if req.LocationID.Set {
+		locationID, err := parseOptionalID("location_id", req.LocationID.Value)
+		if err != nil {
+			return domain.DevicePatch{}, err
+		}
+		patch.LocationID = locationID
+		patch.ClearLocation = locationID == nil
+	}
+	if req.ModelID.Set {
+		modelID, err := parseOptionalID("model_id", req.ModelID.Value)
+		if err != nil {
+			return domain.DevicePatch{}, err
+		}
+		patch.ModelID = modelID
+		patch.ClearModel = modelID == nil
+	}
+	if req.PurchaseDate.Set {
+		date, err := parseOptionalDate("purchase_date", req.PurchaseDate.Value)
+		if err != nil {
+			return domain.DevicePatch{}, err
+		}
+		patch.PurchaseDate = date
+		patch.ClearPurchaseDate = date == nil
+	}
+	if req.WarrantyEnd.Set {
+		date, err := parseOptionalDate("warranty_end", req.WarrantyEnd.Value)
+		if err != nil {
+			return domain.DevicePatch{}, err
+		}
+		patch.WarrantyEnd = date
+		patch.ClearWarrantyEnd = date == nil
+	}
+	if req.EOLDate.Set {
+		date, err := parseOptionalDate("eol_date", req.EOLDate.Value)
+		if err != nil {
+			return domain.DevicePatch{}, err
+		}
+		patch.EOLDate = date
+		patch.ClearEOLDate = date == nil
+	}
+	return patch, nil
+}
+
+// MapLocationToResponse converts a domain location to a response DTO
+func MapLocationToResponse(location *domain.Location) dto.LocationResponse {
+	return dto.LocationResponse{
+		ID:        location.ID.String(),
+		Name:      location.Name,
+		Type:      string(location.Type),
+		ParentID:  formatOptionalID(location.ParentID),
+		CreatedAt: location.CreatedAt,
+	}
+}
+
+// MapLocationsToResponse converts a list of domain locations to response DTOs
+func MapLocationsToResponse(locations []*domain.Location) []dto.LocationResponse {
+	responses := make([]dto.LocationResponse, len(locations))
+	for i, location := range locations {
+		responses[i] = MapLocationToResponse(location)
+	}
+	return responses
+}
+
+// MapBrandToResponse converts a domain brand to a response DTO
+func MapBrandToResponse(brand *domain.Brand) dto.BrandResponse {
+	aliases := brand.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return dto.BrandResponse{
+		ID:          brand.ID.String(),
+		Name:        brand.Name,
+		Aliases:     aliases,
+		DeviceCount: brand.DeviceCount,
+		CreatedAt:   brand.CreatedAt,
+	}
+}
+
+// MapBrandsToResponse converts a list of domain brands to response DTOs
+func MapBrandsToResponse(brands []*domain.Brand) []dto.BrandResponse {
+	responses := make([]dto.BrandResponse, len(brands))
+	for i, brand := range brands {
+		responses[i] = MapBrandToResponse(brand)
+	}
+	return responses
+}
+
+// MapModelToResponse converts a domain model to a response DTO
+func MapModelToResponse(model *domain.Model) dto.ModelResponse {
+	return dto.ModelResponse{
+		ID:                model.ID.String(),
+		Name:              model.Name,
+		Brand:             model.Brand,
+		BrandID:           model.BrandID.String(),
+		Category:          string(model.Category),
+		DefaultAttributes: mapAttributes(model.DefaultAttributes),
+		LifecycleMonths:   model.LifecycleMonths,
+		CreatedAt:         model.CreatedAt,
+	}
+}
+
+// MapModelsToResponse converts a list of domain models to response DTOs
+func MapModelsToResponse(models []*domain.Model) []dto.ModelResponse {
+	responses := make([]dto.ModelResponse, len(models))
+	for i, model := range models {
+		responses[i] = MapModelToResponse(model)
+	}
+	return responses
+}
+
+// parseOptionalID parses an optional UUID, reporting failures on field
+func parseOptionalID(field string, value *string) (*uuid.UUID, error) {
+	if value == nil {
+		return nil, nil
+	}
+	id, err := uuid.Parse(*value)
+	if err != nil {
+		return nil, domain.NewValidationError(field, "must be a valid UUID")
+	}
+	return &id, nil
+}
+
+// formatOptionalID renders an optional UUID, keeping nil as JSON null
+func formatOptionalID(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
+
+// parseOptionalDate parses an optional YYYY-MM-DD date, treating an empty value as none
+// and reporting failures on field
+func parseOptionalDate(field string, value *string) (*time.Time, error) {
+	if value == nil || *value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, *value)
+	if err != nil {
+		return nil, domain.NewValidationError(field, "must be a date in YYYY-MM-DD format")
+	}
+	return &date, nil
+}
+
+// formatOptionalDate renders an optional date as YYYY-MM-DD
+func formatOptionalDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	s := date.Format(time.DateOnly)
+	return &s
+}
+
+// dateOptions converts the purchase, warranty end and EOL dates of a request to device options.
+// Omitted dates produce no option, and empty ones remove the date.
+func dateOptions(purchaseDate, warrantyEnd, eolDate *string) ([]domain.DeviceOption, error) {
+	var opts []domain.DeviceOption
+	fields := []struct {
+		name  string
+		value *string
+		opt   func(*time.Time) domain.DeviceOption
+	}{
+		{"purchase_date", purchaseDate, domain.WithPurchaseDate},
+		{"warranty_end", warrantyEnd, domain.WithWarrantyEnd},
+		{"eol_date", eolDate, domain.WithEOLDate},
+	}
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+		date, err := parseOptionalDate(field.name, field.value)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, field.opt(date))
+	}
+	return opts, nil
+}
+
+// MapExpiryReportToResponse converts an expiry report to a response DTO, counting days left from today
+func MapExpiryReportToResponse(report *domain.ExpiryReport, today time.Time) dto.ExpiryReportResponse {
+	brands := make([]dto.BrandExpiryResponse, len(report.Brands))
+	for i, brand := range report.Brands {
+		items := make([]dto.ExpiryResponse, len(brand.Expiries))
+		for j, expiry := range brand.Expiries {
+			items[j] = dto.ExpiryResponse{
+				DeviceID:     expiry.DeviceID.String(),
+				Name:         expiry.DeviceName,
+				SerialNumber: expiry.SerialNumber,
+				Kind:         string(expiry.Kind),
+				Date:         expiry.Date.Format(time.DateOnly),
+				DaysLeft:     expiry.DaysLeft(today),
+			}
+		}
+		brands[i] = dto.BrandExpiryResponse{
+			BrandID: brand.BrandID.String(),
+			Brand:   brand.Brand,
+			Items:   items,
+		}
+	}
+	return dto.ExpiryReportResponse{
+		From:   report.From.Format(time.DateOnly),
+		To:     report.To.Format(time.DateOnly),
+		Brands: brands,
+	}
+}
+
+// MapMaintenanceToResponse converts a domain maintenance record to a response DTO
+func MapMaintenanceToResponse(maintenance *domain.Maintenance) dto.MaintenanceResponse {
+	return dto.MaintenanceResponse{
+		ID:            maintenance.ID.String(),
+		DeviceID:      maintenance.DeviceID.String(),
+		Type:          string(maintenance.Type),
+		Status:        string(maintenance.Status()),
+		ScheduledAt:   maintenance.ScheduledAt,
+		StartedAt:     maintenance.StartedAt,
+		CompletedAt:   maintenance.CompletedAt,
+		Vendor:        maintenance.Vendor,
+		CostCents:     maintenance.CostCents,
+		Notes:         maintenance.Notes,
+		PreviousState: string(maintenance.PreviousState),
+		CreatedAt:     maintenance.CreatedAt,
+	}
+}
+
+// MapMaintenanceListToResponse converts a list of domain maintenance records to response DTOs
+func MapMaintenanceListToResponse(records []*domain.Maintenance) []dto.MaintenanceResponse {
+	responses := make([]dto.MaintenanceResponse, len(records))
+	for i, maintenance := range records {
+		responses[i] = MapMaintenanceToResponse(maintenance)
+	}
+	return responses
+}
+
+// MapDueMaintenanceToResponse converts devices due for maintenance to response DTOs
+func MapDueMaintenanceToResponse(due []domain.DueMaintenance) []dto.DueMaintenanceResponse {
+	responses := make([]dto.DueMaintenanceResponse, len(due))
+	for i, item := range due {
+		responses[i] = dto.DueMaintenanceResponse{
+			Device:           MapDeviceToResponse(item.Device),
+			LastMaintainedAt: item.LastMaintainedAt,
+			DueAt:            item.DueAt,
+		}
+	}
+	return responses
+}
+
+// MapTelemetryToResponse converts the buckets of a telemetry query to a response DTO
+func MapTelemetryToResponse(query domain.TelemetryQuery, buckets []domain.TelemetryBucket) dto.TelemetryResponse {
+	responses := make([]dto.TelemetryBucketResponse, len(buckets))
+	for i, bucket := range buckets {
+		responses[i] = dto.TelemetryBucketResponse{
+			Start: bucket.Start,
+			Min:   bucket.Min,
+			Max:   bucket.Max,
+			Avg:   bucket.Avg,
+			Count: bucket.Count,
+		}
+	}
+	return dto.TelemetryResponse{
+		DeviceID: query.DeviceID.String(),
+		Metric:   query.Metric,
+		From:     query.From,
+		To:       query.To,
+		Bucket:   query.Bucket.String(),
+		Buckets:  responses,
+	}
+}
+
+// MapCacheStatsToResponse converts cache counters to a response DTO
+func MapCacheStatsToResponse(stats domain.CacheStats) dto.CacheStatsResponse {
+	response := dto.CacheStatsResponse{
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+		Size:      stats.Size,
+		Capacity:  stats.Capacity,
+	}
+	if reads := stats.Hits + stats.Misses; reads > 0 {
+		response.HitRatio = float64(stats.Hits) / float64(reads)
+	}
+	return response
+}
+
+// MapDeviceStatsToResponse converts grouped device counts to a response DTO
+func MapDeviceStatsToResponse(query domain.DeviceStatsQuery, stats []domain.DeviceStatsGroup) dto.DeviceStatsResponse {
+	response := dto.DeviceStatsResponse{
+		GroupBy:  make([]string, len(query.GroupBy)),
+		Interval: string(query.Interval),
+		Groups:   make([]dto.DeviceStatsGroupResponse, len(stats)),
+	}
+	for i, dimension := range query.GroupBy {
+		response.GroupBy[i] = string(dimension)
+	}
+	for i, group := range stats {
+		keys := make(map[string]*string, len(query.GroupBy))
+		for _, dimension := range query.GroupBy {
+			if value, ok := group.Keys[dimension]; ok {
+				keys[string(dimension)] = &value
+			} else {
+				keys[string(dimension)] = nil
+			}
+		}
+		response.Groups[i] = dto.DeviceStatsGroupResponse{
+			Keys:           keys,
+			Period:         group.Period,
+			Count:          group.Count,
+			AverageAgeDays: group.AverageAge.Hours() / 24,
+		}
+		response.Total += group.Count
+	}
+	return response
+}
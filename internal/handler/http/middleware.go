@@ -0,0 +1,165 @@
+package http
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+	"devices-api/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// RequestIDHeader is the header used to accept and return request IDs
+	RequestIDHeader = "X-Request-ID"
+
+	// requestIDKey is the gin context key holding the request ID
+	requestIDKey = "request_id"
+
+	// principalKey is the gin context key under which authentication
+	// middleware stores the authenticated caller
+	principalKey = "principal"
+
+	// deviceRoutePrefix starts the routes whose :id path parameter is a device ID
+	deviceRoutePrefix = "/api/v1/devices/"
+)
+
+// validRequestID limits inbound request IDs to a safe, bounded charset
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestLogger accepts or generates an X-Request-ID, echoes it in the response
+// and stores a request-scoped slog.Logger in the request context.
+// It must run after the tracing middleware so the trace ID can be attached.
+func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		reqLogger := logger.With("request_id", requestID)
+		if spanCtx := trace.SpanContextFromContext(c.Request.Context()); spanCtx.HasTraceID() {
+			reqLogger = reqLogger.With("trace_id", spanCtx.TraceID().String())
+		}
+
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), reqLogger))
+		c.Next()
+	}
+}
+
+// AccessLog emits one structured log line per request once the handler chain completes
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if principal := c.GetString(principalKey); principal != "" {
+			attrs = append(attrs, slog.String("principal", principal))
+		}
+		// Other routes use :id for locations, brands and models
+		if deviceID := c.Param("id"); deviceID != "" && strings.HasPrefix(route, deviceRoutePrefix) {
+			attrs = append(attrs, slog.String("device_id", deviceID))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		logging.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "HTTP request", attrs...)
+	}
+}
+
+// DeviceAuth authenticates a device by the bearer token in the Authorization header,
+// which must be the heartbeat token of the device in the :id path parameter.
+// The device is recorded as the principal for the access log.
+func DeviceAuth(heartbeats *service.HeartbeatService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deviceID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, dto.ErrorResponse{
+				Error:   "invalid_id",
+				Message: "Invalid UUID format",
+			})
+			return
+		}
+
+		// Any other scheme counts as a missing token
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			token = ""
+		}
+		if err := heartbeats.Authenticate(c.Request.Context(), deviceID, strings.TrimSpace(token)); err != nil {
+			handleError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(principalKey, "device:"+deviceID.String())
+		c.Next()
+	}
+}
+
+// AdminAuth authenticates an operator by the bearer token in the Authorization header,
+// which must equal the configured admin token. An empty admin token rejects every
+// request, so admin endpoints are closed until a token is configured.
+func AdminAuth(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		token = strings.TrimSpace(token)
+		if !ok || adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			handleError(c, domain.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		c.Set(principalKey, "admin")
+		c.Next()
+	}
+}
+
+// Recovery converts panics into a 500 response and logs them with the request logger
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		logging.FromContext(c.Request.Context()).Error("Panic recovered",
+			"panic", recovered,
+			"stack", string(debug.Stack()),
+		)
+		abortWithError(c, http.StatusInternalServerError, dto.ErrorResponse{
+			Error:   "internal_error",
+			Message: "An unexpected error occurred",
+		})
+	})
+}
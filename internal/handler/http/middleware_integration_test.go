@@ -0,0 +1,87 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httphandler "devices-api/internal/handler/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Request ID Tests ==========
+
+func TestRequestID_GeneratedWhenMissing(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/devices")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	requestID := resp.Header.Get(httphandler.RequestIDHeader)
+	_, err = uuid.Parse(requestID)
+	assert.NoError(t, err, "expected a generated UUID request ID, got %q", requestID)
+}
+
+func TestRequestID_PropagatedFromRequest(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/devices", nil)
+	require.NoError(t, err)
+	req.Header.Set(httphandler.RequestIDHeader, "client-req-42")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "client-req-42", resp.Header.Get(httphandler.RequestIDHeader))
+}
+
+func TestRequestID_InvalidValueReplaced(t *testing.T) {
+	server := setupTestRouter(t)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/devices", nil)
+	require.NoError(t, err)
+	req.Header.Set(httphandler.RequestIDHeader, "bad id with spaces")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	requestID := resp.Header.Get(httphandler.RequestIDHeader)
+	assert.NotEqual(t, "bad id with spaces", requestID)
+	_, err = uuid.Parse(requestID)
+	assert.NoError(t, err)
+}
+
+// ========== Access Log Tests ==========
+
+func TestAccessLog_DeviceIDOnlyOnDeviceRoutes(t *testing.T) {
+	var logs bytes.Buffer
+	router := gin.New()
+	router.Use(httphandler.RequestLogger(slog.New(slog.NewJSONHandler(&logs, nil))), httphandler.AccessLog())
+	router.GET("/api/v1/devices/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/api/v1/locations/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	accessLog := func(path string) map[string]any {
+		logs.Reset()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		return entry
+	}
+
+	deviceID := uuid.NewString()
+	assert.Equal(t, deviceID, accessLog("/api/v1/devices/" + deviceID)["device_id"])
+	assert.NotContains(t, accessLog("/api/v1/locations/"+uuid.NewString()), "device_id")
+}
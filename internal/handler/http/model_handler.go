@@ -0,0 +1,178 @@
+package http
+
+import (
+	"net/http"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ModelHandler handles HTTP requests for the model catalog
+type ModelHandler struct {
+	service *service.ModelService
+}
+
+// NewModelHandler creates a new model handler
+func NewModelHandler(service *service.ModelService) *ModelHandler {
+	return &ModelHandler{
+		service: service,
+	}
+}
+
+// CreateModel godoc
+// @Summary Create a catalog model
+// @Description Add a model such as "MacBook Pro 14 M3" with a category, default attributes and lifecycle policy
+// @Tags models
+// @Accept json
+// @Produce json
+// @Param model body dto.CreateModelRequest true "Model data"
+// @Success 201 {object} dto.ModelResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /models [post]
+func (h *ModelHandler) CreateModel(c *gin.Context) {
+	var req dto.CreateModelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	model, err := h.service.CreateModel(c.Request.Context(), req.Name, req.Brand,
+		domain.DeviceCategory(req.Category), req.DefaultAttributes, req.LifecycleMonths)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, MapModelToResponse(model))
+}
+
+// GetModel godoc
+// @Summary Get a catalog model by ID
+// @Description Get a single catalog model by its ID
+// @Tags models
+// @Produce json
+// @Param id path string true "Model ID (UUID)"
+// @Success 200 {object} dto.ModelResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /models/{id} [get]
+func (h *ModelHandler) GetModel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	model, err := h.service.GetModel(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapModelToResponse(model))
+}
+
+// ListModels godoc
+// @Summary List catalog models
+// @Description List models ordered by brand and name, optionally filtered by brand or category
+// @Tags models
+// @Produce json
+// @Param brand query string false "Filter by brand name or alias, ignoring case"
+// @Param category query string false "Filter by category (laptop, phone, tablet, sensor)"
+// @Success 200 {object} dto.ListModelsResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /models [get]
+func (h *ModelHandler) ListModels(c *gin.Context) {
+	filter := domain.ModelFilter{
+		Brand:    c.Query("brand"),
+		Category: domain.DeviceCategory(c.Query("category")),
+	}
+
+	models, err := h.service.ListModels(c.Request.Context(), filter)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ListModelsResponse{
+		Models: MapModelsToResponse(models),
+		Total:  len(models),
+	})
+}
+
+// UpdateModel godoc
+// @Summary Update a catalog model
+// @Description Change a model's name, default attributes and lifecycle policy. Brand and category cannot change.
+// @Tags models
+// @Accept json
+// @Produce json
+// @Param id path string true "Model ID (UUID)"
+// @Param model body dto.UpdateModelRequest true "Model data"
+// @Success 200 {object} dto.ModelResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /models/{id} [put]
+func (h *ModelHandler) UpdateModel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	var req dto.UpdateModelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		bindError(c, err)
+		return
+	}
+
+	model, err := h.service.UpdateModel(c.Request.Context(), id, req.Name, req.DefaultAttributes, req.LifecycleMonths)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapModelToResponse(model))
+}
+
+// DeleteModel godoc
+// @Summary Delete a catalog model
+// @Description Delete a model that no device references
+// @Tags models
+// @Param id path string true "Model ID (UUID)"
+// @Success 204 "No Content"
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 422 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /models/{id} [delete]
+func (h *ModelHandler) DeleteModel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	if err := h.service.DeleteModel(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,225 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+	"devices-api/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// problemContentType is the media type of RFC 9457 problem details
+	problemContentType = "application/problem+json"
+
+	// problemTypePrefix prefixes the error code to form a problem's type URI,
+	// e.g. urn:devices-api:problem:validation-error
+	problemTypePrefix = "urn:devices-api:problem:"
+
+	// problemInstancePrefix prefixes the request ID to form a problem's instance URI
+	problemInstancePrefix = "urn:devices-api:request:"
+)
+
+func init() {
+	// Report invalid fields by their JSON names rather than Go field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// writeError sends an error response in the format the client accepts.
+// Clients preferring application/json get the legacy ErrorResponse, everyone else
+// an application/problem+json document listing the invalid field, if any.
+func writeError(c *gin.Context, status int, response dto.ErrorResponse) {
+	respondError(c, status, response, invalidFields(nil, response))
+}
+
+// abortWithError sends an error response and stops the handler chain
+func abortWithError(c *gin.Context, status int, response dto.ErrorResponse) {
+	writeError(c, status, response)
+	c.Abort()
+}
+
+// bindError responds to a request body that could not be bound, listing every invalid field
+func bindError(c *gin.Context, err error) {
+	if domain.IsValidationError(err) {
+		handleError(c, err)
+		return
+	}
+	logging.FromContext(c.Request.Context()).Info("Request rejected", "error", err)
+
+	invalid, detail := describeBindError(err)
+	response := dto.ErrorResponse{
+		Error:   "validation_error",
+		Message: detail,
+	}
+	if len(invalid) > 0 {
+		response.Field = invalid[0].Field
+	}
+	respondError(c, http.StatusBadRequest, response, invalid)
+}
+
+// respondError negotiates the error format and writes the response
+func respondError(c *gin.Context, status int, response dto.ErrorResponse, invalid []dto.InvalidField) {
+	if c.NegotiateFormat(problemContentType, gin.MIMEJSON) == gin.MIMEJSON {
+		c.JSON(status, response)
+		return
+	}
+
+	problem := dto.ProblemDetails{
+		Type:   problemTypePrefix + strings.ReplaceAll(response.Error, "_", "-"),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: response.Message,
+		Code:   response.Error,
+		Errors: invalid,
+	}
+	if requestID := c.GetString(requestIDKey); requestID != "" {
+		problem.Instance = problemInstancePrefix + requestID
+	}
+
+	// The JSON renderer keeps a Content-Type that is already set
+	c.Header("Content-Type", problemContentType)
+	c.JSON(status, problem)
+}
+
+// describeBindError lists the fields a binding error is about and summarizes it
+func describeBindError(err error) ([]dto.InvalidField, string) {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+		invalid        []dto.InvalidField
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fieldErr := range validationErrs {
+			invalid = append(invalid, dto.InvalidField{
+				Field:   fieldPath(fieldErr),
+				Message: validationMessage(fieldErr),
+			})
+		}
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		invalid = append(invalid, dto.InvalidField{
+			Field:   typeErr.Field,
+			Message: "must be " + jsonTypeName(typeErr.Type),
+		})
+	case errors.As(err, &syntaxErr):
+		return nil, fmt.Sprintf("Request body is not valid JSON (at byte %d)", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return nil, "Request body is not valid JSON (unexpected end)"
+	case errors.Is(err, io.EOF):
+		return nil, "Request body is empty"
+	default:
+		return nil, "Request body is invalid"
+	}
+
+	return invalid, summarizeFields(invalid)
+}
+
+// invalidFields lists the fields an error is about: every violation when err holds
+// ValidationErrors, otherwise the field of its response, if any
+func invalidFields(err error, response dto.ErrorResponse) []dto.InvalidField {
+	var violations domain.ValidationErrors
+	if errors.As(err, &violations) {
+		invalid := make([]dto.InvalidField, len(violations))
+		for i, violation := range violations {
+			invalid[i] = dto.InvalidField{Field: violation.Field, Message: violation.Message}
+		}
+		return invalid
+	}
+	if response.Field != "" {
+		return []dto.InvalidField{{Field: response.Field, Message: response.Message}}
+	}
+	return nil
+}
+
+// summarizeFields joins invalid fields into a single message
+func summarizeFields(invalid []dto.InvalidField) string {
+	parts := make([]string, len(invalid))
+	for i, field := range invalid {
+		parts[i] = field.Field + " " + field.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// fieldPath returns the JSON path of an invalid field, without the request type
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, found := strings.Cut(fieldErr.Namespace(), ".")
+	if !found {
+		return fieldErr.Field()
+	}
+	return path
+}
+
+// validationMessage describes a failed validation rule
+func validationMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required", "required_without":
+		return "is required"
+	case "min":
+		return "must be at least " + sizeOf(fieldErr.Kind(), param)
+	case "max":
+		return "must be at most " + sizeOf(fieldErr.Kind(), param)
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "uuid":
+		return "must be a UUID"
+	default:
+		return "is invalid"
+	}
+}
+
+// sizeOf phrases a min or max bound for the kind of field it applies to
+func sizeOf(kind reflect.Kind, bound string) string {
+	switch kind {
+	case reflect.String:
+		return bound + " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return bound + " items"
+	default:
+		return bound
+	}
+}
+
+// jsonTypeName names the JSON type a Go type is decoded from
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "a valid value"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	default:
+		return "a valid value"
+	}
+}
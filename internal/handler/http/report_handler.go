@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultExpiryWindow is used when /reports/expiring has no within parameter
+const defaultExpiryWindow = "30d"
+
+// ReportHandler handles HTTP requests for reports
+type ReportHandler struct {
+	service *service.ReportService
+}
+
+// NewReportHandler creates a new report handler
+func NewReportHandler(service *service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		service: service,
+	}
+}
+
+// ListExpiring godoc
+// @Summary List devices with expiring warranty or end of life
+// @Description List devices whose warranty ends or planned end of life falls between today and the window, grouped by brand.
+// @Description A device with both dates in the window is listed once per date.
+// @Tags reports
+// @Produce json
+// @Param within query string false "Window as days or weeks, e.g. 30d or 4w" default(30d)
+// @Success 200 {object} dto.ExpiryReportResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /reports/expiring [get]
+func (h *ReportHandler) ListExpiring(c *gin.Context) {
+	days, err := parseWindowDays(c.DefaultQuery("within", defaultExpiryWindow))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	report, err := h.service.ExpiringDevices(c.Request.Context(), days)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapExpiryReportToResponse(report, time.Now().UTC()))
+}
+
+// parseWindowDays parses a window such as "30d" or "4w" into days
+func parseWindowDays(s string) (int, error) {
+	invalid := domain.NewValidationError("within", "must be a number of days or weeks, e.g. 30d or 4w")
+
+	multiplier := 0
+	switch {
+	case strings.HasSuffix(s, "d"):
+		multiplier = 1
+	case strings.HasSuffix(s, "w"):
+		multiplier = 7
+	default:
+		return 0, invalid
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil {
+		return 0, invalid
+	}
+	return n * multiplier, nil
+}
@@ -1,17 +1,156 @@
 package http
 
 import (
+	"log/slog"
+	"strings"
+
 	"devices-api/docs"
+	"devices-api/internal/domain"
+	"devices-api/internal/health"
 	"devices-api/internal/service"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// serviceName identifies this service in traces
+const serviceName = "devices-api"
+
+// routerOptions holds optional router dependencies
+type routerOptions struct {
+	logger      *slog.Logger
+	probe       *health.Probe
+	locations   *service.LocationService
+	brands      *service.BrandService
+	models      *service.ModelService
+	reports     *service.ReportService
+	maintenance *service.MaintenanceService
+	heartbeats  *service.HeartbeatService
+	telemetry   *service.TelemetryService
+	deviceCache domain.DeviceCache
+	adminToken  string
+
+	graphQLMaxDepth      int
+	graphQLMaxComplexity int
+}
+
+// RouterOption customizes the router
+type RouterOption func(*routerOptions)
+
+// WithLogger sets the base logger used for request-scoped and access logs
+func WithLogger(logger *slog.Logger) RouterOption {
+	return func(o *routerOptions) {
+		o.logger = logger
+	}
+}
+
+// WithReadinessProbe sets the probe backing /readyz
+func WithReadinessProbe(probe *health.Probe) RouterOption {
+	return func(o *routerOptions) {
+		o.probe = probe
+	}
+}
+
+// WithLocationService enables the /locations endpoints
+func WithLocationService(locations *service.LocationService) RouterOption {
+	return func(o *routerOptions) {
+		o.locations = locations
+	}
+}
+
+// WithBrandService enables the /brands endpoints
+func WithBrandService(brands *service.BrandService) RouterOption {
+	return func(o *routerOptions) {
+		o.brands = brands
+	}
+}
+
+// WithModelService enables the /models endpoints
+func WithModelService(models *service.ModelService) RouterOption {
+	return func(o *routerOptions) {
+		o.models = models
+	}
+}
+
+// WithReportService enables the /reports endpoints
+func WithReportService(reports *service.ReportService) RouterOption {
+	return func(o *routerOptions) {
+		o.reports = reports
+	}
+}
+
+// WithMaintenanceService enables the maintenance endpoints
+func WithMaintenanceService(maintenance *service.MaintenanceService) RouterOption {
+	return func(o *routerOptions) {
+		o.maintenance = maintenance
+	}
+}
+
+// WithHeartbeatService enables device heartbeats and heartbeat tokens
+func WithHeartbeatService(heartbeats *service.HeartbeatService) RouterOption {
+	return func(o *routerOptions) {
+		o.heartbeats = heartbeats
+	}
+}
+
+// WithTelemetryService enables the telemetry endpoints.
+// Ingestion authenticates devices by their heartbeat tokens, so it also needs WithHeartbeatService.
+func WithTelemetryService(telemetry *service.TelemetryService) RouterOption {
+	return func(o *routerOptions) {
+		o.telemetry = telemetry
+	}
+}
+
+// WithDeviceCache enables the admin endpoints inspecting and invalidating the device cache.
+// A nil cache leaves them disabled.
+func WithDeviceCache(cache domain.DeviceCache) RouterOption {
+	return func(o *routerOptions) {
+		o.deviceCache = cache
+	}
+}
+
+// WithAdminToken sets the bearer token operators use for admin endpoints.
+// Without it every admin endpoint answers 401.
+func WithAdminToken(token string) RouterOption {
+	return func(o *routerOptions) {
+		o.adminToken = token
+	}
+}
+
+// WithGraphQLLimits bounds the depth and complexity of /graphql queries
+func WithGraphQLLimits(maxDepth, maxComplexity int) RouterOption {
+	return func(o *routerOptions) {
+		o.graphQLMaxDepth = maxDepth
+		o.graphQLMaxComplexity = maxComplexity
+	}
+}
+
 // SetupRouter configures all HTTP routes
-func SetupRouter(deviceService *service.DeviceService) *gin.Engine {
-	router := gin.Default()
+func SetupRouter(deviceService *service.DeviceService, opts ...RouterOption) *gin.Engine {
+	options := routerOptions{
+		logger: slog.Default(),
+		probe:  health.NewProbe(health.DefaultCheckTimeout),
+
+		graphQLMaxDepth:      DefaultGraphQLMaxDepth,
+		graphQLMaxComplexity: DefaultGraphQLMaxComplexity,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	router := gin.New()
+
+	// Middleware order matters:
+	// tracing starts the span, the request logger picks up its trace ID,
+	// the access log reads the request logger and recovery runs innermost
+	router.Use(
+		otelgin.Middleware(serviceName, otelgin.WithGinFilter(shouldTrace)),
+		RequestLogger(options.logger),
+		AccessLog(),
+		Recovery(),
+	)
 
 	// Programmatically set swagger info (for dynamic host configuration)
 	docs.SwaggerInfo.Title = "Devices API"
@@ -20,16 +159,21 @@ func SetupRouter(deviceService *service.DeviceService) *gin.Engine {
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	docs.SwaggerInfo.Schemes = []string{"http", "https"}
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-		})
-	})
+	// Health probes
+	healthHandler := NewHealthHandler(options.probe)
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	// Deprecated: kept for existing monitors, use /livez instead
+	router.GET("/health", healthHandler.Livez)
 
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// GraphQL queries and mutations over the same services as the REST routes
+	graphQLHandler := NewGraphQLHandler(deviceService, options.locations, options.models,
+		options.graphQLMaxDepth, options.graphQLMaxComplexity)
+	router.POST("/graphql", graphQLHandler.Query)
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -39,12 +183,114 @@ func SetupRouter(deviceService *service.DeviceService) *gin.Engine {
 		{
 			devices.POST("", deviceHandler.CreateDevice)
 			devices.GET("", deviceHandler.ListDevices)
+			devices.GET("/by-serial/:brand/:serial", deviceHandler.GetDeviceBySerial)
 			devices.GET("/:id", deviceHandler.GetDevice)
 			devices.PUT("/:id", deviceHandler.UpdateDevice)
 			devices.PATCH("/:id", deviceHandler.PartialUpdateDevice)
 			devices.DELETE("/:id", deviceHandler.DeleteDevice)
+
+			devices.GET("/:id/labels", deviceHandler.GetLabels)
+			devices.PUT("/:id/labels", deviceHandler.ReplaceLabels)
+			devices.PATCH("/:id/labels", deviceHandler.UpdateLabels)
+			devices.DELETE("/:id/labels/*key", deviceHandler.DeleteLabel)
+		}
+
+		v1.GET("/stats/devices", deviceHandler.GetDeviceStats)
+
+		if options.maintenance != nil {
+			maintenanceHandler := NewMaintenanceHandler(options.maintenance, deviceService)
+
+			devices.POST("/:id/maintenance", maintenanceHandler.ScheduleMaintenance)
+			devices.GET("/:id/maintenance", maintenanceHandler.ListMaintenance)
+			devices.POST("/:id/maintenance/:maintenanceId/start", maintenanceHandler.StartMaintenance)
+			devices.POST("/:id/maintenance/:maintenanceId/complete", maintenanceHandler.CompleteMaintenance)
+
+			v1.GET("/maintenance/due", maintenanceHandler.ListDueMaintenance)
+		}
+
+		if options.heartbeats != nil {
+			heartbeatHandler := NewHeartbeatHandler(options.heartbeats)
+
+			devices.POST("/:id/heartbeat", DeviceAuth(options.heartbeats), heartbeatHandler.RecordHeartbeat)
+			devices.POST("/:id/heartbeat/token", AdminAuth(options.adminToken), heartbeatHandler.IssueToken)
+		}
+
+		if options.telemetry != nil {
+			telemetryHandler := NewTelemetryHandler(options.telemetry)
+
+			devices.GET("/:id/telemetry", telemetryHandler.QueryTelemetry)
+			if options.heartbeats != nil {
+				devices.POST("/:id/telemetry", DeviceAuth(options.heartbeats), telemetryHandler.IngestTelemetry)
+			}
+		}
+
+		if options.deviceCache != nil {
+			cacheHandler := NewCacheHandler(options.deviceCache)
+
+			cache := v1.Group("/admin/cache", AdminAuth(options.adminToken))
+			{
+				cache.GET("/devices", cacheHandler.GetDeviceCacheStats)
+				cache.DELETE("/devices", cacheHandler.PurgeDeviceCache)
+				cache.DELETE("/devices/:id", cacheHandler.InvalidateDevice)
+			}
+		}
+
+		if options.locations != nil {
+			locationHandler := NewLocationHandler(options.locations)
+
+			locations := v1.Group("/locations")
+			{
+				locations.POST("", locationHandler.CreateLocation)
+				locations.GET("", locationHandler.ListLocations)
+				locations.GET("/:id", locationHandler.GetLocation)
+				locations.PUT("/:id", locationHandler.UpdateLocation)
+				locations.DELETE("/:id", locationHandler.DeleteLocation)
+			}
+		}
+
+		if options.brands != nil {
+			brandHandler := NewBrandHandler(options.brands)
+
+			brands := v1.Group("/brands")
+			{
+				brands.GET("", brandHandler.ListBrands)
+				brands.GET("/:id", brandHandler.GetBrand)
+				brands.POST("/:id/aliases", brandHandler.AddAlias)
+			}
+		}
+
+		if options.models != nil {
+			modelHandler := NewModelHandler(options.models)
+
+			models := v1.Group("/models")
+			{
+				models.POST("", modelHandler.CreateModel)
+				models.GET("", modelHandler.ListModels)
+				models.GET("/:id", modelHandler.GetModel)
+				models.PUT("/:id", modelHandler.UpdateModel)
+				models.DELETE("/:id", modelHandler.DeleteModel)
+			}
+		}
+
+		if options.reports != nil {
+			reportHandler := NewReportHandler(options.reports)
+
+			reports := v1.Group("/reports")
+			{
+				reports.GET("/expiring", reportHandler.ListExpiring)
+			}
 		}
 	}
 
 	return router
 }
+
+// shouldTrace excludes health probes and Swagger assets from tracing
+func shouldTrace(c *gin.Context) bool {
+	switch path := c.Request.URL.Path; path {
+	case "/health", "/livez", "/readyz":
+		return false
+	default:
+		return !strings.HasPrefix(path, "/swagger/")
+	}
+}
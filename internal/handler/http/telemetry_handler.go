@@ -0,0 +1,176 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/handler/http/dto"
+	"devices-api/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultTelemetryRange is queried when a telemetry query has no from parameter
+	defaultTelemetryRange = 24 * time.Hour
+	// defaultTelemetryBucket is used when a telemetry query has no bucket parameter
+	defaultTelemetryBucket = "1h"
+)
+
+// TelemetryHandler handles metric samples reported by devices
+type TelemetryHandler struct {
+	service *service.TelemetryService
+}
+
+// NewTelemetryHandler creates a new telemetry handler
+func NewTelemetryHandler(service *service.TelemetryService) *TelemetryHandler {
+	return &TelemetryHandler{
+		service: service,
+	}
+}
+
+// IngestTelemetry godoc
+// @Summary Send telemetry
+// @Description Store a batch of metric samples of a device, one JSON object per line (NDJSON).
+// @Description The batch is stored as a whole or not at all. Samples must be within the retention period and not in the future.
+// @Description Authenticated with the device's heartbeat token as a bearer token.
+// @Tags telemetry
+// @Accept x-ndjson
+// @Produce json
+// @Security DeviceToken
+// @Param id path string true "Device ID (UUID)"
+// @Param samples body dto.TelemetrySample true "One sample per line"
+// @Success 202 {object} dto.TelemetryIngestResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 401 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/telemetry [post]
+func (h *TelemetryHandler) IngestTelemetry(c *gin.Context) {
+	// DeviceAuth has already validated the ID
+	deviceID := uuid.MustParse(c.Param("id"))
+
+	points, err := decodeTelemetry(c.Request.Body, deviceID, h.service.MaxBatch())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if err := h.service.Ingest(c.Request.Context(), deviceID, points); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, dto.TelemetryIngestResponse{
+		Accepted: len(points),
+	})
+}
+
+// QueryTelemetry godoc
+// @Summary Query telemetry
+// @Description Get the min, max, average and count of one metric of a device per time bucket, oldest first.
+// @Description Buckets start at from; buckets without samples are omitted.
+// @Tags telemetry
+// @Produce json
+// @Param id path string true "Device ID (UUID)"
+// @Param metric query string true "Metric name, e.g. battery"
+// @Param from query string false "Start of the range (RFC 3339, inclusive); defaults to 24 hours before to"
+// @Param to query string false "End of the range (RFC 3339, exclusive); defaults to now"
+// @Param bucket query string false "Bucket size as a whole number of minutes, e.g. 5m or 1h" default(1h)
+// @Success 200 {object} dto.TelemetryResponse
+// @Failure 400 {object} dto.ProblemDetails
+// @Failure 404 {object} dto.ProblemDetails
+// @Failure 500 {object} dto.ProblemDetails
+// @Router /devices/{id}/telemetry [get]
+func (h *TelemetryHandler) QueryTelemetry(c *gin.Context) {
+	deviceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeError(c, http.StatusBadRequest, dto.ErrorResponse{
+			Error:   "invalid_id",
+			Message: "Invalid UUID format",
+		})
+		return
+	}
+
+	query, err := parseTelemetryQuery(c, deviceID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	buckets, err := h.service.Query(c.Request.Context(), query)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, MapTelemetryToResponse(query, buckets))
+}
+
+// decodeTelemetry reads the NDJSON samples of a batch. Reading stops after
+// one sample more than maxBatch, which is enough for the service to reject it.
+func decodeTelemetry(body io.Reader, deviceID uuid.UUID, maxBatch int) ([]domain.TelemetryPoint, error) {
+	decoder := json.NewDecoder(body)
+
+	var points []domain.TelemetryPoint
+	for len(points) <= maxBatch {
+		field := domain.TelemetryPointField(len(points))
+
+		var sample dto.TelemetrySample
+		if err := decoder.Decode(&sample); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, domain.NewValidationError(field, "must be a JSON object with metric, value and ts: "+err.Error())
+		}
+		if sample.Value == nil {
+			return nil, domain.NewValidationError(field+".value", "is required")
+		}
+
+		point, err := domain.NewTelemetryPoint(field, deviceID, sample.Metric, *sample.Value, sample.TS)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// parseTelemetryQuery reads the metric, range and bucket of a telemetry query
+func parseTelemetryQuery(c *gin.Context, deviceID uuid.UUID) (domain.TelemetryQuery, error) {
+	query := domain.TelemetryQuery{
+		DeviceID: deviceID,
+		Metric:   c.Query("metric"),
+		To:       time.Now().UTC(),
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return query, domain.NewValidationError("to", "must be an RFC 3339 timestamp")
+		}
+		query.To = to.UTC()
+	}
+
+	query.From = query.To.Add(-defaultTelemetryRange)
+	if raw := c.Query("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return query, domain.NewValidationError("from", "must be an RFC 3339 timestamp")
+		}
+		query.From = from.UTC()
+	}
+
+	bucket, err := time.ParseDuration(c.DefaultQuery("bucket", defaultTelemetryBucket))
+	if err != nil {
+		return query, domain.NewValidationError("bucket", "must be a duration such as 5m or 1h")
+	}
+	query.Bucket = bucket
+
+	return query, nil
+}
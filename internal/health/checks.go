@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Pinger is implemented by connection pools that can verify connectivity
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Querier is the subset of pgxpool.Pool used by the migration check
+type Querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// undefinedTable is the PostgreSQL SQLSTATE for a missing relation
+const undefinedTable = "42P01"
+
+// PostgresCheck pings the database pool
+func PostgresCheck(pool Pinger) Check {
+	return Check{
+		Name: "postgres",
+		Run:  pool.Ping,
+	}
+}
+
+// MigrationCheck verifies that the schema is at the expected migration version
+// and not left dirty by a failed migration
+func MigrationCheck(db Querier, expected uint) Check {
+	return Check{
+		Name: "migrations",
+		Run: func(ctx context.Context) error {
+			var (
+				version int64
+				dirty   bool
+			)
+			err := db.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+			if err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == undefinedTable {
+					return errors.New("schema_migrations table not found: migrations have not been applied")
+				}
+				if errors.Is(err, pgx.ErrNoRows) {
+					return errors.New("no migration version recorded")
+				}
+				return fmt.Errorf("failed to read migration version: %w", err)
+			}
+
+			if dirty {
+				return fmt.Errorf("migration version %d is dirty", version)
+			}
+			if version != int64(expected) {
+				return fmt.Errorf("schema at version %d, expected %d", version, expected)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Overall readiness statuses
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "draining"
+)
+
+// Component statuses
+const (
+	ComponentUp   = "up"
+	ComponentDown = "down"
+)
+
+// DefaultCheckTimeout bounds each dependency check when no timeout is configured
+const DefaultCheckTimeout = 2 * time.Second
+
+// Check verifies a single dependency
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// ComponentResult is the outcome of a single check
+type ComponentResult struct {
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// Report is the outcome of a readiness probe
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentResult `json:"components,omitempty"`
+}
+
+// Ready reports whether the probe passed
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+// Probe runs readiness checks and tracks whether the process is draining
+type Probe struct {
+	checks   []Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewProbe creates a readiness probe. Each check is bounded by timeout.
+func NewProbe(timeout time.Duration, checks ...Check) *Probe {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	return &Probe{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// StartDraining makes every subsequent readiness probe fail so load balancers
+// stop routing new traffic before the server shuts down
+func (p *Probe) StartDraining() {
+	p.draining.Store(true)
+}
+
+// Draining reports whether the probe is draining
+func (p *Probe) Draining() bool {
+	return p.draining.Load()
+}
+
+// Check runs all dependency checks concurrently and aggregates the results
+func (p *Probe) Check(ctx context.Context) Report {
+	if p.Draining() {
+		return Report{Status: StatusDraining}
+	}
+
+	report := Report{
+		Status:     StatusOK,
+		Components: make(map[string]ComponentResult, len(p.checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range p.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := p.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[check.Name] = result
+			if result.Status != ComponentUp {
+				report.Status = StatusUnavailable
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// run executes a single check with the probe timeout
+func (p *Probe) run(ctx context.Context, check Check) ComponentResult {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Run(ctx)
+	result := ComponentResult{
+		Status:    ComponentUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = ComponentDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"devices-api/internal/health"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func okCheck(name string) health.Check {
+	return health.Check{Name: name, Run: func(context.Context) error { return nil }}
+}
+
+func TestProbe_AllChecksPass(t *testing.T) {
+	probe := health.NewProbe(time.Second, okCheck("postgres"), okCheck("migrations"))
+
+	report := probe.Check(context.Background())
+
+	assert.True(t, report.Ready())
+	assert.Equal(t, health.StatusOK, report.Status)
+	assert.Equal(t, health.ComponentUp, report.Components["postgres"].Status)
+	assert.Equal(t, health.ComponentUp, report.Components["migrations"].Status)
+}
+
+func TestProbe_FailingCheck(t *testing.T) {
+	failing := health.Check{
+		Name: "postgres",
+		Run:  func(context.Context) error { return errors.New("connection refused") },
+	}
+	probe := health.NewProbe(time.Second, failing, okCheck("migrations"))
+
+	report := probe.Check(context.Background())
+
+	assert.False(t, report.Ready())
+	assert.Equal(t, health.StatusUnavailable, report.Status)
+	assert.Equal(t, health.ComponentDown, report.Components["postgres"].Status)
+	assert.Equal(t, "connection refused", report.Components["postgres"].Error)
+	assert.Equal(t, health.ComponentUp, report.Components["migrations"].Status)
+}
+
+func TestProbe_CheckTimeout(t *testing.T) {
+	slow := health.Check{
+		Name: "postgres",
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	probe := health.NewProbe(10*time.Millisecond, slow)
+
+	report := probe.Check(context.Background())
+
+	assert.False(t, report.Ready())
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Components["postgres"].Error)
+}
+
+func TestProbe_Draining(t *testing.T) {
+	called := false
+	check := health.Check{
+		Name: "postgres",
+		Run: func(context.Context) error {
+			called = true
+			return nil
+		},
+	}
+	probe := health.NewProbe(time.Second, check)
+
+	probe.StartDraining()
+	report := probe.Check(context.Background())
+
+	assert.False(t, report.Ready())
+	assert.Equal(t, health.StatusDraining, report.Status)
+	assert.False(t, called, "checks should not run while draining")
+}
@@ -0,0 +1,267 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// undefinedTable is the PostgreSQL SQLSTATE for a missing relation
+const undefinedTable = "42P01"
+
+// querier is satisfied by pools and single connections
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// lockKey identifies the advisory lock serializing migrations across replicas
+const lockKey int64 = 0x64657669636573 // "devices"
+
+// MigrationStatus reports whether a single migration is applied
+type MigrationStatus struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+// Status describes the schema version of the database
+type Status struct {
+	Version    uint
+	Dirty      bool
+	Latest     uint
+	Migrations []MigrationStatus
+}
+
+// Runner applies embedded migrations and records the version in schema_migrations.
+// The table layout is compatible with golang-migrate.
+type Runner struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	logger     *slog.Logger
+}
+
+// NewRunner creates a migration runner for the migrations found in fsys
+func NewRunner(pool *pgxpool.Pool, fsys fs.FS, logger *slog.Logger) (*Runner, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &Runner{
+		pool:       pool,
+		migrations: migrations,
+		logger:     logger,
+	}, nil
+}
+
+// Latest returns the highest known migration version (0 if there are none)
+func (r *Runner) Latest() uint {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Up applies all pending migrations
+func (r *Runner) Up(ctx context.Context) error {
+	return r.Goto(ctx, r.Latest())
+}
+
+// Down reverts the given number of applied migrations
+func (r *Runner) Down(ctx context.Context, steps int) error {
+	if steps < 1 {
+		return errors.New("steps must be at least 1")
+	}
+
+	return r.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := r.cleanVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		index := r.indexOf(current)
+		targetIndex := index - steps
+		target := uint(0)
+		if targetIndex >= 0 {
+			target = r.migrations[targetIndex].Version
+		}
+
+		return r.migrateTo(ctx, conn, current, target)
+	})
+}
+
+// Goto migrates up or down to the given version (0 reverts everything)
+func (r *Runner) Goto(ctx context.Context, target uint) error {
+	if target != 0 && r.indexOf(target) < 0 {
+		return fmt.Errorf("unknown migration version %d", target)
+	}
+
+	return r.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := r.cleanVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		return r.migrateTo(ctx, conn, current, target)
+	})
+}
+
+// Status reports the current schema version and which migrations are applied.
+// It only reads schema_migrations, so it does not wait for the migration lock
+// and answers while another replica is migrating.
+func (r *Runner) Status(ctx context.Context) (Status, error) {
+	version, dirty, err := readVersion(ctx, r.pool)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != undefinedTable {
+			return Status{}, err
+		}
+		// Nothing was ever migrated
+		version, dirty = 0, false
+	}
+
+	status := Status{
+		Version: version,
+		Dirty:   dirty,
+		Latest:  r.Latest(),
+	}
+	for _, m := range r.migrations {
+		status.Migrations = append(status.Migrations, MigrationStatus{
+			Version: m.Version,
+			Name:    m.Name,
+			Applied: m.Version <= version,
+		})
+	}
+
+	return status, nil
+}
+
+// migrateTo applies or reverts migrations one at a time until target is reached
+func (r *Runner) migrateTo(ctx context.Context, conn *pgxpool.Conn, current, target uint) error {
+	if current == target {
+		r.logger.Info("Schema is up to date", "version", current)
+		return nil
+	}
+
+	if target > current {
+		for _, m := range r.migrations {
+			if m.Version <= current || m.Version > target {
+				continue
+			}
+			if err := r.apply(ctx, conn, m.Up, m.Version); err != nil {
+				return fmt.Errorf("failed to apply migration %06d_%s: %w", m.Version, m.Name, err)
+			}
+			r.logger.Info("Applied migration", "version", m.Version, "name", m.Name)
+		}
+		return nil
+	}
+
+	for i := r.indexOf(current); i >= 0 && r.migrations[i].Version > target; i-- {
+		m := r.migrations[i]
+		previous := uint(0)
+		if i > 0 {
+			previous = r.migrations[i-1].Version
+		}
+		if err := r.apply(ctx, conn, m.Down, previous); err != nil {
+			return fmt.Errorf("failed to revert migration %06d_%s: %w", m.Version, m.Name, err)
+		}
+		r.logger.Info("Reverted migration", "version", m.Version, "name", m.Name)
+	}
+	return nil
+}
+
+// apply runs one migration script and records the resulting version atomically
+func (r *Runner) apply(ctx context.Context, conn *pgxpool.Conn, sql string, version uint) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `TRUNCATE schema_migrations`); err != nil {
+			return fmt.Errorf("failed to clear schema version: %w", err)
+		}
+		if version == 0 {
+			return nil
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+		return nil
+	})
+}
+
+// cleanVersion returns the current version, refusing to continue from a dirty
+// or unknown state
+func (r *Runner) cleanVersion(ctx context.Context, conn *pgxpool.Conn) (uint, error) {
+	version, dirty, err := readVersion(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("schema version %d is dirty: fix the database manually before migrating", version)
+	}
+	if version != 0 && r.indexOf(version) < 0 {
+		return 0, fmt.Errorf("schema version %d is not known to this build (latest is %d)", version, r.Latest())
+	}
+	return version, nil
+}
+
+// indexOf returns the position of version in the sorted migrations, or -1
+func (r *Runner) indexOf(version uint) int {
+	for i, m := range r.migrations {
+		if m.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock,
+// so concurrent replicas wait for each other instead of racing
+func (r *Runner) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			r.logger.Error("Failed to release migration lock", "error", err)
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// readVersion returns the recorded schema version (0 when nothing is applied)
+func readVersion(ctx context.Context, conn querier) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
@@ -0,0 +1,118 @@
+package migrate_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"devices-api/internal/migrate"
+	"devices-api/internal/testhelper"
+	"devices-api/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// migrationLockKey matches the advisory lock key used by the runner
+const migrationLockKey int64 = 0x64657669636573
+
+// tableExists reports whether the devices table is present
+func tableExists(t *testing.T, pool *pgxpool.Pool) bool {
+	var exists bool
+	err := pool.QueryRow(context.Background(), `SELECT to_regclass('public.devices') IS NOT NULL`).Scan(&exists)
+	require.NoError(t, err)
+	return exists
+}
+
+func TestRunner_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	pgContainer, err := testhelper.NewPostgresContainer(ctx)
+	require.NoError(t, err)
+	defer pgContainer.Close(ctx)
+
+	pool := pgContainer.GetPool()
+	runner, err := migrate.NewRunner(pool, migrations.FS, nil)
+	require.NoError(t, err)
+
+	t.Run("status on empty database", func(t *testing.T) {
+		status, err := runner.Status(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint(0), status.Version)
+		assert.Equal(t, runner.Latest(), status.Latest)
+		for _, m := range status.Migrations {
+			assert.False(t, m.Applied)
+		}
+	})
+
+	t.Run("concurrent up applies each migration once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- runner.Up(ctx)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+
+		status, err := runner.Status(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, runner.Latest(), status.Version)
+		assert.False(t, status.Dirty)
+		assert.True(t, tableExists(t, pool))
+	})
+
+	t.Run("status does not wait for the migration lock", func(t *testing.T) {
+		conn, err := pool.Acquire(ctx)
+		require.NoError(t, err)
+		defer conn.Release()
+
+		// Hold the lock like a replica in the middle of migrating
+		_, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey)
+		require.NoError(t, err)
+		defer func() {
+			_, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+			assert.NoError(t, err)
+		}()
+
+		statusCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		status, err := runner.Status(statusCtx)
+		require.NoError(t, err)
+		assert.Equal(t, runner.Latest(), status.Version)
+	})
+
+	t.Run("down reverts one step", func(t *testing.T) {
+		require.NoError(t, runner.Down(ctx, 1))
+
+		status, err := runner.Status(ctx)
+		require.NoError(t, err)
+		assert.Less(t, status.Version, runner.Latest())
+	})
+
+	t.Run("goto zero reverts everything", func(t *testing.T) {
+		require.NoError(t, runner.Goto(ctx, 0))
+
+		status, err := runner.Status(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint(0), status.Version)
+		assert.False(t, tableExists(t, pool))
+	})
+
+	t.Run("goto unknown version fails", func(t *testing.T) {
+		err := runner.Goto(ctx, runner.Latest()+100)
+		assert.ErrorContains(t, err, "unknown migration version")
+	})
+}
@@ -0,0 +1,61 @@
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"devices-api/internal/migrate"
+	"devices-api/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_SortsAndPairsMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_add_index.up.sql":      {Data: []byte("CREATE INDEX i ON t(c);")},
+		"000002_add_index.down.sql":    {Data: []byte("DROP INDEX i;")},
+		"000001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (c INT);")},
+		"000001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+		"migrations.go":                {Data: []byte("package migrations")},
+	}
+
+	loaded, err := migrate.Load(fsys)
+
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, uint(1), loaded[0].Version)
+	assert.Equal(t, "create_table", loaded[0].Name)
+	assert.Equal(t, "CREATE TABLE t (c INT);", loaded[0].Up)
+	assert.Equal(t, "DROP TABLE t;", loaded[0].Down)
+	assert.Equal(t, uint(2), loaded[1].Version)
+}
+
+func TestLoad_MissingDownFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_create_table.up.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+	}
+
+	_, err := migrate.Load(fsys)
+
+	assert.ErrorContains(t, err, "missing its down file")
+}
+
+func TestLoad_ConflictingNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_create_table.up.sql": {Data: []byte("CREATE TABLE t (c INT);")},
+		"000001_other_name.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+
+	_, err := migrate.Load(fsys)
+
+	assert.ErrorContains(t, err, "conflicting names")
+}
+
+func TestLoad_EmbeddedMigrations(t *testing.T) {
+	loaded, err := migrate.Load(migrations.FS)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+	assert.Equal(t, uint(1), loaded[0].Version)
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+// CachedDeviceRepository decorates a domain.DeviceRepository with a bounded
+// in-memory LRU cache of GetByID results.
+//
+// Update and Delete through the decorator drop the cached copy. Other
+// repositories writing devices on this replica, such as heartbeats and
+// maintenance transitions, are wrapped with NewInvalidatingHeartbeatRepository
+// and NewInvalidatingMaintenanceRepository to do the same. Writes on other
+// replicas become visible once the entry expires after ttl. Reads in a context marked with domain.WithFreshReads
+// always go to the wrapped repository.
+type CachedDeviceRepository struct {
+	domain.DeviceRepository
+
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]*list.Element
+	lru     *list.List
+	// generation changes on every invalidation, so a load that started before
+	// it does not store what it read
+	generation uint64
+
+	loads singleflight.Group
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+// cacheEntry is a cached device and when it expires
+type cacheEntry struct {
+	id      uuid.UUID
+	device  *domain.Device
+	expires time.Time
+}
+
+// NewCachedDeviceRepository caches up to capacity devices of next for ttl each
+func NewCachedDeviceRepository(next domain.DeviceRepository, capacity int, ttl time.Duration) *CachedDeviceRepository {
+	return &CachedDeviceRepository{
+		DeviceRepository: next,
+		capacity:         capacity,
+		ttl:              ttl,
+		now:              time.Now,
+		entries:          make(map[uuid.UUID]*list.Element),
+		lru:              list.New(),
+	}
+}
+
+// GetByID returns a copy of the cached device, loading it on a miss.
+// Concurrent misses for the same device share a single load.
+func (r *CachedDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	if domain.FreshReadsRequired(ctx) {
+		return r.DeviceRepository.GetByID(ctx, id)
+	}
+
+	if device, ok := r.get(id); ok {
+		r.hits.Add(1)
+		return device.Clone(), nil
+	}
+	r.misses.Add(1)
+
+	result := r.loads.DoChan(id.String(), func() (any, error) {
+		r.mu.Lock()
+		generation := r.generation
+		r.mu.Unlock()
+
+		// The load is shared, so one caller giving up must not fail the others
+		device, err := r.DeviceRepository.GetByID(context.WithoutCancel(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+		r.put(id, device, generation)
+		return device, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*domain.Device).Clone(), nil
+	}
+}
+
+// Update saves the device and drops its cached copy. In a transaction the copy
+// is dropped once the transaction ends, so no read before the commit caches the
+// device as it was.
+func (r *CachedDeviceRepository) Update(ctx context.Context, device *domain.Device) error {
+	// Dropped even when the update fails, as it may have been applied anyway
+	defer afterTx(ctx, func() { r.Invalidate(device.ID) })
+	return r.DeviceRepository.Update(ctx, device)
+}
+
+// Delete removes the device and drops its cached copy like Update
+func (r *CachedDeviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer afterTx(ctx, func() { r.Invalidate(id) })
+	return r.DeviceRepository.Delete(ctx, id)
+}
+
+// Invalidate drops the cached copy of a device, if any
+func (r *CachedDeviceRepository) Invalidate(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	if element, ok := r.entries[id]; ok {
+		r.lru.Remove(element)
+		delete(r.entries, id)
+	}
+	// Later misses must not join a load that started before the invalidation
+	r.loads.Forget(id.String())
+}
+
+// Purge drops every cached device. Loads already in flight are not cached
+// when they complete.
+func (r *CachedDeviceRepository) Purge() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	r.entries = make(map[uuid.UUID]*list.Element)
+	r.lru.Init()
+}
+
+// Stats returns the cache counters since startup
+func (r *CachedDeviceRepository) Stats() domain.CacheStats {
+	r.mu.Lock()
+	size := r.lru.Len()
+	r.mu.Unlock()
+
+	return domain.CacheStats{
+		Hits:      r.hits.Load(),
+		Misses:    r.misses.Load(),
+		Evictions: r.evictions.Load(),
+		Size:      size,
+		Capacity:  r.capacity,
+	}
+}
+
+// get returns the cached device unless it is missing or expired
+func (r *CachedDeviceRepository) get(id uuid.UUID) (*domain.Device, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.entries[id]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !r.now().Before(entry.expires) {
+		r.lru.Remove(element)
+		delete(r.entries, id)
+		return nil, false
+	}
+
+	r.lru.MoveToFront(element)
+	return entry.device, true
+}
+
+// put caches device unless the cache was invalidated since generation,
+// evicting the least recently used devices beyond capacity
+func (r *CachedDeviceRepository) put(id uuid.UUID, device *domain.Device, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.generation != generation {
+		return
+	}
+
+	entry := &cacheEntry{id: id, device: device, expires: r.now().Add(r.ttl)}
+	if element, ok := r.entries[id]; ok {
+		element.Value = entry
+		r.lru.MoveToFront(element)
+		return
+	}
+	r.entries[id] = r.lru.PushFront(entry)
+
+	for r.lru.Len() > r.capacity {
+		oldest := r.lru.Back()
+		r.lru.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).id)
+		r.evictions.Add(1)
+	}
+}
+
+// InvalidatingHeartbeatRepository decorates a domain.HeartbeatRepository so
+// that heartbeats and stale-device writes drop the cached copy of their device
+type InvalidatingHeartbeatRepository struct {
+	domain.HeartbeatRepository
+
+	cache domain.DeviceCache
+}
+
+// NewInvalidatingHeartbeatRepository invalidates the devices next writes in cache
+func NewInvalidatingHeartbeatRepository(next domain.HeartbeatRepository, cache domain.DeviceCache) *InvalidatingHeartbeatRepository {
+	return &InvalidatingHeartbeatRepository{
+		HeartbeatRepository: next,
+		cache:               cache,
+	}
+}
+
+// Record saves the heartbeat and drops the cached copy of its device
+func (r *InvalidatingHeartbeatRepository) Record(ctx context.Context, heartbeat *domain.Heartbeat) error {
+	defer afterTx(ctx, func() { r.cache.Invalidate(heartbeat.DeviceID) })
+	return r.HeartbeatRepository.Record(ctx, heartbeat)
+}
+
+// MarkInactive saves the inactive state of the device and drops its cached copy
+func (r *InvalidatingHeartbeatRepository) MarkInactive(ctx context.Context, device *domain.Device, previousState domain.DeviceState, seenBefore time.Time) (bool, error) {
+	defer afterTx(ctx, func() { r.cache.Invalidate(device.ID) })
+	return r.HeartbeatRepository.MarkInactive(ctx, device, previousState, seenBefore)
+}
+
+// InvalidatingMaintenanceRepository decorates a domain.MaintenanceRepository so
+// that maintenance transitions drop the cached copy of their device
+type InvalidatingMaintenanceRepository struct {
+	domain.MaintenanceRepository
+
+	cache domain.DeviceCache
+}
+
+// NewInvalidatingMaintenanceRepository invalidates the devices next writes in cache
+func NewInvalidatingMaintenanceRepository(next domain.MaintenanceRepository, cache domain.DeviceCache) *InvalidatingMaintenanceRepository {
+	return &InvalidatingMaintenanceRepository{
+		MaintenanceRepository: next,
+		cache:                 cache,
+	}
+}
+
+// SaveTransition saves the maintenance and the state of its device, and drops
+// the cached copy of the device
+func (r *InvalidatingMaintenanceRepository) SaveTransition(ctx context.Context, maintenance *domain.Maintenance, device *domain.Device, previousState domain.DeviceState) error {
+	defer afterTx(ctx, func() { r.cache.Invalidate(device.ID) })
+	return r.MaintenanceRepository.SaveTransition(ctx, maintenance, device, previousState)
+}
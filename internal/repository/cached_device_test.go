@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDeviceRepository serves devices from memory and counts GetByID calls.
+// When release is set, GetByID blocks until it is closed.
+type fakeDeviceRepository struct {
+	domain.DeviceRepository
+
+	mu      sync.Mutex
+	devices map[uuid.UUID]*domain.Device
+	reads   atomic.Int32
+	release chan struct{}
+}
+
+func newFakeDeviceRepository(devices ...*domain.Device) *fakeDeviceRepository {
+	repo := &fakeDeviceRepository{devices: make(map[uuid.UUID]*domain.Device)}
+	for _, device := range devices {
+		repo.devices[device.ID] = device.Clone()
+	}
+	return repo
+}
+
+func (f *fakeDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	f.reads.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	device, ok := f.devices[id]
+	if !ok {
+		return nil, domain.ErrDeviceNotFound
+	}
+	return device.Clone(), nil
+}
+
+func (f *fakeDeviceRepository) Update(ctx context.Context, device *domain.Device) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.devices[device.ID] = device.Clone()
+	return nil
+}
+
+func (f *fakeDeviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.devices, id)
+	return nil
+}
+
+func newTestDevice(t *testing.T, name string) *domain.Device {
+	t.Helper()
+	device, err := domain.NewDevice(name, "Apple", domain.WithLabels(domain.Labels{"team": "mobile"}))
+	require.NoError(t, err)
+	return device
+}
+
+func TestCachedDeviceRepository_GetByID_ServesCopiesFromCache(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	repo := NewCachedDeviceRepository(next, 10, time.Minute)
+	ctx := context.Background()
+
+	first, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	first.Name = "Changed"
+	first.Labels["team"] = "changed"
+
+	second, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Kiosk screen", second.Name, "callers cannot change the cached device")
+	assert.Equal(t, "mobile", second.Labels["team"])
+	assert.Equal(t, int32(1), next.reads.Load())
+	assert.Equal(t, domain.CacheStats{Hits: 1, Misses: 1, Size: 1, Capacity: 10}, repo.Stats())
+}
+
+func TestCachedDeviceRepository_GetByID_Expires(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	repo := NewCachedDeviceRepository(next, 10, time.Minute)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	_, err = repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(2), next.reads.Load())
+}
+
+func TestCachedDeviceRepository_GetByID_EvictsLeastRecentlyUsed(t *testing.T) {
+	a, b, c := newTestDevice(t, "Screen A"), newTestDevice(t, "Screen B"), newTestDevice(t, "Screen C")
+	next := newFakeDeviceRepository(a, b, c)
+	repo := NewCachedDeviceRepository(next, 2, time.Minute)
+	ctx := context.Background()
+
+	for _, id := range []uuid.UUID{a.ID, b.ID, a.ID, c.ID} {
+		_, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+	}
+	reads := next.reads.Load()
+
+	// b was used least recently, so c evicted it
+	_, err := repo.GetByID(ctx, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, reads, next.reads.Load())
+	_, err = repo.GetByID(ctx, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, reads+1, next.reads.Load())
+	assert.Equal(t, uint64(2), repo.Stats().Evictions)
+}
+
+func TestCachedDeviceRepository_GetByID_DoesNotCacheNotFound(t *testing.T) {
+	next := newFakeDeviceRepository()
+	repo := NewCachedDeviceRepository(next, 10, time.Minute)
+	id := uuid.New()
+
+	_, err := repo.GetByID(context.Background(), id)
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+
+	require.NoError(t, next.Update(context.Background(), &domain.Device{ID: id, Name: "Late"}))
+	device, err := repo.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "Late", device.Name)
+}
+
+func TestCachedDeviceRepository_GetByID_CollapsesConcurrentMisses(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	next.release = make(chan struct{})
+	repo := NewCachedDeviceRepository(next, 10, time.Minute)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	results := make(chan *domain.Device, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			found, err := repo.GetByID(context.Background(), device.ID)
+			assert.NoError(t, err)
+			results <- found
+		}()
+	}
+
+	require.Eventually(t, func() bool { return repo.Stats().Misses == callers }, time.Second, time.Millisecond)
+	// Give the last callers time to join the load between counting the miss and waiting
+	time.Sleep(20 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), next.reads.Load())
+	for found := range results {
+		assert.Equal(t, device.ID, found.ID)
+	}
+}
+
+func TestCachedDeviceRepository_WritesInvalidate(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	repo := NewCachedDeviceRepository(next, 10, time.Minute)
+	ctx := context.Background()
+
+	cached, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+
+	cached.Name = "Lobby screen"
+	require.NoError(t, repo.Update(ctx, cached))
+	found, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Lobby screen", found.Name)
+
+	require.NoError(t, repo.Delete(ctx, device.ID))
+	_, err = repo.GetByID(ctx, device.ID)
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+}
+
+func TestCachedDeviceRepository_InvalidateDuringLoad(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	next.release = make(chan struct{})
+	repo := NewCachedDeviceRepository(next, 10, time.Minute)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := repo.GetByID(context.Background(), device.ID)
+		assert.NoError(t, err)
+	}()
+	require.Eventually(t, func() bool { return next.reads.Load() == 1 }, time.Second, time.Millisecond)
+
+	// The load read the device before it was invalidated, so it must not be cached
+	repo.Invalidate(device.ID)
+	close(next.release)
+	<-done
+
+	assert.Equal(t, 0, repo.Stats().Size)
+}
+
+func TestCachedDeviceRepository_FreshReadsBypassCache(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	repo := NewCachedDeviceRepository(next, 10, time.Minute)
+	ctx := context.Background()
+
+	_, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+
+	// A write that bypasses the decorator, such as a heartbeat
+	changed := device.Clone()
+	changed.State = domain.DeviceStateMaintenance
+	require.NoError(t, next.Update(ctx, changed))
+
+	found, err := repo.GetByID(domain.WithFreshReads(ctx), device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateMaintenance, found.State)
+
+	repo.Purge()
+	found, err = repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateMaintenance, found.State)
+}
+
+func TestCachedDeviceRepository_InvalidatesWhenTransactionEnds(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	repo := NewCachedDeviceRepository(next, 10, time.Minute)
+
+	_, err := repo.GetByID(context.Background(), device.ID)
+	require.NoError(t, err)
+
+	// Until the transaction commits, other readers still see the stored device
+	state := &txState{}
+	ctx := context.WithValue(context.Background(), txKey{}, state)
+	require.NoError(t, repo.Update(ctx, device.Clone()))
+	assert.Equal(t, 1, repo.Stats().Size)
+
+	for _, f := range state.afterEnd {
+		f()
+	}
+	assert.Equal(t, 0, repo.Stats().Size)
+}
+
+// fakeHeartbeatRepository records heartbeats and stale devices in the devices of a fakeDeviceRepository
+type fakeHeartbeatRepository struct {
+	domain.HeartbeatRepository
+
+	devices *fakeDeviceRepository
+}
+
+func (f *fakeHeartbeatRepository) Record(ctx context.Context, heartbeat *domain.Heartbeat) error {
+	f.devices.mu.Lock()
+	defer f.devices.mu.Unlock()
+	seenAt := heartbeat.SeenAt
+	f.devices.devices[heartbeat.DeviceID].LastSeenAt = &seenAt
+	return nil
+}
+
+func (f *fakeHeartbeatRepository) MarkInactive(ctx context.Context, device *domain.Device, previousState domain.DeviceState, seenBefore time.Time) (bool, error) {
+	return true, f.devices.Update(ctx, device)
+}
+
+// fakeMaintenanceRepository saves the device of a transition in a fakeDeviceRepository
+type fakeMaintenanceRepository struct {
+	domain.MaintenanceRepository
+
+	devices *fakeDeviceRepository
+}
+
+func (f *fakeMaintenanceRepository) SaveTransition(ctx context.Context, maintenance *domain.Maintenance, device *domain.Device, previousState domain.DeviceState) error {
+	return f.devices.Update(ctx, device)
+}
+
+func TestInvalidatingHeartbeatRepository_WritesInvalidate(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	cached := NewCachedDeviceRepository(next, 10, time.Minute)
+	repo := NewInvalidatingHeartbeatRepository(&fakeHeartbeatRepository{devices: next}, cached)
+	ctx := context.Background()
+
+	_, err := cached.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+
+	heartbeat, err := domain.NewHeartbeat(device.ID, time.Now(), nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.Record(ctx, heartbeat))
+	found, err := cached.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.LastSeenAt)
+
+	previous := found.State
+	changed, err := found.Deactivate()
+	require.NoError(t, err)
+	require.True(t, changed)
+	saved, err := repo.MarkInactive(ctx, found, previous, time.Now())
+	require.NoError(t, err)
+	require.True(t, saved)
+	found, err = cached.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateInactive, found.State)
+}
+
+func TestInvalidatingMaintenanceRepository_SaveTransitionInvalidates(t *testing.T) {
+	device := newTestDevice(t, "Kiosk screen")
+	next := newFakeDeviceRepository(device)
+	cached := NewCachedDeviceRepository(next, 10, time.Minute)
+	repo := NewInvalidatingMaintenanceRepository(&fakeMaintenanceRepository{devices: next}, cached)
+	ctx := context.Background()
+
+	found, err := cached.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+
+	maintenance, err := domain.NewMaintenance(device.ID, domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	require.NoError(t, err)
+	previous := found.State
+	require.NoError(t, maintenance.Start(found, time.Now()))
+	require.NoError(t, repo.SaveTransition(ctx, maintenance, found, previous))
+
+	found, err = cached.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateMaintenance, found.State)
+}
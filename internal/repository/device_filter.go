@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"devices-api/internal/domain"
+)
+
+// whereBuilder accumulates SQL conditions and their positional arguments
+type whereBuilder struct {
+	conditions []string
+	args       []any
+}
+
+// arg registers a query argument and returns its placeholder
+func (b *whereBuilder) arg(value any) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *whereBuilder) add(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+// clause returns the WHERE clause, or an empty string when there are no conditions
+func (b *whereBuilder) clause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// buildDeviceFilter translates a DeviceFilter into a WHERE clause and arguments.
+// Columns are qualified with the table aliases of deviceSource.
+func buildDeviceFilter(filter domain.DeviceFilter) (string, []any) {
+	var b whereBuilder
+
+	if filter.Brand != "" {
+		b.add("d.brand_id IN (" + brandMatchQuery(b.arg(filter.Brand)) + ")")
+	}
+	if filter.State != "" {
+		b.add("d.state = " + b.arg(filter.State))
+	}
+	for _, attr := range filter.Attributes {
+		b.add(attributeCondition(&b, attr))
+	}
+	for _, requirement := range filter.Labels {
+		b.add(labelCondition(&b, requirement))
+	}
+	if filter.LocationID != nil {
+		b.add("d.location_id IN (" + locationSubtreeQuery(b.arg(*filter.LocationID)) + ")")
+	}
+	if filter.ModelID != nil {
+		b.add("d.model_id = " + b.arg(*filter.ModelID))
+	}
+	if filter.Category != "" {
+		b.add("m.category = " + b.arg(filter.Category))
+	}
+
+	return b.clause(), b.args
+}
+
+// attributeCondition builds the condition for a single attribute filter.
+// Equality uses JSONB containment so it can be served by the GIN index.
+func attributeCondition(b *whereBuilder, f domain.AttributeFilter) string {
+	switch f.Operator {
+	case domain.AttributeOpExists:
+		return "d.attributes ? " + b.arg(f.Key) + "::text"
+	case domain.AttributeOpEqual:
+		return attributeEquals(b, f)
+	case domain.AttributeOpNotEqual:
+		return "NOT " + attributeEquals(b, f)
+	default:
+		// Non-numeric values never match a range comparison instead of failing the cast.
+		// The value was checked to be numeric by domain validation.
+		value, _ := strconv.ParseFloat(f.Value, 64)
+		key := b.arg(f.Key)
+		return "(CASE WHEN jsonb_typeof(d.attributes -> " + key + "::text) = 'number'" +
+			" THEN (d.attributes ->> " + key + "::text)::numeric END) " +
+			string(f.Operator) + " " + b.arg(value) + "::numeric"
+	}
+}
+
+// attributeEquals matches the value as a string, and also as a number or
+// boolean when it parses as one, since query strings carry no type information
+func attributeEquals(b *whereBuilder, f domain.AttributeFilter) string {
+	candidates := []any{f.Value}
+
+	var number float64
+	if err := json.Unmarshal([]byte(f.Value), &number); err == nil {
+		candidates = append(candidates, json.Number(f.Value))
+	}
+	if f.Value == "true" || f.Value == "false" {
+		candidates = append(candidates, f.Value == "true")
+	}
+
+	conditions := make([]string, 0, len(candidates))
+	for _, value := range candidates {
+		doc, _ := json.Marshal(map[string]any{f.Key: value})
+		conditions = append(conditions, "d.attributes @> "+b.arg(string(doc))+"::jsonb")
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// labelCondition builds the condition for a single label selector requirement.
+// As in Kubernetes, != and notin also match devices without the label.
+func labelCondition(b *whereBuilder, r domain.LabelRequirement) string {
+	switch r.Operator {
+	case domain.SelectorOpEquals:
+		doc, _ := json.Marshal(map[string]string{r.Key: r.Values[0]})
+		return "d.labels @> " + b.arg(string(doc)) + "::jsonb"
+	case domain.SelectorOpNotEquals:
+		doc, _ := json.Marshal(map[string]string{r.Key: r.Values[0]})
+		return "NOT d.labels @> " + b.arg(string(doc)) + "::jsonb"
+	case domain.SelectorOpIn:
+		return "d.labels ->> " + b.arg(r.Key) + "::text = ANY(" + b.arg(r.Values) + "::text[])"
+	case domain.SelectorOpNotIn:
+		return "COALESCE(d.labels ->> " + b.arg(r.Key) + "::text <> ALL(" + b.arg(r.Values) + "::text[]), true)"
+	case domain.SelectorOpDoesNotExist:
+		return "NOT d.labels ? " + b.arg(r.Key) + "::text"
+	default:
+		return "d.labels ? " + b.arg(r.Key) + "::text"
+	}
+}
+
+// locationSubtreeQuery selects the IDs of a location and all of its descendants
+func locationSubtreeQuery(rootPlaceholder string) string {
+	return "WITH RECURSIVE subtree AS (" +
+		"SELECT id FROM locations WHERE id = " + rootPlaceholder +
+		" UNION ALL SELECT l.id FROM locations l JOIN subtree s ON l.parent_id = s.id" +
+		") SELECT id FROM subtree"
+}
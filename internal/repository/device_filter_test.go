@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDeviceFilter(t *testing.T) {
+	where, args := buildDeviceFilter(domain.DeviceFilter{
+		Brand: "Apple",
+		State: domain.DeviceStateActive,
+		Attributes: []domain.AttributeFilter{
+			{Key: "os", Operator: domain.AttributeOpEqual, Value: "ios"},
+			{Key: "ram_gb", Operator: domain.AttributeOpGreaterEqual, Value: "16"},
+			{Key: "warranty", Operator: domain.AttributeOpExists},
+		},
+	})
+
+	assert.Equal(t, "WHERE d.brand_id IN (SELECT id FROM brands WHERE name_key = lower(btrim($1::text))"+
+		" UNION SELECT brand_id FROM brand_aliases WHERE alias_key = lower(btrim($1::text)))"+
+		" AND d.state = $2"+
+		" AND (d.attributes @> $3::jsonb)"+
+		" AND (CASE WHEN jsonb_typeof(d.attributes -> $4::text) = 'number' THEN (d.attributes ->> $4::text)::numeric END) >= $5::numeric"+
+		" AND d.attributes ? $6::text", where)
+	assert.Equal(t, []any{"Apple", domain.DeviceStateActive, `{"os":"ios"}`, "ram_gb", 16.0, "warranty"}, args)
+}
+
+func TestBuildDeviceFilter_TypedEquality(t *testing.T) {
+	where, args := buildDeviceFilter(domain.DeviceFilter{
+		Attributes: []domain.AttributeFilter{
+			{Key: "ram_gb", Operator: domain.AttributeOpNotEqual, Value: "16"},
+			{Key: "esim", Operator: domain.AttributeOpEqual, Value: "true"},
+		},
+	})
+
+	assert.Equal(t, "WHERE NOT (d.attributes @> $1::jsonb OR d.attributes @> $2::jsonb)"+
+		" AND (d.attributes @> $3::jsonb OR d.attributes @> $4::jsonb)", where)
+	assert.Equal(t, []any{`{"ram_gb":"16"}`, `{"ram_gb":16}`, `{"esim":"true"}`, `{"esim":true}`}, args)
+}
+
+func TestBuildDeviceFilter_Empty(t *testing.T) {
+	where, args := buildDeviceFilter(domain.DeviceFilter{})
+
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+}
+
+func TestBuildDeviceFilter_LabelSelector(t *testing.T) {
+	selector, err := domain.ParseLabelSelector("team=mobile,env!=prod,tier in (gold,silver),region notin (eu),gpu,!deprecated")
+	assert.NoError(t, err)
+
+	where, args := buildDeviceFilter(domain.DeviceFilter{Brand: "Apple", Labels: selector})
+
+	assert.Equal(t, "WHERE d.brand_id IN ("+brandMatchQuery("$1")+")"+
+		" AND d.labels @> $2::jsonb"+
+		" AND NOT d.labels @> $3::jsonb"+
+		" AND d.labels ->> $4::text = ANY($5::text[])"+
+		" AND COALESCE(d.labels ->> $6::text <> ALL($7::text[]), true)"+
+		" AND d.labels ? $8::text"+
+		" AND NOT d.labels ? $9::text", where)
+	assert.Equal(t, []any{
+		"Apple", `{"team":"mobile"}`, `{"env":"prod"}`,
+		"tier", []string{"gold", "silver"}, "region", []string{"eu"},
+		"gpu", "deprecated",
+	}, args)
+}
+
+func TestBuildDeviceFilter_LocationSubtree(t *testing.T) {
+	locationID := uuid.New()
+
+	where, args := buildDeviceFilter(domain.DeviceFilter{State: domain.DeviceStateActive, LocationID: &locationID})
+
+	assert.Equal(t, "WHERE d.state = $1 AND d.location_id IN ("+
+		"WITH RECURSIVE subtree AS (SELECT id FROM locations WHERE id = $2"+
+		" UNION ALL SELECT l.id FROM locations l JOIN subtree s ON l.parent_id = s.id"+
+		") SELECT id FROM subtree)", where)
+	assert.Equal(t, []any{domain.DeviceStateActive, locationID}, args)
+}
+
+func TestBuildDeviceFilter_Model(t *testing.T) {
+	modelID := uuid.New()
+
+	where, args := buildDeviceFilter(domain.DeviceFilter{ModelID: &modelID, Category: domain.DeviceCategoryPhone})
+
+	assert.Equal(t, "WHERE d.model_id = $1 AND m.category = $2", where)
+	assert.Equal(t, []any{modelID, domain.DeviceCategoryPhone}, args)
+}
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgUniqueViolation is the SQLSTATE raised when a unique constraint is violated
+const pgUniqueViolation = "23505"
+
+// brandSelect selects brands with their aliases and the number of devices referencing them
+const brandSelect = `
+	SELECT b.id, b.name, b.created_at,
+		COALESCE((SELECT array_agg(a.alias ORDER BY a.alias) FROM brand_aliases a WHERE a.brand_id = b.id), '{}'),
+		(SELECT count(*) FROM devices d WHERE d.brand_id = b.id)
+	FROM brands b`
+
+// PostgresBrandRepository implements the domain.BrandRepository interface
+type PostgresBrandRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresBrandRepository creates a new PostgreSQL brand repository
+func NewPostgresBrandRepository(pool *pgxpool.Pool) *PostgresBrandRepository {
+	return &PostgresBrandRepository{
+		pool: pool,
+	}
+}
+
+// Resolve finds the brand whose name or alias matches name, ignoring case
+func (r *PostgresBrandRepository) Resolve(ctx context.Context, name string) (*domain.Brand, error) {
+	query := brandSelect + `
+		WHERE b.id IN (` + brandMatchQuery("$1") + `)
+	`
+
+	brand, err := scanBrand(conn(ctx, r.pool).QueryRow(ctx, query, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBrandNotFound
+		}
+		return nil, fmt.Errorf("failed to resolve brand: %w", err)
+	}
+
+	return brand, nil
+}
+
+// GetByID retrieves a brand with its aliases and device count
+func (r *PostgresBrandRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Brand, error) {
+	query := brandSelect + `
+		WHERE b.id = $1
+	`
+
+	brand, err := scanBrand(conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBrandNotFound
+		}
+		return nil, fmt.Errorf("failed to get brand: %w", err)
+	}
+
+	return brand, nil
+}
+
+// List retrieves all brands with their aliases and device counts, ordered by name
+func (r *PostgresBrandRepository) List(ctx context.Context) ([]*domain.Brand, error) {
+	query := brandSelect + `
+		ORDER BY b.name_key, b.id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list brands: %w", err)
+	}
+	defer rows.Close()
+
+	var brands []*domain.Brand
+	for rows.Next() {
+		brand, err := scanBrand(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan brand: %w", err)
+		}
+		brands = append(brands, brand)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating brands: %w", err)
+	}
+
+	return brands, nil
+}
+
+// AddAlias registers alias as an alternative name for the brand
+func (r *PostgresBrandRepository) AddAlias(ctx context.Context, id uuid.UUID, alias string) error {
+	query := `INSERT INTO brand_aliases (alias, brand_id) VALUES (btrim($2::text), $1)`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, id, alias); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgUniqueViolation:
+				return domain.NewValidationError("alias", "already in use")
+			case pgForeignKeyViolation:
+				return domain.ErrBrandNotFound
+			}
+		}
+		return fmt.Errorf("failed to add brand alias: %w", err)
+	}
+
+	return nil
+}
+
+// ensureBrand resolves name through the brand catalog, creating the brand when
+// it is unknown, and returns its ID and canonical name.
+// Callers run it in the transaction of the write that references the brand,
+// so a failed write does not leave an unused brand behind.
+func ensureBrand(ctx context.Context, db queryRower, name string) (uuid.UUID, string, error) {
+	query := `
+		WITH existing AS (
+			SELECT id, name FROM brands WHERE id IN (` + brandMatchQuery("$1") + `)
+		), inserted AS (
+			INSERT INTO brands (id, name)
+			SELECT $2, btrim($1::text)
+			WHERE NOT EXISTS (SELECT 1 FROM existing)
+			ON CONFLICT (name_key) DO NOTHING
+			RETURNING id, name
+		)
+		SELECT id, name FROM existing
+		UNION ALL
+		SELECT id, name FROM inserted
+		LIMIT 1`
+
+	var (
+		id        uuid.UUID
+		canonical string
+	)
+	err := db.QueryRow(ctx, query, name, uuid.New()).Scan(&id, &canonical)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// A concurrent insert of the same brand won the conflict;
+		// the next statement sees it as an existing brand
+		err = db.QueryRow(ctx, query, name, uuid.New()).Scan(&id, &canonical)
+	}
+	if err != nil {
+		return uuid.Nil, "", fmt.Errorf("failed to resolve brand: %w", err)
+	}
+	return id, canonical, nil
+}
+
+// queryRower is implemented by pools and transactions
+type queryRower interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// brandMatchQuery selects the ID of the brand whose name or alias matches
+// the argument at placeholder, ignoring case and surrounding spaces
+func brandMatchQuery(placeholder string) string {
+	return "SELECT id FROM brands WHERE name_key = lower(btrim(" + placeholder + "::text))" +
+		" UNION SELECT brand_id FROM brand_aliases WHERE alias_key = lower(btrim(" + placeholder + "::text))"
+}
+
+// scanBrand scans a single row selected with brandSelect
+func scanBrand(row pgx.Row) (*domain.Brand, error) {
+	var brand domain.Brand
+	err := row.Scan(
+		&brand.ID,
+		&brand.Name,
+		&brand.CreatedAt,
+		&brand.Aliases,
+		&brand.DeviceCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
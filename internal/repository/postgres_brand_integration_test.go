@@ -0,0 +1,102 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupBrandTest cleans the database and returns both repositories
+func setupBrandTest(t *testing.T) (*repository.PostgresBrandRepository, *repository.PostgresDeviceRepository) {
+	deviceRepo := setupTest(t)
+	return repository.NewPostgresBrandRepository(pgContainer.GetPool()), deviceRepo
+}
+
+func TestPostgresBrandRepository_VariantSpellings(t *testing.T) {
+	repo, deviceRepo := setupBrandTest(t)
+	ctx := context.Background()
+
+	var brandIDs []uuid.UUID
+	for _, brand := range []string{"Apple", "apple", "APPLE "} {
+		device, err := domain.NewDevice("iPhone 15", brand)
+		require.NoError(t, err)
+		require.NoError(t, deviceRepo.Create(ctx, device))
+		assert.Equal(t, "Apple", device.Brand)
+		brandIDs = append(brandIDs, device.BrandID)
+	}
+	assert.Equal(t, brandIDs[0], brandIDs[1])
+	assert.Equal(t, brandIDs[0], brandIDs[2])
+
+	devices, err := deviceRepo.ListByBrand(ctx, "apple", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, devices, 3)
+
+	brands, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, brands, 1)
+	assert.Equal(t, "Apple", brands[0].Name)
+	assert.Equal(t, 3, brands[0].DeviceCount)
+	assert.Empty(t, brands[0].Aliases)
+}
+
+func TestPostgresBrandRepository_Alias(t *testing.T) {
+	repo, deviceRepo := setupBrandTest(t)
+	ctx := context.Background()
+
+	laptop, err := domain.NewDevice("EliteBook", "Hewlett-Packard")
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, laptop))
+
+	require.NoError(t, repo.AddAlias(ctx, laptop.BrandID, " HP "))
+
+	brand, err := repo.Resolve(ctx, "hp")
+	require.NoError(t, err)
+	assert.Equal(t, laptop.BrandID, brand.ID)
+	assert.Equal(t, []string{"HP"}, brand.Aliases)
+
+	printer, err := domain.NewDevice("LaserJet", "hp")
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, printer))
+	assert.Equal(t, laptop.BrandID, printer.BrandID)
+	assert.Equal(t, "Hewlett-Packard", printer.Brand)
+
+	devices, err := deviceRepo.Search(ctx, domain.DeviceFilter{Brand: "HP"}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, devices, 2)
+
+	// Aliases are unique regardless of case
+	err = repo.AddAlias(ctx, laptop.BrandID, "hp")
+	assert.True(t, domain.IsValidationError(err))
+
+	err = repo.AddAlias(ctx, uuid.New(), "Compaq")
+	assert.ErrorIs(t, err, domain.ErrBrandNotFound)
+
+	_, err = repo.Resolve(ctx, "Dell")
+	assert.ErrorIs(t, err, domain.ErrBrandNotFound)
+}
+
+func TestPostgresBrandRepository_FailedDeviceWriteCreatesNoBrand(t *testing.T) {
+	repo, deviceRepo := setupBrandTest(t)
+	ctx := context.Background()
+
+	device, err := domain.NewDevice("iPhone 15", "Apple")
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, device))
+
+	// Same ID, so the insert fails after the new brand was resolved
+	duplicate := *device
+	duplicate.Brand = "Samsung"
+	err = deviceRepo.Create(ctx, &duplicate)
+	require.ErrorIs(t, err, domain.ErrDeviceAlreadyExists)
+
+	brands, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, brands, 1)
+	assert.Equal(t, "Apple", brands[0].Name)
+}
@@ -4,65 +4,138 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"devices-api/internal/domain"
+	"devices-api/pkg/database"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// PostgresDeviceRepository implements the domain.DeviceRepository interface
+// deviceColumns is the column list shared by every device SELECT from deviceSource
+const deviceColumns = "d.id, d.name, b.name, d.brand_id, COALESCE(d.serial_number, ''), d.state, d.created_at," +
+	" d.attributes, d.labels, d.location_id, d.model_id, COALESCE(m.category, ''), d.purchase_date, d.warranty_end, d.eol_date," +
+	" d.last_seen_at, d.reported"
+
+// deviceSource joins devices with their brand so reads return the canonical brand name,
+// and with their model so reads return the category
+const deviceSource = "devices d JOIN brands b ON b.id = d.brand_id LEFT JOIN models m ON m.id = d.model_id"
+
+// deviceVersionSource is deviceSource with every device as it was at the time
+// in placeholder, including devices deleted since. Heartbeat fields are not
+// versioned, so they read as never reported.
+func deviceVersionSource(placeholder string) string {
+	return "(SELECT v.*, NULL::timestamptz AS last_seen_at, '{}'::jsonb AS reported FROM device_versions v" +
+		" WHERE tstzrange(v.valid_from, v.valid_to, '[)') @> " + placeholder + "::timestamptz) d" +
+		" JOIN brands b ON b.id = d.brand_id LEFT JOIN models m ON m.id = d.model_id"
+}
+
+// filteredDevices returns the source and WHERE clause selecting the devices of
+// filter, from their versions when filter.AsOf is set. The time is the last argument.
+func filteredDevices(filter domain.DeviceFilter) (source, where string, args []any) {
+	where, args = buildDeviceFilter(filter)
+	if filter.AsOf == nil {
+		return deviceSource, where, args
+	}
+	args = append(args, *filter.AsOf)
+	return deviceVersionSource("$" + strconv.Itoa(len(args))), where, args
+}
+
+// PostgresDeviceRepository implements the domain.DeviceRepository interface.
+// List and search queries go to the read replica while it is healthy; writes,
+// lookups of a single device and reads in a context marked with
+// domain.WithFreshReads, such as reads in a transaction, always go to the primary.
 type PostgresDeviceRepository struct {
-	pool *pgxpool.Pool
+	pool    *pgxpool.Pool
+	replica *database.Replica
+}
+
+// DeviceRepositoryOption configures a PostgresDeviceRepository
+type DeviceRepositoryOption func(*PostgresDeviceRepository)
+
+// WithReadReplica routes list and search queries to replica while it is healthy
+func WithReadReplica(replica *database.Replica) DeviceRepositoryOption {
+	return func(r *PostgresDeviceRepository) {
+		r.replica = replica
+	}
 }
 
 // NewPostgresDeviceRepository creates a new PostgreSQL device repository
-func NewPostgresDeviceRepository(pool *pgxpool.Pool) *PostgresDeviceRepository {
-	return &PostgresDeviceRepository{
+func NewPostgresDeviceRepository(pool *pgxpool.Pool, opts ...DeviceRepositoryOption) *PostgresDeviceRepository {
+	r := &PostgresDeviceRepository{
 		pool: pool,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// Create persists a new device
+// Create persists a new device.
+// The brand name is resolved through the brand catalog, creating the brand if it is unknown,
+// and device.Brand and device.BrandID are set to the canonical brand.
+// A brand is only created when the device is saved with it.
+// A serial number already used for the brand is reported as domain.ErrDeviceAlreadyExists.
 func (r *PostgresDeviceRepository) Create(ctx context.Context, device *domain.Device) error {
-	query := `
-		INSERT INTO devices (id, name, brand, state, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-	`
+	var brandID uuid.UUID
+	var brandName string
 
-	_, err := r.pool.Exec(ctx, query,
-		device.ID,
-		device.Name,
-		device.Brand,
-		device.State,
-		device.CreatedAt,
-	)
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		var err error
+		brandID, brandName, err = ensureBrand(ctx, tx, device.Brand)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO devices (id, name, brand_id, serial_number, state, created_at, attributes, labels, location_id, model_id,
+				purchase_date, warranty_end, eol_date)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		`
+
+		_, err = tx.Exec(ctx, query,
+			device.ID,
+			device.Name,
+			brandID,
+			device.SerialNumber,
+			device.State,
+			device.CreatedAt,
+			attributesOrEmpty(device.Attributes),
+			labelsOrEmpty(device.Labels),
+			device.LocationID,
+			device.ModelID,
+			device.PurchaseDate,
+			device.WarrantyEnd,
+			device.EOLDate,
+		)
+		return err
+	})
 
 	if err != nil {
+		if conflict := deviceConflict(err); conflict != nil {
+			return conflict
+		}
 		return fmt.Errorf("failed to create device: %w", err)
 	}
 
+	device.BrandID, device.Brand = brandID, brandName
 	return nil
 }
 
 // GetByID retrieves a device by its unique identifier
 func (r *PostgresDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
 	query := `
-		SELECT id, name, brand, state, created_at
-		FROM devices
-		WHERE id = $1
+		SELECT ` + deviceColumns + `
+		FROM ` + deviceSource + `
+		WHERE d.id = $1
 	`
 
-	var device domain.Device
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&device.ID,
-		&device.Name,
-		&device.Brand,
-		&device.State,
-		&device.CreatedAt,
-	)
-
+	device, err := scanDevice(conn(ctx, r.pool).QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrDeviceNotFound
@@ -70,88 +143,303 @@ func (r *PostgresDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*
 		return nil, fmt.Errorf("failed to get device: %w", err)
 	}
 
-	return &device, nil
+	return device, nil
+}
+
+// GetByIDAsOf retrieves the version of a device that was current at the given time
+func (r *PostgresDeviceRepository) GetByIDAsOf(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Device, error) {
+	query := `
+		SELECT ` + deviceColumns + `
+		FROM ` + deviceVersionSource("$2") + `
+		WHERE d.id = $1
+	`
+
+	device, err := scanDevice(conn(ctx, r.pool).QueryRow(ctx, query, id, at))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to get device version: %w", err)
+	}
+
+	return device, nil
+}
+
+// GetByIDForUpdate retrieves a device like GetByID and locks its row until the
+// transaction of ctx ends. Outside a transaction the lock is released at once.
+func (r *PostgresDeviceRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	query := `
+		SELECT ` + deviceColumns + `
+		FROM ` + deviceSource + `
+		WHERE d.id = $1
+		FOR UPDATE OF d
+	`
+
+	device, err := scanDevice(conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to lock device: %w", err)
+	}
+
+	return device, nil
+}
+
+// GetBySerial retrieves the device with serialNumber whose brand matches brand by name or alias
+func (r *PostgresDeviceRepository) GetBySerial(ctx context.Context, brand, serialNumber string) (*domain.Device, error) {
+	query := `
+		SELECT ` + deviceColumns + `
+		FROM ` + deviceSource + `
+		WHERE d.brand_id IN (` + brandMatchQuery("$1") + `) AND d.serial_number = $2
+	`
+
+	device, err := scanDevice(conn(ctx, r.pool).QueryRow(ctx, query, brand, serialNumber))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to get device by serial number: %w", err)
+	}
+
+	return device, nil
 }
 
 // List retrieves all devices with optional pagination
 func (r *PostgresDeviceRepository) List(ctx context.Context, limit, offset int) ([]*domain.Device, error) {
 	query := `
-		SELECT id, name, brand, state, created_at
-		FROM devices
-		ORDER BY created_at DESC
+		SELECT ` + deviceColumns + `
+		FROM ` + deviceSource + `
+		ORDER BY d.created_at DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	devices, err := r.queryDevices(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list devices: %w", err)
 	}
-	defer rows.Close()
-
-	return r.scanDevices(rows)
+	return devices, nil
 }
 
 // ListByBrand retrieves devices filtered by brand
 func (r *PostgresDeviceRepository) ListByBrand(ctx context.Context, brand string, limit, offset int) ([]*domain.Device, error) {
 	query := `
-		SELECT id, name, brand, state, created_at
-		FROM devices
-		WHERE brand = $1
-		ORDER BY created_at DESC
+		SELECT ` + deviceColumns + `
+		FROM ` + deviceSource + `
+		WHERE d.brand_id IN (` + brandMatchQuery("$1") + `)
+		ORDER BY d.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.pool.Query(ctx, query, brand, limit, offset)
+	devices, err := r.queryDevices(ctx, query, brand, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list devices by brand: %w", err)
 	}
-	defer rows.Close()
-
-	return r.scanDevices(rows)
+	return devices, nil
 }
 
 // ListByState retrieves devices filtered by state
 func (r *PostgresDeviceRepository) ListByState(ctx context.Context, state domain.DeviceState, limit, offset int) ([]*domain.Device, error) {
 	query := `
-		SELECT id, name, brand, state, created_at
-		FROM devices
-		WHERE state = $1
-		ORDER BY created_at DESC
+		SELECT ` + deviceColumns + `
+		FROM ` + deviceSource + `
+		WHERE d.state = $1
+		ORDER BY d.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.pool.Query(ctx, query, state, limit, offset)
+	devices, err := r.queryDevices(ctx, query, state, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list devices by state: %w", err)
 	}
-	defer rows.Close()
-
-	return r.scanDevices(rows)
+	return devices, nil
 }
 
-// Update modifies an existing device
-func (r *PostgresDeviceRepository) Update(ctx context.Context, device *domain.Device) error {
+// Search retrieves devices matching every criterion in filter
+func (r *PostgresDeviceRepository) Search(ctx context.Context, filter domain.DeviceFilter, limit, offset int) ([]*domain.Device, error) {
+	source, where, args := filteredDevices(filter)
+	args = append(args, limit, offset)
+
 	query := `
-		UPDATE devices
-		SET name = $2, brand = $3, state = $4
-		WHERE id = $1
-	`
+		SELECT ` + deviceColumns + `
+		FROM ` + source + `
+		` + where + `
+		ORDER BY d.created_at DESC
+		LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
 
-	result, err := r.pool.Exec(ctx, query,
-		device.ID,
-		device.Name,
-		device.Brand,
-		device.State,
-	)
+	devices, err := r.queryDevices(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search devices: %w", err)
+	}
+	return devices, nil
+}
 
+// Aggregate counts the devices matching the query's filter in groups and
+// averages their age, as of the filter's AsOf when set. It runs on the read
+// replica like lists.
+func (r *PostgresDeviceRepository) Aggregate(ctx context.Context, query domain.DeviceStatsQuery) ([]domain.DeviceStatsGroup, error) {
+	source, where, args := filteredDevices(query.Filter)
+	// Ages are measured at the time the devices are counted at
+	reference := "now()"
+	if query.Filter.AsOf != nil {
+		reference = "$" + strconv.Itoa(len(args)) + "::timestamptz"
+	}
+
+	columns := make([]string, 0, len(query.GroupBy)+3)
+	for _, dimension := range query.GroupBy {
+		columns = append(columns, statsDimensionColumn(dimension))
+	}
+	if query.Interval != "" {
+		args = append(args, string(query.Interval))
+		columns = append(columns, "date_trunc($"+strconv.Itoa(len(args))+", d.created_at, 'UTC')")
+	}
+	groups := len(columns)
+	ordinals := make([]string, groups)
+	for i := range ordinals {
+		ordinals[i] = strconv.Itoa(i + 1)
+	}
+	columns = append(columns, "COUNT(*)", "EXTRACT(EPOCH FROM AVG("+reference+" - d.created_at))::float8")
+
+	sql := `
+		SELECT ` + strings.Join(columns, ", ") + `
+		FROM ` + source + `
+		` + where
+	if groups > 0 {
+		sql += `
+		GROUP BY ` + strings.Join(ordinals, ", ") + `
+		ORDER BY ` + strings.Join(ordinals, ", ")
+	}
+
+	var stats []domain.DeviceStatsGroup
+	err := r.read(ctx, func(db dbtx) error {
+		rows, err := db.Query(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		stats = nil
+		for rows.Next() {
+			group, err := scanStatsGroup(rows, query)
+			if err != nil {
+				return err
+			}
+			// Without grouping, an empty result still has its single row
+			if group.Count > 0 {
+				stats = append(stats, group)
+			}
+		}
+		return rows.Err()
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update device: %w", err)
+		return nil, fmt.Errorf("failed to compute device stats: %w", err)
 	}
 
-	if result.RowsAffected() == 0 {
-		return domain.ErrDeviceNotFound
+	return stats, nil
+}
+
+// statsDimensionColumn is the grouped expression of a dimension, NULL for devices without a value
+func statsDimensionColumn(dimension domain.StatsDimension) string {
+	switch dimension {
+	case domain.StatsByBrand:
+		return "b.name"
+	case domain.StatsByState:
+		return "d.state"
+	case domain.StatsByCategory:
+		return "m.category"
+	default:
+		return "d.location_id::text"
+	}
+}
+
+// scanStatsGroup scans a row selected by Aggregate
+func scanStatsGroup(row pgx.Row, query domain.DeviceStatsQuery) (domain.DeviceStatsGroup, error) {
+	keys := make([]*string, len(query.GroupBy))
+	var period *time.Time
+	var group domain.DeviceStatsGroup
+	var averageAge *float64
+
+	dest := make([]any, 0, len(keys)+3)
+	for i := range keys {
+		dest = append(dest, &keys[i])
+	}
+	if query.Interval != "" {
+		dest = append(dest, &period)
+	}
+	dest = append(dest, &group.Count, &averageAge)
+	if err := row.Scan(dest...); err != nil {
+		return domain.DeviceStatsGroup{}, err
+	}
+
+	group.Keys = make(map[domain.StatsDimension]string, len(keys))
+	for i, key := range keys {
+		if key != nil {
+			group.Keys[query.GroupBy[i]] = *key
+		}
+	}
+	if period != nil {
+		utc := period.UTC()
+		group.Period = &utc
+	}
+	if averageAge != nil {
+		group.AverageAge = time.Duration(*averageAge * float64(time.Second))
+	}
+	return group, nil
+}
+
+// Update modifies an existing device, resolving its brand like Create
+func (r *PostgresDeviceRepository) Update(ctx context.Context, device *domain.Device) error {
+	var brandID uuid.UUID
+	var brandName string
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		var err error
+		brandID, brandName, err = ensureBrand(ctx, tx, device.Brand)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE devices
+			SET name = $2, brand_id = $3, serial_number = NULLIF($4, ''), state = $5, attributes = $6, labels = $7,
+				location_id = $8, model_id = $9, purchase_date = $10, warranty_end = $11, eol_date = $12
+			WHERE id = $1
+		`
+
+		result, err := tx.Exec(ctx, query,
+			device.ID,
+			device.Name,
+			brandID,
+			device.SerialNumber,
+			device.State,
+			attributesOrEmpty(device.Attributes),
+			labelsOrEmpty(device.Labels),
+			device.LocationID,
+			device.ModelID,
+			device.PurchaseDate,
+			device.WarrantyEnd,
+			device.EOLDate,
+		)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return domain.ErrDeviceNotFound
+		}
+		return nil
+	})
+
+	if err != nil {
+		if errors.Is(err, domain.ErrDeviceNotFound) {
+			return err
+		}
+		if conflict := deviceConflict(err); conflict != nil {
+			return conflict
+		}
+		return fmt.Errorf("failed to update device: %w", err)
 	}
 
+	device.BrandID, device.Brand = brandID, brandName
 	return nil
 }
 
@@ -159,7 +447,7 @@ func (r *PostgresDeviceRepository) Update(ctx context.Context, device *domain.De
 func (r *PostgresDeviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM devices WHERE id = $1`
 
-	result, err := r.pool.Exec(ctx, query, id)
+	result, err := conn(ctx, r.pool).Exec(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete device: %w", err)
 	}
@@ -176,7 +464,7 @@ func (r *PostgresDeviceRepository) ExistsByID(ctx context.Context, id uuid.UUID)
 	query := `SELECT EXISTS(SELECT 1 FROM devices WHERE id = $1)`
 
 	var exists bool
-	err := r.pool.QueryRow(ctx, query, id).Scan(&exists)
+	err := conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check device existence: %w", err)
 	}
@@ -184,23 +472,52 @@ func (r *PostgresDeviceRepository) ExistsByID(ctx context.Context, id uuid.UUID)
 	return exists, nil
 }
 
+// queryDevices runs a device query like read and scans the result
+func (r *PostgresDeviceRepository) queryDevices(ctx context.Context, query string, args ...any) ([]*domain.Device, error) {
+	var devices []*domain.Device
+	err := r.read(ctx, func(db dbtx) error {
+		rows, err := db.Query(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		devices, err = r.scanDevices(rows)
+		return err
+	})
+	return devices, err
+}
+
+// read runs fn on the read replica while it is healthy, and on the primary
+// otherwise. A read the replica fails is retried on the primary; a replica that
+// could not answer at all is taken out of rotation until its next successful
+// health check.
+func (r *PostgresDeviceRepository) read(ctx context.Context, fn func(db dbtx) error) error {
+	if replica, ok := r.replica.Pool(); ok && !domain.FreshReadsRequired(ctx) {
+		err := fn(replica)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		// Errors reported by the server, such as queries cancelled by a conflict
+		// with recovery, do not mean the replica is down
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			r.replica.MarkUnhealthy()
+		}
+	}
+	return fn(conn(ctx, r.pool))
+}
+
 // scanDevices is a helper function to scan multiple device rows
 func (r *PostgresDeviceRepository) scanDevices(rows pgx.Rows) ([]*domain.Device, error) {
 	var devices []*domain.Device
 
 	for rows.Next() {
-		var device domain.Device
-		err := rows.Scan(
-			&device.ID,
-			&device.Name,
-			&device.Brand,
-			&device.State,
-			&device.CreatedAt,
-		)
+		device, err := scanDevice(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan device: %w", err)
 		}
-		devices = append(devices, &device)
+		devices = append(devices, device)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -209,3 +526,72 @@ func (r *PostgresDeviceRepository) scanDevices(rows pgx.Rows) ([]*domain.Device,
 
 	return devices, nil
 }
+
+// scanDevice scans a single row selected with deviceColumns, followed by any extra columns into extra
+func scanDevice(row pgx.Row, extra ...any) (*domain.Device, error) {
+	var device domain.Device
+	dest := []any{
+		&device.ID,
+		&device.Name,
+		&device.Brand,
+		&device.BrandID,
+		&device.SerialNumber,
+		&device.State,
+		&device.CreatedAt,
+		&device.Attributes,
+		&device.Labels,
+		&device.LocationID,
+		&device.ModelID,
+		&device.Category,
+		&device.PurchaseDate,
+		&device.WarrantyEnd,
+		&device.EOLDate,
+		&device.LastSeenAt,
+		&device.Reported,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// Unique constraints of the devices table, as named by Postgres
+const (
+	devicesPrimaryKey     = "devices_pkey"
+	devicesBrandSerialKey = "idx_devices_brand_serial"
+)
+
+// deviceConflict translates a unique violation on devices into domain.ErrDeviceAlreadyExists,
+// naming the conflicting field. A violation of any other constraint is wrapped as an
+// unexpected error. It returns nil for errors that are not unique violations.
+func deviceConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return nil
+	}
+	switch pgErr.ConstraintName {
+	case devicesBrandSerialKey:
+		return domain.NewConflictError(domain.ErrDeviceAlreadyExists, "serial_number",
+			"a device with this serial number already exists for the brand")
+	case devicesPrimaryKey:
+		return domain.NewConflictError(domain.ErrDeviceAlreadyExists, "id", "a device with this ID already exists")
+	default:
+		return fmt.Errorf("unexpected unique violation on %s: %w", pgErr.ConstraintName, err)
+	}
+}
+
+// attributesOrEmpty avoids writing JSON null into the NOT NULL attributes column
+func attributesOrEmpty(attributes domain.Attributes) domain.Attributes {
+	if attributes == nil {
+		return domain.Attributes{}
+	}
+	return attributes
+}
+
+// labelsOrEmpty avoids writing JSON null into the NOT NULL labels column
+func labelsOrEmpty(labels domain.Labels) domain.Labels {
+	if labels == nil {
+		return domain.Labels{}
+	}
+	return labels
+}
@@ -0,0 +1,130 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dbNow returns the database clock, which versions are stamped with
+func dbNow(t *testing.T) time.Time {
+	t.Helper()
+	var now time.Time
+	require.NoError(t, pgContainer.GetPool().QueryRow(context.Background(), "SELECT now()").Scan(&now))
+	return now
+}
+
+// countVersions returns how many versions of a device were recorded
+func countVersions(t *testing.T, device *domain.Device) int {
+	t.Helper()
+	var count int
+	err := pgContainer.GetPool().QueryRow(context.Background(), "SELECT count(*) FROM device_versions WHERE id = $1", device.ID).Scan(&count)
+	require.NoError(t, err)
+	return count
+}
+
+func TestPostgresDeviceRepository_History(t *testing.T) {
+	repo := setupTest(t)
+	ctx := context.Background()
+
+	device, err := domain.NewDevice("iPhone 15", "Apple")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, device))
+	created := dbNow(t)
+
+	device.State = domain.DeviceStateInUse
+	require.NoError(t, repo.Update(ctx, device))
+	checkedOut := dbNow(t)
+
+	device.State = domain.DeviceStateActive
+	require.NoError(t, repo.Update(ctx, device))
+	require.NoError(t, repo.Delete(ctx, device.ID))
+	deleted := dbNow(t)
+
+	found, err := repo.GetByIDAsOf(ctx, device.ID, created)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateActive, found.State)
+	assert.Equal(t, "Apple", found.Brand)
+
+	found, err = repo.GetByIDAsOf(ctx, device.ID, checkedOut)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateInUse, found.State)
+
+	_, err = repo.GetByIDAsOf(ctx, device.ID, deleted)
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+	_, err = repo.GetByIDAsOf(ctx, device.ID, created.Add(-time.Hour))
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound, "the device did not exist yet")
+
+	// The deleted device is still listed and counted as it was
+	devices, err := repo.Search(ctx, domain.DeviceFilter{State: domain.DeviceStateInUse, AsOf: &checkedOut}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, device.ID, devices[0].ID)
+
+	groups, err := repo.Aggregate(ctx, domain.DeviceStatsQuery{
+		Filter:  domain.DeviceFilter{AsOf: &checkedOut},
+		GroupBy: []domain.StatsDimension{domain.StatsByState},
+	})
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "in-use", groups[0].Keys[domain.StatsByState])
+
+	devices, err = repo.Search(ctx, domain.DeviceFilter{AsOf: &deleted}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+}
+
+func TestPostgresDeviceRepository_History_IgnoresHeartbeats(t *testing.T) {
+	repo := setupTest(t)
+	heartbeats := repository.NewPostgresHeartbeatRepository(pgContainer.GetPool())
+	ctx := context.Background()
+
+	device, err := domain.NewDevice("iPhone 15", "Apple")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, device))
+	require.Equal(t, 1, countVersions(t, device))
+
+	require.NoError(t, heartbeats.Record(ctx, &domain.Heartbeat{DeviceID: device.ID, SeenAt: time.Now()}))
+	assert.Equal(t, 1, countVersions(t, device))
+
+	// Saving the device unchanged adds no version either
+	require.NoError(t, repo.Update(ctx, device))
+	assert.Equal(t, 1, countVersions(t, device))
+}
+
+func TestPostgresDeviceRepository_History_OverlappingTransactions(t *testing.T) {
+	repo := setupTest(t)
+	pool := pgContainer.GetPool()
+	ctx := context.Background()
+
+	device, err := domain.NewDevice("iPhone 15", "Apple")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, device))
+
+	// The earlier transaction's now() predates the version the later one writes
+	earlier, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer earlier.Rollback(ctx)
+	var startedAt time.Time
+	require.NoError(t, earlier.QueryRow(ctx, "SELECT now()").Scan(&startedAt))
+	require.Eventually(t, func() bool { return dbNow(t).After(startedAt) }, time.Second, time.Millisecond)
+
+	device.State = domain.DeviceStateInUse
+	require.NoError(t, repo.Update(ctx, device))
+
+	_, err = earlier.Exec(ctx, "UPDATE devices SET name = 'iPhone 15 Pro' WHERE id = $1", device.ID)
+	require.NoError(t, err, "closing a version written after the transaction started must not violate its period")
+	require.NoError(t, earlier.Commit(ctx))
+	assert.Equal(t, 3, countVersions(t, device))
+
+	found, err := repo.GetByIDAsOf(ctx, device.ID, dbNow(t))
+	require.NoError(t, err)
+	assert.Equal(t, "iPhone 15 Pro", found.Name)
+	assert.Equal(t, domain.DeviceStateInUse, found.State)
+}
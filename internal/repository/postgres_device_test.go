@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"devices-api/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceConflict(t *testing.T) {
+	uniqueViolation := func(constraint string) error {
+		return fmt.Errorf("insert: %w", &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: constraint})
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		field    string
+		conflict bool
+	}{
+		{name: "serial number", err: uniqueViolation(devicesBrandSerialKey), field: "serial_number", conflict: true},
+		{name: "primary key", err: uniqueViolation(devicesPrimaryKey), field: "id", conflict: true},
+		{name: "other constraint", err: uniqueViolation("idx_devices_asset_tag")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := deviceConflict(tt.err)
+
+			var conflict *domain.ConflictError
+			if !tt.conflict {
+				assert.False(t, errors.As(err, &conflict))
+				assert.ErrorIs(t, err, tt.err)
+				assert.Contains(t, err.Error(), "idx_devices_asset_tag")
+				return
+			}
+			assert.ErrorIs(t, err, domain.ErrDeviceAlreadyExists)
+			if assert.ErrorAs(t, err, &conflict) {
+				assert.Equal(t, tt.field, conflict.Field)
+			}
+		})
+	}
+
+	t.Run("not a unique violation", func(t *testing.T) {
+		assert.NoError(t, deviceConflict(errors.New("connection reset")))
+		assert.NoError(t, deviceConflict(&pgconn.PgError{Code: "23503"}))
+	})
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresExpiryRepository implements the domain.ExpiryRepository interface
+type PostgresExpiryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresExpiryRepository creates a new PostgreSQL expiry repository
+func NewPostgresExpiryRepository(pool *pgxpool.Pool) *PostgresExpiryRepository {
+	return &PostgresExpiryRepository{
+		pool: pool,
+	}
+}
+
+// ListExpiring retrieves warranty and EOL dates between from and to (inclusive),
+// ordered by brand name and date. A device with both dates in range is listed twice.
+func (r *PostgresExpiryRepository) ListExpiring(ctx context.Context, from, to time.Time) ([]domain.Expiry, error) {
+	query := `
+		SELECT d.id, d.name, b.name, d.brand_id, COALESCE(d.serial_number, ''), e.kind, e.expires_on
+		FROM devices d
+		JOIN brands b ON b.id = d.brand_id
+		CROSS JOIN LATERAL (VALUES ('warranty', d.warranty_end), ('eol', d.eol_date)) AS e(kind, expires_on)
+		WHERE e.expires_on BETWEEN $1 AND $2
+		ORDER BY b.name_key, b.id, e.expires_on, d.name, d.id, e.kind
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring devices: %w", err)
+	}
+	defer rows.Close()
+
+	var expiries []domain.Expiry
+	for rows.Next() {
+		var expiry domain.Expiry
+		if err := rows.Scan(
+			&expiry.DeviceID,
+			&expiry.DeviceName,
+			&expiry.Brand,
+			&expiry.BrandID,
+			&expiry.SerialNumber,
+			&expiry.Kind,
+			&expiry.Date,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan expiry: %w", err)
+		}
+		expiries = append(expiries, expiry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expiries: %w", err)
+	}
+
+	return expiries, nil
+}
+
+// MarkNotified records a notice and reports false when it was already recorded.
+// The insert is atomic, so concurrent monitors never both claim the same notice.
+func (r *PostgresExpiryRepository) MarkNotified(ctx context.Context, notice domain.ExpiryNotice) (bool, error) {
+	query := `
+		INSERT INTO device_expiry_notifications (device_id, kind, expires_on, threshold_days)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, notice.DeviceID, notice.Kind, notice.Date, notice.ThresholdDays)
+	if err != nil {
+		return false, fmt.Errorf("failed to record expiry notice: %w", err)
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// UnmarkNotified removes a notice, so it is emitted again on the next check
+func (r *PostgresExpiryRepository) UnmarkNotified(ctx context.Context, notice domain.ExpiryNotice) error {
+	query := `
+		DELETE FROM device_expiry_notifications
+		WHERE device_id = $1 AND kind = $2 AND expires_on = $3 AND threshold_days = $4
+	`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, notice.DeviceID, notice.Kind, notice.Date, notice.ThresholdDays); err != nil {
+		return fmt.Errorf("failed to remove expiry notice: %w", err)
+	}
+
+	return nil
+}
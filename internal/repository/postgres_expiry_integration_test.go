@@ -0,0 +1,91 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupExpiryTest cleans the database and returns both repositories
+func setupExpiryTest(t *testing.T) (*repository.PostgresExpiryRepository, *repository.PostgresDeviceRepository) {
+	deviceRepo := setupTest(t)
+	return repository.NewPostgresExpiryRepository(pgContainer.GetPool()), deviceRepo
+}
+
+// inDays returns the date days from today
+func inDays(days int) *time.Time {
+	date := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, days)
+	return &date
+}
+
+func TestPostgresExpiryRepository_ListExpiring(t *testing.T) {
+	expiryRepo, deviceRepo := setupExpiryTest(t)
+	ctx := context.Background()
+
+	laptop, err := domain.NewDevice("MacBook Pro", "Apple",
+		domain.WithWarrantyEnd(inDays(5)), domain.WithEOLDate(inDays(20)))
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, laptop))
+	phone, err := domain.NewDevice("Galaxy S24", "Samsung", domain.WithWarrantyEnd(inDays(3)))
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, phone))
+	later, err := domain.NewDevice("iPad Air", "Apple", domain.WithEOLDate(inDays(90)))
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, later))
+
+	from, to, err := domain.ExpiryWindow(time.Now(), 30)
+	require.NoError(t, err)
+	expiries, err := expiryRepo.ListExpiring(ctx, from, to)
+	require.NoError(t, err)
+
+	// Ordered by brand, then date; the laptop is listed once per date
+	require.Len(t, expiries, 3)
+	assert.Equal(t, laptop.ID, expiries[0].DeviceID)
+	assert.Equal(t, domain.ExpiryKindWarranty, expiries[0].Kind)
+	assert.Equal(t, 5, expiries[0].DaysLeft(time.Now()))
+	assert.Equal(t, laptop.ID, expiries[1].DeviceID)
+	assert.Equal(t, domain.ExpiryKindEOL, expiries[1].Kind)
+	assert.Equal(t, phone.ID, expiries[2].DeviceID)
+	assert.Equal(t, "Samsung", expiries[2].Brand)
+}
+
+func TestPostgresExpiryRepository_MarkNotified(t *testing.T) {
+	expiryRepo, deviceRepo := setupExpiryTest(t)
+	ctx := context.Background()
+
+	device, err := domain.NewDevice("MacBook Pro", "Apple", domain.WithWarrantyEnd(inDays(5)))
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, device))
+
+	notice := domain.ExpiryNotice{
+		Expiry:        domain.Expiry{DeviceID: device.ID, Kind: domain.ExpiryKindWarranty, Date: *device.WarrantyEnd},
+		ThresholdDays: 7,
+	}
+
+	claimed, err := expiryRepo.MarkNotified(ctx, notice)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = expiryRepo.MarkNotified(ctx, notice)
+	require.NoError(t, err)
+	assert.False(t, claimed, "a notice is only claimed once")
+
+	// Another threshold is a separate notice
+	other := notice
+	other.ThresholdDays = 1
+	claimed, err = expiryRepo.MarkNotified(ctx, other)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Releasing a notice lets it be claimed again
+	require.NoError(t, expiryRepo.UnmarkNotified(ctx, notice))
+	claimed, err = expiryRepo.MarkNotified(ctx, notice)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresHeartbeatRepository implements the domain.HeartbeatRepository interface
+type PostgresHeartbeatRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresHeartbeatRepository creates a new PostgreSQL heartbeat repository
+func NewPostgresHeartbeatRepository(pool *pgxpool.Pool) *PostgresHeartbeatRepository {
+	return &PostgresHeartbeatRepository{
+		pool: pool,
+	}
+}
+
+// SetTokenHash stores the hash of a device's heartbeat token, replacing any previous one.
+// A device that does not exist is reported as domain.ErrDeviceNotFound.
+func (r *PostgresHeartbeatRepository) SetTokenHash(ctx context.Context, deviceID uuid.UUID, hash []byte) error {
+	query := `
+		INSERT INTO device_heartbeat_tokens (device_id, token_hash)
+		VALUES ($1, $2)
+		ON CONFLICT (device_id) DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = NOW()
+	`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, deviceID, hash); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return domain.ErrDeviceNotFound
+		}
+		return fmt.Errorf("failed to save heartbeat token: %w", err)
+	}
+
+	return nil
+}
+
+// TokenHash retrieves the hash of a device's heartbeat token.
+// A device without a token is reported as domain.ErrDeviceNotFound.
+func (r *PostgresHeartbeatRepository) TokenHash(ctx context.Context, deviceID uuid.UUID) ([]byte, error) {
+	var hash []byte
+	err := conn(ctx, r.pool).QueryRow(ctx, `SELECT token_hash FROM device_heartbeat_tokens WHERE device_id = $1`, deviceID).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to get heartbeat token: %w", err)
+	}
+
+	return hash, nil
+}
+
+// Record saves the time of a heartbeat and, when present, replaces the reported fields.
+// A heartbeat older than the last one recorded does not move last_seen_at back.
+func (r *PostgresHeartbeatRepository) Record(ctx context.Context, heartbeat *domain.Heartbeat) error {
+	query := `
+		UPDATE devices
+		SET last_seen_at = GREATEST(last_seen_at, $2), reported = COALESCE($3, reported)
+		WHERE id = $1
+	`
+
+	// A nil map would be written as JSON null; pass SQL NULL to keep the reported fields
+	var reported any
+	if heartbeat.Reported != nil {
+		reported = heartbeat.Reported
+	}
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, heartbeat.DeviceID, heartbeat.SeenAt, reported)
+	if err != nil {
+		return fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+// ListStale retrieves devices last seen before seenBefore that are not inactive,
+// in use or in maintenance, longest unseen first. Devices that never sent a heartbeat are never stale.
+func (r *PostgresHeartbeatRepository) ListStale(ctx context.Context, seenBefore time.Time, limit, offset int) ([]*domain.Device, error) {
+	query := `
+		SELECT ` + deviceColumns + `
+		FROM ` + deviceSource + `
+		WHERE d.last_seen_at < $1 AND d.state NOT IN ('inactive', 'in-use', 'maintenance')
+		ORDER BY d.last_seen_at, d.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, seenBefore, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale devices: %w", err)
+	}
+	defer rows.Close()
+
+	var devices []*domain.Device
+	for rows.Next() {
+		device, err := scanDevice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan device: %w", err)
+		}
+		devices = append(devices, device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// MarkInactive saves the state of a stale device. Nothing is saved when the device
+// sent a heartbeat or changed state since it was listed, so a concurrent update wins.
+func (r *PostgresHeartbeatRepository) MarkInactive(ctx context.Context, device *domain.Device, previousState domain.DeviceState, seenBefore time.Time) (bool, error) {
+	query := `
+		UPDATE devices
+		SET state = $2
+		WHERE id = $1 AND state = $3 AND last_seen_at < $4
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, device.ID, device.State, previousState, seenBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark device inactive: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
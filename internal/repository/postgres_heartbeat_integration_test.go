@@ -0,0 +1,116 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupHeartbeatTest cleans the database and returns both repositories
+func setupHeartbeatTest(t *testing.T) (*repository.PostgresHeartbeatRepository, *repository.PostgresDeviceRepository) {
+	deviceRepo := setupTest(t)
+	return repository.NewPostgresHeartbeatRepository(pgContainer.GetPool()), deviceRepo
+}
+
+// recordHeartbeat records a heartbeat of the device at seenAt
+func recordHeartbeat(t *testing.T, repo *repository.PostgresHeartbeatRepository, deviceID uuid.UUID, seenAt time.Time, reported domain.Attributes) {
+	t.Helper()
+	heartbeat, err := domain.NewHeartbeat(deviceID, seenAt, reported)
+	require.NoError(t, err)
+	require.NoError(t, repo.Record(context.Background(), heartbeat))
+}
+
+func TestPostgresHeartbeatRepository_TokenHash(t *testing.T) {
+	repo, deviceRepo := setupHeartbeatTest(t)
+	ctx := context.Background()
+
+	device := createDevice(t, deviceRepo, "Lab sensor", "Bosch")
+
+	_, err := repo.TokenHash(ctx, device.ID)
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+
+	require.NoError(t, repo.SetTokenHash(ctx, device.ID, []byte("first")))
+	require.NoError(t, repo.SetTokenHash(ctx, device.ID, []byte("second")))
+
+	hash, err := repo.TokenHash(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), hash)
+
+	assert.ErrorIs(t, repo.SetTokenHash(ctx, uuid.New(), []byte("orphan")), domain.ErrDeviceNotFound)
+}
+
+func TestPostgresHeartbeatRepository_Record(t *testing.T) {
+	repo, deviceRepo := setupHeartbeatTest(t)
+	ctx := context.Background()
+
+	device := createDevice(t, deviceRepo, "Lab sensor", "Bosch")
+	seenAt := time.Now().UTC().Truncate(time.Millisecond)
+
+	recordHeartbeat(t, repo, device.ID, seenAt, domain.Attributes{"firmware": "1.4.2"})
+	// An older heartbeat without reported fields changes neither
+	recordHeartbeat(t, repo, device.ID, seenAt.Add(-time.Minute), nil)
+
+	found, err := deviceRepo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.LastSeenAt)
+	assert.True(t, found.LastSeenAt.Equal(seenAt))
+	assert.Equal(t, "1.4.2", found.Reported["firmware"])
+
+	heartbeat, err := domain.NewHeartbeat(uuid.New(), seenAt, nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.Record(ctx, heartbeat), domain.ErrDeviceNotFound)
+}
+
+func TestPostgresHeartbeatRepository_ListStaleAndMarkInactive(t *testing.T) {
+	repo, deviceRepo := setupHeartbeatTest(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	cutoff := domain.StaleCutoff(now, 15*time.Minute)
+
+	stale := createDevice(t, deviceRepo, "Stale sensor", "Bosch")
+	recordHeartbeat(t, repo, stale.ID, now.Add(-time.Hour), nil)
+	fresh := createDevice(t, deviceRepo, "Fresh sensor", "Bosch")
+	recordHeartbeat(t, repo, fresh.ID, now, nil)
+	inUse := createDevice(t, deviceRepo, "Busy sensor", "Bosch")
+	recordHeartbeat(t, repo, inUse.ID, now.Add(-time.Hour), nil)
+	inUse.State = domain.DeviceStateInUse
+	require.NoError(t, deviceRepo.Update(ctx, inUse))
+	serviced := createDevice(t, deviceRepo, "Serviced sensor", "Bosch")
+	recordHeartbeat(t, repo, serviced.ID, now.Add(-time.Hour), nil)
+	serviced.State = domain.DeviceStateMaintenance
+	require.NoError(t, deviceRepo.Update(ctx, serviced))
+	createDevice(t, deviceRepo, "Silent sensor", "Bosch")
+
+	devices, err := repo.ListStale(ctx, cutoff, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, stale.ID, devices[0].ID)
+
+	device := devices[0]
+	changed, err := device.Deactivate()
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	// A heartbeat after listing wins over the stale check
+	recordHeartbeat(t, repo, stale.ID, now, nil)
+	saved, err := repo.MarkInactive(ctx, device, domain.DeviceStateActive, cutoff)
+	require.NoError(t, err)
+	assert.False(t, saved)
+
+	later := now.Add(time.Hour)
+	saved, err = repo.MarkInactive(ctx, device, domain.DeviceStateActive, domain.StaleCutoff(later, 15*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, saved)
+
+	found, err := deviceRepo.GetByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateInactive, found.State)
+}
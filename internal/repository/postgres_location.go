@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// locationColumns is the column list shared by every location SELECT
+const locationColumns = "id, name, type, parent_id, created_at"
+
+// pgForeignKeyViolation is the SQLSTATE raised when a referenced row is deleted
+const pgForeignKeyViolation = "23503"
+
+// PostgresLocationRepository implements the domain.LocationRepository interface
+type PostgresLocationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresLocationRepository creates a new PostgreSQL location repository
+func NewPostgresLocationRepository(pool *pgxpool.Pool) *PostgresLocationRepository {
+	return &PostgresLocationRepository{
+		pool: pool,
+	}
+}
+
+// Create persists a new location
+func (r *PostgresLocationRepository) Create(ctx context.Context, location *domain.Location) error {
+	query := `
+		INSERT INTO locations (id, name, type, parent_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query,
+		location.ID,
+		location.Name,
+		location.Type,
+		location.ParentID,
+		location.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create location: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a location by its unique identifier
+func (r *PostgresLocationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Location, error) {
+	query := `
+		SELECT ` + locationColumns + `
+		FROM locations
+		WHERE id = $1
+	`
+
+	location, err := scanLocation(conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLocationNotFound
+		}
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+
+	return location, nil
+}
+
+// List retrieves locations matching filter, ordered by name
+func (r *PostgresLocationRepository) List(ctx context.Context, filter domain.LocationFilter) ([]*domain.Location, error) {
+	var b whereBuilder
+	if filter.Type != "" {
+		b.add("type = " + b.arg(filter.Type))
+	}
+	if filter.ParentID != nil {
+		b.add("parent_id = " + b.arg(*filter.ParentID))
+	}
+	if len(filter.IDs) > 0 {
+		b.add("id = ANY(" + b.arg(filter.IDs) + ")")
+	}
+
+	query := `
+		SELECT ` + locationColumns + `
+		FROM locations
+		` + b.clause() + `
+		ORDER BY name, id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []*domain.Location
+	for rows.Next() {
+		location, err := scanLocation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan location: %w", err)
+		}
+		locations = append(locations, location)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating locations: %w", err)
+	}
+
+	return locations, nil
+}
+
+// Update modifies an existing location
+func (r *PostgresLocationRepository) Update(ctx context.Context, location *domain.Location) error {
+	query := `
+		UPDATE locations
+		SET name = $2, parent_id = $3
+		WHERE id = $1
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, location.ID, location.Name, location.ParentID)
+	if err != nil {
+		return fmt.Errorf("failed to update location: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrLocationNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a location by its unique identifier.
+// The foreign keys reject deleting a location that is still referenced,
+// which covers devices or children added after the service checked for them.
+func (r *PostgresLocationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM locations WHERE id = $1`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return domain.NewBusinessRuleError("cannot delete location that still has devices or child locations")
+		}
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrLocationNotFound
+	}
+
+	return nil
+}
+
+// HasChildren checks if any location has the given location as parent
+func (r *PostgresLocationRepository) HasChildren(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM locations WHERE parent_id = $1)`
+
+	var exists bool
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check child locations: %w", err)
+	}
+
+	return exists, nil
+}
+
+// HasDevices checks if any device is assigned to the location
+func (r *PostgresLocationRepository) HasDevices(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM devices WHERE location_id = $1)`
+
+	var exists bool
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check location devices: %w", err)
+	}
+
+	return exists, nil
+}
+
+// scanLocation scans a single location row
+func scanLocation(row pgx.Row) (*domain.Location, error) {
+	var location domain.Location
+	err := row.Scan(
+		&location.ID,
+		&location.Name,
+		&location.Type,
+		&location.ParentID,
+		&location.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
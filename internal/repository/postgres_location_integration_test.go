@@ -0,0 +1,152 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupLocationTest cleans the database and returns both repositories
+func setupLocationTest(t *testing.T) (*repository.PostgresLocationRepository, *repository.PostgresDeviceRepository) {
+	deviceRepo := setupTest(t)
+	return repository.NewPostgresLocationRepository(pgContainer.GetPool()), deviceRepo
+}
+
+// createLocation persists a location under parent and returns it
+func createLocation(t *testing.T, repo *repository.PostgresLocationRepository, name string, locationType domain.LocationType, parent *domain.Location) *domain.Location {
+	t.Helper()
+	location, err := domain.NewLocation(name, locationType, parent)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(context.Background(), location))
+	return location
+}
+
+func TestPostgresLocationRepository_CreateAndGet(t *testing.T) {
+	repo, _ := setupLocationTest(t)
+	ctx := context.Background()
+
+	site := createLocation(t, repo, "Berlin", domain.LocationTypeSite, nil)
+	building := createLocation(t, repo, "HQ", domain.LocationTypeBuilding, site)
+
+	found, err := repo.GetByID(ctx, building.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "HQ", found.Name)
+	assert.Equal(t, domain.LocationTypeBuilding, found.Type)
+	assert.Equal(t, &site.ID, found.ParentID)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrLocationNotFound)
+}
+
+func TestPostgresLocationRepository_List(t *testing.T) {
+	repo, _ := setupLocationTest(t)
+	ctx := context.Background()
+
+	site := createLocation(t, repo, "Berlin", domain.LocationTypeSite, nil)
+	createLocation(t, repo, "Lisbon", domain.LocationTypeSite, nil)
+	building := createLocation(t, repo, "HQ", domain.LocationTypeBuilding, site)
+
+	sites, err := repo.List(ctx, domain.LocationFilter{Type: domain.LocationTypeSite})
+	require.NoError(t, err)
+	assert.Len(t, sites, 2)
+	assert.Equal(t, "Berlin", sites[0].Name)
+
+	children, err := repo.List(ctx, domain.LocationFilter{ParentID: &site.ID})
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, building.ID, children[0].ID)
+
+	byID, err := repo.List(ctx, domain.LocationFilter{IDs: []uuid.UUID{building.ID, site.ID, uuid.New()}})
+	require.NoError(t, err)
+	require.Len(t, byID, 2)
+	assert.Equal(t, "Berlin", byID[0].Name)
+	assert.Equal(t, "HQ", byID[1].Name)
+}
+
+func TestPostgresLocationRepository_SearchDevicesInSubtree(t *testing.T) {
+	repo, deviceRepo := setupLocationTest(t)
+	ctx := context.Background()
+
+	site := createLocation(t, repo, "Berlin", domain.LocationTypeSite, nil)
+	building := createLocation(t, repo, "HQ", domain.LocationTypeBuilding, site)
+	room := createLocation(t, repo, "Lab 1", domain.LocationTypeRoom, building)
+	otherSite := createLocation(t, repo, "Lisbon", domain.LocationTypeSite, nil)
+
+	for name, location := range map[string]*domain.Location{
+		"In Room":     room,
+		"In Building": building,
+		"Elsewhere":   otherSite,
+	} {
+		device, err := domain.NewDevice(name, "Brand", domain.WithLocation(&location.ID))
+		require.NoError(t, err)
+		require.NoError(t, deviceRepo.Create(ctx, device))
+	}
+	unassigned, err := domain.NewDevice("Unassigned", "Brand")
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, unassigned))
+
+	tests := []struct {
+		location *domain.Location
+		want     []string
+	}{
+		{site, []string{"In Room", "In Building"}},
+		{building, []string{"In Room", "In Building"}},
+		{room, []string{"In Room"}},
+		{otherSite, []string{"Elsewhere"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location.Name, func(t *testing.T) {
+			devices, err := deviceRepo.Search(ctx, domain.DeviceFilter{LocationID: &tt.location.ID}, 10, 0)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, deviceNames(devices))
+		})
+	}
+}
+
+func TestPostgresLocationRepository_DeleteWithDevices(t *testing.T) {
+	repo, deviceRepo := setupLocationTest(t)
+	ctx := context.Background()
+
+	site := createLocation(t, repo, "Berlin", domain.LocationTypeSite, nil)
+	device, err := domain.NewDevice("iPhone 15", "Apple", domain.WithLocation(&site.ID))
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, device))
+
+	hasDevices, err := repo.HasDevices(ctx, site.ID)
+	require.NoError(t, err)
+	assert.True(t, hasDevices)
+
+	// The foreign key rejects the delete even without the service check
+	err = repo.Delete(ctx, site.ID)
+	assert.True(t, domain.IsBusinessRuleError(err))
+
+	device.LocationID = nil
+	require.NoError(t, deviceRepo.Update(ctx, device))
+	require.NoError(t, repo.Delete(ctx, site.ID))
+
+	err = repo.Delete(ctx, site.ID)
+	assert.ErrorIs(t, err, domain.ErrLocationNotFound)
+}
+
+func TestPostgresLocationRepository_HasChildren(t *testing.T) {
+	repo, _ := setupLocationTest(t)
+	ctx := context.Background()
+
+	site := createLocation(t, repo, "Berlin", domain.LocationTypeSite, nil)
+	building := createLocation(t, repo, "HQ", domain.LocationTypeBuilding, site)
+
+	hasChildren, err := repo.HasChildren(ctx, site.ID)
+	require.NoError(t, err)
+	assert.True(t, hasChildren)
+
+	hasChildren, err = repo.HasChildren(ctx, building.ID)
+	require.NoError(t, err)
+	assert.False(t, hasChildren)
+}
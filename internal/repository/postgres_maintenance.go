@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maintenanceColumns is the column list shared by every maintenance SELECT
+const maintenanceColumns = "id, device_id, type, scheduled_at, started_at, completed_at, vendor, cost_cents, notes," +
+	" COALESCE(previous_state, ''), created_at"
+
+// PostgresMaintenanceRepository implements the domain.MaintenanceRepository interface
+type PostgresMaintenanceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresMaintenanceRepository creates a new PostgreSQL maintenance repository
+func NewPostgresMaintenanceRepository(pool *pgxpool.Pool) *PostgresMaintenanceRepository {
+	return &PostgresMaintenanceRepository{
+		pool: pool,
+	}
+}
+
+// Create persists a new maintenance record.
+// A device that does not exist is reported as domain.ErrDeviceNotFound.
+func (r *PostgresMaintenanceRepository) Create(ctx context.Context, maintenance *domain.Maintenance) error {
+	query := `
+		INSERT INTO maintenance (id, device_id, type, scheduled_at, vendor, cost_cents, notes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query,
+		maintenance.ID,
+		maintenance.DeviceID,
+		maintenance.Type,
+		maintenance.ScheduledAt,
+		maintenance.Vendor,
+		maintenance.CostCents,
+		maintenance.Notes,
+		maintenance.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return domain.ErrDeviceNotFound
+		}
+		return fmt.Errorf("failed to create maintenance: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a maintenance record by its unique identifier
+func (r *PostgresMaintenanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Maintenance, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance WHERE id = $1`
+
+	maintenance, err := scanMaintenance(conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMaintenanceNotFound
+		}
+		return nil, fmt.Errorf("failed to get maintenance: %w", err)
+	}
+
+	return maintenance, nil
+}
+
+// ListByDevice retrieves the maintenance records of a device, most recently scheduled first
+func (r *PostgresMaintenanceRepository) ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]*domain.Maintenance, error) {
+	query := `
+		SELECT ` + maintenanceColumns + `
+		FROM maintenance
+		WHERE device_id = $1
+		ORDER BY scheduled_at DESC, id
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*domain.Maintenance
+	for rows.Next() {
+		maintenance, err := scanMaintenance(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance: %w", err)
+		}
+		records = append(records, maintenance)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating maintenance: %w", err)
+	}
+
+	return records, nil
+}
+
+// SaveTransition persists a started or completed maintenance together with the
+// state of its device. Both are saved atomically when ctx carries a transaction
+// of PostgresTxManager.
+// The record is only updated from the preceding status, so a concurrent start
+// or completion of the same maintenance is rejected as a business rule violation,
+// as is starting a second maintenance on a device.
+// The device is only updated while its state is still previousState, so a
+// concurrent device update is not overwritten and a stale state is never restored.
+func (r *PostgresMaintenanceRepository) SaveTransition(ctx context.Context, maintenance *domain.Maintenance, device *domain.Device, previousState domain.DeviceState) error {
+	// The status the record must still have in the database
+	precondition := "started_at IS NULL"
+	if maintenance.CompletedAt != nil {
+		precondition = "started_at IS NOT NULL AND completed_at IS NULL"
+	}
+
+	db := conn(ctx, r.pool)
+
+	query := `
+		UPDATE maintenance
+		SET started_at = $2, completed_at = $3, previous_state = NULLIF($4, ''), cost_cents = $5, notes = $6
+		WHERE id = $1 AND ` + precondition
+
+	result, err := db.Exec(ctx, query,
+		maintenance.ID,
+		maintenance.StartedAt,
+		maintenance.CompletedAt,
+		maintenance.PreviousState,
+		maintenance.CostCents,
+		maintenance.Notes,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.NewBusinessRuleError("device is already in maintenance")
+		}
+		return fmt.Errorf("failed to update maintenance: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.NewBusinessRuleError("maintenance was changed by another request")
+	}
+
+	result, err = db.Exec(ctx, `UPDATE devices SET state = $2 WHERE id = $1 AND state = $3`, device.ID, device.State, previousState)
+	if err != nil {
+		return fmt.Errorf("failed to update device state: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		exists, err := deviceExists(ctx, db, device.ID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrDeviceNotFound
+		}
+		return domain.NewBusinessRuleError("device was changed by another request")
+	}
+
+	return nil
+}
+
+// ListDue retrieves devices whose periodic maintenance is due at now, oldest due first.
+// The interval of the device's category counts from its last completed maintenance,
+// or from its purchase date or creation when it never had one.
+// Devices without a model, in a category without an interval, or with a
+// scheduled or in-progress maintenance are never due.
+func (r *PostgresMaintenanceRepository) ListDue(ctx context.Context, intervals domain.MaintenanceIntervals, now time.Time, limit, offset int) ([]domain.DueMaintenance, error) {
+	if len(intervals) == 0 {
+		return nil, nil
+	}
+
+	categories := make([]string, 0, len(intervals))
+	days := make([]int32, 0, len(intervals))
+	for category, interval := range intervals {
+		categories = append(categories, string(category))
+		days = append(days, int32(interval))
+	}
+
+	query := `
+		SELECT ` + deviceColumns + `, last.completed_at, due.due_at
+		FROM ` + deviceSource + `
+		JOIN unnest($1::text[], $2::int[]) AS i(category, days) ON i.category = m.category
+		CROSS JOIN LATERAL (
+			SELECT max(completed_at) AS completed_at FROM maintenance WHERE device_id = d.id
+		) last
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(last.completed_at, d.purchase_date::timestamp AT TIME ZONE 'UTC', d.created_at)
+				+ make_interval(days => i.days) AS due_at
+		) due
+		WHERE due.due_at <= $3
+			AND NOT EXISTS (SELECT 1 FROM maintenance o WHERE o.device_id = d.id AND o.completed_at IS NULL)
+		ORDER BY due.due_at, d.id
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, categories, days, now, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due maintenance: %w", err)
+	}
+	defer rows.Close()
+
+	var due []domain.DueMaintenance
+	for rows.Next() {
+		var item domain.DueMaintenance
+		item.Device, err = scanDevice(rows, &item.LastMaintainedAt, &item.DueAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan due maintenance: %w", err)
+		}
+		due = append(due, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating due maintenance: %w", err)
+	}
+
+	return due, nil
+}
+
+// scanMaintenance scans a single row selected with maintenanceColumns
+func scanMaintenance(row pgx.Row) (*domain.Maintenance, error) {
+	var maintenance domain.Maintenance
+	err := row.Scan(
+		&maintenance.ID,
+		&maintenance.DeviceID,
+		&maintenance.Type,
+		&maintenance.ScheduledAt,
+		&maintenance.StartedAt,
+		&maintenance.CompletedAt,
+		&maintenance.Vendor,
+		&maintenance.CostCents,
+		&maintenance.Notes,
+		&maintenance.PreviousState,
+		&maintenance.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &maintenance, nil
+}
+
+// deviceExists checks whether a device exists, as seen by tx
+func deviceExists(ctx context.Context, tx queryRower, id uuid.UUID) (bool, error) {
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM devices WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check device existence: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,175 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMaintenanceTest cleans the database and returns both repositories
+func setupMaintenanceTest(t *testing.T) (*repository.PostgresMaintenanceRepository, *repository.PostgresDeviceRepository) {
+	deviceRepo := setupTest(t)
+	return repository.NewPostgresMaintenanceRepository(pgContainer.GetPool()), deviceRepo
+}
+
+// createDevice persists a device without a model and returns it
+func createDevice(t *testing.T, repo *repository.PostgresDeviceRepository, name, brand string) *domain.Device {
+	t.Helper()
+	device, err := domain.NewDevice(name, brand)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(context.Background(), device))
+	return device
+}
+
+// scheduleMaintenance persists a maintenance of the device and returns it
+func scheduleMaintenance(t *testing.T, repo *repository.PostgresMaintenanceRepository, deviceID uuid.UUID, scheduledAt time.Time) *domain.Maintenance {
+	t.Helper()
+	maintenance, err := domain.NewMaintenance(deviceID, domain.MaintenanceTypePreventive, scheduledAt, "iFixit", 4500, "")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(context.Background(), maintenance))
+	return maintenance
+}
+
+func TestPostgresMaintenanceRepository_CreateAndList(t *testing.T) {
+	repo, deviceRepo := setupMaintenanceTest(t)
+	ctx := context.Background()
+
+	device := createDevice(t, deviceRepo, "MacBook Pro", "Apple")
+	first := scheduleMaintenance(t, repo, device.ID, time.Now().Add(-48*time.Hour))
+	second := scheduleMaintenance(t, repo, device.ID, time.Now().Add(24*time.Hour))
+
+	found, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, device.ID, found.DeviceID)
+	assert.Equal(t, "iFixit", found.Vendor)
+	assert.Equal(t, int64(4500), found.CostCents)
+	assert.Equal(t, domain.MaintenanceStatusScheduled, found.Status())
+
+	records, err := repo.ListByDevice(ctx, device.ID)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, second.ID, records[0].ID, "most recently scheduled first")
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrMaintenanceNotFound)
+
+	orphan, err := domain.NewMaintenance(uuid.New(), domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.Create(ctx, orphan), domain.ErrDeviceNotFound)
+}
+
+func TestPostgresMaintenanceRepository_SaveTransition(t *testing.T) {
+	repo, deviceRepo := setupMaintenanceTest(t)
+	ctx := context.Background()
+
+	device := createDevice(t, deviceRepo, "MacBook Pro", "Apple")
+	device.State = domain.DeviceStateInUse
+	require.NoError(t, deviceRepo.Update(ctx, device))
+	maintenance := scheduleMaintenance(t, repo, device.ID, time.Now())
+
+	require.NoError(t, maintenance.Start(device, time.Now()))
+	require.NoError(t, repo.SaveTransition(ctx, maintenance, device, domain.DeviceStateInUse))
+
+	stored, err := deviceRepo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateMaintenance, stored.State)
+	found, err := repo.GetByID(ctx, maintenance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MaintenanceStatusInProgress, found.Status())
+	assert.Equal(t, domain.DeviceStateInUse, found.PreviousState)
+
+	// Saving the same start again loses against the stored status
+	err = repo.SaveTransition(ctx, maintenance, device, domain.DeviceStateInUse)
+	assert.True(t, domain.IsBusinessRuleError(err))
+
+	// A second maintenance cannot be in progress on the same device
+	other := scheduleMaintenance(t, repo, device.ID, time.Now())
+	otherDevice := *device
+	otherDevice.State = domain.DeviceStateInactive
+	require.NoError(t, other.Start(&otherDevice, time.Now()))
+	err = repo.SaveTransition(ctx, other, &otherDevice, domain.DeviceStateInactive)
+	assert.True(t, domain.IsBusinessRuleError(err))
+
+	require.NoError(t, maintenance.Complete(device, time.Now(), nil, nil))
+	require.NoError(t, repo.SaveTransition(ctx, maintenance, device, domain.DeviceStateMaintenance))
+
+	stored, err = deviceRepo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateInUse, stored.State)
+}
+
+func TestPostgresMaintenanceRepository_SaveTransitionAfterConcurrentUpdate(t *testing.T) {
+	repo, deviceRepo := setupMaintenanceTest(t)
+	ctx := context.Background()
+
+	device := createDevice(t, deviceRepo, "MacBook Pro", "Apple")
+	maintenance := scheduleMaintenance(t, repo, device.ID, time.Now())
+
+	// The device is put in use after the maintenance read it as active
+	read := *device
+	device.State = domain.DeviceStateInUse
+	require.NoError(t, deviceRepo.Update(ctx, device))
+
+	// The rejected device write rolls back the maintenance write of the same transaction
+	require.NoError(t, maintenance.Start(&read, time.Now()))
+	err := repository.NewPostgresTxManager(pgContainer.GetPool()).WithinTx(ctx, func(ctx context.Context) error {
+		return repo.SaveTransition(ctx, maintenance, &read, domain.DeviceStateActive)
+	})
+	assert.True(t, domain.IsBusinessRuleError(err))
+
+	stored, err := deviceRepo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeviceStateInUse, stored.State)
+	found, err := repo.GetByID(ctx, maintenance.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.MaintenanceStatusScheduled, found.Status())
+}
+
+func TestPostgresMaintenanceRepository_ListDue(t *testing.T) {
+	repo, deviceRepo := setupMaintenanceTest(t)
+	modelRepo := repository.NewPostgresModelRepository(pgContainer.GetPool())
+	ctx := context.Background()
+
+	laptopModel := createModel(t, modelRepo, "MacBook Pro 14", "Apple", domain.DeviceCategoryLaptop)
+	phoneModel := createModel(t, modelRepo, "iPhone 15", "Apple", domain.DeviceCategoryPhone)
+	intervals := domain.MaintenanceIntervals{domain.DeviceCategoryLaptop: 365}
+	purchased := time.Now().UTC().Truncate(24*time.Hour).AddDate(-2, 0, 0)
+
+	newDevice := func(name string, modelID uuid.UUID) *domain.Device {
+		device, err := domain.NewDevice(name, "Apple", domain.WithModel(&modelID), domain.WithPurchaseDate(&purchased))
+		require.NoError(t, err)
+		require.NoError(t, deviceRepo.Create(ctx, device))
+		return device
+	}
+	overdue := newDevice("Dev laptop", laptopModel.ID)
+	maintained := newDevice("Spare laptop", laptopModel.ID)
+	newDevice("Dev phone", phoneModel.ID)
+	createDevice(t, deviceRepo, "Unmodelled laptop", "Apple")
+
+	// Recently maintained
+	m := scheduleMaintenance(t, repo, maintained.ID, time.Now().AddDate(0, -1, 0))
+	require.NoError(t, m.Start(maintained, time.Now().AddDate(0, -1, 0)))
+	require.NoError(t, repo.SaveTransition(ctx, m, maintained, domain.DeviceStateActive))
+	require.NoError(t, m.Complete(maintained, time.Now().AddDate(0, -1, 0), nil, nil))
+	require.NoError(t, repo.SaveTransition(ctx, m, maintained, domain.DeviceStateMaintenance))
+
+	due, err := repo.ListDue(ctx, intervals, time.Now(), 10, 0)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, overdue.ID, due[0].Device.ID)
+	assert.Nil(t, due[0].LastMaintainedAt)
+	assert.True(t, due[0].DueAt.Equal(purchased.AddDate(0, 0, 365)))
+
+	// Scheduling a maintenance takes the device off the list
+	scheduleMaintenance(t, repo, overdue.ID, time.Now().Add(24*time.Hour))
+	due, err = repo.ListDue(ctx, intervals, time.Now(), 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
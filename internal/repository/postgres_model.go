@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// modelColumns is the column list shared by every model SELECT from modelSource
+const modelColumns = "m.id, m.name, b.name, m.brand_id, m.category, m.default_attributes, m.lifecycle_months, m.created_at"
+
+// modelSource joins models with their brand so reads return the canonical brand name
+const modelSource = "models m JOIN brands b ON b.id = m.brand_id"
+
+// PostgresModelRepository implements the domain.ModelRepository interface
+type PostgresModelRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresModelRepository creates a new PostgreSQL model repository
+func NewPostgresModelRepository(pool *pgxpool.Pool) *PostgresModelRepository {
+	return &PostgresModelRepository{
+		pool: pool,
+	}
+}
+
+// Create persists a new model.
+// The brand name is resolved through the brand catalog like a device's brand,
+// and model.Brand and model.BrandID are set to the canonical brand.
+func (r *PostgresModelRepository) Create(ctx context.Context, model *domain.Model) error {
+	var brandID uuid.UUID
+	var brandName string
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		var err error
+		brandID, brandName, err = ensureBrand(ctx, tx, model.Brand)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO models (id, name, brand_id, category, default_attributes, lifecycle_months, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`
+
+		_, err = tx.Exec(ctx, query,
+			model.ID,
+			model.Name,
+			brandID,
+			model.Category,
+			attributesOrEmpty(model.DefaultAttributes),
+			model.LifecycleMonths,
+			model.CreatedAt,
+		)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.NewValidationError("name", "a model with this name already exists for the brand")
+		}
+		return fmt.Errorf("failed to create model: %w", err)
+	}
+
+	model.BrandID, model.Brand = brandID, brandName
+	return nil
+}
+
+// GetByID retrieves a model by its unique identifier
+func (r *PostgresModelRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Model, error) {
+	query := `
+		SELECT ` + modelColumns + `
+		FROM ` + modelSource + `
+		WHERE m.id = $1
+	`
+
+	model, err := scanModel(conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrModelNotFound
+		}
+		return nil, fmt.Errorf("failed to get model: %w", err)
+	}
+
+	return model, nil
+}
+
+// List retrieves models matching filter, ordered by brand and name
+func (r *PostgresModelRepository) List(ctx context.Context, filter domain.ModelFilter) ([]*domain.Model, error) {
+	var b whereBuilder
+	if filter.Brand != "" {
+		b.add("m.brand_id IN (" + brandMatchQuery(b.arg(filter.Brand)) + ")")
+	}
+	if filter.Category != "" {
+		b.add("m.category = " + b.arg(filter.Category))
+	}
+	if len(filter.IDs) > 0 {
+		b.add("m.id = ANY(" + b.arg(filter.IDs) + ")")
+	}
+
+	query := `
+		SELECT ` + modelColumns + `
+		FROM ` + modelSource + `
+		` + b.clause() + `
+		ORDER BY b.name_key, lower(m.name), m.id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	defer rows.Close()
+
+	var models []*domain.Model
+	for rows.Next() {
+		model, err := scanModel(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan model: %w", err)
+		}
+		models = append(models, model)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating models: %w", err)
+	}
+
+	return models, nil
+}
+
+// Update modifies an existing model; brand and category are fixed
+func (r *PostgresModelRepository) Update(ctx context.Context, model *domain.Model) error {
+	query := `
+		UPDATE models
+		SET name = $2, default_attributes = $3, lifecycle_months = $4
+		WHERE id = $1
+	`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query,
+		model.ID,
+		model.Name,
+		attributesOrEmpty(model.DefaultAttributes),
+		model.LifecycleMonths,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return domain.NewValidationError("name", "a model with this name already exists for the brand")
+		}
+		return fmt.Errorf("failed to update model: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrModelNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a model by its unique identifier.
+// The foreign key rejects deleting a model that devices still reference.
+func (r *PostgresModelRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM models WHERE id = $1`
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return domain.NewBusinessRuleError("cannot delete model that still has devices")
+		}
+		return fmt.Errorf("failed to delete model: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrModelNotFound
+	}
+
+	return nil
+}
+
+// HasDevices checks if any device references the model
+func (r *PostgresModelRepository) HasDevices(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM devices WHERE model_id = $1)`
+
+	var exists bool
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, id).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check model devices: %w", err)
+	}
+
+	return exists, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
+
+// scanModel scans a single row selected with modelColumns
+func scanModel(row pgx.Row) (*domain.Model, error) {
+	var model domain.Model
+	err := row.Scan(
+		&model.ID,
+		&model.Name,
+		&model.Brand,
+		&model.BrandID,
+		&model.Category,
+		&model.DefaultAttributes,
+		&model.LifecycleMonths,
+		&model.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
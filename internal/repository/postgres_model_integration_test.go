@@ -0,0 +1,109 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupModelTest cleans the database and returns both repositories
+func setupModelTest(t *testing.T) (*repository.PostgresModelRepository, *repository.PostgresDeviceRepository) {
+	deviceRepo := setupTest(t)
+	return repository.NewPostgresModelRepository(pgContainer.GetPool()), deviceRepo
+}
+
+// createModel persists a catalog model and returns it
+func createModel(t *testing.T, repo *repository.PostgresModelRepository, name, brand string, category domain.DeviceCategory) *domain.Model {
+	t.Helper()
+	model, err := domain.NewModel(name, brand, category, domain.Attributes{"os": "macos"}, 48)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(context.Background(), model))
+	return model
+}
+
+func TestPostgresModelRepository_CreateAndGet(t *testing.T) {
+	repo, _ := setupModelTest(t)
+	ctx := context.Background()
+
+	model := createModel(t, repo, "MacBook Pro 14 M3", "apple", domain.DeviceCategoryLaptop)
+	assert.Equal(t, "apple", model.Brand)
+	assert.NotEqual(t, uuid.Nil, model.BrandID)
+
+	found, err := repo.GetByID(ctx, model.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "MacBook Pro 14 M3", found.Name)
+	assert.Equal(t, domain.DeviceCategoryLaptop, found.Category)
+	assert.Equal(t, domain.Attributes{"os": "macos"}, found.DefaultAttributes)
+	assert.Equal(t, 48, found.LifecycleMonths)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrModelNotFound)
+
+	// Names are unique per brand, ignoring case
+	duplicate, err := domain.NewModel("macbook pro 14 m3", "APPLE", domain.DeviceCategoryLaptop, nil, 0)
+	require.NoError(t, err)
+	err = repo.Create(ctx, duplicate)
+	assert.True(t, domain.IsValidationError(err))
+}
+
+func TestPostgresModelRepository_List(t *testing.T) {
+	repo, _ := setupModelTest(t)
+	ctx := context.Background()
+
+	createModel(t, repo, "iPhone 15", "Apple", domain.DeviceCategoryPhone)
+	laptop := createModel(t, repo, "MacBook Air", "Apple", domain.DeviceCategoryLaptop)
+	galaxy := createModel(t, repo, "Galaxy S24", "Samsung", domain.DeviceCategoryPhone)
+
+	phones, err := repo.List(ctx, domain.ModelFilter{Category: domain.DeviceCategoryPhone})
+	require.NoError(t, err)
+	require.Len(t, phones, 2)
+	assert.Equal(t, "iPhone 15", phones[0].Name)
+
+	apple, err := repo.List(ctx, domain.ModelFilter{Brand: "APPLE"})
+	require.NoError(t, err)
+	assert.Len(t, apple, 2)
+
+	byID, err := repo.List(ctx, domain.ModelFilter{IDs: []uuid.UUID{laptop.ID, galaxy.ID}})
+	require.NoError(t, err)
+	assert.Len(t, byID, 2)
+}
+
+func TestPostgresModelRepository_DevicesByCategory(t *testing.T) {
+	repo, deviceRepo := setupModelTest(t)
+	ctx := context.Background()
+
+	laptop := createModel(t, repo, "MacBook Air", "Apple", domain.DeviceCategoryLaptop)
+
+	device, err := domain.NewDevice("Dev laptop", "Apple")
+	require.NoError(t, err)
+	require.NoError(t, device.AssignModel(laptop, true))
+	require.NoError(t, deviceRepo.Create(ctx, device))
+
+	other, err := domain.NewDevice("Unmodelled", "Apple")
+	require.NoError(t, err)
+	require.NoError(t, deviceRepo.Create(ctx, other))
+
+	found, err := deviceRepo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &laptop.ID, found.ModelID)
+	assert.Equal(t, domain.DeviceCategoryLaptop, found.Category)
+	assert.Equal(t, "macos", found.Attributes["os"])
+
+	devices, err := deviceRepo.Search(ctx, domain.DeviceFilter{Category: domain.DeviceCategoryLaptop}, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Dev laptop"}, deviceNames(devices))
+
+	// The foreign key rejects the delete even without the service check
+	err = repo.Delete(ctx, laptop.ID)
+	assert.True(t, domain.IsBusinessRuleError(err))
+
+	hasDevices, err := repo.HasDevices(ctx, laptop.ID)
+	require.NoError(t, err)
+	assert.True(t, hasDevices)
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// telemetryPartitionPrefix starts the name of every daily telemetry partition
+	telemetryPartitionPrefix = "telemetry_p"
+	// telemetryPartitionLayout formats the UTC day in a partition name
+	telemetryPartitionLayout = "20060102"
+
+	// pgDuplicateTable is raised when a concurrent request created the same partition
+	pgDuplicateTable = "42P07"
+)
+
+// PostgresTelemetryRepository implements the domain.TelemetryRepository interface.
+// Samples are stored in daily partitions of the telemetry table, which are
+// created on first use and dropped as a whole by DropBefore.
+type PostgresTelemetryRepository struct {
+	pool *pgxpool.Pool
+
+	// partitions caches the days whose partition is known to exist
+	partitions sync.Map
+}
+
+// NewPostgresTelemetryRepository creates a new PostgreSQL telemetry repository
+func NewPostgresTelemetryRepository(pool *pgxpool.Pool) *PostgresTelemetryRepository {
+	return &PostgresTelemetryRepository{
+		pool: pool,
+	}
+}
+
+// Insert stores a batch of samples with a single COPY, creating the daily
+// partitions they fall into first. A sample of a device that does not exist
+// is reported as domain.ErrDeviceNotFound and nothing is stored.
+func (r *PostgresTelemetryRepository) Insert(ctx context.Context, points []domain.TelemetryPoint) error {
+	for _, point := range points {
+		if err := r.ensurePartition(ctx, point.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.pool.CopyFrom(ctx,
+		pgx.Identifier{"telemetry"},
+		[]string{"device_id", "metric", "value", "ts"},
+		pgx.CopyFromSlice(len(points), func(i int) ([]any, error) {
+			return []any{points[i].DeviceID, points[i].Metric, points[i].Value, points[i].Timestamp}, nil
+		}),
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return domain.ErrDeviceNotFound
+		}
+		return fmt.Errorf("failed to insert telemetry: %w", err)
+	}
+
+	return nil
+}
+
+// Aggregate returns the min, max, average and count of the samples matching query
+// per bucket, oldest first. Buckets start at query.From, and empty buckets are left out.
+func (r *PostgresTelemetryRepository) Aggregate(ctx context.Context, query domain.TelemetryQuery) ([]domain.TelemetryBucket, error) {
+	sql := `
+		SELECT date_bin(make_interval(secs => $5), ts, $3) AS bucket, min(value), max(value), avg(value), count(*)
+		FROM telemetry
+		WHERE device_id = $1 AND metric = $2 AND ts >= $3 AND ts < $4
+		GROUP BY bucket
+		ORDER BY bucket
+	`
+
+	rows, err := r.pool.Query(ctx, sql, query.DeviceID, query.Metric, query.From, query.To, query.Bucket.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate telemetry: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []domain.TelemetryBucket
+	for rows.Next() {
+		var bucket domain.TelemetryBucket
+		if err := rows.Scan(&bucket.Start, &bucket.Min, &bucket.Max, &bucket.Avg, &bucket.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan telemetry bucket: %w", err)
+		}
+		bucket.Start = bucket.Start.UTC()
+		buckets = append(buckets, bucket)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating telemetry buckets: %w", err)
+	}
+
+	return buckets, nil
+}
+
+// DropBefore drops every daily partition that ends at or before cutoff and
+// returns the number of partitions dropped. The partition holding cutoff is
+// kept, so samples up to a day older than cutoff may remain until the next call.
+func (r *PostgresTelemetryRepository) DropBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'telemetry'::regclass
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list telemetry partitions: %w", err)
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, fmt.Errorf("failed to list telemetry partitions: %w", err)
+	}
+
+	dropped := 0
+	for _, name := range names {
+		day, ok := partitionDay(name)
+		if !ok || day.AddDate(0, 0, 1).After(cutoff) {
+			continue
+		}
+		if _, err := r.pool.Exec(ctx, `DROP TABLE IF EXISTS `+pgx.Identifier{name}.Sanitize()); err != nil {
+			return dropped, fmt.Errorf("failed to drop telemetry partition %s: %w", name, err)
+		}
+		r.partitions.Delete(day)
+		dropped++
+	}
+
+	return dropped, nil
+}
+
+// ensurePartition creates the partition for the UTC day of ts unless it is known to exist
+func (r *PostgresTelemetryRepository) ensurePartition(ctx context.Context, ts time.Time) error {
+	day := ts.UTC().Truncate(24 * time.Hour)
+	if _, ok := r.partitions.Load(day); ok {
+		return nil
+	}
+
+	name := telemetryPartitionPrefix + day.Format(telemetryPartitionLayout)
+	// Bounds are literals: partition bounds cannot be query parameters
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF telemetry FOR VALUES FROM ('%s') TO ('%s')`,
+		pgx.Identifier{name}.Sanitize(), day.Format(time.RFC3339), day.AddDate(0, 0, 1).Format(time.RFC3339))
+
+	if _, err := r.pool.Exec(ctx, query); err != nil {
+		// IF NOT EXISTS does not cover a concurrent create of the same partition
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || (pgErr.Code != pgDuplicateTable && pgErr.Code != pgUniqueViolation) {
+			return fmt.Errorf("failed to create telemetry partition %s: %w", name, err)
+		}
+	}
+
+	r.partitions.Store(day, struct{}{})
+	return nil
+}
+
+// partitionDay parses the UTC day from the name of a daily telemetry partition
+func partitionDay(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, telemetryPartitionPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	day, err := time.Parse(telemetryPartitionLayout, suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day, true
+}
@@ -0,0 +1,122 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTelemetryTest cleans the database and returns both repositories
+func setupTelemetryTest(t *testing.T) (*repository.PostgresTelemetryRepository, *repository.PostgresDeviceRepository) {
+	deviceRepo := setupTest(t)
+	return repository.NewPostgresTelemetryRepository(pgContainer.GetPool()), deviceRepo
+}
+
+// telemetryPoint builds a sample of metric of the device at ts
+func telemetryPoint(t *testing.T, deviceID uuid.UUID, metric string, value float64, ts time.Time) domain.TelemetryPoint {
+	t.Helper()
+	point, err := domain.NewTelemetryPoint(domain.TelemetryPointField(0), deviceID, metric, value, ts)
+	require.NoError(t, err)
+	return point
+}
+
+// countTelemetryPartitions returns the number of daily telemetry partitions
+func countTelemetryPartitions(t *testing.T) int {
+	t.Helper()
+	var count int
+	err := pgContainer.GetPool().QueryRow(context.Background(),
+		`SELECT count(*) FROM pg_inherits WHERE inhparent = 'telemetry'::regclass`).Scan(&count)
+	require.NoError(t, err)
+	return count
+}
+
+func TestPostgresTelemetryRepository_Aggregate(t *testing.T) {
+	repo, deviceRepo := setupTelemetryTest(t)
+	ctx := context.Background()
+
+	device := createDevice(t, deviceRepo, "Lab sensor", "Bosch")
+	other := createDevice(t, deviceRepo, "Lab sensor 2", "Bosch")
+	from := time.Date(2025, 6, 1, 23, 0, 0, 0, time.UTC)
+
+	// The samples span midnight, so they land in two partitions
+	require.NoError(t, repo.Insert(ctx, []domain.TelemetryPoint{
+		telemetryPoint(t, device.ID, "battery", 90, from.Add(10*time.Minute)),
+		telemetryPoint(t, device.ID, "battery", 80, from.Add(20*time.Minute)),
+		telemetryPoint(t, device.ID, "battery", 70, from.Add(70*time.Minute)),
+		telemetryPoint(t, device.ID, "temperature", 40, from.Add(10*time.Minute)),
+		telemetryPoint(t, other.ID, "battery", 10, from.Add(10*time.Minute)),
+	}))
+
+	buckets, err := repo.Aggregate(ctx, domain.TelemetryQuery{
+		DeviceID: device.ID,
+		Metric:   "battery",
+		From:     from,
+		To:       from.Add(3 * time.Hour),
+		Bucket:   time.Hour,
+	})
+
+	require.NoError(t, err)
+	require.Len(t, buckets, 2, "the empty third hour is left out")
+	assert.Equal(t, domain.TelemetryBucket{Start: from, Min: 80, Max: 90, Avg: 85, Count: 2}, buckets[0])
+	assert.Equal(t, domain.TelemetryBucket{Start: from.Add(time.Hour), Min: 70, Max: 70, Avg: 70, Count: 1}, buckets[1])
+}
+
+func TestPostgresTelemetryRepository_Insert_UnknownDevice(t *testing.T) {
+	repo, deviceRepo := setupTelemetryTest(t)
+	ctx := context.Background()
+
+	device := createDevice(t, deviceRepo, "Lab sensor", "Bosch")
+	ts := time.Now().UTC()
+
+	err := repo.Insert(ctx, []domain.TelemetryPoint{
+		telemetryPoint(t, device.ID, "battery", 90, ts),
+		telemetryPoint(t, uuid.New(), "battery", 80, ts),
+	})
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+
+	// Nothing of the batch is stored
+	buckets, err := repo.Aggregate(ctx, domain.TelemetryQuery{
+		DeviceID: device.ID, Metric: "battery", From: ts.Add(-time.Hour), To: ts.Add(time.Hour), Bucket: time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, buckets)
+}
+
+func TestPostgresTelemetryRepository_DropBefore(t *testing.T) {
+	repo, deviceRepo := setupTelemetryTest(t)
+	ctx := context.Background()
+
+	device := createDevice(t, deviceRepo, "Lab sensor", "Bosch")
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Insert(ctx, []domain.TelemetryPoint{
+		telemetryPoint(t, device.ID, "battery", 90, day.Add(12*time.Hour)),
+		telemetryPoint(t, device.ID, "battery", 80, day.Add(36*time.Hour)),
+	}))
+	before := countTelemetryPartitions(t)
+
+	// The cutoff falls within the second day, so only the first one is dropped
+	dropped, err := repo.DropBefore(ctx, day.Add(30*time.Hour))
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, dropped)
+	assert.Equal(t, before-1, countTelemetryPartitions(t))
+
+	buckets, err := repo.Aggregate(ctx, domain.TelemetryQuery{
+		DeviceID: device.ID, Metric: "battery", From: day, To: day.Add(48 * time.Hour), Bucket: 24 * time.Hour,
+	})
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, day.Add(24*time.Hour), buckets[0].Start)
+
+	// A later sample for the dropped day recreates its partition
+	require.NoError(t, repo.Insert(ctx, []domain.TelemetryPoint{
+		telemetryPoint(t, device.ID, "battery", 70, day.Add(time.Hour)),
+	}))
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+
+	"devices-api/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// dbtx is implemented by both the connection pool and a transaction, so
+// repositories run the same queries in and outside a transaction
+type dbtx interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// txKey is the context key of the transaction started by PostgresTxManager
+type txKey struct{}
+
+// txState is the transaction of a context and the work to run once it ends
+type txState struct {
+	tx       pgx.Tx
+	afterEnd []func()
+}
+
+// PostgresTxManager implements the domain.TxManager interface
+type PostgresTxManager struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresTxManager creates a transaction manager for the repositories sharing pool
+func NewPostgresTxManager(pool *pgxpool.Pool) *PostgresTxManager {
+	return &PostgresTxManager{
+		pool: pool,
+	}
+}
+
+// WithinTx runs fn in a transaction. Reads in the transaction bypass caches,
+// so they see what the transaction itself wrote.
+func (m *PostgresTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	state := &txState{}
+	defer func() {
+		for _, f := range state.afterEnd {
+			f()
+		}
+	}()
+
+	return pgx.BeginFunc(ctx, m.pool, func(tx pgx.Tx) error {
+		state.tx = tx
+		return fn(domain.WithFreshReads(context.WithValue(ctx, txKey{}, state)))
+	})
+}
+
+// conn returns the transaction of ctx, or pool outside a transaction
+func conn(ctx context.Context, pool *pgxpool.Pool) dbtx {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+	return pool
+}
+
+// afterTx runs f once the transaction of ctx has ended, or at once outside a transaction
+func afterTx(ctx context.Context, f func()) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterEnd = append(state.afterEnd, f)
+		return
+	}
+	f()
+}
@@ -0,0 +1,110 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresTxManager_CommitsAcrossRepositories(t *testing.T) {
+	repo := setupTest(t)
+	pool := pgContainer.GetPool()
+	txManager := repository.NewPostgresTxManager(pool)
+	locations := repository.NewPostgresLocationRepository(pool)
+	ctx := context.Background()
+
+	site, err := domain.NewLocation("HQ", domain.LocationTypeSite, nil)
+	require.NoError(t, err)
+	device, err := domain.NewDevice("iPhone 15", "Apple", domain.WithLocation(&site.ID))
+	require.NoError(t, err)
+
+	err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := locations.Create(ctx, site); err != nil {
+			return err
+		}
+		return repo.Create(ctx, device)
+	})
+	require.NoError(t, err)
+
+	found, err := repo.GetByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, site.ID, *found.LocationID)
+}
+
+func TestPostgresTxManager_RollsBackOnError(t *testing.T) {
+	repo := setupTest(t)
+	txManager := repository.NewPostgresTxManager(pgContainer.GetPool())
+	ctx := context.Background()
+
+	device, err := domain.NewDevice("iPhone 15", "Apple")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, device))
+
+	failure := errors.New("rule violated")
+	err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Nested calls join the outer transaction
+		return txManager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.Delete(ctx, device.ID); err != nil {
+				return err
+			}
+			return failure
+		})
+	})
+	assert.ErrorIs(t, err, failure)
+
+	_, err = repo.GetByID(ctx, device.ID)
+	assert.NoError(t, err, "the delete was rolled back")
+}
+
+func TestPostgresDeviceRepository_GetByIDForUpdate_LocksUntilCommit(t *testing.T) {
+	repo := setupTest(t)
+	txManager := repository.NewPostgresTxManager(pgContainer.GetPool())
+	ctx := context.Background()
+
+	device, err := domain.NewDevice("iPhone 15", "Apple")
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(ctx, device))
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- txManager.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := repo.GetByIDForUpdate(ctx, device.ID); err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+
+	// A second lock waits for the first transaction
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	err = txManager.WithinTx(waitCtx, func(ctx context.Context) error {
+		_, err := repo.GetByIDForUpdate(ctx, device.ID)
+		return err
+	})
+	assert.Error(t, err)
+
+	close(release)
+	require.NoError(t, <-done)
+
+	err = txManager.WithinTx(ctx, func(ctx context.Context) error {
+		found, err := repo.GetByIDForUpdate(ctx, device.ID)
+		if err == nil {
+			assert.Equal(t, device.ID, found.ID)
+		}
+		return err
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// BrandService handles business logic for the brand catalog
+type BrandService struct {
+	repo domain.BrandRepository
+}
+
+// NewBrandService creates a new brand service
+func NewBrandService(repo domain.BrandRepository) *BrandService {
+	return &BrandService{
+		repo: repo,
+	}
+}
+
+// GetBrand retrieves a brand with its aliases and device count
+func (s *BrandService) GetBrand(ctx context.Context, id uuid.UUID) (brand *domain.Brand, err error) {
+	ctx, span := startSpan(ctx, "BrandService.GetBrand", brandIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListBrands retrieves all brands with their aliases and device counts
+func (s *BrandService) ListBrands(ctx context.Context) (brands []*domain.Brand, err error) {
+	ctx, span := startSpan(ctx, "BrandService.ListBrands")
+	defer func() { endSpan(span, err) }()
+
+	brands, err = s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list brands: %w", err)
+	}
+
+	if brands == nil {
+		brands = []*domain.Brand{}
+	}
+
+	return brands, nil
+}
+
+// AddAlias registers alias as an alternative name for a brand, so devices
+// created with the alias reference the brand
+// Enforces that an alias does not already name or alias any brand
+func (s *BrandService) AddAlias(ctx context.Context, id uuid.UUID, alias string) (brand *domain.Brand, err error) {
+	ctx, span := startSpan(ctx, "BrandService.AddAlias", brandIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if err := domain.ValidateBrandName("alias", alias); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.Resolve(ctx, alias)
+	switch {
+	case err == nil:
+		return nil, domain.NewValidationError("alias", fmt.Sprintf("already refers to brand %q", existing.Name))
+	case !errors.Is(err, domain.ErrBrandNotFound):
+		return nil, fmt.Errorf("failed to resolve alias: %w", err)
+	}
+
+	if err := s.repo.AddAlias(ctx, id, alias); err != nil {
+		if domain.IsValidationError(err) || domain.IsNotFoundError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to add brand alias: %w", err)
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
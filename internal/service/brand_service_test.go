@@ -0,0 +1,146 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBrandRepository is a mock implementation of domain.BrandRepository
+type MockBrandRepository struct {
+	mock.Mock
+}
+
+func (m *MockBrandRepository) Resolve(ctx context.Context, name string) (*domain.Brand, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Brand), args.Error(1)
+}
+
+func (m *MockBrandRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Brand, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Brand), args.Error(1)
+}
+
+func (m *MockBrandRepository) List(ctx context.Context) ([]*domain.Brand, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Brand), args.Error(1)
+}
+
+func (m *MockBrandRepository) AddAlias(ctx context.Context, id uuid.UUID, alias string) error {
+	args := m.Called(ctx, id, alias)
+	return args.Error(0)
+}
+
+// ========== ListBrands Tests ==========
+
+// TestListBrands_Empty tests that an empty catalog is returned as an empty list
+func TestListBrands_Empty(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBrandRepository)
+	svc := service.NewBrandService(mockRepo)
+
+	mockRepo.On("List", mock.Anything).Return(nil, nil)
+
+	// Act
+	brands, err := svc.ListBrands(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, brands)
+	assert.Empty(t, brands)
+}
+
+// ========== AddAlias Tests ==========
+
+// TestAddAlias_Success tests registering a new alias
+func TestAddAlias_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBrandRepository)
+	svc := service.NewBrandService(mockRepo)
+	ctx := context.Background()
+
+	brand := &domain.Brand{ID: uuid.New(), Name: "Hewlett-Packard"}
+	withAlias := &domain.Brand{ID: brand.ID, Name: brand.Name, Aliases: []string{"HP"}}
+
+	mockRepo.On("GetByID", mock.Anything, brand.ID).Return(brand, nil).Once()
+	mockRepo.On("Resolve", mock.Anything, "HP").Return(nil, domain.ErrBrandNotFound)
+	mockRepo.On("AddAlias", mock.Anything, brand.ID, "HP").Return(nil)
+	mockRepo.On("GetByID", mock.Anything, brand.ID).Return(withAlias, nil).Once()
+
+	// Act
+	result, err := svc.AddAlias(ctx, brand.ID, "HP")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"HP"}, result.Aliases)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestAddAlias_AlreadyInUse tests that an alias cannot name another brand
+func TestAddAlias_AlreadyInUse(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBrandRepository)
+	svc := service.NewBrandService(mockRepo)
+	ctx := context.Background()
+
+	brand := &domain.Brand{ID: uuid.New(), Name: "Hewlett-Packard"}
+	other := &domain.Brand{ID: uuid.New(), Name: "Apple"}
+
+	mockRepo.On("GetByID", mock.Anything, brand.ID).Return(brand, nil)
+	mockRepo.On("Resolve", mock.Anything, "apple").Return(other, nil)
+
+	// Act
+	result, err := svc.AddAlias(ctx, brand.ID, "apple")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "AddAlias", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestAddAlias_InvalidAlias tests alias validation
+func TestAddAlias_InvalidAlias(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBrandRepository)
+	svc := service.NewBrandService(mockRepo)
+
+	// Act
+	result, err := svc.AddAlias(context.Background(), uuid.New(), " ")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+// TestAddAlias_BrandNotFound tests adding an alias to an unknown brand
+func TestAddAlias_BrandNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockBrandRepository)
+	svc := service.NewBrandService(mockRepo)
+	id := uuid.New()
+
+	mockRepo.On("GetByID", mock.Anything, id).Return(nil, domain.ErrBrandNotFound)
+
+	// Act
+	result, err := svc.AddAlias(context.Background(), id, "HP")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domain.ErrBrandNotFound)
+}
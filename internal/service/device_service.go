@@ -2,11 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"devices-api/internal/domain"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
@@ -14,27 +18,138 @@ const (
 	DefaultPageLimit = 10
 	// DefaultPageOffset is the default offset for paginated queries
 	DefaultPageOffset = 0
+	// MaxPageLimit is the default upper bound for paginated queries
+	MaxPageLimit = 100
 )
 
 // DeviceService handles business logic for device operations
 type DeviceService struct {
-	repo domain.DeviceRepository
+	repo         domain.DeviceRepository
+	locations    domain.LocationRepository
+	brands       domain.BrandRepository
+	models       domain.ModelRepository
+	tx           domain.TxManager
+	defaultLimit int
+	maxLimit     int
+}
+
+// Option customizes a DeviceService
+type Option func(*DeviceService)
+
+// WithPagination sets the page size used when none is requested and the largest allowed page size
+func WithPagination(defaultLimit, maxLimit int) Option {
+	return func(s *DeviceService) {
+		if defaultLimit > 0 {
+			s.defaultLimit = defaultLimit
+		}
+		if maxLimit > 0 {
+			s.maxLimit = maxLimit
+		}
+	}
+}
+
+// WithLocationRepository enables checking that a device's location exists
+// before it is saved, so unknown locations are reported as validation errors
+func WithLocationRepository(locations domain.LocationRepository) Option {
+	return func(s *DeviceService) {
+		s.locations = locations
+	}
+}
+
+// WithBrandRepository enables resolving brand names through the brand catalog
+// before devices are validated, so aliases and variant spellings count as the
+// same brand, e.g. when checking whether the brand of an in-use device changes
+func WithBrandRepository(brands domain.BrandRepository) Option {
+	return func(s *DeviceService) {
+		s.brands = brands
+	}
+}
+
+// WithModelRepository enables creating devices from catalog models and
+// referencing models on update
+func WithModelRepository(models domain.ModelRepository) Option {
+	return func(s *DeviceService) {
+		s.models = models
+	}
+}
+
+// WithTxManager runs read-modify-write paths in transactions that lock the
+// device, so concurrent changes cannot slip between a business rule check and
+// the write it allows
+func WithTxManager(tx domain.TxManager) Option {
+	return func(s *DeviceService) {
+		s.tx = tx
+	}
+}
+
+// noTxManager runs work without a transaction, for services built without WithTxManager
+type noTxManager struct{}
+
+func (noTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
 }
 
 // NewDeviceService creates a new device service
-func NewDeviceService(repo domain.DeviceRepository) *DeviceService {
-	return &DeviceService{
-		repo: repo,
+func NewDeviceService(repo domain.DeviceRepository, opts ...Option) *DeviceService {
+	s := &DeviceService{
+		repo:         repo,
+		tx:           noTxManager{},
+		defaultLimit: DefaultPageLimit,
+		maxLimit:     MaxPageLimit,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // CreateDevice creates a new device
-func (s *DeviceService) CreateDevice(ctx context.Context, name, brand string) (*domain.Device, error) {
+func (s *DeviceService) CreateDevice(ctx context.Context, name, brand string, opts ...domain.DeviceOption) (device *domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.CreateDevice", attribute.String("device.brand", brand))
+	defer func() { endSpan(span, err) }()
+
+	return s.createDevice(ctx, name, brand, nil, opts...)
+}
+
+// CreateDeviceFromModel creates a new device referencing a catalog model
+// The device starts with the model's default attributes, explicit attributes take precedence,
+// and is validated against the rules of the model's category.
+// An empty brand defaults to the model's brand; any other brand must match it.
+func (s *DeviceService) CreateDeviceFromModel(ctx context.Context, modelID uuid.UUID, name, brand string, opts ...domain.DeviceOption) (device *domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.CreateDeviceFromModel", modelIDAttr(modelID.String()))
+	defer func() { endSpan(span, err) }()
+
+	model, err := s.getModel(ctx, modelID)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(brand) == "" {
+		brand = model.Brand
+	}
+
+	return s.createDevice(ctx, name, brand, model, opts...)
+}
+
+// createDevice validates and persists a new device, attaching model when set
+func (s *DeviceService) createDevice(ctx context.Context, name, brand string, model *domain.Model, opts ...domain.DeviceOption) (*domain.Device, error) {
+	brand, err := s.canonicalBrand(ctx, brand)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create device with domain validation
-	device, err := domain.NewDevice(name, brand)
+	device, err := domain.NewDevice(name, brand, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create device: %w", err)
 	}
+	if model != nil {
+		if err := device.AssignModel(model, true); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkLocation(ctx, device.LocationID); err != nil {
+		return nil, err
+	}
 
 	// Persist device
 	if err := s.repo.Create(ctx, device); err != nil {
@@ -45,8 +160,43 @@ func (s *DeviceService) CreateDevice(ctx context.Context, name, brand string) (*
 }
 
 // GetDevice retrieves a device by ID
-func (s *DeviceService) GetDevice(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
-	device, err := s.repo.GetByID(ctx, id)
+func (s *DeviceService) GetDevice(ctx context.Context, id uuid.UUID) (device *domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.GetDevice", deviceIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	device, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// GetDeviceAsOf retrieves a device as it was at the given time, even if it has been deleted since
+func (s *DeviceService) GetDeviceAsOf(ctx context.Context, id uuid.UUID, at time.Time) (device *domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.GetDeviceAsOf", deviceIDAttr(id.String()), attribute.String("device.as_of", at.Format(time.RFC3339)))
+	defer func() { endSpan(span, err) }()
+
+	device, err = s.repo.GetByIDAsOf(ctx, id, at)
+	if err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// GetDeviceBySerial retrieves a device by brand and serial number.
+// The brand may be the canonical name or any alias.
+func (s *DeviceService) GetDeviceBySerial(ctx context.Context, brand, serialNumber string) (device *domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.GetDeviceBySerial", attribute.String("device.brand", brand))
+	defer func() { endSpan(span, err) }()
+
+	if strings.TrimSpace(brand) == "" {
+		return nil, domain.NewValidationError("brand", "cannot be empty")
+	}
+	if strings.TrimSpace(serialNumber) == "" {
+		return nil, domain.NewValidationError("serial_number", "cannot be empty")
+	}
+
+	device, err = s.repo.GetBySerial(ctx, brand, strings.TrimSpace(serialNumber))
 	if err != nil {
 		return nil, err
 	}
@@ -54,10 +204,13 @@ func (s *DeviceService) GetDevice(ctx context.Context, id uuid.UUID) (*domain.De
 }
 
 // ListDevices retrieves all devices with pagination
-func (s *DeviceService) ListDevices(ctx context.Context, limit, offset int) ([]*domain.Device, error) {
-	limit, offset = normalizePagination(limit, offset)
+func (s *DeviceService) ListDevices(ctx context.Context, limit, offset int) (devices []*domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.ListDevices")
+	defer func() { endSpan(span, err) }()
+
+	limit, offset = s.NormalizePagination(limit, offset)
 
-	devices, err := s.repo.List(ctx, limit, offset)
+	devices, err = s.repo.List(ctx, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list devices: %w", err)
 	}
@@ -66,14 +219,17 @@ func (s *DeviceService) ListDevices(ctx context.Context, limit, offset int) ([]*
 }
 
 // ListDevicesByBrand retrieves devices filtered by brand
-func (s *DeviceService) ListDevicesByBrand(ctx context.Context, brand string, limit, offset int) ([]*domain.Device, error) {
+func (s *DeviceService) ListDevicesByBrand(ctx context.Context, brand string, limit, offset int) (devices []*domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.ListDevicesByBrand", attribute.String("device.brand", brand))
+	defer func() { endSpan(span, err) }()
+
 	if brand == "" {
 		return nil, domain.NewValidationError("brand", "cannot be empty")
 	}
 
-	limit, offset = normalizePagination(limit, offset)
+	limit, offset = s.NormalizePagination(limit, offset)
 
-	devices, err := s.repo.ListByBrand(ctx, brand, limit, offset)
+	devices, err = s.repo.ListByBrand(ctx, brand, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list devices by brand: %w", err)
 	}
@@ -82,15 +238,18 @@ func (s *DeviceService) ListDevicesByBrand(ctx context.Context, brand string, li
 }
 
 // ListDevicesByState retrieves devices filtered by state
-func (s *DeviceService) ListDevicesByState(ctx context.Context, state domain.DeviceState, limit, offset int) ([]*domain.Device, error) {
+func (s *DeviceService) ListDevicesByState(ctx context.Context, state domain.DeviceState, limit, offset int) (devices []*domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.ListDevicesByState", attribute.String("device.state", string(state)))
+	defer func() { endSpan(span, err) }()
+
 	// Validate state
-	if err := state.IsValid(); err != nil {
+	if err = state.IsValid(); err != nil {
 		return nil, err
 	}
 
-	limit, offset = normalizePagination(limit, offset)
+	limit, offset = s.NormalizePagination(limit, offset)
 
-	devices, err := s.repo.ListByState(ctx, state, limit, offset)
+	devices, err = s.repo.ListByState(ctx, state, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list devices by state: %w", err)
 	}
@@ -98,10 +257,61 @@ func (s *DeviceService) ListDevicesByState(ctx context.Context, state domain.Dev
 	return devices, nil
 }
 
-// normalizePagination ensures limit and offset have valid values
-func normalizePagination(limit, offset int) (int, int) {
+// SearchDevices lists devices matching every criterion in filter
+func (s *DeviceService) SearchDevices(ctx context.Context, filter domain.DeviceFilter, limit, offset int) (devices []*domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.SearchDevices",
+		attribute.String("device.brand", filter.Brand),
+		attribute.String("device.state", string(filter.State)),
+		attribute.Int("filter.attributes", len(filter.Attributes)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err = filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	limit, offset = s.NormalizePagination(limit, offset)
+
+	devices, err = s.repo.Search(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// DeviceStats counts the devices matching the query's filter, grouped by its
+// dimensions and creation period
+func (s *DeviceService) DeviceStats(ctx context.Context, query domain.DeviceStatsQuery) (stats []domain.DeviceStatsGroup, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.DeviceStats",
+		attribute.Int("stats.dimensions", len(query.GroupBy)),
+		attribute.String("stats.interval", string(query.Interval)),
+	)
+	defer func() { endSpan(span, err) }()
+
+	if err = query.Validate(); err != nil {
+		return nil, err
+	}
+
+	stats, err = s.repo.Aggregate(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute device stats: %w", err)
+	}
+	if stats == nil {
+		stats = []domain.DeviceStatsGroup{}
+	}
+
+	return stats, nil
+}
+
+// NormalizePagination applies the configured default and maximum page size
+// and clamps negative offsets
+func (s *DeviceService) NormalizePagination(limit, offset int) (int, int) {
 	if limit <= 0 {
-		limit = DefaultPageLimit
+		limit = s.defaultLimit
+	}
+	if limit > s.maxLimit {
+		limit = s.maxLimit
 	}
 	if offset < 0 {
 		offset = DefaultPageOffset
@@ -113,81 +323,243 @@ func normalizePagination(limit, offset int) (int, int) {
 // Enforces business rules:
 // - Name and brand cannot be updated if device is in-use
 // - CreatedAt is immutable (enforced by domain)
-func (s *DeviceService) UpdateDevice(ctx context.Context, id uuid.UUID, name, brand string, state domain.DeviceState) (*domain.Device, error) {
-	// Retrieve existing device
-	device, err := s.repo.GetByID(ctx, id)
+// Attributes are replaced only when passed via domain.WithAttributes.
+func (s *DeviceService) UpdateDevice(ctx context.Context, id uuid.UUID, name, brand string, state domain.DeviceState, opts ...domain.DeviceOption) (device *domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.UpdateDevice", deviceIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	brand, err = s.canonicalBrand(ctx, brand)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply update with domain validation and business rules
-	if err := device.Update(name, brand, state); err != nil {
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Rules are checked against the locked stored state, never a cached copy
+		device, err = s.repo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Apply update with domain validation and business rules
+		previous := *device
+		if err := device.Update(name, brand, state, opts...); err != nil {
+			return err
+		}
+		if err := s.checkModel(ctx, &previous, device); err != nil {
+			return err
+		}
+		if err := s.checkMove(ctx, previous.LocationID, device.LocationID); err != nil {
+			return err
+		}
+
+		// Persist changes
+		if err := s.repo.Update(ctx, device); err != nil {
+			return fmt.Errorf("failed to update device: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Persist changes
-	if err := s.repo.Update(ctx, device); err != nil {
-		return nil, fmt.Errorf("failed to update device: %w", err)
-	}
-
 	return device, nil
 }
 
 // PartialUpdateDevice updates specific fields of a device
-// Only updates the fields that are provided (non-empty)
-func (s *DeviceService) PartialUpdateDevice(ctx context.Context, id uuid.UUID, name, brand *string, state *domain.DeviceState) (*domain.Device, error) {
-	// Retrieve existing device
-	device, err := s.repo.GetByID(ctx, id)
+// Only updates the fields set in patch; attributes are merged
+func (s *DeviceService) PartialUpdateDevice(ctx context.Context, id uuid.UUID, patch domain.DevicePatch) (device *domain.Device, err error) {
+	ctx, span := startSpan(ctx, "DeviceService.PartialUpdateDevice", deviceIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if patch.Brand != nil {
+		brand, err := s.canonicalBrand(ctx, *patch.Brand)
+		if err != nil {
+			return nil, err
+		}
+		patch.Brand = &brand
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Rules are checked against the locked stored state, never a cached copy
+		device, err = s.repo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Apply update with domain validation and business rules
+		previous := *device
+		if err := device.ApplyPatch(patch); err != nil {
+			return err
+		}
+		if err := s.checkModel(ctx, &previous, device); err != nil {
+			return err
+		}
+		if err := s.checkMove(ctx, previous.LocationID, device.LocationID); err != nil {
+			return err
+		}
+
+		// Persist changes
+		if err := s.repo.Update(ctx, device); err != nil {
+			return fmt.Errorf("failed to update device: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Prepare values for update (use existing values if not provided)
-	updatedName := device.Name
-	updatedBrand := device.Brand
-	updatedState := device.State
+	return device, nil
+}
 
-	if name != nil {
-		updatedName = *name
-	}
-	if brand != nil {
-		updatedBrand = *brand
-	}
-	if state != nil {
-		updatedState = *state
-	}
+// ReplaceLabels replaces all labels of a device
+// Labels are metadata and can be changed regardless of the device state
+func (s *DeviceService) ReplaceLabels(ctx context.Context, id uuid.UUID, labels domain.Labels) (device *domain.Device, err error) {
+	return s.changeLabels(ctx, "DeviceService.ReplaceLabels", id, func(device *domain.Device) error {
+		return device.SetLabels(labels)
+	})
+}
 
-	// Apply update with domain validation and business rules
-	if err := device.Update(updatedName, updatedBrand, updatedState); err != nil {
+// UpdateLabels merges changes into the labels of a device; a nil value removes the label
+func (s *DeviceService) UpdateLabels(ctx context.Context, id uuid.UUID, changes map[string]*string) (device *domain.Device, err error) {
+	return s.changeLabels(ctx, "DeviceService.UpdateLabels", id, func(device *domain.Device) error {
+		return device.SetLabels(device.Labels.Merge(changes))
+	})
+}
+
+// DeleteLabel removes a single label from a device
+// Returns domain.ErrLabelNotFound if the device has no such label
+func (s *DeviceService) DeleteLabel(ctx context.Context, id uuid.UUID, key string) (device *domain.Device, err error) {
+	return s.changeLabels(ctx, "DeviceService.DeleteLabel", id, func(device *domain.Device) error {
+		if _, ok := device.Labels[key]; !ok {
+			return fmt.Errorf("label %q: %w", key, domain.ErrLabelNotFound)
+		}
+		return device.SetLabels(device.Labels.Merge(map[string]*string{key: nil}))
+	})
+}
+
+// changeLabels loads a device, applies change and persists the result
+func (s *DeviceService) changeLabels(ctx context.Context, spanName string, id uuid.UUID, change func(*domain.Device) error) (device *domain.Device, err error) {
+	ctx, span := startSpan(ctx, spanName, deviceIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		device, err = s.repo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := change(device); err != nil {
+			return err
+		}
+
+		if err := s.repo.Update(ctx, device); err != nil {
+			return fmt.Errorf("failed to update device labels: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Persist changes
-	if err := s.repo.Update(ctx, device); err != nil {
-		return nil, fmt.Errorf("failed to update device: %w", err)
+	return device, nil
+}
+
+// checkMove verifies the new location when a device moved and records the move
+// on the update span, so moves are traced like any other update
+func (s *DeviceService) checkMove(ctx context.Context, from, to *uuid.UUID) error {
+	if domain.SameID(from, to) {
+		return nil
 	}
+	if err := s.checkLocation(ctx, to); err != nil {
+		return err
+	}
+	recordMove(ctx, from, to)
+	return nil
+}
 
-	return device, nil
+// checkLocation verifies that a location exists when a location repository is configured
+func (s *DeviceService) checkLocation(ctx context.Context, locationID *uuid.UUID) error {
+	if locationID == nil || s.locations == nil {
+		return nil
+	}
+	if _, err := s.locations.GetByID(ctx, *locationID); err != nil {
+		if errors.Is(err, domain.ErrLocationNotFound) {
+			return domain.NewValidationError("location_id", "location does not exist")
+		}
+		return fmt.Errorf("failed to check location: %w", err)
+	}
+	return nil
 }
 
-// DeleteDevice deletes a device
-// Enforces business rule: in-use devices cannot be deleted
-func (s *DeviceService) DeleteDevice(ctx context.Context, id uuid.UUID) error {
-	// Retrieve existing device
-	device, err := s.repo.GetByID(ctx, id)
+// checkModel attaches the device's model when the model or brand changed,
+// so the brand is checked against the model and the category rules apply
+func (s *DeviceService) checkModel(ctx context.Context, previous, device *domain.Device) error {
+	if device.ModelID == nil {
+		return nil
+	}
+	if domain.SameID(previous.ModelID, device.ModelID) && previous.Brand == device.Brand {
+		return nil
+	}
+	model, err := s.getModel(ctx, *device.ModelID)
 	if err != nil {
 		return err
 	}
+	return device.AssignModel(model, false)
+}
 
-	// Check if device can be deleted (business rule)
-	if err := device.CanDelete(); err != nil {
-		return err
+// getModel loads a catalog model; a missing model is a validation error
+func (s *DeviceService) getModel(ctx context.Context, id uuid.UUID) (*domain.Model, error) {
+	if s.models == nil {
+		return nil, errors.New("model catalog is not configured")
+	}
+	model, err := s.models.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrModelNotFound) {
+			return nil, domain.NewValidationError("model_id", "model does not exist")
+		}
+		return nil, fmt.Errorf("failed to get model: %w", err)
 	}
+	return model, nil
+}
 
-	// Delete device
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("failed to delete device: %w", err)
+// canonicalBrand returns the catalog name of the brand that name refers to.
+// Unknown brands keep the given spelling and are added to the catalog when the device is saved.
+func (s *DeviceService) canonicalBrand(ctx context.Context, name string) (string, error) {
+	if s.brands == nil || domain.ValidateBrandName("brand", name) != nil {
+		return name, nil
+	}
+	brand, err := s.brands.Resolve(ctx, name)
+	if err != nil {
+		if errors.Is(err, domain.ErrBrandNotFound) {
+			return strings.TrimSpace(name), nil
+		}
+		return "", fmt.Errorf("failed to resolve brand: %w", err)
 	}
+	return brand.Name, nil
+}
 
-	return nil
+// DeleteDevice deletes a device
+// Enforces business rule: in-use devices cannot be deleted
+func (s *DeviceService) DeleteDevice(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "DeviceService.DeleteDevice", deviceIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// The lock keeps the device from being checked out between the check and the delete
+		device, err := s.repo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Check if device can be deleted (business rule)
+		if err := device.CanDelete(); err != nil {
+			return err
+		}
+
+		// Delete device
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete device: %w", err)
+		}
+		return nil
+	})
 }
@@ -3,7 +3,10 @@ package service_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"devices-api/internal/domain"
 	"devices-api/internal/service"
@@ -31,6 +34,30 @@ func (m *MockDeviceRepository) GetByID(ctx context.Context, id uuid.UUID) (*doma
 	return args.Get(0).(*domain.Device), args.Error(1)
 }
 
+func (m *MockDeviceRepository) GetByIDAsOf(ctx context.Context, id uuid.UUID, at time.Time) (*domain.Device, error) {
+	args := m.Called(ctx, id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) GetBySerial(ctx context.Context, brand, serialNumber string) (*domain.Device, error) {
+	args := m.Called(ctx, brand, serialNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
 func (m *MockDeviceRepository) List(ctx context.Context, limit, offset int) ([]*domain.Device, error) {
 	args := m.Called(ctx, limit, offset)
 	if args.Get(0) == nil {
@@ -55,6 +82,22 @@ func (m *MockDeviceRepository) ListByState(ctx context.Context, state domain.Dev
 	return args.Get(0).([]*domain.Device), args.Error(1)
 }
 
+func (m *MockDeviceRepository) Search(ctx context.Context, filter domain.DeviceFilter, limit, offset int) ([]*domain.Device, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) Aggregate(ctx context.Context, query domain.DeviceStatsQuery) ([]domain.DeviceStatsGroup, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.DeviceStatsGroup), args.Error(1)
+}
+
 func (m *MockDeviceRepository) Update(ctx context.Context, device *domain.Device) error {
 	args := m.Called(ctx, device)
 	return args.Error(0)
@@ -77,7 +120,7 @@ func TestCreateDevice_Success(t *testing.T) {
 	svc := service.NewDeviceService(mockRepo)
 	ctx := context.Background()
 
-	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Device")).Return(nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
 
 	// Act
 	device, err := svc.CreateDevice(ctx, "iPhone 15", "Apple")
@@ -156,6 +199,63 @@ func TestCreateDevice_ValidationError(t *testing.T) {
 	}
 }
 
+// TestCreateDevice_WithAttributes tests creation with custom attributes
+func TestCreateDevice_WithAttributes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	attributes := domain.Attributes{"os": "ios", "ram_gb": 8.0, "esim": true}
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act
+	device, err := svc.CreateDevice(ctx, "iPhone 15", "Apple", domain.WithAttributes(attributes))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, attributes, device.Attributes)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateDevice_InvalidAttributes tests attribute key and size limits
+func TestCreateDevice_InvalidAttributes(t *testing.T) {
+	tooMany := domain.Attributes{}
+	for i := range domain.MaxAttributes + 1 {
+		tooMany[fmt.Sprintf("key_%d", i)] = "v"
+	}
+
+	tests := []struct {
+		name       string
+		attributes domain.Attributes
+		field      string
+	}{
+		{"uppercase key", domain.Attributes{"OS": "ios"}, "attributes.OS"},
+		{"key with dash", domain.Attributes{"ram-gb": 8.0}, "attributes.ram-gb"},
+		{"nested value", domain.Attributes{"specs": []any{1, 2}}, "attributes.specs"},
+		{"long value", domain.Attributes{"notes": strings.Repeat("x", domain.MaxAttributeValueLength+1)}, "attributes.notes"},
+		{"too many keys", tooMany, "attributes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockDeviceRepository)
+			svc := service.NewDeviceService(mockRepo)
+
+			// Act
+			device, err := svc.CreateDevice(context.Background(), "iPhone 15", "Apple", domain.WithAttributes(tt.attributes))
+
+			// Assert
+			assert.Nil(t, device)
+			var validationErr *domain.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.field, validationErr.Field)
+			}
+		})
+	}
+}
+
 // TestCreateDevice_RepositoryError tests repository failure
 func TestCreateDevice_RepositoryError(t *testing.T) {
 	// Arrange
@@ -164,7 +264,7 @@ func TestCreateDevice_RepositoryError(t *testing.T) {
 	ctx := context.Background()
 
 	repoErr := errors.New("database connection failed")
-	mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Device")).Return(repoErr)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(repoErr)
 
 	// Act
 	device, err := svc.CreateDevice(ctx, "iPhone 15", "Apple")
@@ -176,6 +276,90 @@ func TestCreateDevice_RepositoryError(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestCreateDevice_ResolvesBrandAlias tests that brand aliases resolve to the canonical name
+func TestCreateDevice_ResolvesBrandAlias(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockBrands := new(MockBrandRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithBrandRepository(mockBrands))
+	ctx := context.Background()
+
+	brand := &domain.Brand{ID: uuid.New(), Name: "Hewlett-Packard"}
+	mockBrands.On("Resolve", mock.Anything, "hp").Return(brand, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act
+	device, err := svc.CreateDevice(ctx, "EliteBook", "hp")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Hewlett-Packard", device.Brand)
+	mockRepo.AssertExpectations(t)
+	mockBrands.AssertExpectations(t)
+}
+
+// TestCreateDevice_UnknownBrand tests that unknown brands keep their trimmed spelling
+func TestCreateDevice_UnknownBrand(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockBrands := new(MockBrandRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithBrandRepository(mockBrands))
+	ctx := context.Background()
+
+	mockBrands.On("Resolve", mock.Anything, " Fairphone ").Return(nil, domain.ErrBrandNotFound)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act
+	device, err := svc.CreateDevice(ctx, "Fairphone 5", " Fairphone ")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Fairphone", device.Brand)
+}
+
+// TestCreateDevice_WithSerialNumber tests that serial numbers are trimmed and validated
+func TestCreateDevice_WithSerialNumber(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act
+	device, err := svc.CreateDevice(ctx, "MacBook Pro", "Apple", domain.WithSerialNumber(" C02XK0AAJGH5 "))
+	_, invalidErr := svc.CreateDevice(ctx, "MacBook Pro", "Apple", domain.WithSerialNumber("C02 XK0"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "C02XK0AAJGH5", device.SerialNumber)
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, invalidErr, &validationErr)
+	assert.Equal(t, "serial_number", validationErr.Field)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+}
+
+// TestCreateDevice_DuplicateSerialNumber tests that repository conflicts are kept intact
+func TestCreateDevice_DuplicateSerialNumber(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	conflict := domain.NewConflictError(domain.ErrDeviceAlreadyExists, "serial_number", "already exists")
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(conflict)
+
+	// Act
+	device, err := svc.CreateDevice(ctx, "MacBook Pro", "Apple", domain.WithSerialNumber("C02XK0AAJGH5"))
+
+	// Assert
+	assert.Nil(t, device)
+	assert.True(t, domain.IsAlreadyExistsError(err))
+	var conflictErr *domain.ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, "serial_number", conflictErr.Field)
+}
+
 // ========== GetDevice Tests ==========
 
 // TestGetDevice_Success tests successful device retrieval
@@ -189,7 +373,7 @@ func TestGetDevice_Success(t *testing.T) {
 	expectedDevice, _ := domain.NewDevice("iPhone 15", "Apple")
 	expectedDevice.ID = deviceID
 
-	mockRepo.On("GetByID", ctx, deviceID).Return(expectedDevice, nil)
+	mockRepo.On("GetByID", mock.Anything, deviceID).Return(expectedDevice, nil)
 
 	// Act
 	device, err := svc.GetDevice(ctx, deviceID)
@@ -211,7 +395,7 @@ func TestGetDevice_NotFound(t *testing.T) {
 	ctx := context.Background()
 
 	deviceID := uuid.New()
-	mockRepo.On("GetByID", ctx, deviceID).Return(nil, domain.ErrDeviceNotFound)
+	mockRepo.On("GetByID", mock.Anything, deviceID).Return(nil, domain.ErrDeviceNotFound)
 
 	// Act
 	device, err := svc.GetDevice(ctx, deviceID)
@@ -223,6 +407,78 @@ func TestGetDevice_NotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestGetDeviceAsOf_Success tests reading a past version of a device
+func TestGetDeviceAsOf_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	at := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	past, _ := domain.NewDevice("iPhone 14", "Apple")
+	mockRepo.On("GetByIDAsOf", mock.Anything, past.ID, at).Return(past, nil)
+
+	// Act
+	device, err := svc.GetDeviceAsOf(ctx, past.ID, at)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, past, device)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+// TestGetDeviceAsOf_NotFound tests a device that did not exist at the time
+func TestGetDeviceAsOf_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+
+	id := uuid.New()
+	at := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetByIDAsOf", mock.Anything, id, at).Return(nil, domain.ErrDeviceNotFound)
+
+	// Act
+	device, err := svc.GetDeviceAsOf(context.Background(), id, at)
+
+	// Assert
+	assert.Nil(t, device)
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+}
+
+// TestGetDeviceBySerial_Success tests lookup by brand and serial number
+func TestGetDeviceBySerial_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	expectedDevice, _ := domain.NewDevice("MacBook Pro", "Apple", domain.WithSerialNumber("C02XK0AAJGH5"))
+	mockRepo.On("GetBySerial", mock.Anything, "apple", "C02XK0AAJGH5").Return(expectedDevice, nil)
+
+	// Act
+	device, err := svc.GetDeviceBySerial(ctx, "apple", " C02XK0AAJGH5")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, expectedDevice.ID, device.ID)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestGetDeviceBySerial_Validation tests that brand and serial number are required
+func TestGetDeviceBySerial_Validation(t *testing.T) {
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	_, err := svc.GetDeviceBySerial(ctx, " ", "C02XK0AAJGH5")
+	assert.True(t, domain.IsValidationError(err))
+
+	_, err = svc.GetDeviceBySerial(ctx, "Apple", "")
+	assert.True(t, domain.IsValidationError(err))
+
+	mockRepo.AssertNotCalled(t, "GetBySerial", mock.Anything, mock.Anything, mock.Anything)
+}
+
 // TestGetDevice_RepositoryError tests repository failure
 func TestGetDevice_RepositoryError(t *testing.T) {
 	// Arrange
@@ -232,7 +488,7 @@ func TestGetDevice_RepositoryError(t *testing.T) {
 
 	deviceID := uuid.New()
 	repoErr := errors.New("database connection failed")
-	mockRepo.On("GetByID", ctx, deviceID).Return(nil, repoErr)
+	mockRepo.On("GetByID", mock.Anything, deviceID).Return(nil, repoErr)
 
 	// Act
 	device, err := svc.GetDevice(ctx, deviceID)
@@ -256,7 +512,7 @@ func TestListDevices_Success(t *testing.T) {
 	device2, _ := domain.NewDevice("Galaxy S24", "Samsung")
 	expectedDevices := []*domain.Device{device1, device2}
 
-	mockRepo.On("List", ctx, 10, 0).Return(expectedDevices, nil)
+	mockRepo.On("List", mock.Anything, 10, 0).Return(expectedDevices, nil)
 
 	// Act
 	devices, err := svc.ListDevices(ctx, 10, 0)
@@ -277,7 +533,7 @@ func TestListDevices_EmptyList(t *testing.T) {
 	svc := service.NewDeviceService(mockRepo)
 	ctx := context.Background()
 
-	mockRepo.On("List", ctx, 10, 0).Return([]*domain.Device{}, nil)
+	mockRepo.On("List", mock.Anything, 10, 0).Return([]*domain.Device{}, nil)
 
 	// Act
 	devices, err := svc.ListDevices(ctx, 10, 0)
@@ -299,7 +555,7 @@ func TestListDevices_WithPagination(t *testing.T) {
 	device1, _ := domain.NewDevice("iPhone 15", "Apple")
 	expectedDevices := []*domain.Device{device1}
 
-	mockRepo.On("List", ctx, 5, 10).Return(expectedDevices, nil)
+	mockRepo.On("List", mock.Anything, 5, 10).Return(expectedDevices, nil)
 
 	// Act
 	devices, err := svc.ListDevices(ctx, 5, 10)
@@ -318,7 +574,7 @@ func TestListDevices_DefaultPagination(t *testing.T) {
 	svc := service.NewDeviceService(mockRepo)
 	ctx := context.Background()
 
-	mockRepo.On("List", ctx, 10, 0).Return([]*domain.Device{}, nil)
+	mockRepo.On("List", mock.Anything, 10, 0).Return([]*domain.Device{}, nil)
 
 	// Act - pass invalid values that should be normalized
 	devices, err := svc.ListDevices(ctx, 0, -1)
@@ -329,6 +585,26 @@ func TestListDevices_DefaultPagination(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestListDevices_ConfiguredPagination tests configured default and maximum page sizes
+func TestListDevices_ConfiguredPagination(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithPagination(25, 50))
+	ctx := context.Background()
+
+	mockRepo.On("List", mock.Anything, 25, 0).Return([]*domain.Device{}, nil)
+	mockRepo.On("List", mock.Anything, 50, 0).Return([]*domain.Device{}, nil)
+
+	// Act - default, then a limit above the maximum
+	_, errDefault := svc.ListDevices(ctx, 0, 0)
+	_, errCapped := svc.ListDevices(ctx, 500, 0)
+
+	// Assert
+	assert.NoError(t, errDefault)
+	assert.NoError(t, errCapped)
+	mockRepo.AssertExpectations(t)
+}
+
 // TestListDevices_RepositoryError tests repository failure
 func TestListDevices_RepositoryError(t *testing.T) {
 	// Arrange
@@ -337,7 +613,7 @@ func TestListDevices_RepositoryError(t *testing.T) {
 	ctx := context.Background()
 
 	repoErr := errors.New("database connection failed")
-	mockRepo.On("List", ctx, 10, 0).Return(nil, repoErr)
+	mockRepo.On("List", mock.Anything, 10, 0).Return(nil, repoErr)
 
 	// Act
 	devices, err := svc.ListDevices(ctx, 10, 0)
@@ -362,7 +638,7 @@ func TestListDevicesByBrand_Success(t *testing.T) {
 	device2, _ := domain.NewDevice("MacBook Pro", "Apple")
 	expectedDevices := []*domain.Device{device1, device2}
 
-	mockRepo.On("ListByBrand", ctx, "Apple", 10, 0).Return(expectedDevices, nil)
+	mockRepo.On("ListByBrand", mock.Anything, "Apple", 10, 0).Return(expectedDevices, nil)
 
 	// Act
 	devices, err := svc.ListDevicesByBrand(ctx, "Apple", 10, 0)
@@ -402,7 +678,7 @@ func TestListDevicesByState_Success(t *testing.T) {
 	device1, _ := domain.NewDevice("iPhone 15", "Apple")
 	expectedDevices := []*domain.Device{device1}
 
-	mockRepo.On("ListByState", ctx, domain.DeviceStateActive, 10, 0).Return(expectedDevices, nil)
+	mockRepo.On("ListByState", mock.Anything, domain.DeviceStateActive, 10, 0).Return(expectedDevices, nil)
 
 	// Act
 	devices, err := svc.ListDevicesByState(ctx, domain.DeviceStateActive, 10, 0)
@@ -443,8 +719,8 @@ func TestUpdateDevice_Success(t *testing.T) {
 	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
 	existingDevice.ID = deviceID
 
-	mockRepo.On("GetByID", ctx, deviceID).Return(existingDevice, nil)
-	mockRepo.On("Update", ctx, mock.AnythingOfType("*domain.Device")).Return(nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
 
 	// Act
 	device, err := svc.UpdateDevice(ctx, deviceID, "iPhone 15 Pro", "Apple", domain.DeviceStateActive)
@@ -466,7 +742,7 @@ func TestUpdateDevice_NotFound(t *testing.T) {
 	ctx := context.Background()
 
 	deviceID := uuid.New()
-	mockRepo.On("GetByID", ctx, deviceID).Return(nil, domain.ErrDeviceNotFound)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(nil, domain.ErrDeviceNotFound)
 
 	// Act
 	device, err := svc.UpdateDevice(ctx, deviceID, "iPhone 15", "Apple", domain.DeviceStateActive)
@@ -490,7 +766,7 @@ func TestUpdateDevice_InUseDevice(t *testing.T) {
 	inUseDevice.ID = deviceID
 	inUseDevice.State = domain.DeviceStateInUse
 
-	mockRepo.On("GetByID", ctx, deviceID).Return(inUseDevice, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(inUseDevice, nil)
 
 	// Act
 	device, err := svc.UpdateDevice(ctx, deviceID, "iPhone 15", "Samsung", domain.DeviceStateInUse)
@@ -502,6 +778,34 @@ func TestUpdateDevice_InUseDevice(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestUpdateDevice_InUseDeviceBrandVariant tests that a variant spelling of the
+// current brand does not count as a brand change
+func TestUpdateDevice_InUseDeviceBrandVariant(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockBrands := new(MockBrandRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithBrandRepository(mockBrands))
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	inUseDevice, _ := domain.NewDevice("iPhone 14", "Apple")
+	inUseDevice.ID = deviceID
+	inUseDevice.State = domain.DeviceStateInUse
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(inUseDevice, nil)
+	mockBrands.On("Resolve", mock.Anything, "APPLE ").Return(&domain.Brand{ID: uuid.New(), Name: "Apple"}, nil)
+	mockRepo.On("Update", mock.Anything, inUseDevice).Return(nil)
+
+	// Act
+	device, err := svc.UpdateDevice(ctx, deviceID, "iPhone 14", "APPLE ", domain.DeviceStateActive)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Apple", device.Brand)
+	assert.Equal(t, domain.DeviceStateActive, device.State)
+	mockRepo.AssertExpectations(t)
+}
+
 // TestUpdateDevice_ValidationError tests validation during update
 func TestUpdateDevice_ValidationError(t *testing.T) {
 	// Arrange
@@ -513,7 +817,7 @@ func TestUpdateDevice_ValidationError(t *testing.T) {
 	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
 	existingDevice.ID = deviceID
 
-	mockRepo.On("GetByID", ctx, deviceID).Return(existingDevice, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
 
 	// Act - try to update with invalid name (too short)
 	device, err := svc.UpdateDevice(ctx, deviceID, "ab", "Apple", domain.DeviceStateActive)
@@ -536,8 +840,8 @@ func TestUpdateDevice_RepositoryError(t *testing.T) {
 	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
 	existingDevice.ID = deviceID
 
-	mockRepo.On("GetByID", ctx, deviceID).Return(existingDevice, nil)
-	mockRepo.On("Update", ctx, mock.AnythingOfType("*domain.Device")).Return(errors.New("database error"))
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(errors.New("database error"))
 
 	// Act
 	device, err := svc.UpdateDevice(ctx, deviceID, "iPhone 15", "Apple", domain.DeviceStateActive)
@@ -563,11 +867,11 @@ func TestPartialUpdateDevice_Success(t *testing.T) {
 	existingDevice.ID = deviceID
 
 	newName := "iPhone 15"
-	mockRepo.On("GetByID", ctx, deviceID).Return(existingDevice, nil)
-	mockRepo.On("Update", ctx, mock.AnythingOfType("*domain.Device")).Return(nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
 
 	// Act - only update name
-	device, err := svc.PartialUpdateDevice(ctx, deviceID, &newName, nil, nil)
+	device, err := svc.PartialUpdateDevice(ctx, deviceID, domain.DevicePatch{Name: &newName})
 
 	// Assert
 	assert.NoError(t, err)
@@ -589,11 +893,11 @@ func TestPartialUpdateDevice_UpdateState(t *testing.T) {
 	existingDevice.ID = deviceID
 
 	newState := domain.DeviceStateInactive
-	mockRepo.On("GetByID", ctx, deviceID).Return(existingDevice, nil)
-	mockRepo.On("Update", ctx, mock.AnythingOfType("*domain.Device")).Return(nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
 
 	// Act - only update state
-	device, err := svc.PartialUpdateDevice(ctx, deviceID, nil, nil, &newState)
+	device, err := svc.PartialUpdateDevice(ctx, deviceID, domain.DevicePatch{State: &newState})
 
 	// Assert
 	assert.NoError(t, err)
@@ -603,6 +907,381 @@ func TestPartialUpdateDevice_UpdateState(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// TestPartialUpdateDevice_MergesAttributes tests attribute merge semantics
+func TestPartialUpdateDevice_MergesAttributes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple",
+		domain.WithAttributes(domain.Attributes{"os": "ios", "ram_gb": 6.0, "po": "PO-1"}))
+	existingDevice.ID = deviceID
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act - change one key, add one, remove one
+	device, err := svc.PartialUpdateDevice(ctx, deviceID, domain.DevicePatch{
+		Attributes: map[string]any{"ram_gb": 8.0, "os_version": "17.1", "po": nil},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Attributes{"os": "ios", "ram_gb": 8.0, "os_version": "17.1"}, device.Attributes)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPartialUpdateDevice_InvalidAttributes tests attribute validation on patch
+func TestPartialUpdateDevice_InvalidAttributes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
+	existingDevice.ID = deviceID
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+
+	// Act - nested values are not allowed
+	device, err := svc.PartialUpdateDevice(ctx, deviceID, domain.DevicePatch{
+		Attributes: map[string]any{"specs": map[string]any{"ram": 8}},
+	})
+
+	// Assert
+	assert.Nil(t, device)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+// TestPartialUpdateDevice_MoveToLocation tests moving a device to an existing location
+func TestPartialUpdateDevice_MoveToLocation(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockLocations := new(MockLocationRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithLocationRepository(mockLocations))
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
+	existingDevice.ID = deviceID
+	existingDevice.State = domain.DeviceStateInUse
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockLocations.On("GetByID", mock.Anything, site.ID).Return(site, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act - in-use devices can still move
+	device, err := svc.PartialUpdateDevice(ctx, deviceID, domain.DevicePatch{LocationID: &site.ID})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &site.ID, device.LocationID)
+	mockRepo.AssertExpectations(t)
+	mockLocations.AssertExpectations(t)
+}
+
+// TestPartialUpdateDevice_UnknownLocation tests that unknown locations are rejected
+func TestPartialUpdateDevice_UnknownLocation(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockLocations := new(MockLocationRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithLocationRepository(mockLocations))
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
+	existingDevice.ID = deviceID
+	locationID := uuid.New()
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockLocations.On("GetByID", mock.Anything, locationID).Return(nil, domain.ErrLocationNotFound)
+
+	// Act
+	device, err := svc.PartialUpdateDevice(ctx, deviceID, domain.DevicePatch{LocationID: &locationID})
+
+	// Assert
+	assert.Nil(t, device)
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "location_id", validationErr.Field)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+// TestPartialUpdateDevice_ClearLocation tests removing a device from its location
+func TestPartialUpdateDevice_ClearLocation(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockLocations := new(MockLocationRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithLocationRepository(mockLocations))
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	locationID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple", domain.WithLocation(&locationID))
+	existingDevice.ID = deviceID
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act
+	device, err := svc.PartialUpdateDevice(ctx, deviceID, domain.DevicePatch{ClearLocation: true})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Nil(t, device.LocationID)
+	mockLocations.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+// ========== SearchDevices Tests ==========
+
+// TestSearchDevices_Success tests searching with combined filters
+func TestSearchDevices_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	filter := domain.DeviceFilter{
+		Brand: "Apple",
+		State: domain.DeviceStateActive,
+		Attributes: []domain.AttributeFilter{
+			{Key: "ram_gb", Operator: domain.AttributeOpGreaterEqual, Value: "16"},
+		},
+	}
+	mockRepo.On("Search", mock.Anything, filter, 10, 0).Return([]*domain.Device{}, nil)
+
+	// Act
+	devices, err := svc.SearchDevices(ctx, filter, 0, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, devices)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestSearchDevices_InvalidFilter tests filter validation
+func TestSearchDevices_InvalidFilter(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	filter := domain.DeviceFilter{
+		Attributes: []domain.AttributeFilter{
+			{Key: "ram_gb", Operator: domain.AttributeOpGreater, Value: "lots"},
+		},
+	}
+
+	// Act
+	devices, err := svc.SearchDevices(ctx, filter, 0, 0)
+
+	// Assert
+	assert.Nil(t, devices)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestSearchDevices_LabelSelector tests that label selectors reach the repository
+func TestSearchDevices_LabelSelector(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	selector, err := domain.ParseLabelSelector("team=mobile,env in (lab,staging)")
+	assert.NoError(t, err)
+	filter := domain.DeviceFilter{Labels: selector}
+	mockRepo.On("Search", mock.Anything, filter, 10, 0).Return([]*domain.Device{}, nil)
+
+	// Act
+	devices, err := svc.SearchDevices(ctx, filter, 0, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, devices)
+	mockRepo.AssertExpectations(t)
+}
+
+// ========== Stats Tests ==========
+
+// TestDeviceStats_Success tests grouped statistics
+func TestDeviceStats_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	query := domain.DeviceStatsQuery{GroupBy: []domain.StatsDimension{domain.StatsByBrand}, Interval: domain.StatsIntervalMonth}
+	groups := []domain.DeviceStatsGroup{{Keys: map[domain.StatsDimension]string{domain.StatsByBrand: "Apple"}, Count: 3}}
+	mockRepo.On("Aggregate", mock.Anything, query).Return(groups, nil)
+
+	// Act
+	stats, err := svc.DeviceStats(ctx, query)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, groups, stats)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeviceStats_NoDevices tests that no matching devices yield an empty, non-nil result
+func TestDeviceStats_NoDevices(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	mockRepo.On("Aggregate", mock.Anything, domain.DeviceStatsQuery{}).Return(nil, nil)
+
+	// Act
+	stats, err := svc.DeviceStats(ctx, domain.DeviceStatsQuery{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, stats)
+	assert.Empty(t, stats)
+}
+
+// TestDeviceStats_InvalidDimension tests that invalid grouping never reaches the repository
+func TestDeviceStats_InvalidDimension(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+
+	// Act
+	stats, err := svc.DeviceStats(context.Background(), domain.DeviceStatsQuery{GroupBy: []domain.StatsDimension{"color"}})
+
+	// Assert
+	assert.Nil(t, stats)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "Aggregate", mock.Anything, mock.Anything)
+}
+
+// ========== Label Tests ==========
+
+// TestReplaceLabels_InUseDevice tests that labels can change while a device is in use
+func TestReplaceLabels_InUseDevice(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple", domain.WithLabels(domain.Labels{"team": "web"}))
+	existingDevice.ID = deviceID
+	existingDevice.State = domain.DeviceStateInUse
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act
+	device, err := svc.ReplaceLabels(ctx, deviceID, domain.Labels{"team": "mobile", "env": "lab"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Labels{"team": "mobile", "env": "lab"}, device.Labels)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestReplaceLabels_InvalidLabel tests label syntax validation
+func TestReplaceLabels_InvalidLabel(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
+	existingDevice.ID = deviceID
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+
+	// Act
+	device, err := svc.ReplaceLabels(ctx, deviceID, domain.Labels{"team": "-mobile"})
+
+	// Assert
+	assert.Nil(t, device)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+// TestUpdateLabels_MergesLabels tests label merge semantics
+func TestUpdateLabels_MergesLabels(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple",
+		domain.WithLabels(domain.Labels{"team": "web", "env": "lab", "owner": "alice"}))
+	existingDevice.ID = deviceID
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act - change one label, add one, remove one
+	team, tier := "mobile", "gold"
+	device, err := svc.UpdateLabels(ctx, deviceID, map[string]*string{"team": &team, "tier": &tier, "owner": nil})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Labels{"team": "mobile", "env": "lab", "tier": "gold"}, device.Labels)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteLabel_Success tests removing a single label
+func TestDeleteLabel_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple",
+		domain.WithLabels(domain.Labels{"team": "mobile", "example.com/env": "lab"}))
+	existingDevice.ID = deviceID
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Update", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act
+	device, err := svc.DeleteLabel(ctx, deviceID, "example.com/env")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Labels{"team": "mobile"}, device.Labels)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteLabel_NotFound tests removing a label the device does not have
+func TestDeleteLabel_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	svc := service.NewDeviceService(mockRepo)
+	ctx := context.Background()
+
+	deviceID := uuid.New()
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
+	existingDevice.ID = deviceID
+
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+
+	// Act
+	device, err := svc.DeleteLabel(ctx, deviceID, "team")
+
+	// Assert
+	assert.Nil(t, device)
+	assert.ErrorIs(t, err, domain.ErrLabelNotFound)
+	assert.True(t, domain.IsNotFoundError(err))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
 // ========== DeleteDevice Tests ==========
 
 // TestDeleteDevice_Success tests successful device deletion
@@ -617,8 +1296,8 @@ func TestDeleteDevice_Success(t *testing.T) {
 	existingDevice.ID = deviceID
 	existingDevice.State = domain.DeviceStateActive
 
-	mockRepo.On("GetByID", ctx, deviceID).Return(existingDevice, nil)
-	mockRepo.On("Delete", ctx, deviceID).Return(nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Delete", mock.Anything, deviceID).Return(nil)
 
 	// Act
 	err := svc.DeleteDevice(ctx, deviceID)
@@ -636,7 +1315,7 @@ func TestDeleteDevice_NotFound(t *testing.T) {
 	ctx := context.Background()
 
 	deviceID := uuid.New()
-	mockRepo.On("GetByID", ctx, deviceID).Return(nil, domain.ErrDeviceNotFound)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(nil, domain.ErrDeviceNotFound)
 
 	// Act
 	err := svc.DeleteDevice(ctx, deviceID)
@@ -659,7 +1338,7 @@ func TestDeleteDevice_InUseDevice(t *testing.T) {
 	inUseDevice.ID = deviceID
 	inUseDevice.State = domain.DeviceStateInUse
 
-	mockRepo.On("GetByID", ctx, deviceID).Return(inUseDevice, nil)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(inUseDevice, nil)
 
 	// Act
 	err := svc.DeleteDevice(ctx, deviceID)
@@ -682,8 +1361,8 @@ func TestDeleteDevice_RepositoryError(t *testing.T) {
 	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
 	existingDevice.ID = deviceID
 
-	mockRepo.On("GetByID", ctx, deviceID).Return(existingDevice, nil)
-	mockRepo.On("Delete", ctx, deviceID).Return(errors.New("database error"))
+	mockRepo.On("GetByIDForUpdate", mock.Anything, deviceID).Return(existingDevice, nil)
+	mockRepo.On("Delete", mock.Anything, deviceID).Return(errors.New("database error"))
 
 	// Act
 	err := svc.DeleteDevice(ctx, deviceID)
@@ -693,3 +1372,64 @@ func TestDeleteDevice_RepositoryError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to delete device")
 	mockRepo.AssertExpectations(t)
 }
+
+// fakeTxManager marks the context passed to the work so tests can check which calls ran in the transaction
+type fakeTxManager struct {
+	results []error
+}
+
+// inTxKey marks contexts of a fakeTxManager transaction
+type inTxKey struct{}
+
+func (f *fakeTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(context.WithValue(ctx, inTxKey{}, true))
+	f.results = append(f.results, err)
+	return err
+}
+
+// inTx matches contexts of a fakeTxManager transaction
+var inTx = mock.MatchedBy(func(ctx context.Context) bool {
+	return ctx.Value(inTxKey{}) != nil
+})
+
+// TestDeleteDevice_LocksInTransaction tests that the check and the delete run in one transaction
+func TestDeleteDevice_LocksInTransaction(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	tx := &fakeTxManager{}
+	svc := service.NewDeviceService(mockRepo, service.WithTxManager(tx))
+
+	existingDevice, _ := domain.NewDevice("iPhone 14", "Apple")
+	mockRepo.On("GetByIDForUpdate", inTx, existingDevice.ID).Return(existingDevice, nil)
+	mockRepo.On("Delete", inTx, existingDevice.ID).Return(nil)
+
+	// Act
+	err := svc.DeleteDevice(context.Background(), existingDevice.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []error{nil}, tx.results)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUpdateDevice_RuleViolationRollsBack tests that a rejected update ends its transaction with the error
+func TestUpdateDevice_RuleViolationRollsBack(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	tx := &fakeTxManager{}
+	svc := service.NewDeviceService(mockRepo, service.WithTxManager(tx))
+
+	inUseDevice, _ := domain.NewDevice("iPhone 14", "Apple")
+	inUseDevice.State = domain.DeviceStateInUse
+	mockRepo.On("GetByIDForUpdate", inTx, inUseDevice.ID).Return(inUseDevice, nil)
+
+	// Act
+	device, err := svc.UpdateDevice(context.Background(), inUseDevice.ID, "iPhone 15", "Apple", domain.DeviceStateInUse)
+
+	// Assert
+	assert.Nil(t, device)
+	assert.True(t, domain.IsBusinessRuleError(err))
+	assert.Len(t, tx.results, 1)
+	assert.True(t, domain.IsBusinessRuleError(tx.results[0]))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ExpiryMonitor periodically emits a domain.EventDeviceExpiringSoon event when a
+// device's warranty end or EOL date comes within one of the thresholds.
+// Each device is notified once per date and threshold, even across restarts
+// and replicas, because notices are claimed in the repository before publishing.
+type ExpiryMonitor struct {
+	repo       domain.ExpiryRepository
+	publisher  domain.EventPublisher
+	thresholds []int
+	logger     *slog.Logger
+}
+
+// NewExpiryMonitor creates an expiry monitor notifying at thresholds (days before expiry)
+func NewExpiryMonitor(repo domain.ExpiryRepository, publisher domain.EventPublisher, thresholds []int, logger *slog.Logger) *ExpiryMonitor {
+	return &ExpiryMonitor{
+		repo:       repo,
+		publisher:  publisher,
+		thresholds: thresholds,
+		logger:     logger,
+	}
+}
+
+// Run checks for expiries immediately and then every interval until ctx is cancelled.
+// Failed checks are logged and retried on the next tick.
+func (m *ExpiryMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		emitted, err := m.Check(ctx, time.Now().UTC())
+		if err != nil && ctx.Err() == nil {
+			m.logger.Error("Expiry check failed", "error", err)
+		} else if emitted > 0 {
+			m.logger.Info("Expiry check completed", "events", emitted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check emits an event for every expiry that reached a threshold as of now and
+// has not been notified yet, and returns the number of events emitted.
+// A failed publish is released so the next check retries it.
+func (m *ExpiryMonitor) Check(ctx context.Context, now time.Time) (emitted int, err error) {
+	ctx, span := startSpan(ctx, "ExpiryMonitor.Check")
+	defer func() {
+		span.SetAttributes(attribute.Int("events.emitted", emitted))
+		endSpan(span, err)
+	}()
+
+	if len(m.thresholds) == 0 {
+		return 0, nil
+	}
+
+	from, to, err := domain.ExpiryWindow(now, slices.Max(m.thresholds))
+	if err != nil {
+		return 0, err
+	}
+	expiries, err := m.repo.ListExpiring(ctx, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expiring devices: %w", err)
+	}
+
+	var errs []error
+	for _, expiry := range expiries {
+		daysLeft := expiry.DaysLeft(now)
+		threshold, ok := domain.ReachedThreshold(daysLeft, m.thresholds)
+		if !ok {
+			continue
+		}
+
+		notice := domain.ExpiryNotice{Expiry: expiry, ThresholdDays: threshold}
+		claimed, err := m.repo.MarkNotified(ctx, notice)
+		if err != nil {
+			return emitted, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := m.publisher.Publish(ctx, expiringSoonEvent(notice, daysLeft, now)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish expiry event for device %s: %w", expiry.DeviceID, err))
+			if err := m.repo.UnmarkNotified(ctx, notice); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		emitted++
+	}
+
+	return emitted, errors.Join(errs...)
+}
+
+// expiringSoonEvent builds the event published for notice
+func expiringSoonEvent(notice domain.ExpiryNotice, daysLeft int, now time.Time) domain.Event {
+	return domain.Event{
+		Type:       domain.EventDeviceExpiringSoon,
+		DeviceID:   notice.DeviceID,
+		OccurredAt: now,
+		Data: map[string]any{
+			"device_name":    notice.DeviceName,
+			"brand":          notice.Brand,
+			"serial_number":  notice.SerialNumber,
+			"kind":           string(notice.Kind),
+			"expires_on":     notice.Date.Format(time.DateOnly),
+			"days_left":      daysLeft,
+			"threshold_days": notice.ThresholdDays,
+		},
+	}
+}
@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockExpiryRepository is a mock implementation of domain.ExpiryRepository
+type MockExpiryRepository struct {
+	mock.Mock
+}
+
+func (m *MockExpiryRepository) ListExpiring(ctx context.Context, from, to time.Time) ([]domain.Expiry, error) {
+	args := m.Called(ctx, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Expiry), args.Error(1)
+}
+
+func (m *MockExpiryRepository) MarkNotified(ctx context.Context, notice domain.ExpiryNotice) (bool, error) {
+	args := m.Called(ctx, notice)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockExpiryRepository) UnmarkNotified(ctx context.Context, notice domain.ExpiryNotice) error {
+	args := m.Called(ctx, notice)
+	return args.Error(0)
+}
+
+// MockEventPublisher is a mock implementation of domain.EventPublisher
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(ctx context.Context, event domain.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+var checkTime = time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+
+func expiryIn(days int) domain.Expiry {
+	return domain.Expiry{
+		DeviceID:   uuid.New(),
+		DeviceName: "Dev laptop",
+		Brand:      "Apple",
+		BrandID:    uuid.New(),
+		Kind:       domain.ExpiryKindWarranty,
+		Date:       time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days),
+	}
+}
+
+func newTestMonitor(repo domain.ExpiryRepository, publisher domain.EventPublisher) *service.ExpiryMonitor {
+	return service.NewExpiryMonitor(repo, publisher, []int{30, 7, 1}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// ========== ExpiryMonitor Tests ==========
+
+// TestExpiryMonitor_Check_PublishesSmallestThreshold tests that each expiry is notified at the threshold it reached
+func TestExpiryMonitor_Check_PublishesSmallestThreshold(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockExpiryRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestMonitor(mockRepo, mockPublisher)
+
+	soon := expiryIn(5)
+	mockRepo.On("ListExpiring", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Expiry{soon}, nil)
+	mockRepo.On("MarkNotified", mock.Anything, domain.ExpiryNotice{Expiry: soon, ThresholdDays: 7}).Return(true, nil)
+	mockPublisher.On("Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		return event.Type == domain.EventDeviceExpiringSoon &&
+			event.DeviceID == soon.DeviceID &&
+			event.Data["days_left"] == 5 &&
+			event.Data["threshold_days"] == 7 &&
+			event.Data["expires_on"] == "2025-06-06"
+	})).Return(nil)
+
+	// Act
+	emitted, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, emitted)
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+// TestExpiryMonitor_Check_SkipsAlreadyNotified tests that a notice is emitted only once
+func TestExpiryMonitor_Check_SkipsAlreadyNotified(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockExpiryRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestMonitor(mockRepo, mockPublisher)
+
+	soon := expiryIn(20)
+	mockRepo.On("ListExpiring", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Expiry{soon}, nil)
+	mockRepo.On("MarkNotified", mock.Anything, domain.ExpiryNotice{Expiry: soon, ThresholdDays: 30}).Return(false, nil)
+
+	// Act
+	emitted, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 0, emitted)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// TestExpiryMonitor_Check_ListsUpToLargestThreshold tests the window queried from the repository
+func TestExpiryMonitor_Check_ListsUpToLargestThreshold(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockExpiryRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestMonitor(mockRepo, mockPublisher)
+
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("ListExpiring", mock.Anything, from, from.AddDate(0, 0, 30)).Return([]domain.Expiry{}, nil)
+
+	// Act
+	emitted, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 0, emitted)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestExpiryMonitor_Check_PublishFailureReleasesNotice tests that failed events are retried on the next check
+func TestExpiryMonitor_Check_PublishFailureReleasesNotice(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockExpiryRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestMonitor(mockRepo, mockPublisher)
+
+	failing, ok := expiryIn(1), expiryIn(3)
+	failingNotice := domain.ExpiryNotice{Expiry: failing, ThresholdDays: 1}
+	publishErr := errors.New("broker unavailable")
+	mockRepo.On("ListExpiring", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Expiry{failing, ok}, nil)
+	mockRepo.On("MarkNotified", mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("UnmarkNotified", mock.Anything, failingNotice).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		return event.DeviceID == failing.DeviceID
+	})).Return(publishErr)
+	mockPublisher.On("Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		return event.DeviceID == ok.DeviceID
+	})).Return(nil)
+
+	// Act
+	emitted, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	assert.ErrorIs(t, err, publishErr)
+	assert.Equal(t, 1, emitted)
+	mockRepo.AssertExpectations(t)
+}
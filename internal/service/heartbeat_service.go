@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// heartbeatTokenBytes is the number of random bytes in a heartbeat token
+const heartbeatTokenBytes = 32
+
+// HeartbeatService handles heartbeats sent by connected devices and the
+// tokens they authenticate with
+type HeartbeatService struct {
+	repo domain.HeartbeatRepository
+}
+
+// NewHeartbeatService creates a new heartbeat service
+func NewHeartbeatService(repo domain.HeartbeatRepository) *HeartbeatService {
+	return &HeartbeatService{
+		repo: repo,
+	}
+}
+
+// IssueToken creates a new heartbeat token for the device, replacing any previous one.
+// The token is only returned here; the repository keeps a hash of it.
+func (s *HeartbeatService) IssueToken(ctx context.Context, deviceID uuid.UUID) (token string, err error) {
+	ctx, span := startSpan(ctx, "HeartbeatService.IssueToken", deviceIDAttr(deviceID.String()))
+	defer func() { endSpan(span, err) }()
+
+	secret := make([]byte, heartbeatTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate heartbeat token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(secret)
+
+	if err := s.repo.SetTokenHash(ctx, deviceID, hashToken(token)); err != nil {
+		if domain.IsNotFoundError(err) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to save heartbeat token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Authenticate checks that token is the heartbeat token of the device.
+// Unknown devices, devices without a token and wrong tokens all fail with
+// domain.ErrUnauthorized, so callers cannot probe which devices exist.
+func (s *HeartbeatService) Authenticate(ctx context.Context, deviceID uuid.UUID, token string) (err error) {
+	ctx, span := startSpan(ctx, "HeartbeatService.Authenticate", deviceIDAttr(deviceID.String()))
+	defer func() { endSpan(span, err) }()
+
+	if token == "" {
+		return domain.ErrUnauthorized
+	}
+
+	hash, err := s.repo.TokenHash(ctx, deviceID)
+	if err != nil {
+		if domain.IsNotFoundError(err) {
+			return domain.ErrUnauthorized
+		}
+		return fmt.Errorf("failed to get heartbeat token: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare(hash, hashToken(token)) != 1 {
+		return domain.ErrUnauthorized
+	}
+
+	return nil
+}
+
+// RecordHeartbeat records that the device is alive now. Non-nil reported fields
+// replace the fields the device reported before.
+func (s *HeartbeatService) RecordHeartbeat(ctx context.Context, deviceID uuid.UUID, reported domain.Attributes) (heartbeat *domain.Heartbeat, err error) {
+	ctx, span := startSpan(ctx, "HeartbeatService.RecordHeartbeat", deviceIDAttr(deviceID.String()))
+	defer func() { endSpan(span, err) }()
+
+	heartbeat, err = domain.NewHeartbeat(deviceID, time.Now(), reported)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Record(ctx, heartbeat); err != nil {
+		if domain.IsNotFoundError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to record heartbeat: %w", err)
+	}
+
+	return heartbeat, nil
+}
+
+// hashToken returns the SHA-256 hash stored for token.
+// Tokens are random, so a fast unsalted hash is enough.
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
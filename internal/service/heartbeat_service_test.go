@@ -0,0 +1,154 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockHeartbeatRepository is a mock implementation of domain.HeartbeatRepository
+type MockHeartbeatRepository struct {
+	mock.Mock
+}
+
+func (m *MockHeartbeatRepository) SetTokenHash(ctx context.Context, deviceID uuid.UUID, hash []byte) error {
+	args := m.Called(ctx, deviceID, hash)
+	return args.Error(0)
+}
+
+func (m *MockHeartbeatRepository) TokenHash(ctx context.Context, deviceID uuid.UUID) ([]byte, error) {
+	args := m.Called(ctx, deviceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockHeartbeatRepository) Record(ctx context.Context, heartbeat *domain.Heartbeat) error {
+	args := m.Called(ctx, heartbeat)
+	return args.Error(0)
+}
+
+func (m *MockHeartbeatRepository) ListStale(ctx context.Context, seenBefore time.Time, limit, offset int) ([]*domain.Device, error) {
+	args := m.Called(ctx, seenBefore, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Device), args.Error(1)
+}
+
+func (m *MockHeartbeatRepository) MarkInactive(ctx context.Context, device *domain.Device, previousState domain.DeviceState, seenBefore time.Time) (bool, error) {
+	args := m.Called(ctx, device, previousState, seenBefore)
+	return args.Bool(0), args.Error(1)
+}
+
+// ========== Heartbeat Token Tests ==========
+
+// TestIssueToken_StoresHashOnly tests that the issued token authenticates and only its hash is stored
+func TestIssueToken_StoresHashOnly(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockHeartbeatRepository)
+	svc := service.NewHeartbeatService(mockRepo)
+
+	deviceID := uuid.New()
+	var stored []byte
+	mockRepo.On("SetTokenHash", mock.Anything, deviceID, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(2).([]byte)
+	}).Return(nil)
+
+	// Act
+	token, err := svc.IssueToken(context.Background(), deviceID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	sum := sha256.Sum256([]byte(token))
+	assert.Equal(t, sum[:], stored)
+	assert.NotContains(t, string(stored), token)
+}
+
+// TestAuthenticate tests accepted and rejected heartbeat tokens
+func TestAuthenticate(t *testing.T) {
+	deviceID := uuid.New()
+	sum := sha256.Sum256([]byte("s3cret"))
+
+	tests := []struct {
+		name    string
+		token   string
+		hash    []byte
+		repoErr error
+		wantErr error
+	}{
+		{"valid token", "s3cret", sum[:], nil, nil},
+		{"wrong token", "guess", sum[:], nil, domain.ErrUnauthorized},
+		{"missing token", "", sum[:], nil, domain.ErrUnauthorized},
+		{"device without token", "s3cret", nil, domain.ErrDeviceNotFound, domain.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockHeartbeatRepository)
+			svc := service.NewHeartbeatService(mockRepo)
+			mockRepo.On("TokenHash", mock.Anything, deviceID).Return(tt.hash, tt.repoErr)
+
+			// Act
+			err := svc.Authenticate(context.Background(), deviceID, tt.token)
+
+			// Assert
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// ========== RecordHeartbeat Tests ==========
+
+// TestRecordHeartbeat_Success tests that a heartbeat is recorded with its reported fields
+func TestRecordHeartbeat_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockHeartbeatRepository)
+	svc := service.NewHeartbeatService(mockRepo)
+
+	deviceID := uuid.New()
+	mockRepo.On("Record", mock.Anything, mock.MatchedBy(func(h *domain.Heartbeat) bool {
+		return h.DeviceID == deviceID && h.Reported["firmware"] == "1.4.2"
+	})).Return(nil)
+
+	// Act
+	heartbeat, err := svc.RecordHeartbeat(context.Background(), deviceID, domain.Attributes{"firmware": "1.4.2"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), heartbeat.SeenAt, time.Second)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestRecordHeartbeat_InvalidReported tests that reported fields follow the attribute rules
+func TestRecordHeartbeat_InvalidReported(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockHeartbeatRepository)
+	svc := service.NewHeartbeatService(mockRepo)
+
+	// Act
+	heartbeat, err := svc.RecordHeartbeat(context.Background(), uuid.New(), domain.Attributes{"Battery": 80})
+
+	// Assert
+	assert.Nil(t, heartbeat)
+	var validationErr *domain.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "reported.Battery", validationErr.Field)
+	mockRepo.AssertNotCalled(t, "Record", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// LocationService handles business logic for location operations
+type LocationService struct {
+	repo domain.LocationRepository
+}
+
+// NewLocationService creates a new location service
+func NewLocationService(repo domain.LocationRepository) *LocationService {
+	return &LocationService{
+		repo: repo,
+	}
+}
+
+// CreateLocation creates a new location under parentID (nil for sites)
+func (s *LocationService) CreateLocation(ctx context.Context, name string, locationType domain.LocationType, parentID *uuid.UUID) (location *domain.Location, err error) {
+	ctx, span := startSpan(ctx, "LocationService.CreateLocation", attribute.String("location.type", string(locationType)))
+	defer func() { endSpan(span, err) }()
+
+	parent, err := s.getParent(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create location with domain validation and hierarchy rules
+	location, err = domain.NewLocation(name, locationType, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create location: %w", err)
+	}
+
+	if err := s.repo.Create(ctx, location); err != nil {
+		return nil, fmt.Errorf("failed to save location: %w", err)
+	}
+
+	return location, nil
+}
+
+// GetLocation retrieves a location by ID
+func (s *LocationService) GetLocation(ctx context.Context, id uuid.UUID) (location *domain.Location, err error) {
+	ctx, span := startSpan(ctx, "LocationService.GetLocation", locationIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListLocations retrieves locations matching filter
+func (s *LocationService) ListLocations(ctx context.Context, filter domain.LocationFilter) (locations []*domain.Location, err error) {
+	ctx, span := startSpan(ctx, "LocationService.ListLocations")
+	defer func() { endSpan(span, err) }()
+
+	if filter.Type != "" {
+		if err := filter.Type.IsValid(); err != nil {
+			return nil, err
+		}
+	}
+
+	locations, err = s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+
+	if locations == nil {
+		locations = []*domain.Location{}
+	}
+
+	return locations, nil
+}
+
+// UpdateLocation renames a location and moves it under parentID
+// Enforces the site > building > room hierarchy; the type cannot change
+func (s *LocationService) UpdateLocation(ctx context.Context, id uuid.UUID, name string, parentID *uuid.UUID) (location *domain.Location, err error) {
+	ctx, span := startSpan(ctx, "LocationService.UpdateLocation", locationIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	location, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := s.getParent(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := location.Update(name, parent); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, location); err != nil {
+		return nil, fmt.Errorf("failed to update location: %w", err)
+	}
+
+	return location, nil
+}
+
+// DeleteLocation deletes a location
+// Enforces business rule: locations with child locations or devices cannot be deleted
+func (s *LocationService) DeleteLocation(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "LocationService.DeleteLocation", locationIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	hasChildren, err := s.repo.HasChildren(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+	if hasChildren {
+		return domain.NewBusinessRuleError("cannot delete location that still has child locations")
+	}
+
+	hasDevices, err := s.repo.HasDevices(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+	if hasDevices {
+		return domain.NewBusinessRuleError("cannot delete location that still has devices")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if domain.IsBusinessRuleError(err) || domain.IsNotFoundError(err) {
+			return err
+		}
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+
+	return nil
+}
+
+// getParent loads the parent location; a missing parent is a validation error
+func (s *LocationService) getParent(ctx context.Context, parentID *uuid.UUID) (*domain.Location, error) {
+	if parentID == nil {
+		return nil, nil
+	}
+	parent, err := s.repo.GetByID(ctx, *parentID)
+	if err != nil {
+		if domain.IsNotFoundError(err) {
+			return nil, domain.NewValidationError("parent_id", "parent location does not exist")
+		}
+		return nil, fmt.Errorf("failed to get parent location: %w", err)
+	}
+	return parent, nil
+}
@@ -0,0 +1,244 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLocationRepository is a mock implementation of domain.LocationRepository
+type MockLocationRepository struct {
+	mock.Mock
+}
+
+func (m *MockLocationRepository) Create(ctx context.Context, location *domain.Location) error {
+	args := m.Called(ctx, location)
+	return args.Error(0)
+}
+
+func (m *MockLocationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Location, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Location), args.Error(1)
+}
+
+func (m *MockLocationRepository) List(ctx context.Context, filter domain.LocationFilter) ([]*domain.Location, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Location), args.Error(1)
+}
+
+func (m *MockLocationRepository) Update(ctx context.Context, location *domain.Location) error {
+	args := m.Called(ctx, location)
+	return args.Error(0)
+}
+
+func (m *MockLocationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockLocationRepository) HasChildren(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLocationRepository) HasDevices(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// ========== CreateLocation Tests ==========
+
+// TestCreateLocation_Building tests creating a building under a site
+func TestCreateLocation_Building(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+	ctx := context.Background()
+
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	mockRepo.On("GetByID", mock.Anything, site.ID).Return(site, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Location")).Return(nil)
+
+	// Act
+	location, err := svc.CreateLocation(ctx, "HQ", domain.LocationTypeBuilding, &site.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, &site.ID, location.ParentID)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateLocation_UnknownParent tests that a missing parent is a validation error
+func TestCreateLocation_UnknownParent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+	ctx := context.Background()
+
+	parentID := uuid.New()
+	mockRepo.On("GetByID", mock.Anything, parentID).Return(nil, domain.ErrLocationNotFound)
+
+	// Act
+	location, err := svc.CreateLocation(ctx, "HQ", domain.LocationTypeBuilding, &parentID)
+
+	// Assert
+	assert.Nil(t, location)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestCreateLocation_WrongParentType tests hierarchy enforcement
+func TestCreateLocation_WrongParentType(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+	ctx := context.Background()
+
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	mockRepo.On("GetByID", mock.Anything, site.ID).Return(site, nil)
+
+	// Act - rooms belong to buildings, not sites
+	location, err := svc.CreateLocation(ctx, "Lab 1", domain.LocationTypeRoom, &site.ID)
+
+	// Assert
+	assert.Nil(t, location)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// ========== ListLocations Tests ==========
+
+// TestListLocations_InvalidType tests type filter validation
+func TestListLocations_InvalidType(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+
+	// Act
+	locations, err := svc.ListLocations(context.Background(), domain.LocationFilter{Type: "campus"})
+
+	// Assert
+	assert.Nil(t, locations)
+	assert.True(t, domain.IsValidationError(err))
+}
+
+// ========== UpdateLocation Tests ==========
+
+// TestUpdateLocation_MoveBuilding tests moving a building to another site
+func TestUpdateLocation_MoveBuilding(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+	ctx := context.Background()
+
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	otherSite, _ := domain.NewLocation("Lisbon", domain.LocationTypeSite, nil)
+	building, _ := domain.NewLocation("HQ", domain.LocationTypeBuilding, site)
+
+	mockRepo.On("GetByID", mock.Anything, building.ID).Return(building, nil)
+	mockRepo.On("GetByID", mock.Anything, otherSite.ID).Return(otherSite, nil)
+	mockRepo.On("Update", mock.Anything, building).Return(nil)
+
+	// Act
+	location, err := svc.UpdateLocation(ctx, building.ID, "HQ Lisbon", &otherSite.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "HQ Lisbon", location.Name)
+	assert.Equal(t, &otherSite.ID, location.ParentID)
+	mockRepo.AssertExpectations(t)
+}
+
+// ========== DeleteLocation Tests ==========
+
+// TestDeleteLocation_Success tests deleting an empty location
+func TestDeleteLocation_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+	ctx := context.Background()
+
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	mockRepo.On("GetByID", mock.Anything, site.ID).Return(site, nil)
+	mockRepo.On("HasChildren", mock.Anything, site.ID).Return(false, nil)
+	mockRepo.On("HasDevices", mock.Anything, site.ID).Return(false, nil)
+	mockRepo.On("Delete", mock.Anything, site.ID).Return(nil)
+
+	// Act
+	err := svc.DeleteLocation(ctx, site.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestDeleteLocation_HasDevices tests that locations with devices cannot be deleted
+func TestDeleteLocation_HasDevices(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+	ctx := context.Background()
+
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	mockRepo.On("GetByID", mock.Anything, site.ID).Return(site, nil)
+	mockRepo.On("HasChildren", mock.Anything, site.ID).Return(false, nil)
+	mockRepo.On("HasDevices", mock.Anything, site.ID).Return(true, nil)
+
+	// Act
+	err := svc.DeleteLocation(ctx, site.ID)
+
+	// Assert
+	assert.True(t, domain.IsBusinessRuleError(err))
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// TestDeleteLocation_HasChildren tests that locations with children cannot be deleted
+func TestDeleteLocation_HasChildren(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+	ctx := context.Background()
+
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	mockRepo.On("GetByID", mock.Anything, site.ID).Return(site, nil)
+	mockRepo.On("HasChildren", mock.Anything, site.ID).Return(true, nil)
+
+	// Act
+	err := svc.DeleteLocation(ctx, site.ID)
+
+	// Assert
+	assert.True(t, domain.IsBusinessRuleError(err))
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// TestDeleteLocation_RepositoryError tests repository error handling
+func TestDeleteLocation_RepositoryError(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockLocationRepository)
+	svc := service.NewLocationService(mockRepo)
+	ctx := context.Background()
+
+	site, _ := domain.NewLocation("Berlin", domain.LocationTypeSite, nil)
+	mockRepo.On("GetByID", mock.Anything, site.ID).Return(site, nil)
+	mockRepo.On("HasChildren", mock.Anything, site.ID).Return(false, errors.New("connection lost"))
+
+	// Act
+	err := svc.DeleteLocation(ctx, site.ID)
+
+	// Assert
+	assert.Error(t, err)
+	assert.False(t, domain.IsBusinessRuleError(err))
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MaintenanceService handles business logic for device maintenance records
+type MaintenanceService struct {
+	repo      domain.MaintenanceRepository
+	devices   domain.DeviceRepository
+	tx        domain.TxManager
+	intervals domain.MaintenanceIntervals
+}
+
+// NewMaintenanceService creates a new maintenance service.
+// Starts and completions run in transactions of tx that lock the device.
+// intervals sets the days between periodic maintenances per device category.
+func NewMaintenanceService(repo domain.MaintenanceRepository, devices domain.DeviceRepository, tx domain.TxManager, intervals domain.MaintenanceIntervals) *MaintenanceService {
+	return &MaintenanceService{
+		repo:      repo,
+		devices:   devices,
+		tx:        tx,
+		intervals: intervals,
+	}
+}
+
+// ScheduleMaintenance records a maintenance of a device planned for scheduledAt
+func (s *MaintenanceService) ScheduleMaintenance(ctx context.Context, deviceID uuid.UUID, maintenanceType domain.MaintenanceType, scheduledAt time.Time, vendor string, costCents int64, notes string) (maintenance *domain.Maintenance, err error) {
+	ctx, span := startSpan(ctx, "MaintenanceService.ScheduleMaintenance",
+		deviceIDAttr(deviceID.String()), attribute.String("maintenance.type", string(maintenanceType)))
+	defer func() { endSpan(span, err) }()
+
+	maintenance, err = domain.NewMaintenance(deviceID, maintenanceType, scheduledAt, vendor, costCents, notes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, maintenance); err != nil {
+		if domain.IsNotFoundError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save maintenance: %w", err)
+	}
+
+	return maintenance, nil
+}
+
+// ListMaintenance retrieves the maintenance records of a device, most recently scheduled first
+func (s *MaintenanceService) ListMaintenance(ctx context.Context, deviceID uuid.UUID) (records []*domain.Maintenance, err error) {
+	ctx, span := startSpan(ctx, "MaintenanceService.ListMaintenance", deviceIDAttr(deviceID.String()))
+	defer func() { endSpan(span, err) }()
+
+	exists, err := s.devices.ExistsByID(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check device: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrDeviceNotFound
+	}
+
+	records, err = s.repo.ListByDevice(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance: %w", err)
+	}
+
+	if records == nil {
+		records = []*domain.Maintenance{}
+	}
+
+	return records, nil
+}
+
+// StartMaintenance starts a scheduled maintenance, moving the device into the
+// maintenance state so it cannot be put in use until the maintenance is completed
+func (s *MaintenanceService) StartMaintenance(ctx context.Context, deviceID, maintenanceID uuid.UUID) (maintenance *domain.Maintenance, err error) {
+	ctx, span := startSpan(ctx, "MaintenanceService.StartMaintenance",
+		deviceIDAttr(deviceID.String()), maintenanceIDAttr(maintenanceID.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.transition(ctx, deviceID, maintenanceID, func(m *domain.Maintenance, device *domain.Device) error {
+		return m.Start(device, time.Now())
+	})
+}
+
+// CompleteMaintenance completes a started maintenance and returns the device to the
+// state it had before. A non-nil costCents or notes replaces the recorded value.
+func (s *MaintenanceService) CompleteMaintenance(ctx context.Context, deviceID, maintenanceID uuid.UUID, costCents *int64, notes *string) (maintenance *domain.Maintenance, err error) {
+	ctx, span := startSpan(ctx, "MaintenanceService.CompleteMaintenance",
+		deviceIDAttr(deviceID.String()), maintenanceIDAttr(maintenanceID.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.transition(ctx, deviceID, maintenanceID, func(m *domain.Maintenance, device *domain.Device) error {
+		return m.Complete(device, time.Now(), costCents, notes)
+	})
+}
+
+// transition loads a maintenance of the device and the locked device, applies change
+// and saves both in one transaction
+func (s *MaintenanceService) transition(ctx context.Context, deviceID, maintenanceID uuid.UUID, change func(*domain.Maintenance, *domain.Device) error) (maintenance *domain.Maintenance, err error) {
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		maintenance, err = s.repo.GetByID(ctx, maintenanceID)
+		if err != nil {
+			return err
+		}
+		// A maintenance of another device is reported as not found
+		if maintenance.DeviceID != deviceID {
+			return domain.ErrMaintenanceNotFound
+		}
+
+		// Rules are checked against the locked stored state, never a cached copy
+		device, err := s.devices.GetByIDForUpdate(ctx, deviceID)
+		if err != nil {
+			return err
+		}
+
+		previousState := device.State
+		if err := change(maintenance, device); err != nil {
+			return err
+		}
+
+		if err := s.repo.SaveTransition(ctx, maintenance, device, previousState); err != nil {
+			if domain.IsBusinessRuleError(err) || domain.IsNotFoundError(err) {
+				return err
+			}
+			return fmt.Errorf("failed to save maintenance: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return maintenance, nil
+}
+
+// ListDueMaintenance retrieves devices due for periodic maintenance, oldest due first
+func (s *MaintenanceService) ListDueMaintenance(ctx context.Context, limit, offset int) (due []domain.DueMaintenance, err error) {
+	ctx, span := startSpan(ctx, "MaintenanceService.ListDueMaintenance")
+	defer func() { endSpan(span, err) }()
+
+	due, err = s.repo.ListDue(ctx, s.intervals, time.Now().UTC(), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due maintenance: %w", err)
+	}
+
+	if due == nil {
+		due = []domain.DueMaintenance{}
+	}
+
+	return due, nil
+}
@@ -0,0 +1,242 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockMaintenanceRepository is a mock implementation of domain.MaintenanceRepository
+type MockMaintenanceRepository struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceRepository) Create(ctx context.Context, maintenance *domain.Maintenance) error {
+	args := m.Called(ctx, maintenance)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Maintenance, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Maintenance), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) ListByDevice(ctx context.Context, deviceID uuid.UUID) ([]*domain.Maintenance, error) {
+	args := m.Called(ctx, deviceID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Maintenance), args.Error(1)
+}
+
+func (m *MockMaintenanceRepository) SaveTransition(ctx context.Context, maintenance *domain.Maintenance, device *domain.Device, previousState domain.DeviceState) error {
+	args := m.Called(ctx, maintenance, device, previousState)
+	return args.Error(0)
+}
+
+func (m *MockMaintenanceRepository) ListDue(ctx context.Context, intervals domain.MaintenanceIntervals, now time.Time, limit, offset int) ([]domain.DueMaintenance, error) {
+	args := m.Called(ctx, intervals, now, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.DueMaintenance), args.Error(1)
+}
+
+var testIntervals = domain.MaintenanceIntervals{domain.DeviceCategoryLaptop: 365}
+
+// ========== ScheduleMaintenance Tests ==========
+
+// TestScheduleMaintenance_Success tests scheduling a maintenance
+func TestScheduleMaintenance_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMaintenanceRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewMaintenanceService(mockRepo, mockDevices, &fakeTxManager{}, testIntervals)
+
+	deviceID := uuid.New()
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Maintenance")).Return(nil)
+
+	// Act
+	m, err := svc.ScheduleMaintenance(context.Background(), deviceID, domain.MaintenanceTypeRepair,
+		time.Now().Add(48*time.Hour), "iFixit", 0, "Broken screen")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, deviceID, m.DeviceID)
+	assert.Equal(t, domain.MaintenanceStatusScheduled, m.Status())
+	mockRepo.AssertExpectations(t)
+}
+
+// TestScheduleMaintenance_DeviceNotFound tests scheduling for an unknown device
+func TestScheduleMaintenance_DeviceNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMaintenanceRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewMaintenanceService(mockRepo, mockDevices, &fakeTxManager{}, testIntervals)
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(domain.ErrDeviceNotFound)
+
+	// Act
+	m, err := svc.ScheduleMaintenance(context.Background(), uuid.New(), domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+
+	// Assert
+	assert.Nil(t, m)
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+}
+
+// ========== StartMaintenance Tests ==========
+
+// TestStartMaintenance_MovesDeviceIntoMaintenance tests that starting saves both the record and the device state
+func TestStartMaintenance_MovesDeviceIntoMaintenance(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMaintenanceRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewMaintenanceService(mockRepo, mockDevices, &fakeTxManager{}, testIntervals)
+
+	device, _ := domain.NewDevice("Dev laptop", "Apple")
+	device.State = domain.DeviceStateInUse
+	m, _ := domain.NewMaintenance(device.ID, domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	mockRepo.On("GetByID", mock.Anything, m.ID).Return(m, nil)
+	mockDevices.On("GetByIDForUpdate", inTx, device.ID).Return(device, nil)
+	mockRepo.On("SaveTransition", inTx, m, mock.MatchedBy(func(d *domain.Device) bool {
+		return d.State == domain.DeviceStateMaintenance
+	}), domain.DeviceStateInUse).Return(nil)
+
+	// Act
+	started, err := svc.StartMaintenance(context.Background(), device.ID, m.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.MaintenanceStatusInProgress, started.Status())
+	assert.Equal(t, domain.DeviceStateInUse, started.PreviousState)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestStartMaintenance_OtherDevice tests that a maintenance is only found through its own device
+func TestStartMaintenance_OtherDevice(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMaintenanceRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewMaintenanceService(mockRepo, mockDevices, &fakeTxManager{}, testIntervals)
+
+	m, _ := domain.NewMaintenance(uuid.New(), domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	mockRepo.On("GetByID", mock.Anything, m.ID).Return(m, nil)
+
+	// Act
+	started, err := svc.StartMaintenance(context.Background(), uuid.New(), m.ID)
+
+	// Assert
+	assert.Nil(t, started)
+	assert.ErrorIs(t, err, domain.ErrMaintenanceNotFound)
+	mockRepo.AssertNotCalled(t, "SaveTransition", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestStartMaintenance_SavesInTransaction tests that a failed save is reported by the transaction,
+// so the device lock and the maintenance write are rolled back together
+func TestStartMaintenance_SavesInTransaction(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMaintenanceRepository)
+	mockDevices := new(MockDeviceRepository)
+	tx := &fakeTxManager{}
+	svc := service.NewMaintenanceService(mockRepo, mockDevices, tx, testIntervals)
+
+	device, _ := domain.NewDevice("Dev laptop", "Apple")
+	m, _ := domain.NewMaintenance(device.ID, domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	conflict := domain.NewBusinessRuleError("device is already in maintenance")
+	mockRepo.On("GetByID", inTx, m.ID).Return(m, nil)
+	mockDevices.On("GetByIDForUpdate", inTx, device.ID).Return(device, nil)
+	mockRepo.On("SaveTransition", inTx, m, device, domain.DeviceStateActive).Return(conflict)
+
+	// Act
+	started, err := svc.StartMaintenance(context.Background(), device.ID, m.ID)
+
+	// Assert
+	assert.Nil(t, started)
+	assert.ErrorIs(t, err, conflict)
+	assert.Equal(t, []error{conflict}, tx.results)
+	mockDevices.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// ========== CompleteMaintenance Tests ==========
+
+// TestCompleteMaintenance_NotStarted tests that only started maintenance can be completed
+func TestCompleteMaintenance_NotStarted(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMaintenanceRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewMaintenanceService(mockRepo, mockDevices, &fakeTxManager{}, testIntervals)
+
+	device, _ := domain.NewDevice("Dev laptop", "Apple")
+	m, _ := domain.NewMaintenance(device.ID, domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	mockRepo.On("GetByID", mock.Anything, m.ID).Return(m, nil)
+	mockDevices.On("GetByIDForUpdate", inTx, device.ID).Return(device, nil)
+
+	// Act
+	completed, err := svc.CompleteMaintenance(context.Background(), device.ID, m.ID, nil, nil)
+
+	// Assert
+	assert.Nil(t, completed)
+	assert.True(t, domain.IsBusinessRuleError(err))
+	mockRepo.AssertNotCalled(t, "SaveTransition", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestCompleteMaintenance_RestoresState tests that completing returns the device to its previous state
+func TestCompleteMaintenance_RestoresState(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMaintenanceRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewMaintenanceService(mockRepo, mockDevices, &fakeTxManager{}, testIntervals)
+
+	device, _ := domain.NewDevice("Dev laptop", "Apple")
+	device.State = domain.DeviceStateInactive
+	m, _ := domain.NewMaintenance(device.ID, domain.MaintenanceTypeRepair, time.Now(), "", 0, "")
+	require.NoError(t, m.Start(device, time.Now()))
+	mockRepo.On("GetByID", mock.Anything, m.ID).Return(m, nil)
+	mockDevices.On("GetByIDForUpdate", inTx, device.ID).Return(device, nil)
+	mockRepo.On("SaveTransition", inTx, m, mock.MatchedBy(func(d *domain.Device) bool {
+		return d.State == domain.DeviceStateInactive
+	}), domain.DeviceStateMaintenance).Return(nil)
+
+	// Act
+	notes := "Replaced battery"
+	completed, err := svc.CompleteMaintenance(context.Background(), device.ID, m.ID, nil, &notes)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.MaintenanceStatusCompleted, completed.Status())
+	assert.Equal(t, "Replaced battery", completed.Notes)
+	mockRepo.AssertExpectations(t)
+}
+
+// ========== ListDueMaintenance Tests ==========
+
+// TestListDueMaintenance_UsesIntervals tests that the configured intervals drive the query
+func TestListDueMaintenance_UsesIntervals(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockMaintenanceRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewMaintenanceService(mockRepo, mockDevices, &fakeTxManager{}, testIntervals)
+
+	mockRepo.On("ListDue", mock.Anything, testIntervals, mock.Anything, 10, 0).Return(nil, nil)
+
+	// Act
+	due, err := svc.ListDueMaintenance(context.Background(), 10, 0)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotNil(t, due)
+	assert.Empty(t, due)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ModelService handles business logic for the model catalog
+type ModelService struct {
+	repo domain.ModelRepository
+}
+
+// NewModelService creates a new model service
+func NewModelService(repo domain.ModelRepository) *ModelService {
+	return &ModelService{
+		repo: repo,
+	}
+}
+
+// CreateModel creates a new catalog model
+func (s *ModelService) CreateModel(ctx context.Context, name, brand string, category domain.DeviceCategory, defaults domain.Attributes, lifecycleMonths int) (model *domain.Model, err error) {
+	ctx, span := startSpan(ctx, "ModelService.CreateModel", attribute.String("model.category", string(category)))
+	defer func() { endSpan(span, err) }()
+
+	model, err = domain.NewModel(name, brand, category, defaults, lifecycleMonths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create model: %w", err)
+	}
+
+	if err := s.repo.Create(ctx, model); err != nil {
+		if domain.IsValidationError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to save model: %w", err)
+	}
+
+	return model, nil
+}
+
+// GetModel retrieves a model by ID
+func (s *ModelService) GetModel(ctx context.Context, id uuid.UUID) (model *domain.Model, err error) {
+	ctx, span := startSpan(ctx, "ModelService.GetModel", modelIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListModels retrieves models matching filter
+func (s *ModelService) ListModels(ctx context.Context, filter domain.ModelFilter) (models []*domain.Model, err error) {
+	ctx, span := startSpan(ctx, "ModelService.ListModels")
+	defer func() { endSpan(span, err) }()
+
+	if filter.Category != "" {
+		if err := filter.Category.IsValid(); err != nil {
+			return nil, err
+		}
+	}
+
+	models, err = s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	if models == nil {
+		models = []*domain.Model{}
+	}
+
+	return models, nil
+}
+
+// UpdateModel changes a model's name, default attributes and lifecycle policy
+// Brand and category cannot change, since existing devices were validated against them
+func (s *ModelService) UpdateModel(ctx context.Context, id uuid.UUID, name string, defaults domain.Attributes, lifecycleMonths int) (model *domain.Model, err error) {
+	ctx, span := startSpan(ctx, "ModelService.UpdateModel", modelIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	model, err = s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := model.Update(name, defaults, lifecycleMonths); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, model); err != nil {
+		if domain.IsValidationError(err) || domain.IsNotFoundError(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update model: %w", err)
+	}
+
+	return model, nil
+}
+
+// DeleteModel deletes a model
+// Enforces business rule: models referenced by devices cannot be deleted
+func (s *ModelService) DeleteModel(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "ModelService.DeleteModel", modelIDAttr(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	hasDevices, err := s.repo.HasDevices(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete model: %w", err)
+	}
+	if hasDevices {
+		return domain.NewBusinessRuleError("cannot delete model that still has devices")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if domain.IsBusinessRuleError(err) || domain.IsNotFoundError(err) {
+			return err
+		}
+		return fmt.Errorf("failed to delete model: %w", err)
+	}
+
+	return nil
+}
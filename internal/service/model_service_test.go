@@ -0,0 +1,222 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockModelRepository is a mock implementation of domain.ModelRepository
+type MockModelRepository struct {
+	mock.Mock
+}
+
+func (m *MockModelRepository) Create(ctx context.Context, model *domain.Model) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockModelRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Model, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Model), args.Error(1)
+}
+
+func (m *MockModelRepository) List(ctx context.Context, filter domain.ModelFilter) ([]*domain.Model, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Model), args.Error(1)
+}
+
+func (m *MockModelRepository) Update(ctx context.Context, model *domain.Model) error {
+	args := m.Called(ctx, model)
+	return args.Error(0)
+}
+
+func (m *MockModelRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockModelRepository) HasDevices(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// ========== CreateModel Tests ==========
+
+// TestCreateModel_Success tests creating a catalog model
+func TestCreateModel_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockModelRepository)
+	svc := service.NewModelService(mockRepo)
+
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Model")).Return(nil)
+
+	// Act
+	model, err := svc.CreateModel(context.Background(), "MacBook Pro 14 M3", "Apple",
+		domain.DeviceCategoryLaptop, domain.Attributes{"ram_gb": 16}, 48)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DeviceCategoryLaptop, model.Category)
+	assert.Equal(t, 48, model.LifecycleMonths)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateModel_InvalidCategory tests category validation
+func TestCreateModel_InvalidCategory(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockModelRepository)
+	svc := service.NewModelService(mockRepo)
+
+	// Act
+	model, err := svc.CreateModel(context.Background(), "Watch", "Apple", "wearable", nil, 0)
+
+	// Assert
+	assert.Nil(t, model)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// ========== UpdateModel Tests ==========
+
+// TestUpdateModel_Success tests changing defaults and lifecycle policy
+func TestUpdateModel_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockModelRepository)
+	svc := service.NewModelService(mockRepo)
+
+	model, _ := domain.NewModel("MacBook Pro 14 M3", "Apple", domain.DeviceCategoryLaptop, nil, 36)
+	mockRepo.On("GetByID", mock.Anything, model.ID).Return(model, nil)
+	mockRepo.On("Update", mock.Anything, model).Return(nil)
+
+	// Act
+	updated, err := svc.UpdateModel(context.Background(), model.ID, "MacBook Pro 14 M3 Max", domain.Attributes{"ram_gb": 36}, 48)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "MacBook Pro 14 M3 Max", updated.Name)
+	assert.Equal(t, domain.Attributes{"ram_gb": 36}, updated.DefaultAttributes)
+	assert.Equal(t, domain.DeviceCategoryLaptop, updated.Category)
+	mockRepo.AssertExpectations(t)
+}
+
+// ========== DeleteModel Tests ==========
+
+// TestDeleteModel_HasDevices tests that models with devices cannot be deleted
+func TestDeleteModel_HasDevices(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockModelRepository)
+	svc := service.NewModelService(mockRepo)
+
+	model, _ := domain.NewModel("iPhone 15", "Apple", domain.DeviceCategoryPhone, nil, 0)
+	mockRepo.On("GetByID", mock.Anything, model.ID).Return(model, nil)
+	mockRepo.On("HasDevices", mock.Anything, model.ID).Return(true, nil)
+
+	// Act
+	err := svc.DeleteModel(context.Background(), model.ID)
+
+	// Assert
+	assert.True(t, domain.IsBusinessRuleError(err))
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// ========== CreateDeviceFromModel Tests ==========
+
+// TestCreateDeviceFromModel_PrefillsAttributes tests that model defaults and brand are applied
+func TestCreateDeviceFromModel_PrefillsAttributes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockModels := new(MockModelRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithModelRepository(mockModels))
+
+	model, _ := domain.NewModel("MacBook Pro 14 M3", "Apple", domain.DeviceCategoryLaptop,
+		domain.Attributes{"ram_gb": 16, "os": "macos"}, 48)
+	mockModels.On("GetByID", mock.Anything, model.ID).Return(model, nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Device")).Return(nil)
+
+	// Act
+	device, err := svc.CreateDeviceFromModel(context.Background(), model.ID, "Dev laptop", "",
+		domain.WithAttributes(domain.Attributes{"ram_gb": 32}))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Apple", device.Brand)
+	assert.Equal(t, &model.ID, device.ModelID)
+	assert.Equal(t, domain.DeviceCategoryLaptop, device.Category)
+	assert.Equal(t, domain.Attributes{"ram_gb": 32, "os": "macos"}, device.Attributes)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestCreateDeviceFromModel_PhoneRequiresIMEI tests the phone category rule
+func TestCreateDeviceFromModel_PhoneRequiresIMEI(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockModels := new(MockModelRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithModelRepository(mockModels))
+
+	model, _ := domain.NewModel("iPhone 15", "Apple", domain.DeviceCategoryPhone, nil, 36)
+	mockModels.On("GetByID", mock.Anything, model.ID).Return(model, nil)
+
+	// Act
+	device, err := svc.CreateDeviceFromModel(context.Background(), model.ID, "iPhone 15", "Apple")
+
+	// Assert
+	assert.Nil(t, device)
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "attributes.imei", validationErr.Field)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestCreateDeviceFromModel_UnknownModel tests that a missing model is a validation error
+func TestCreateDeviceFromModel_UnknownModel(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockModels := new(MockModelRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithModelRepository(mockModels))
+
+	modelID := uuid.New()
+	mockModels.On("GetByID", mock.Anything, modelID).Return(nil, domain.ErrModelNotFound)
+
+	// Act
+	device, err := svc.CreateDeviceFromModel(context.Background(), modelID, "iPhone 15", "Apple")
+
+	// Assert
+	assert.Nil(t, device)
+	var validationErr *domain.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "model_id", validationErr.Field)
+}
+
+// TestPartialUpdateDevice_ChangeModel tests that a new model's category rules apply on update
+func TestPartialUpdateDevice_ChangeModel(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockDeviceRepository)
+	mockModels := new(MockModelRepository)
+	svc := service.NewDeviceService(mockRepo, service.WithModelRepository(mockModels))
+
+	existingDevice, _ := domain.NewDevice("Galaxy", "Samsung")
+	phone, _ := domain.NewModel("Galaxy S24", "Samsung", domain.DeviceCategoryPhone, nil, 0)
+	mockRepo.On("GetByIDForUpdate", mock.Anything, existingDevice.ID).Return(existingDevice, nil)
+	mockModels.On("GetByID", mock.Anything, phone.ID).Return(phone, nil)
+
+	// Act - the device has no IMEI
+	device, err := svc.PartialUpdateDevice(context.Background(), existingDevice.ID, domain.DevicePatch{ModelID: &phone.ID})
+
+	// Assert
+	assert.Nil(t, device)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
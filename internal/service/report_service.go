@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ReportService builds read-only reports across devices
+type ReportService struct {
+	expiries domain.ExpiryRepository
+}
+
+// NewReportService creates a new report service
+func NewReportService(expiries domain.ExpiryRepository) *ReportService {
+	return &ReportService{
+		expiries: expiries,
+	}
+}
+
+// ExpiringDevices lists devices whose warranty ends or whose planned end of life
+// falls between today and withinDays from now, grouped by brand
+func (s *ReportService) ExpiringDevices(ctx context.Context, withinDays int) (report *domain.ExpiryReport, err error) {
+	ctx, span := startSpan(ctx, "ReportService.ExpiringDevices", attribute.Int("report.within_days", withinDays))
+	defer func() { endSpan(span, err) }()
+
+	from, to, err := domain.ExpiryWindow(time.Now().UTC(), withinDays)
+	if err != nil {
+		return nil, err
+	}
+
+	expiries, err := s.expiries.ListExpiring(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring devices: %w", err)
+	}
+
+	return domain.NewExpiryReport(from, to, expiries), nil
+}
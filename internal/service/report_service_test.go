@@ -0,0 +1,50 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== ExpiringDevices Tests ==========
+
+// TestExpiringDevices_GroupsByBrand tests the expiring devices report
+func TestExpiringDevices_GroupsByBrand(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockExpiryRepository)
+	svc := service.NewReportService(mockRepo)
+
+	first, second := expiryIn(3), expiryIn(10)
+	second.BrandID = first.BrandID
+	mockRepo.On("ListExpiring", mock.Anything, mock.Anything, mock.Anything).Return([]domain.Expiry{first, second}, nil)
+
+	// Act
+	report, err := svc.ExpiringDevices(context.Background(), 30)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Brands, 1)
+	assert.Len(t, report.Brands[0].Expiries, 2)
+	assert.Equal(t, 30, int(report.To.Sub(report.From).Hours()/24))
+}
+
+// TestExpiringDevices_InvalidWindow tests that the window is validated before querying
+func TestExpiringDevices_InvalidWindow(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockExpiryRepository)
+	svc := service.NewReportService(mockRepo)
+
+	// Act
+	report, err := svc.ExpiringDevices(context.Background(), 0)
+
+	// Assert
+	assert.Nil(t, report)
+	assert.True(t, domain.IsValidationError(err))
+	mockRepo.AssertNotCalled(t, "ListExpiring", mock.Anything, mock.Anything, mock.Anything)
+}
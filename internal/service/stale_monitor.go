@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// staleBatchSize is the number of stale devices loaded at a time
+const staleBatchSize = 100
+
+// StaleMonitor periodically makes devices inactive when they have not sent a
+// heartbeat within staleAfter, and emits a domain.EventDeviceStale event for each.
+// Devices in use or in maintenance keep their state, and devices that never
+// sent a heartbeat are never considered stale.
+type StaleMonitor struct {
+	repo       domain.HeartbeatRepository
+	publisher  domain.EventPublisher
+	staleAfter time.Duration
+	logger     *slog.Logger
+}
+
+// NewStaleMonitor creates a monitor for devices unseen for longer than staleAfter
+func NewStaleMonitor(repo domain.HeartbeatRepository, publisher domain.EventPublisher, staleAfter time.Duration, logger *slog.Logger) *StaleMonitor {
+	return &StaleMonitor{
+		repo:       repo,
+		publisher:  publisher,
+		staleAfter: staleAfter,
+		logger:     logger,
+	}
+}
+
+// Run checks for stale devices immediately and then every interval until ctx is cancelled.
+// Failed checks are logged and retried on the next tick.
+func (m *StaleMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deactivated, err := m.Check(ctx, time.Now().UTC())
+		if err != nil && ctx.Err() == nil {
+			m.logger.Error("Stale device check failed", "error", err)
+		} else if deactivated > 0 {
+			m.logger.Info("Stale device check completed", "deactivated", deactivated)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check makes every device last seen more than staleAfter before now inactive
+// and returns the number of devices changed.
+// The state is saved before the event is published, so a failed publish is
+// reported but not retried.
+func (m *StaleMonitor) Check(ctx context.Context, now time.Time) (deactivated int, err error) {
+	ctx, span := startSpan(ctx, "StaleMonitor.Check")
+	defer func() {
+		span.SetAttributes(attribute.Int("devices.deactivated", deactivated))
+		endSpan(span, err)
+	}()
+
+	cutoff := domain.StaleCutoff(now, m.staleAfter)
+
+	// Deactivated devices drop out of the listing; every other row may still be listed,
+	// so it is paged over. A row that dropped out anyway is checked on the next run.
+	var errs []error
+	skipped := 0
+	for {
+		devices, err := m.repo.ListStale(ctx, cutoff, staleBatchSize, skipped)
+		if err != nil {
+			return deactivated, errors.Join(append(errs, fmt.Errorf("failed to list stale devices: %w", err))...)
+		}
+
+		for _, device := range devices {
+			previous := device.State
+			changed, err := device.Deactivate()
+			if err != nil {
+				// Business rules, such as the maintenance state, keep the device as it is
+				if !domain.IsBusinessRuleError(err) {
+					errs = append(errs, fmt.Errorf("failed to deactivate device %s: %w", device.ID, err))
+				}
+				skipped++
+				continue
+			}
+			if !changed {
+				skipped++
+				continue
+			}
+
+			saved, err := m.repo.MarkInactive(ctx, device, previous, cutoff)
+			if err != nil {
+				return deactivated, errors.Join(append(errs, err)...)
+			}
+			if !saved {
+				// Seen or changed since it was listed
+				skipped++
+				continue
+			}
+			deactivated++
+
+			if err := m.publisher.Publish(ctx, staleEvent(device, previous, now)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to publish stale event for device %s: %w", device.ID, err))
+			}
+		}
+
+		if len(devices) < staleBatchSize {
+			return deactivated, errors.Join(errs...)
+		}
+	}
+}
+
+// staleEvent builds the event published when device is made inactive
+func staleEvent(device *domain.Device, previous domain.DeviceState, now time.Time) domain.Event {
+	data := map[string]any{
+		"device_name":    device.Name,
+		"brand":          device.Brand,
+		"serial_number":  device.SerialNumber,
+		"previous_state": string(previous),
+	}
+	if device.LastSeenAt != nil {
+		data["last_seen_at"] = device.LastSeenAt.UTC().Format(time.RFC3339)
+	}
+
+	return domain.Event{
+		Type:       domain.EventDeviceStale,
+		DeviceID:   device.ID,
+		OccurredAt: now,
+		Data:       data,
+	}
+}
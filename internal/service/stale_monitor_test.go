@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// staleDevice returns a device last seen an hour before checkTime
+func staleDevice(t *testing.T, state domain.DeviceState) *domain.Device {
+	t.Helper()
+	device, err := domain.NewDevice("Lab sensor", "Bosch")
+	require.NoError(t, err)
+	device.State = state
+	lastSeen := checkTime.Add(-time.Hour)
+	device.LastSeenAt = &lastSeen
+	return device
+}
+
+func newTestStaleMonitor(repo domain.HeartbeatRepository, publisher domain.EventPublisher) *service.StaleMonitor {
+	return service.NewStaleMonitor(repo, publisher, 15*time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// ========== StaleMonitor Tests ==========
+
+// TestStaleMonitor_Check_DeactivatesAndPublishes tests that a stale active device is made inactive with an event
+func TestStaleMonitor_Check_DeactivatesAndPublishes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockHeartbeatRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestStaleMonitor(mockRepo, mockPublisher)
+
+	device := staleDevice(t, domain.DeviceStateActive)
+	cutoff := checkTime.Add(-15 * time.Minute)
+	mockRepo.On("ListStale", mock.Anything, cutoff, mock.Anything, 0).Return([]*domain.Device{device}, nil)
+	mockRepo.On("MarkInactive", mock.Anything, mock.MatchedBy(func(d *domain.Device) bool {
+		return d.State == domain.DeviceStateInactive
+	}), domain.DeviceStateActive, cutoff).Return(true, nil)
+	mockPublisher.On("Publish", mock.Anything, mock.MatchedBy(func(event domain.Event) bool {
+		return event.Type == domain.EventDeviceStale &&
+			event.DeviceID == device.ID &&
+			event.Data["previous_state"] == "active" &&
+			event.Data["last_seen_at"] == "2025-06-01T08:00:00Z"
+	})).Return(nil)
+
+	// Act
+	deactivated, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, deactivated)
+	mockRepo.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+}
+
+// TestStaleMonitor_Check_KeepsMaintenanceState tests that the domain rules keep devices in maintenance as they are
+func TestStaleMonitor_Check_KeepsMaintenanceState(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockHeartbeatRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestStaleMonitor(mockRepo, mockPublisher)
+
+	device := staleDevice(t, domain.DeviceStateMaintenance)
+	mockRepo.On("ListStale", mock.Anything, mock.Anything, mock.Anything, 0).Return([]*domain.Device{device}, nil)
+
+	// Act
+	deactivated, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 0, deactivated)
+	assert.Equal(t, domain.DeviceStateMaintenance, device.State)
+	mockRepo.AssertNotCalled(t, "MarkInactive", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// TestStaleMonitor_Check_SkipsDevicesSeenMeanwhile tests that a device seen after it was listed emits no event
+func TestStaleMonitor_Check_SkipsDevicesSeenMeanwhile(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockHeartbeatRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestStaleMonitor(mockRepo, mockPublisher)
+
+	device := staleDevice(t, domain.DeviceStateActive)
+	mockRepo.On("ListStale", mock.Anything, mock.Anything, mock.Anything, 0).Return([]*domain.Device{device}, nil)
+	mockRepo.On("MarkInactive", mock.Anything, device, domain.DeviceStateActive, mock.Anything).Return(false, nil)
+
+	// Act
+	deactivated, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 0, deactivated)
+	mockPublisher.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// TestStaleMonitor_Check_PagesOverUnsavedDevices tests that devices that could not be saved
+// count toward the offset of the next page, since they may still be listed
+func TestStaleMonitor_Check_PagesOverUnsavedDevices(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockHeartbeatRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestStaleMonitor(mockRepo, mockPublisher)
+
+	page := make([]*domain.Device, 100)
+	for i := range page {
+		page[i] = staleDevice(t, domain.DeviceStateActive)
+	}
+	mockRepo.On("ListStale", mock.Anything, mock.Anything, 100, 0).Return(page, nil).Once()
+	mockRepo.On("ListStale", mock.Anything, mock.Anything, 100, 100).Return([]*domain.Device{}, nil).Once()
+	mockRepo.On("MarkInactive", mock.Anything, mock.Anything, domain.DeviceStateActive, mock.Anything).Return(false, nil)
+
+	// Act
+	deactivated, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 0, deactivated)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestStaleMonitor_Check_PublishFailure tests that a failed publish is reported after the state is saved
+func TestStaleMonitor_Check_PublishFailure(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockHeartbeatRepository)
+	mockPublisher := new(MockEventPublisher)
+	monitor := newTestStaleMonitor(mockRepo, mockPublisher)
+
+	device := staleDevice(t, domain.DeviceStateActive)
+	mockRepo.On("ListStale", mock.Anything, mock.Anything, mock.Anything, 0).Return([]*domain.Device{device}, nil)
+	mockRepo.On("MarkInactive", mock.Anything, device, domain.DeviceStateActive, mock.Anything).Return(true, nil)
+	mockPublisher.On("Publish", mock.Anything, mock.Anything).Return(errors.New("broker down"))
+
+	// Act
+	deactivated, err := monitor.Check(context.Background(), checkTime)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, 1, deactivated)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TelemetryPruner periodically drops telemetry that is past the retention period
+type TelemetryPruner struct {
+	repo      domain.TelemetryRepository
+	retention time.Duration
+	logger    *slog.Logger
+}
+
+// NewTelemetryPruner creates a pruner keeping telemetry for retention
+func NewTelemetryPruner(repo domain.TelemetryRepository, retention time.Duration, logger *slog.Logger) *TelemetryPruner {
+	return &TelemetryPruner{
+		repo:      repo,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Run prunes telemetry immediately and then every interval until ctx is cancelled.
+// Failed runs are logged and retried on the next tick.
+func (p *TelemetryPruner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		dropped, err := p.Prune(ctx, time.Now().UTC())
+		if err != nil && ctx.Err() == nil {
+			p.logger.Error("Telemetry retention failed", "error", err)
+		} else if dropped > 0 {
+			p.logger.Info("Telemetry retention completed", "partitions_dropped", dropped)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune drops the telemetry past retention at now and returns the number of
+// partitions dropped
+func (p *TelemetryPruner) Prune(ctx context.Context, now time.Time) (dropped int, err error) {
+	ctx, span := startSpan(ctx, "TelemetryPruner.Prune")
+	defer func() {
+		span.SetAttributes(attribute.Int("telemetry.partitions_dropped", dropped))
+		endSpan(span, err)
+	}()
+
+	dropped, err = p.repo.DropBefore(ctx, domain.TelemetryCutoff(now, p.retention))
+	if err != nil {
+		return dropped, fmt.Errorf("failed to drop expired telemetry: %w", err)
+	}
+
+	return dropped, nil
+}
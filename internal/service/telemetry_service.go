@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TelemetryService handles metric samples reported by devices
+type TelemetryService struct {
+	repo      domain.TelemetryRepository
+	devices   domain.DeviceRepository
+	retention time.Duration
+	maxBatch  int
+}
+
+// NewTelemetryService creates a new telemetry service.
+// Samples older than retention are rejected, and a batch holds at most maxBatch samples.
+func NewTelemetryService(repo domain.TelemetryRepository, devices domain.DeviceRepository, retention time.Duration, maxBatch int) *TelemetryService {
+	return &TelemetryService{
+		repo:      repo,
+		devices:   devices,
+		retention: retention,
+		maxBatch:  maxBatch,
+	}
+}
+
+// MaxBatch returns the largest number of samples Ingest accepts at once
+func (s *TelemetryService) MaxBatch() int {
+	return s.maxBatch
+}
+
+// Ingest stores a batch of samples of a device. The batch is stored as a whole
+// or not at all; the first invalid sample is reported by its position.
+func (s *TelemetryService) Ingest(ctx context.Context, deviceID uuid.UUID, points []domain.TelemetryPoint) (err error) {
+	ctx, span := startSpan(ctx, "TelemetryService.Ingest",
+		deviceIDAttr(deviceID.String()), attribute.Int("telemetry.points", len(points)))
+	defer func() { endSpan(span, err) }()
+
+	if len(points) == 0 {
+		return domain.NewValidationError("points", "cannot be empty")
+	}
+	if len(points) > s.maxBatch {
+		return domain.NewValidationError("points", fmt.Sprintf("must not contain more than %d samples", s.maxBatch))
+	}
+
+	now := time.Now().UTC()
+	for i, point := range points {
+		if point.DeviceID != deviceID {
+			return domain.NewValidationError(domain.TelemetryPointField(i)+".device_id", "must be the device the batch is sent for")
+		}
+		if err := point.CheckAge(domain.TelemetryPointField(i), now, s.retention); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Insert(ctx, points); err != nil {
+		if domain.IsNotFoundError(err) {
+			return err
+		}
+		return fmt.Errorf("failed to save telemetry: %w", err)
+	}
+
+	return nil
+}
+
+// Query returns the samples of one metric of a device downsampled into buckets, oldest first
+func (s *TelemetryService) Query(ctx context.Context, query domain.TelemetryQuery) (buckets []domain.TelemetryBucket, err error) {
+	ctx, span := startSpan(ctx, "TelemetryService.Query",
+		deviceIDAttr(query.DeviceID.String()), attribute.String("telemetry.metric", query.Metric))
+	defer func() { endSpan(span, err) }()
+
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.devices.ExistsByID(ctx, query.DeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check device: %w", err)
+	}
+	if !exists {
+		return nil, domain.ErrDeviceNotFound
+	}
+
+	buckets, err = s.repo.Aggregate(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry: %w", err)
+	}
+
+	if buckets == nil {
+		buckets = []domain.TelemetryBucket{}
+	}
+
+	return buckets, nil
+}
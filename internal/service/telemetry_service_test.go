@@ -0,0 +1,209 @@
+package service_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"devices-api/internal/domain"
+	"devices-api/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTelemetryRepository is a mock implementation of domain.TelemetryRepository
+type MockTelemetryRepository struct {
+	mock.Mock
+}
+
+func (m *MockTelemetryRepository) Insert(ctx context.Context, points []domain.TelemetryPoint) error {
+	args := m.Called(ctx, points)
+	return args.Error(0)
+}
+
+func (m *MockTelemetryRepository) Aggregate(ctx context.Context, query domain.TelemetryQuery) ([]domain.TelemetryBucket, error) {
+	args := m.Called(ctx, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.TelemetryBucket), args.Error(1)
+}
+
+func (m *MockTelemetryRepository) DropBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Int(0), args.Error(1)
+}
+
+const testTelemetryRetention = 30 * 24 * time.Hour
+
+// telemetryPoints returns count samples of a device taken a minute apart, ending a minute ago
+func telemetryPoints(t *testing.T, deviceID uuid.UUID, count int) []domain.TelemetryPoint {
+	t.Helper()
+	points := make([]domain.TelemetryPoint, count)
+	for i := range points {
+		ts := time.Now().Add(-time.Duration(count-i) * time.Minute)
+		point, err := domain.NewTelemetryPoint(domain.TelemetryPointField(i), deviceID, "battery", float64(i), ts)
+		require.NoError(t, err)
+		points[i] = point
+	}
+	return points
+}
+
+// ========== Ingest Tests ==========
+
+// TestIngest_Success tests that a valid batch is stored as a whole
+func TestIngest_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTelemetryRepository)
+	svc := service.NewTelemetryService(mockRepo, new(MockDeviceRepository), testTelemetryRetention, 10)
+
+	deviceID := uuid.New()
+	points := telemetryPoints(t, deviceID, 3)
+	mockRepo.On("Insert", mock.Anything, points).Return(nil)
+
+	// Act
+	err := svc.Ingest(context.Background(), deviceID, points)
+
+	// Assert
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestIngest_RejectsBatch tests batches that are rejected before anything is stored
+func TestIngest_RejectsBatch(t *testing.T) {
+	deviceID := uuid.New()
+	expired, err := domain.NewTelemetryPoint(domain.TelemetryPointField(1), deviceID, "battery", 1, time.Now().Add(-31*24*time.Hour))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		points    []domain.TelemetryPoint
+		wantField string
+	}{
+		{"empty batch", nil, "points"},
+		{"too many samples", telemetryPoints(t, deviceID, 11), "points"},
+		{"other device", telemetryPoints(t, uuid.New(), 1), "points[0].device_id"},
+		{"past retention", append(telemetryPoints(t, deviceID, 1), expired), "points[1].ts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockTelemetryRepository)
+			svc := service.NewTelemetryService(mockRepo, new(MockDeviceRepository), testTelemetryRetention, 10)
+
+			// Act
+			err := svc.Ingest(context.Background(), deviceID, tt.points)
+
+			// Assert
+			var validationErr *domain.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.wantField, validationErr.Field)
+			mockRepo.AssertNotCalled(t, "Insert", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestIngest_DeviceNotFound tests that an unknown device is reported as not found
+func TestIngest_DeviceNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTelemetryRepository)
+	svc := service.NewTelemetryService(mockRepo, new(MockDeviceRepository), testTelemetryRetention, 10)
+
+	deviceID := uuid.New()
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(domain.ErrDeviceNotFound)
+
+	// Act
+	err := svc.Ingest(context.Background(), deviceID, telemetryPoints(t, deviceID, 1))
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+}
+
+// ========== Query Tests ==========
+
+// TestQuery_Success tests that buckets are returned for an existing device
+func TestQuery_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTelemetryRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewTelemetryService(mockRepo, mockDevices, testTelemetryRetention, 10)
+
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	query := domain.TelemetryQuery{DeviceID: uuid.New(), Metric: "battery", From: from, To: from.Add(2 * time.Hour), Bucket: time.Hour}
+	buckets := []domain.TelemetryBucket{{Start: from, Min: 70, Max: 90, Avg: 80, Count: 3}}
+	mockDevices.On("ExistsByID", mock.Anything, query.DeviceID).Return(true, nil)
+	mockRepo.On("Aggregate", mock.Anything, query).Return(buckets, nil)
+
+	// Act
+	result, err := svc.Query(context.Background(), query)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, buckets, result)
+}
+
+// TestQuery_NoSamples tests that a device without samples returns an empty list, not nil
+func TestQuery_NoSamples(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTelemetryRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewTelemetryService(mockRepo, mockDevices, testTelemetryRetention, 10)
+
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	query := domain.TelemetryQuery{DeviceID: uuid.New(), Metric: "battery", From: from, To: from.Add(time.Hour), Bucket: time.Minute}
+	mockDevices.On("ExistsByID", mock.Anything, query.DeviceID).Return(true, nil)
+	mockRepo.On("Aggregate", mock.Anything, query).Return(nil, nil)
+
+	// Act
+	result, err := svc.Query(context.Background(), query)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+}
+
+// TestQuery_DeviceNotFound tests querying an unknown device
+func TestQuery_DeviceNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTelemetryRepository)
+	mockDevices := new(MockDeviceRepository)
+	svc := service.NewTelemetryService(mockRepo, mockDevices, testTelemetryRetention, 10)
+
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	query := domain.TelemetryQuery{DeviceID: uuid.New(), Metric: "battery", From: from, To: from.Add(time.Hour), Bucket: time.Minute}
+	mockDevices.On("ExistsByID", mock.Anything, query.DeviceID).Return(false, nil)
+
+	// Act
+	result, err := svc.Query(context.Background(), query)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domain.ErrDeviceNotFound)
+	mockRepo.AssertNotCalled(t, "Aggregate", mock.Anything, mock.Anything)
+}
+
+// ========== TelemetryPruner Tests ==========
+
+// TestTelemetryPruner_Prune_DropsPastRetention tests that telemetry older than the retention period is dropped
+func TestTelemetryPruner_Prune_DropsPastRetention(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockTelemetryRepository)
+	pruner := service.NewTelemetryPruner(mockRepo, testTelemetryRetention, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("DropBefore", mock.Anything, now.Add(-testTelemetryRetention)).Return(2, nil)
+
+	// Act
+	dropped, err := pruner.Prune(context.Background(), now)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, dropped)
+	mockRepo.AssertExpectations(t)
+}
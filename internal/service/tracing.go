@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"devices-api/internal/domain"
+
+	"github.com/google/uuid"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("devices-api/internal/service")
+
+// startSpan starts a span for a service method
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the outcome of a service method and ends its span.
+// Domain errors (not found, validation, business rule) are expected client
+// outcomes, so they are recorded as events without marking the span failed.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !isClientError(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// isClientError reports whether err is caused by the caller rather than the system
+func isClientError(err error) bool {
+	return domain.IsNotFoundError(err) ||
+		domain.IsValidationError(err) ||
+		domain.IsBusinessRuleError(err) ||
+		domain.IsAlreadyExistsError(err) ||
+		errors.Is(err, domain.ErrUnauthorized)
+}
+
+// deviceIDAttr returns the span attribute for a device ID
+func deviceIDAttr(id string) attribute.KeyValue {
+	return attribute.String("device.id", id)
+}
+
+// locationIDAttr returns the span attribute for a location ID
+func locationIDAttr(id string) attribute.KeyValue {
+	return attribute.String("location.id", id)
+}
+
+// brandIDAttr returns the span attribute for a brand ID
+func brandIDAttr(id string) attribute.KeyValue {
+	return attribute.String("brand.id", id)
+}
+
+// modelIDAttr returns the span attribute for a model ID
+func modelIDAttr(id string) attribute.KeyValue {
+	return attribute.String("model.id", id)
+}
+
+// maintenanceIDAttr returns the span attribute for a maintenance ID
+func maintenanceIDAttr(id string) attribute.KeyValue {
+	return attribute.String("maintenance.id", id)
+}
+
+// recordMove adds a device.moved event to the current span.
+// An empty ID means the device had, or now has, no location.
+func recordMove(ctx context.Context, from, to *uuid.UUID) {
+	trace.SpanFromContext(ctx).AddEvent("device.moved", trace.WithAttributes(
+		attribute.String("location.from", optionalID(from)),
+		attribute.String("location.to", optionalID(to)),
+	))
+}
+
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -3,11 +3,12 @@ package testhelper
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"sort"
+	"log/slog"
 	"time"
 
+	"devices-api/internal/migrate"
+	"devices-api/migrations"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
@@ -74,26 +75,15 @@ func (pc *PostgresContainer) GetConnectionString() string {
 	return pc.connStr
 }
 
-// ApplyMigrations applies SQL migration files to the test database
-func (pc *PostgresContainer) ApplyMigrations(ctx context.Context, migrationsPath string) error {
-	files, err := filepath.Glob(filepath.Join(migrationsPath, "*.up.sql"))
+// ApplyMigrations applies the embedded migrations with the same runner used by `migrate up`
+func (pc *PostgresContainer) ApplyMigrations(ctx context.Context) error {
+	runner, err := migrate.NewRunner(pc.pool, migrations.FS, slog.New(slog.DiscardHandler))
 	if err != nil {
-		return fmt.Errorf("failed to find migration files: %w", err)
+		return fmt.Errorf("failed to load migrations: %w", err)
 	}
 
-	// Sort files to ensure migrations run in order
-	sort.Strings(files)
-
-	for _, file := range files {
-		// #nosec G304 - file path is from controlled migrations directory
-		sql, err := os.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read migration file %s: %w", file, err)
-		}
-
-		if _, err := pc.pool.Exec(ctx, string(sql)); err != nil {
-			return fmt.Errorf("failed to apply migration %s: %w", file, err)
-		}
+	if err := runner.Up(ctx); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
 	return nil
@@ -101,7 +91,7 @@ func (pc *PostgresContainer) ApplyMigrations(ctx context.Context, migrationsPath
 
 // Cleanup cleans up the database by truncating all tables
 func (pc *PostgresContainer) Cleanup(ctx context.Context) error {
-	_, err := pc.pool.Exec(ctx, "TRUNCATE TABLE devices CASCADE")
+	_, err := pc.pool.Exec(ctx, "TRUNCATE TABLE devices, device_versions, models, locations, brands CASCADE")
 	return err
 }
 
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_devices_attributes;
+ALTER TABLE devices DROP COLUMN IF EXISTS attributes;
@@ -0,0 +1,7 @@
+-- Custom, type-specific device properties (serial numbers, OS versions, RAM, ...)
+ALTER TABLE devices
+    ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD CONSTRAINT devices_attributes_object CHECK (jsonb_typeof(attributes) = 'object');
+
+-- GIN index serves containment (attr.key=value) and key existence (attr.key) filters
+CREATE INDEX idx_devices_attributes ON devices USING GIN (attributes);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_devices_labels;
+ALTER TABLE devices DROP COLUMN IF EXISTS labels;
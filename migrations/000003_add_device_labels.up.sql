@@ -0,0 +1,7 @@
+-- Key/value labels (team=mobile, env=lab) queried with label selectors
+ALTER TABLE devices
+    ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD CONSTRAINT devices_labels_object CHECK (jsonb_typeof(labels) = 'object');
+
+-- GIN index serves equality (@>) and existence (?) selector requirements
+CREATE INDEX idx_devices_labels ON devices USING GIN (labels);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_devices_location_id;
+ALTER TABLE devices DROP COLUMN IF EXISTS location_id;
+DROP TABLE IF EXISTS locations;
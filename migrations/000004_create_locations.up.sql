@@ -0,0 +1,18 @@
+-- Physical locations: site > building > room
+CREATE TABLE IF NOT EXISTS locations (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('site', 'building', 'room')),
+    parent_id UUID REFERENCES locations(id) ON DELETE RESTRICT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- Only sites are top-level
+    CONSTRAINT locations_parent_required CHECK ((type = 'site') = (parent_id IS NULL))
+);
+
+CREATE INDEX idx_locations_parent_id ON locations(parent_id);
+
+-- Where a device physically lives; locations with devices cannot be deleted
+ALTER TABLE devices
+    ADD COLUMN location_id UUID REFERENCES locations(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_devices_location_id ON devices(location_id);
@@ -0,0 +1,17 @@
+-- Restore the free-text brand column using canonical brand names
+ALTER TABLE devices ADD COLUMN brand VARCHAR(50);
+
+UPDATE devices d
+SET brand = b.name
+FROM brands b
+WHERE b.id = d.brand_id;
+
+ALTER TABLE devices ALTER COLUMN brand SET NOT NULL;
+
+CREATE INDEX idx_devices_brand ON devices(brand);
+
+DROP INDEX IF EXISTS idx_devices_brand_id;
+ALTER TABLE devices DROP COLUMN IF EXISTS brand_id;
+
+DROP TABLE IF EXISTS brand_aliases;
+DROP TABLE IF EXISTS brands;
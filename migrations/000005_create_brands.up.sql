@@ -0,0 +1,44 @@
+-- Brand catalog: one row per brand, matched case-insensitively and ignoring surrounding spaces
+CREATE TABLE IF NOT EXISTS brands (
+    id UUID PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    name_key VARCHAR(50) GENERATED ALWAYS AS (lower(btrim(name))) STORED,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT brands_name_key_unique UNIQUE (name_key)
+);
+
+-- Alternative names that resolve to a brand (e.g. "HP" for "Hewlett-Packard")
+CREATE TABLE IF NOT EXISTS brand_aliases (
+    alias VARCHAR(50) NOT NULL,
+    alias_key VARCHAR(50) GENERATED ALWAYS AS (lower(btrim(alias))) STORED,
+    brand_id UUID NOT NULL REFERENCES brands(id) ON DELETE CASCADE,
+    CONSTRAINT brand_aliases_alias_key_unique UNIQUE (alias_key)
+);
+
+CREATE INDEX idx_brand_aliases_brand_id ON brand_aliases(brand_id);
+
+-- Merge variant spellings ("Apple", "apple", "APPLE ") into one brand,
+-- keeping the most common spelling as the canonical name
+INSERT INTO brands (id, name)
+SELECT gen_random_uuid(), spelling
+FROM (
+    SELECT DISTINCT ON (lower(btrim(brand))) btrim(brand) AS spelling
+    FROM devices
+    GROUP BY lower(btrim(brand)), btrim(brand)
+    ORDER BY lower(btrim(brand)), count(*) DESC, btrim(brand)
+) canonical;
+
+-- Point devices at their brand and drop the free-text column
+ALTER TABLE devices ADD COLUMN brand_id UUID REFERENCES brands(id);
+
+UPDATE devices d
+SET brand_id = b.id
+FROM brands b
+WHERE b.name_key = lower(btrim(d.brand));
+
+ALTER TABLE devices ALTER COLUMN brand_id SET NOT NULL;
+
+CREATE INDEX idx_devices_brand_id ON devices(brand_id);
+
+DROP INDEX IF EXISTS idx_devices_brand;
+ALTER TABLE devices DROP COLUMN brand;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_devices_model_id;
+ALTER TABLE devices DROP COLUMN IF EXISTS model_id;
+
+DROP TABLE IF EXISTS models;
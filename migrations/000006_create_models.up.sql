@@ -0,0 +1,22 @@
+-- Model catalog: products such as "MacBook Pro 14 M3" with default attributes
+CREATE TABLE IF NOT EXISTS models (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    brand_id UUID NOT NULL REFERENCES brands(id) ON DELETE RESTRICT,
+    category VARCHAR(20) NOT NULL CHECK (category IN ('laptop', 'phone', 'tablet', 'sensor')),
+    default_attributes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    -- Expected service life in months; 0 means no lifecycle policy
+    lifecycle_months INTEGER NOT NULL DEFAULT 0 CHECK (lifecycle_months >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT models_default_attributes_object CHECK (jsonb_typeof(default_attributes) = 'object')
+);
+
+-- Model names are unique per brand, ignoring case
+CREATE UNIQUE INDEX idx_models_brand_name ON models(brand_id, lower(btrim(name)));
+CREATE INDEX idx_models_category ON models(category);
+
+-- Optional model reference; models with devices cannot be deleted
+ALTER TABLE devices
+    ADD COLUMN model_id UUID REFERENCES models(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_devices_model_id ON devices(model_id);
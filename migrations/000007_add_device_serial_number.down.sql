@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_devices_brand_serial;
+
+ALTER TABLE devices
+    DROP COLUMN IF EXISTS serial_number;
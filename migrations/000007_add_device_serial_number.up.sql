@@ -0,0 +1,7 @@
+-- Optional manufacturer serial number, unique per brand
+ALTER TABLE devices
+    ADD COLUMN serial_number VARCHAR(64);
+
+-- Partial index: devices without a serial number do not conflict
+CREATE UNIQUE INDEX idx_devices_brand_serial ON devices(brand_id, serial_number)
+    WHERE serial_number IS NOT NULL;
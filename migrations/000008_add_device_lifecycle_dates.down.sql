@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS device_expiry_notifications;
+
+DROP INDEX IF EXISTS idx_devices_eol_date;
+DROP INDEX IF EXISTS idx_devices_warranty_end;
+
+ALTER TABLE devices
+    DROP CONSTRAINT IF EXISTS devices_eol_after_purchase,
+    DROP CONSTRAINT IF EXISTS devices_warranty_after_purchase,
+    DROP COLUMN IF EXISTS eol_date,
+    DROP COLUMN IF EXISTS warranty_end,
+    DROP COLUMN IF EXISTS purchase_date;
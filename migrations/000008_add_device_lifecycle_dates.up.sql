@@ -0,0 +1,22 @@
+-- Purchase, warranty and planned end-of-life dates
+ALTER TABLE devices
+    ADD COLUMN purchase_date DATE,
+    ADD COLUMN warranty_end DATE,
+    ADD COLUMN eol_date DATE,
+    ADD CONSTRAINT devices_warranty_after_purchase CHECK (warranty_end > purchase_date),
+    ADD CONSTRAINT devices_eol_after_purchase CHECK (eol_date > purchase_date);
+
+-- Partial indexes back the expiry report, which only looks at devices with dates
+CREATE INDEX idx_devices_warranty_end ON devices(warranty_end) WHERE warranty_end IS NOT NULL;
+CREATE INDEX idx_devices_eol_date ON devices(eol_date) WHERE eol_date IS NOT NULL;
+
+-- Expiring soon events already emitted, so each device is notified once per
+-- threshold; a new warranty end or EOL date starts over
+CREATE TABLE IF NOT EXISTS device_expiry_notifications (
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('warranty', 'eol')),
+    expires_on DATE NOT NULL,
+    threshold_days INTEGER NOT NULL CHECK (threshold_days > 0),
+    notified_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (device_id, kind, expires_on, threshold_days)
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS maintenance;
+
+-- Devices still under maintenance lose the state; make them inactive
+UPDATE devices SET state = 'inactive' WHERE state = 'maintenance';
+ALTER TABLE devices DROP CONSTRAINT IF EXISTS devices_state_check;
+ALTER TABLE devices
+    ADD CONSTRAINT devices_state_check CHECK (state IN ('active', 'in-use', 'inactive'));
@@ -0,0 +1,33 @@
+-- Devices under maintenance are in the 'maintenance' state until it is completed
+ALTER TABLE devices DROP CONSTRAINT IF EXISTS devices_state_check;
+ALTER TABLE devices
+    ADD CONSTRAINT devices_state_check CHECK (state IN ('active', 'in-use', 'inactive', 'maintenance'));
+
+-- Maintenance records: scheduled, then started, then completed
+CREATE TABLE IF NOT EXISTS maintenance (
+    id UUID PRIMARY KEY,
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('preventive', 'repair', 'inspection', 'upgrade')),
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    vendor VARCHAR(100) NOT NULL DEFAULT '',
+    -- Cost in minor currency units (e.g. cents)
+    cost_cents BIGINT NOT NULL DEFAULT 0 CHECK (cost_cents >= 0),
+    notes TEXT NOT NULL DEFAULT '',
+    -- State the device returns to when the maintenance is completed
+    previous_state VARCHAR(20),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT maintenance_started_before_completed CHECK (completed_at IS NULL OR (started_at IS NOT NULL AND completed_at >= started_at)),
+    CONSTRAINT maintenance_started_with_previous_state CHECK ((started_at IS NULL) = (previous_state IS NULL))
+);
+
+CREATE INDEX idx_maintenance_device_scheduled ON maintenance(device_id, scheduled_at DESC);
+
+-- A device has at most one maintenance in progress
+CREATE UNIQUE INDEX idx_maintenance_in_progress ON maintenance(device_id)
+    WHERE started_at IS NOT NULL AND completed_at IS NULL;
+
+-- Backs the due-for-maintenance query, which looks up the last completed maintenance per device
+CREATE INDEX idx_maintenance_device_completed ON maintenance(device_id, completed_at DESC)
+    WHERE completed_at IS NOT NULL;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS device_heartbeat_tokens;
+
+DROP INDEX IF EXISTS idx_devices_last_seen_at;
+ALTER TABLE devices
+    DROP CONSTRAINT IF EXISTS devices_reported_object,
+    DROP COLUMN IF EXISTS reported,
+    DROP COLUMN IF EXISTS last_seen_at;
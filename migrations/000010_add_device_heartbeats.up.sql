@@ -0,0 +1,15 @@
+-- When the device last reported in, and the fields it reported
+ALTER TABLE devices
+    ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN reported JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ADD CONSTRAINT devices_reported_object CHECK (jsonb_typeof(reported) = 'object');
+
+-- Partial index: the stale check only looks at devices that have reported in
+CREATE INDEX idx_devices_last_seen_at ON devices(last_seen_at) WHERE last_seen_at IS NOT NULL;
+
+-- Credentials devices use to send heartbeats; only a SHA-256 hash of the token is stored
+CREATE TABLE IF NOT EXISTS device_heartbeat_tokens (
+    device_id UUID PRIMARY KEY REFERENCES devices(id) ON DELETE CASCADE,
+    token_hash BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- Dropping the partitioned table drops all of its partitions
+DROP TABLE IF EXISTS telemetry;
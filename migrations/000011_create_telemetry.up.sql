@@ -0,0 +1,12 @@
+-- Metric samples reported by devices, partitioned by day on ts.
+-- Daily partitions (telemetry_pYYYYMMDD) are created by the application as samples arrive
+-- and dropped as a whole once they are past the retention period.
+CREATE TABLE IF NOT EXISTS telemetry (
+    device_id UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    metric VARCHAR(64) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    ts TIMESTAMP WITH TIME ZONE NOT NULL
+) PARTITION BY RANGE (ts);
+
+-- Queries always select one metric of one device over a time range
+CREATE INDEX idx_telemetry_device_metric_ts ON telemetry(device_id, metric, ts);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS devices_version_update ON devices;
+DROP TRIGGER IF EXISTS devices_version_insert_delete ON devices;
+DROP FUNCTION IF EXISTS record_device_version();
+DROP TABLE IF EXISTS device_versions;
@@ -0,0 +1,78 @@
+-- Every version of every device, valid from valid_from until valid_to (NULL while current).
+-- Maintained by triggers on devices, so every write path is recorded. Versions keep no
+-- foreign keys: the history of deleted devices, locations and models stays queryable.
+-- Heartbeat fields (last_seen_at, reported) are not versioned.
+CREATE TABLE IF NOT EXISTS device_versions (
+    version_id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    brand_id UUID NOT NULL,
+    serial_number VARCHAR(64),
+    state VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    attributes JSONB NOT NULL,
+    labels JSONB NOT NULL,
+    location_id UUID,
+    model_id UUID,
+    purchase_date DATE,
+    warranty_end DATE,
+    eol_date DATE,
+    valid_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_to TIMESTAMP WITH TIME ZONE,
+    -- Versions replaced in the transaction that created them are empty and never match
+    CONSTRAINT device_versions_valid_period CHECK (valid_to >= valid_from)
+);
+
+-- At most one current version per device
+CREATE UNIQUE INDEX idx_device_versions_current ON device_versions(id) WHERE valid_to IS NULL;
+
+-- Point-in-time lookups of a single device
+CREATE INDEX idx_device_versions_id_valid_from ON device_versions(id, valid_from DESC);
+
+-- Point-in-time lists and stats: versions whose validity contains a timestamp
+CREATE INDEX idx_device_versions_validity ON device_versions USING GIST (tstzrange(valid_from, valid_to, '[)'));
+
+-- Versions are stamped with the transaction's start time, so all writes of a transaction share it.
+-- A transaction that started before the current version was written closes it at that version's
+-- valid_from instead, leaving it empty, so periods never run backwards.
+CREATE OR REPLACE FUNCTION record_device_version() RETURNS trigger AS $$
+DECLARE
+    closed_at TIMESTAMP WITH TIME ZONE;
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE device_versions SET valid_to = GREATEST(now(), valid_from)
+        WHERE id = OLD.id AND valid_to IS NULL
+        RETURNING valid_to INTO closed_at;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        INSERT INTO device_versions (id, name, brand_id, serial_number, state, created_at, attributes, labels,
+                                     location_id, model_id, purchase_date, warranty_end, eol_date, valid_from)
+        VALUES (NEW.id, NEW.name, NEW.brand_id, NEW.serial_number, NEW.state, NEW.created_at, NEW.attributes, NEW.labels,
+                NEW.location_id, NEW.model_id, NEW.purchase_date, NEW.warranty_end, NEW.eol_date,
+                COALESCE(closed_at, now()));
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER devices_version_insert_delete
+    AFTER INSERT OR DELETE ON devices
+    FOR EACH ROW EXECUTE FUNCTION record_device_version();
+
+-- Heartbeats only touch unversioned fields and must not add versions
+CREATE TRIGGER devices_version_update
+    AFTER UPDATE ON devices
+    FOR EACH ROW
+    WHEN ((OLD.name, OLD.brand_id, OLD.serial_number, OLD.state, OLD.created_at, OLD.attributes, OLD.labels,
+           OLD.location_id, OLD.model_id, OLD.purchase_date, OLD.warranty_end, OLD.eol_date)
+          IS DISTINCT FROM
+          (NEW.name, NEW.brand_id, NEW.serial_number, NEW.state, NEW.created_at, NEW.attributes, NEW.labels,
+           NEW.location_id, NEW.model_id, NEW.purchase_date, NEW.warranty_end, NEW.eol_date))
+    EXECUTE FUNCTION record_device_version();
+
+-- Existing devices start their history in their current state, as of their creation
+INSERT INTO device_versions (id, name, brand_id, serial_number, state, created_at, attributes, labels,
+                             location_id, model_id, purchase_date, warranty_end, eol_date, valid_from)
+SELECT id, name, brand_id, serial_number, state, created_at, attributes, labels,
+       location_id, model_id, purchase_date, warranty_end, eol_date, created_at
+FROM devices;
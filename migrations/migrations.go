@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migration files so they ship inside the binary.
+package migrations
+
+import "embed"
+
+// FS holds every *.up.sql and *.down.sql migration file
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,372 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPageSize is used by Devices when ListOptions.Limit is not set
+const defaultPageSize = 100
+
+// Client is a Devices API client. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retry      RetryPolicy
+	userAgent  string
+	headers    http.Header
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry enables retries with the given policy
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		if policy.MaxAttempts < 1 {
+			policy.MaxAttempts = 1
+		}
+		c.retry = policy
+	}
+}
+
+// WithUserAgent sets the User-Agent header
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHeader adds a header to every request, e.g. for credentials
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers.Add(key, value)
+	}
+}
+
+// WithBearerToken authenticates every request with a bearer token
+func WithBearerToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// New creates a client for the API at baseURL (e.g. "http://localhost:8080")
+func New(baseURL string, opts ...Option) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL: scheme must be http or https, got %q", parsed.Scheme)
+	}
+
+	c := &Client{
+		baseURL:    parsed,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retry:      noRetry,
+		userAgent:  "devices-api-go-client",
+		headers:    make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// CreateDevice creates a new device
+func (c *Client) CreateDevice(ctx context.Context, req CreateDeviceRequest) (*Device, error) {
+	var device Device
+	if err := c.do(ctx, http.MethodPost, "/api/v1/devices", "", req, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// GetDevice retrieves a device by ID
+func (c *Client) GetDevice(ctx context.Context, id string) (*Device, error) {
+	var device Device
+	if err := c.do(ctx, http.MethodGet, devicePath(id), "", nil, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// GetDeviceBySerial retrieves a device by brand (name or alias) and serial number
+func (c *Client) GetDeviceBySerial(ctx context.Context, brand, serialNumber string) (*Device, error) {
+	var device Device
+	path := "/api/v1/devices/by-serial/" + url.PathEscape(brand) + "/" + url.PathEscape(serialNumber)
+	if err := c.do(ctx, http.MethodGet, path, "", nil, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// ListDevices retrieves a single page of devices
+func (c *Client) ListDevices(ctx context.Context, opts ListOptions) (*DeviceList, error) {
+	var list DeviceList
+	if err := c.do(ctx, http.MethodGet, "/api/v1/devices", opts.query(), nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// Devices iterates over every device matching opts, fetching pages lazily.
+// Iteration stops after the first error, which is yielded to the caller.
+func (c *Client) Devices(ctx context.Context, opts ListOptions) iter.Seq2[Device, error] {
+	return func(yield func(Device, error) bool) {
+		if opts.Limit <= 0 {
+			opts.Limit = defaultPageSize
+		}
+
+		for {
+			page, err := c.ListDevices(ctx, opts)
+			if err != nil {
+				yield(Device{}, err)
+				return
+			}
+
+			for _, device := range page.Devices {
+				if !yield(device, nil) {
+					return
+				}
+			}
+
+			if len(page.Devices) < opts.Limit {
+				return
+			}
+			opts.Offset += len(page.Devices)
+		}
+	}
+}
+
+// UpdateDevice fully replaces a device's name, brand and state
+func (c *Client) UpdateDevice(ctx context.Context, id string, req UpdateDeviceRequest) (*Device, error) {
+	var device Device
+	if err := c.do(ctx, http.MethodPut, devicePath(id), "", req, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// PatchDevice updates only the fields set in req
+func (c *Client) PatchDevice(ctx context.Context, id string, req PatchDeviceRequest) (*Device, error) {
+	var device Device
+	if err := c.do(ctx, http.MethodPatch, devicePath(id), "", req, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// DeleteDevice deletes a device
+func (c *Client) DeleteDevice(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, devicePath(id), "", nil, nil)
+}
+
+// GetLabels returns the labels of a device
+func (c *Client) GetLabels(ctx context.Context, id string) (map[string]string, error) {
+	var resp labelsResponse
+	if err := c.do(ctx, http.MethodGet, labelsPath(id), "", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Labels, nil
+}
+
+// SetLabels replaces all labels of a device
+func (c *Client) SetLabels(ctx context.Context, id string, labels map[string]string) (map[string]string, error) {
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	var resp labelsResponse
+	if err := c.do(ctx, http.MethodPut, labelsPath(id), "", labels, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Labels, nil
+}
+
+// PatchLabels merges changes into the labels of a device; a nil value removes the label
+func (c *Client) PatchLabels(ctx context.Context, id string, changes map[string]*string) (map[string]string, error) {
+	var resp labelsResponse
+	if err := c.do(ctx, http.MethodPatch, labelsPath(id), "", changes, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Labels, nil
+}
+
+// DeleteLabel removes a single label from a device
+func (c *Client) DeleteLabel(ctx context.Context, id, key string) error {
+	return c.do(ctx, http.MethodDelete, labelsPath(id)+"/"+key, "", nil, nil)
+}
+
+// do sends a request, retrying according to the retry policy, and decodes the
+// JSON response into out (when non-nil)
+func (c *Client) do(ctx context.Context, method, path, rawQuery string, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	endpoint := c.baseURL.JoinPath(path)
+	endpoint.RawQuery = rawQuery
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.send(ctx, method, endpoint.String(), body)
+		if err != nil {
+			if ctx.Err() != nil || !isIdempotent(method) || attempt >= c.retry.MaxAttempts {
+				return err
+			}
+			if err := c.wait(ctx, c.retry.backoff(attempt)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if shouldRetry(method, resp.StatusCode) && attempt < c.retry.MaxAttempts {
+			delay, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if !ok {
+				delay = c.retry.backoff(attempt)
+			}
+			drain(resp)
+			if err := c.wait(ctx, delay); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return decodeResponse(resp, out)
+	}
+}
+
+// send performs a single HTTP round trip
+func (c *Client) send(ctx context.Context, method, endpoint string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request %s %s failed: %w", method, req.URL.Path, err)
+	}
+	return resp, nil
+}
+
+// wait sleeps for delay unless ctx is cancelled first
+func (c *Client) wait(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// decodeResponse decodes a successful response into out or converts an error
+// response into a typed error
+func decodeResponse(resp *http.Response, out any) error {
+	defer drain(resp)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := APIError{StatusCode: resp.StatusCode}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Code == "" {
+			apiErr.Code = strings.ToLower(strings.ReplaceAll(http.StatusText(resp.StatusCode), " ", "_"))
+		}
+		return newError(apiErr)
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// drain discards the rest of the body so the connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	_ = resp.Body.Close()
+}
+
+// devicePath returns the path of a single device
+func devicePath(id string) string {
+	return "/api/v1/devices/" + url.PathEscape(id)
+}
+
+// labelsPath returns the path of a device's labels
+func labelsPath(id string) string {
+	return devicePath(id) + "/labels"
+}
+
+// query encodes the list options as a URL query string.
+// Attribute filters are appended as escaped attr.EXPR terms because operators
+// such as >= do not fit the key=value form of url.Values.
+func (o ListOptions) query() string {
+	query := url.Values{}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		query.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Brand != "" {
+		query.Set("brand", o.Brand)
+	}
+	if o.State != "" {
+		query.Set("state", o.State)
+	}
+	if o.Selector != "" {
+		query.Set("selector", o.Selector)
+	}
+	if o.LocationID != "" {
+		query.Set("location_id", o.LocationID)
+	}
+	if o.ModelID != "" {
+		query.Set("model_id", o.ModelID)
+	}
+	if o.Category != "" {
+		query.Set("category", o.Category)
+	}
+
+	terms := []string{query.Encode()}
+	for _, expr := range o.Attributes {
+		terms = append(terms, url.QueryEscape("attr."+expr))
+	}
+	return strings.Trim(strings.Join(terms, "&"), "&")
+}
@@ -0,0 +1,362 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"devices-api/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetry keeps retry tests quick
+var fastRetry = client.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...client.Option) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := client.New(server.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	_, err := client.New("localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestCreateDevice_Success(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/devices", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var req client.CreateDeviceRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "iPhone 15", req.Name)
+
+		writeJSON(w, http.StatusCreated, client.Device{ID: "abc", Name: req.Name, Brand: req.Brand, State: client.StateActive})
+	})
+
+	device, err := c.CreateDevice(context.Background(), client.CreateDeviceRequest{Name: "iPhone 15", Brand: "Apple"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "abc", device.ID)
+	assert.Equal(t, client.StateActive, device.State)
+}
+
+func TestGetDevice_NotFound(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not_found", "message": "device not found"})
+	})
+
+	device, err := c.GetDevice(context.Background(), "missing")
+
+	assert.Nil(t, device)
+	assert.True(t, client.IsNotFoundError(err))
+	var notFound *client.NotFoundError
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, http.StatusNotFound, notFound.StatusCode)
+	assert.Equal(t, "device not found", notFound.Message)
+}
+
+func TestCreateDevice_ValidationErrorWithField(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error": "validation_error", "message": "must be at least 3 characters", "field": "name",
+		})
+	})
+
+	_, err := c.CreateDevice(context.Background(), client.CreateDeviceRequest{Name: "ab", Brand: "Apple"})
+
+	var validationErr *client.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "name", validationErr.Field)
+	assert.Contains(t, err.Error(), "field 'name'")
+}
+
+func TestDeleteDevice_BusinessRuleError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{
+			"error": "business_rule_violation", "message": "cannot delete device in 'in-use' state",
+		})
+	})
+
+	err := c.DeleteDevice(context.Background(), "abc")
+
+	assert.True(t, client.IsBusinessRuleError(err))
+	assert.False(t, client.IsNotFoundError(err))
+}
+
+func TestCreateDevice_ConflictError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusConflict, map[string]string{
+			"error": "conflict", "message": "a device with this serial number already exists for the brand", "field": "serial_number",
+		})
+	})
+
+	_, err := c.CreateDevice(context.Background(), client.CreateDeviceRequest{Name: "MacBook Pro", Brand: "Apple", SerialNumber: "SN-1"})
+
+	var conflictErr *client.ConflictError
+	require.True(t, errors.As(err, &conflictErr))
+	assert.Equal(t, "serial_number", conflictErr.Field)
+	assert.True(t, client.IsConflictError(err))
+}
+
+func TestGetDeviceBySerial_EscapesPath(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/devices/by-serial/Hewlett%20Packard/SN-1", r.URL.EscapedPath())
+		writeJSON(w, http.StatusOK, client.Device{ID: "abc", SerialNumber: "SN-1"})
+	})
+
+	device, err := c.GetDeviceBySerial(context.Background(), "Hewlett Packard", "SN-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "SN-1", device.SerialNumber)
+}
+
+func TestDeleteDevice_NoContent(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	assert.NoError(t, c.DeleteDevice(context.Background(), "abc"))
+}
+
+func TestPatchDevice_SendsOnlySetFields(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{"state": "in-use"}, body)
+
+		writeJSON(w, http.StatusOK, client.Device{ID: "abc", State: client.StateInUse})
+	})
+
+	device, err := c.PatchDevice(context.Background(), "abc", client.PatchDeviceRequest{State: client.String(client.StateInUse)})
+
+	require.NoError(t, err)
+	assert.Equal(t, client.StateInUse, device.State)
+}
+
+func TestListDevices_SendsFilters(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "5", r.URL.Query().Get("limit"))
+		assert.Equal(t, "10", r.URL.Query().Get("offset"))
+		assert.Equal(t, "Apple", r.URL.Query().Get("brand"))
+		assert.Equal(t, "active", r.URL.Query().Get("state"))
+		assert.Equal(t, "loc-1", r.URL.Query().Get("location_id"))
+		assert.Equal(t, "model-1", r.URL.Query().Get("model_id"))
+		assert.Equal(t, "phone", r.URL.Query().Get("category"))
+
+		writeJSON(w, http.StatusOK, client.DeviceList{Limit: 5, Offset: 10})
+	})
+
+	list, err := c.ListDevices(context.Background(), client.ListOptions{
+		Limit: 5, Offset: 10, Brand: "Apple", State: "active", LocationID: "loc-1",
+		ModelID: "model-1", Category: "phone",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, list.Limit)
+}
+
+func TestListDevices_SendsAttributeFilters(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "brand=Apple&attr.os%3Dios&attr.ram_gb%3E%3D16", r.URL.RawQuery)
+
+		writeJSON(w, http.StatusOK, client.DeviceList{})
+	})
+
+	_, err := c.ListDevices(context.Background(), client.ListOptions{
+		Brand:      "Apple",
+		Attributes: []string{"os=ios", "ram_gb>=16"},
+	})
+
+	require.NoError(t, err)
+}
+
+func TestListDevices_SendsSelector(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "team=mobile,env in (lab,staging)", r.URL.Query().Get("selector"))
+
+		writeJSON(w, http.StatusOK, client.DeviceList{})
+	})
+
+	_, err := c.ListDevices(context.Background(), client.ListOptions{Selector: "team=mobile,env in (lab,staging)"})
+
+	require.NoError(t, err)
+}
+
+func TestPatchLabels_SendsNullForRemovals(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		assert.Equal(t, "/api/v1/devices/abc/labels", r.URL.Path)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]any{"team": "mobile", "env": nil}, body)
+
+		writeJSON(w, http.StatusOK, map[string]any{"labels": map[string]string{"team": "mobile"}})
+	})
+
+	labels, err := c.PatchLabels(context.Background(), "abc", map[string]*string{"team": client.String("mobile"), "env": nil})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "mobile"}, labels)
+}
+
+func TestDeleteLabel_PrefixedKey(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/api/v1/devices/abc/labels/example.com/env", r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	assert.NoError(t, c.DeleteLabel(context.Background(), "abc", "example.com/env"))
+}
+
+func TestDevices_IteratesAllPages(t *testing.T) {
+	const total = 7
+	var requests atomic.Int32
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+		var devices []client.Device
+		for i := offset; i < total && i < offset+limit; i++ {
+			devices = append(devices, client.Device{ID: strconv.Itoa(i)})
+		}
+		writeJSON(w, http.StatusOK, client.DeviceList{Devices: devices, Total: len(devices), Limit: limit, Offset: offset})
+	})
+
+	var ids []string
+	for device, err := range c.Devices(context.Background(), client.ListOptions{Limit: 3}) {
+		require.NoError(t, err)
+		ids = append(ids, device.ID)
+	}
+
+	assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6"}, ids)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestDevices_StopsOnError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation_error", "field": "state"})
+	})
+
+	var errs []error
+	for _, err := range c.Devices(context.Background(), client.ListOptions{State: "bogus"}) {
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	assert.True(t, client.IsValidationError(errs[0]))
+}
+
+func TestRetry_ServerErrorThenSuccess(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "unavailable"})
+			return
+		}
+		writeJSON(w, http.StatusOK, client.Device{ID: "abc"})
+	}, client.WithRetry(fastRetry))
+
+	device, err := c.GetDevice(context.Background(), "abc")
+
+	require.NoError(t, err)
+	assert.Equal(t, "abc", device.ID)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal_error"})
+	}, client.WithRetry(fastRetry))
+
+	_, err := c.GetDevice(context.Background(), "abc")
+
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestRetry_TooManyRequestsHonorsRetryAfter(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		writeJSON(w, http.StatusCreated, client.Device{ID: "abc"})
+	}, client.WithRetry(fastRetry))
+
+	_, err := c.CreateDevice(context.Background(), client.CreateDeviceRequest{Name: "iPhone 15", Brand: "Apple"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestRetry_PostNotRetriedOnServerError(t *testing.T) {
+	var attempts atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal_error"})
+	}, client.WithRetry(fastRetry))
+
+	_, err := c.CreateDevice(context.Background(), client.CreateDeviceRequest{Name: "iPhone 15", Brand: "Apple"})
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestContextCancellation_StopsRetrying(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, client.WithRetry(fastRetry))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.GetDevice(ctx, "abc")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestWithBearerToken_SetsAuthorizationHeader(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		writeJSON(w, http.StatusOK, client.Device{ID: "abc"})
+	}, client.WithBearerToken("secret"))
+
+	_, err := c.GetDevice(context.Background(), "abc")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,23 @@
+// Package client is the official Go client for the Devices API.
+//
+// Create a client and call device operations with a context:
+//
+//	c, err := client.New("http://localhost:8080", client.WithRetry(client.DefaultRetryPolicy))
+//	if err != nil {
+//		return err
+//	}
+//
+//	device, err := c.CreateDevice(ctx, client.CreateDeviceRequest{Name: "iPhone 15", Brand: "Apple"})
+//	if client.IsValidationError(err) {
+//		// inspect err.(*client.ValidationError).Field
+//	}
+//
+// Use Devices to iterate over every page of ListDevices:
+//
+//	for device, err := range c.Devices(ctx, client.ListOptions{Brand: "Apple"}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(device.Name)
+//	}
+package client
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// APIError is an error response returned by the API.
+// It mirrors the server's ErrorResponse shape.
+type APIError struct {
+	StatusCode int
+	Code       string `json:"error"`
+	Message    string `json:"message"`
+	Field      string `json:"field"`
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("devices api: %d %s", e.StatusCode, e.Code)
+	}
+	return fmt.Sprintf("devices api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// NotFoundError is returned when the requested device does not exist (404)
+type NotFoundError struct {
+	APIError
+}
+
+// ValidationError is returned when the request is invalid (400).
+// Field names the offending field when the server reports one.
+type ValidationError struct {
+	APIError
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.APIError.Error()
+	}
+	return fmt.Sprintf("devices api: validation error on field '%s': %s", e.Field, e.Message)
+}
+
+// BusinessRuleError is returned when a business rule blocks the operation (422),
+// e.g. deleting a device that is in use
+type BusinessRuleError struct {
+	APIError
+}
+
+// ConflictError is returned when a unique field is already taken (409),
+// e.g. a serial number already used by another device of the brand.
+// Field names the conflicting field.
+type ConflictError struct {
+	APIError
+}
+
+// newError converts an error response into the matching typed error
+func newError(apiErr APIError) error {
+	switch apiErr.Code {
+	case "not_found":
+		return &NotFoundError{APIError: apiErr}
+	case "validation_error", "invalid_id":
+		return &ValidationError{APIError: apiErr}
+	case "business_rule_violation":
+		return &BusinessRuleError{APIError: apiErr}
+	case "conflict":
+		return &ConflictError{APIError: apiErr}
+	default:
+		return &apiErr
+	}
+}
+
+// IsNotFoundError reports whether err is a NotFoundError
+func IsNotFoundError(err error) bool {
+	var target *NotFoundError
+	return errors.As(err, &target)
+}
+
+// IsValidationError reports whether err is a ValidationError
+func IsValidationError(err error) bool {
+	var target *ValidationError
+	return errors.As(err, &target)
+}
+
+// IsBusinessRuleError reports whether err is a BusinessRuleError
+func IsBusinessRuleError(err error) bool {
+	var target *BusinessRuleError
+	return errors.As(err, &target)
+}
+
+// IsConflictError reports whether err is a ConflictError
+func IsConflictError(err error) bool {
+	var target *ConflictError
+	return errors.As(err, &target)
+}
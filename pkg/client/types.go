@@ -0,0 +1,123 @@
+package client
+
+import "time"
+
+// Device states
+const (
+	StateActive   = "active"
+	StateInUse    = "in-use"
+	StateInactive = "inactive"
+	// StateMaintenance is set by the API while a maintenance is in progress
+	StateMaintenance = "maintenance"
+)
+
+// Device is a device returned by the API
+type Device struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Brand        string            `json:"brand"`
+	BrandID      string            `json:"brand_id,omitempty"`
+	SerialNumber string            `json:"serial_number,omitempty"`
+	State        string            `json:"state"`
+	CreatedAt    time.Time         `json:"created_at"`
+	Attributes   map[string]any    `json:"attributes,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	LocationID   *string           `json:"location_id,omitempty"`
+	ModelID      *string           `json:"model_id,omitempty"`
+	Category     string            `json:"category,omitempty"`
+	PurchaseDate string            `json:"purchase_date,omitempty"`
+	WarrantyEnd  string            `json:"warranty_end,omitempty"`
+	EOLDate      string            `json:"eol_date,omitempty"`
+	LastSeenAt   *time.Time        `json:"last_seen_at,omitempty"`
+	Reported     map[string]any    `json:"reported,omitempty"`
+}
+
+// DeviceList is a single page of devices
+type DeviceList struct {
+	Devices []Device `json:"devices"`
+	Total   int      `json:"total"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
+}
+
+// CreateDeviceRequest is the payload for CreateDevice.
+// With ModelID the device starts with the model's default attributes,
+// and an empty Brand defaults to the model's brand.
+// SerialNumber must be unique per brand; a duplicate is reported as a ConflictError.
+// Dates use the YYYY-MM-DD format.
+type CreateDeviceRequest struct {
+	Name         string            `json:"name"`
+	Brand        string            `json:"brand,omitempty"`
+	SerialNumber string            `json:"serial_number,omitempty"`
+	Attributes   map[string]any    `json:"attributes,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	LocationID   *string           `json:"location_id,omitempty"`
+	ModelID      *string           `json:"model_id,omitempty"`
+	PurchaseDate string            `json:"purchase_date,omitempty"`
+	WarrantyEnd  string            `json:"warranty_end,omitempty"`
+	EOLDate      string            `json:"eol_date,omitempty"`
+}
+
+// UpdateDeviceRequest is the payload for UpdateDevice (name, brand and state required).
+// Serial number, dates, attributes and labels replace the existing ones when set and are kept when nil.
+type UpdateDeviceRequest struct {
+	Name         string            `json:"name"`
+	Brand        string            `json:"brand"`
+	State        string            `json:"state"`
+	SerialNumber *string           `json:"serial_number,omitempty"`
+	Attributes   map[string]any    `json:"attributes,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	LocationID   *string           `json:"location_id,omitempty"`
+	ModelID      *string           `json:"model_id,omitempty"`
+	PurchaseDate *string           `json:"purchase_date,omitempty"`
+	WarrantyEnd  *string           `json:"warranty_end,omitempty"`
+	EOLDate      *string           `json:"eol_date,omitempty"`
+}
+
+// PatchDeviceRequest is the payload for PatchDevice (nil fields are left unchanged).
+// Attributes and labels are merged into the existing ones; a nil value removes a key.
+// LocationID moves the device to another location, and ModelID references another model.
+// An empty SerialNumber or date removes it.
+type PatchDeviceRequest struct {
+	Name         *string            `json:"name,omitempty"`
+	Brand        *string            `json:"brand,omitempty"`
+	State        *string            `json:"state,omitempty"`
+	SerialNumber *string            `json:"serial_number,omitempty"`
+	Attributes   map[string]any     `json:"attributes,omitempty"`
+	Labels       map[string]*string `json:"labels,omitempty"`
+	LocationID   *string            `json:"location_id,omitempty"`
+	ModelID      *string            `json:"model_id,omitempty"`
+	PurchaseDate *string            `json:"purchase_date,omitempty"`
+	WarrantyEnd  *string            `json:"warranty_end,omitempty"`
+	EOLDate      *string            `json:"eol_date,omitempty"`
+}
+
+// labelsResponse is the body returned by the label endpoints
+type labelsResponse struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// ListOptions filters and paginates ListDevices.
+// Zero values are omitted and the server defaults apply.
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Brand  string
+	State  string
+	// Attributes are attribute filter expressions such as "os=ios",
+	// "ram_gb>=16" or "warranty" (key exists); all must match
+	Attributes []string
+	// Selector is a label selector such as "team=mobile,env in (lab,staging)"
+	Selector string
+	// LocationID matches devices in the location or any location below it
+	LocationID string
+	// ModelID matches devices of a catalog model
+	ModelID string
+	// Category matches devices whose model has the category (laptop, phone, tablet, sensor)
+	Category string
+}
+
+// String returns a pointer to s, for building PatchDeviceRequest values
+func String(s string) *string {
+	return &s
+}
@@ -5,23 +5,56 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// PoolOption customizes the connection pool configuration
+type PoolOption func(*pgxpool.Config)
+
+// WithQueryTracer attaches a pgx query tracer to every connection in the pool
+func WithQueryTracer(tracer pgx.QueryTracer) PoolOption {
+	return func(config *pgxpool.Config) {
+		config.ConnConfig.Tracer = tracer
+	}
+}
+
+// PoolConfig holds connection pool tuning; zero fields keep the pool defaults,
+// except MinConns, where zero means no connections are kept open when idle
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+}
+
+// WithPoolConfig overrides the default pool sizing and connection lifetimes
+func WithPoolConfig(pc PoolConfig) PoolOption {
+	return func(config *pgxpool.Config) {
+		if pc.MaxConns > 0 {
+			config.MaxConns = pc.MaxConns
+		}
+		config.MinConns = pc.MinConns
+		if pc.MaxConnLifetime > 0 {
+			config.MaxConnLifetime = pc.MaxConnLifetime
+		}
+		if pc.MaxConnIdleTime > 0 {
+			config.MaxConnIdleTime = pc.MaxConnIdleTime
+		}
+		if pc.HealthCheckPeriod > 0 {
+			config.HealthCheckPeriod = pc.HealthCheckPeriod
+		}
+	}
+}
+
 // NewPostgresPool creates a new PostgreSQL connection pool
-func NewPostgresPool(ctx context.Context, dbURL string) (*pgxpool.Pool, error) {
-	config, err := pgxpool.ParseConfig(dbURL)
+func NewPostgresPool(ctx context.Context, dbURL string, opts ...PoolOption) (*pgxpool.Pool, error) {
+	config, err := poolConfig(dbURL, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+		return nil, err
 	}
 
-	// Configure connection pool
-	config.MaxConns = 25
-	config.MinConns = 5
-	config.MaxConnLifetime = time.Hour
-	config.MaxConnIdleTime = 30 * time.Minute
-	config.HealthCheckPeriod = time.Minute
-
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
@@ -35,3 +68,24 @@ func NewPostgresPool(ctx context.Context, dbURL string) (*pgxpool.Pool, error) {
 
 	return pool, nil
 }
+
+// poolConfig parses dbURL and applies the pool defaults and opts
+func poolConfig(dbURL string, opts []PoolOption) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse database URL: %w", err)
+	}
+
+	// Configure connection pool defaults (overridable via WithPoolConfig)
+	config.MaxConns = 25
+	config.MinConns = 5
+	config.MaxConnLifetime = time.Hour
+	config.MaxConnIdleTime = 30 * time.Minute
+	config.HealthCheckPeriod = time.Minute
+
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config, nil
+}
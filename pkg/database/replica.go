@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaLagQuery reports how far a standby's replay is behind the primary, and 0
+// for a server that is not a standby. A standby that is streaming and has replayed
+// everything it received is not lagging, even when the primary has been idle
+// since the last replayed transaction.
+const replicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() THEN 0
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn()
+			AND EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming') THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END
+`
+
+// Replica is a read replica that serves queries only while it is reachable and
+// no more than maxStaleness behind the primary. Until the first successful
+// check, and after any failed one, callers are expected to use the primary.
+type Replica struct {
+	pool         *pgxpool.Pool
+	maxStaleness time.Duration
+	healthy      atomic.Bool
+}
+
+// NewReplica creates the connection pool of a read replica. Unlike
+// NewPostgresPool it does not connect: an unreachable replica fails its
+// health checks rather than startup.
+func NewReplica(dbURL string, maxStaleness time.Duration, opts ...PoolOption) (*Replica, error) {
+	config, err := poolConfig(dbURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// The context only bounds pool creation, which does not connect here
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replica connection pool: %w", err)
+	}
+
+	return &Replica{
+		pool:         pool,
+		maxStaleness: maxStaleness,
+	}, nil
+}
+
+// Pool returns the replica pool and true while the replica is healthy.
+// A nil Replica is never healthy, so callers without a replica need no special case.
+func (r *Replica) Pool() (*pgxpool.Pool, bool) {
+	if r == nil || !r.healthy.Load() {
+		return nil, false
+	}
+	return r.pool, true
+}
+
+// MarkUnhealthy stops routing queries to the replica until the next successful check
+func (r *Replica) MarkUnhealthy() {
+	r.healthy.Store(false)
+}
+
+// Check measures the replication lag and marks the replica healthy when it is
+// reachable and within maxStaleness, and unhealthy otherwise
+func (r *Replica) Check(ctx context.Context) (lag time.Duration, err error) {
+	defer func() { r.healthy.Store(err == nil) }()
+
+	var seconds *float64
+	if err := r.pool.QueryRow(ctx, replicaLagQuery).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("failed to check replica: %w", err)
+	}
+
+	return replicationLag(seconds, r.maxStaleness)
+}
+
+// Run checks the replica immediately and then every interval until ctx is cancelled,
+// logging whenever it starts or stops serving queries
+func (r *Replica) Run(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	checked, wasHealthy := false, false
+	for {
+		lag, err := r.Check(ctx)
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		healthy := err == nil
+		if !checked || healthy != wasHealthy {
+			if healthy {
+				logger.Info("Read replica healthy, serving reads", "lag", lag)
+			} else {
+				logger.Warn("Read replica unhealthy, reads use the primary", "error", err)
+			}
+		}
+		checked, wasHealthy = true, healthy
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close closes the replica connection pool
+func (r *Replica) Close() {
+	r.pool.Close()
+}
+
+// replicationLag converts the lag reported by replicaLagQuery and checks it
+// against maxStaleness. An unknown lag, as on a standby that has not replayed
+// anything yet, counts as too stale.
+func replicationLag(seconds *float64, maxStaleness time.Duration) (time.Duration, error) {
+	if seconds == nil {
+		return 0, errors.New("replica has not replayed any transaction yet")
+	}
+
+	lag := time.Duration(math.Max(*seconds, 0) * float64(time.Second))
+	if lag > maxStaleness {
+		return lag, fmt.Errorf("replica is %s behind, more than %s", lag.Round(time.Millisecond), maxStaleness)
+	}
+	return lag, nil
+}
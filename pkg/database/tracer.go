@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"devices-api/pkg/logging"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "devices-api/pkg/database"
+
+// operationKey stores the SQL operation between TraceQueryStart and TraceQueryEnd
+type operationKey struct{}
+
+// QueryTracer implements pgx.QueryTracer and records one span per query
+type QueryTracer struct {
+	tracer trace.Tracer
+}
+
+// NewQueryTracer creates a query tracer backed by the global tracer provider
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{
+		tracer: otel.Tracer(tracerName),
+	}
+}
+
+// TraceQueryStart starts a span for the query
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	statement := normalizeSQL(data.SQL)
+	operation := sqlOperation(statement)
+
+	ctx, _ = t.tracer.Start(ctx, "postgres "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(statement),
+			attribute.Int("db.query.args", len(data.Args)),
+		),
+	)
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// TraceQueryEnd records the row count or error, then ends the span.
+// Failed queries are also logged with the request-scoped logger.
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		logging.FromContext(ctx).Warn("Database query failed",
+			"operation", ctx.Value(operationKey{}),
+			"error", data.Err,
+		)
+		return
+	}
+
+	span.SetAttributes(semconv.DBResponseReturnedRows(int(data.CommandTag.RowsAffected())))
+}
+
+// normalizeSQL collapses whitespace so multi-line queries read well in traces
+func normalizeSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+// sqlOperation returns the leading SQL keyword (SELECT, INSERT, ...)
+func sqlOperation(statement string) string {
+	operation, _, _ := strings.Cut(statement, " ")
+	if operation == "" {
+		return "QUERY"
+	}
+	return strings.ToUpper(operation)
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestTracer() (*QueryTracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return &QueryTracer{tracer: provider.Tracer(tracerName)}, recorder
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestQueryTracer_RecordsStatementAndRowCount(t *testing.T) {
+	tracer, recorder := newTestTracer()
+	ctx := context.Background()
+
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+		SQL:  "\n\t\tUPDATE devices\n\t\tSET name = $2\n\t\tWHERE id = $1\n\t",
+		Args: []any{"id", "name"},
+	})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{
+		CommandTag: pgconn.NewCommandTag("UPDATE 1"),
+	})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "postgres UPDATE", spans[0].Name())
+
+	attrs := spanAttributes(spans[0])
+	assert.Equal(t, "UPDATE devices SET name = $2 WHERE id = $1", attrs["db.query.text"].AsString())
+	assert.Equal(t, "UPDATE", attrs["db.operation.name"].AsString())
+	assert.Equal(t, int64(1), attrs["db.response.returned_rows"].AsInt64())
+	assert.Equal(t, int64(2), attrs["db.query.args"].AsInt64())
+}
+
+func TestQueryTracer_RecordsError(t *testing.T) {
+	tracer, recorder := newTestTracer()
+	ctx := context.Background()
+
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: errors.New("connection reset")})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "connection reset", spans[0].Status().Description)
+}
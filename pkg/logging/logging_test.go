@@ -0,0 +1,37 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"devices-api/pkg/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromContext_ReturnsStoredLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil)).With("request_id", "req-123")
+
+	ctx := logging.WithLogger(context.Background(), logger)
+	logging.FromContext(ctx).Info("hello")
+
+	assert.Contains(t, buf.String(), `"request_id":"req-123"`)
+}
+
+func TestFromContext_FallsBackToDefault(t *testing.T) {
+	assert.Same(t, slog.Default(), logging.FromContext(context.Background()))
+}
+
+func TestNew_AppliesLevelAndFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logging.New(&buf, "warn", "text")
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "level=WARN msg=kept")
+}
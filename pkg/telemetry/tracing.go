@@ -0,0 +1,132 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// Supported trace exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Supported OTLP transport protocols
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+// TracingOptions configures the tracer provider
+type TracingOptions struct {
+	ServiceName  string
+	Exporter     string
+	OTLPEndpoint string
+	OTLPProtocol string
+	OTLPInsecure bool
+	SampleRatio  float64
+}
+
+// ShutdownFunc flushes and stops the tracer provider
+type ShutdownFunc func(ctx context.Context) error
+
+// SetupTracing installs the global tracer provider and W3C propagators.
+// Propagators are always installed so that inbound trace context is forwarded
+// even when no exporter is configured.
+func SetupTracing(ctx context.Context, opts TracingOptions) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporterName := strings.ToLower(strings.TrimSpace(opts.Exporter))
+	if exporterName == "" || exporterName == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := newExporter(ctx, exporterName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(opts.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// newExporter creates the span exporter for the given name
+func newExporter(ctx context.Context, name string, opts TracingOptions) (sdktrace.SpanExporter, error) {
+	switch name {
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		return exporter, nil
+	case ExporterOTLP:
+		return newOTLPExporter(ctx, opts)
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter: %s (must be: none, stdout, or otlp)", name)
+	}
+}
+
+// newOTLPExporter creates an OTLP exporter over gRPC or HTTP.
+// When no endpoint is given the exporter falls back to the standard
+// OTEL_EXPORTER_OTLP_* environment variables.
+func newOTLPExporter(ctx context.Context, opts TracingOptions) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(opts.OTLPProtocol) {
+	case "", ProtocolGRPC:
+		var grpcOpts []otlptracegrpc.Option
+		if opts.OTLPEndpoint != "" {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithEndpointURL(opts.OTLPEndpoint))
+		}
+		if opts.OTLPInsecure {
+			grpcOpts = append(grpcOpts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err := otlptracegrpc.New(ctx, grpcOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP gRPC exporter: %w", err)
+		}
+		return exporter, nil
+	case ProtocolHTTP, "http":
+		var httpOpts []otlptracehttp.Option
+		if opts.OTLPEndpoint != "" {
+			httpOpts = append(httpOpts, otlptracehttp.WithEndpointURL(opts.OTLPEndpoint))
+		}
+		if opts.OTLPInsecure {
+			httpOpts = append(httpOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, httpOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP HTTP exporter: %w", err)
+		}
+		return exporter, nil
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol: %s (must be: grpc or http/protobuf)", opts.OTLPProtocol)
+	}
+}